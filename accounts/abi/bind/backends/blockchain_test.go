@@ -320,6 +320,9 @@ func initBackendForFiltererTests(t *testing.T, bc *blockchain.BlockChain) *Block
 	subscribeNewTxsEvent := func(ch chan<- blockchain.NewTxsEvent) klaytn.Subscription {
 		return txPool.SubscribeNewTxsEvent(ch)
 	}
+	subscribeDroppedTxsEvent := func(ch chan<- blockchain.DroppedTxsEvent) klaytn.Subscription {
+		return txPool.SubscribeDroppedTxsEvent(ch)
+	}
 	subscribeLogsEvent := func(ch chan<- []*types.Log) klaytn.Subscription {
 		return bc.SubscribeLogsEvent(ch)
 	}
@@ -330,6 +333,7 @@ func initBackendForFiltererTests(t *testing.T, bc *blockchain.BlockChain) *Block
 		return bc.SubscribeChainEvent(ch)
 	}
 	mockBackend.EXPECT().SubscribeNewTxsEvent(any).DoAndReturn(subscribeNewTxsEvent).AnyTimes()
+	mockBackend.EXPECT().SubscribeDroppedTxsEvent(any).DoAndReturn(subscribeDroppedTxsEvent).AnyTimes()
 	mockBackend.EXPECT().SubscribeLogsEvent(any).DoAndReturn(subscribeLogsEvent).AnyTimes()
 	mockBackend.EXPECT().SubscribeRemovedLogsEvent(any).DoAndReturn(subscribeRemovedLogsEvent).AnyTimes()
 	mockBackend.EXPECT().SubscribeChainEvent(any).DoAndReturn(subscribeChainEvent).AnyTimes()
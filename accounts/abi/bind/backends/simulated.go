@@ -672,6 +672,10 @@ func (fb *filterBackend) SubscribeNewTxsEvent(_ chan<- blockchain.NewTxsEvent) e
 	return nullSubscription()
 }
 
+func (fb *filterBackend) SubscribeDroppedTxsEvent(_ chan<- blockchain.DroppedTxsEvent) event.Subscription {
+	return nullSubscription()
+}
+
 func (fb *filterBackend) SubscribeChainEvent(ch chan<- blockchain.ChainEvent) event.Subscription {
 	return fb.bc.SubscribeChainEvent(ch)
 }
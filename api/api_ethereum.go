@@ -200,8 +200,42 @@ func (api *EthereumAPI) NewPendingTransactionFilter() rpc.ID {
 
 // NewPendingTransactions creates a subscription that is triggered each time a transaction
 // enters the transaction pool and was signed from one of the transactions this nodes manages.
-func (api *EthereumAPI) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
-	return api.publicFilterAPI.NewPendingTransactions(ctx)
+// The transactions can be restricted by the given criteria, and are notified with their
+// bodies in the Ethereum representation instead of their hashes if crit.FullTx is set.
+func (api *EthereumAPI) NewPendingTransactions(ctx context.Context, crit *filters.PendingTxsCriteria) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	fullTx := crit != nil && crit.FullTx
+
+	go func() {
+		txs := make(chan []*types.Transaction, 128)
+		pendingTxSub := api.publicFilterAPI.Events().SubscribePendingTxs(crit, txs)
+
+		for {
+			select {
+			case pTxs := <-txs:
+				for _, tx := range pTxs {
+					if fullTx {
+						notifier.Notify(rpcSub.ID, newEthRPCPendingTransaction(tx))
+					} else {
+						notifier.Notify(rpcSub.ID, tx.Hash())
+					}
+				}
+			case <-rpcSub.Err():
+				pendingTxSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				pendingTxSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
 }
 
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
@@ -444,7 +478,24 @@ func doGetProof(ctx context.Context, b Backend, address common.Address, storageK
 			return nil, err
 		}
 	}
-	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	// The proofs are regenerated from the snapshot if the trie nodes are pruned,
+	// either from the state of the snapshot or from the one of the state histories.
+	// Regenerating a trie traverses the whole snapshot, so it is bounded by the
+	// RPC timeout.
+	if timeout := b.RPCEVMTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	header, err := b.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if header == nil || err != nil {
+		return nil, err
+	}
+	state, _, err := b.StateAndHeaderByNumberOrHash(ctx, rpc.NewBlockNumberOrHashWithHash(header.Hash(), false))
+	if isMissingTrieNode(err) {
+		// The state trie is pruned, so the state is read from the snapshot.
+		state, err = snapshotStateAt(b, header)
+	}
 	if state == nil || err != nil {
 		return nil, err
 	}
@@ -458,7 +509,7 @@ func doGetProof(ctx context.Context, b Backend, address common.Address, storageK
 
 	// if we have a storageTrie, (which means the account exists), we can update the storagehash
 	if len(keys) > 0 {
-		storageTrie, err := statedb.NewTrie(contractStorageRoot, state.Database().TrieDB(), nil)
+		proofs, err := proveStorage(ctx, b, state.Database().TrieDB(), header.Root, address, contractStorageRoot, keys)
 		if err != nil {
 			return nil, err
 		}
@@ -474,28 +525,16 @@ func doGetProof(ctx context.Context, b Backend, address common.Address, storageK
 			} else {
 				outputKey = hexutil.Encode(key[:])
 			}
-			if storageTrie == nil {
-				storageProof[i] = EthStorageResult{outputKey, &hexutil.Big{}, []string{}}
-				continue
-			}
-			var proof proofList
-			if err := storageTrie.Prove(crypto.Keccak256(key.Bytes()), 0, &proof); err != nil {
-				return nil, err
-			}
 			value := (*hexutil.Big)(state.GetState(address, key).Big())
-			storageProof[i] = EthStorageResult{outputKey, value, proof}
+			storageProof[i] = EthStorageResult{outputKey, value, proofs[i]}
 		}
 	}
 
 	// Create the accountProof.
-	trie, err := statedb.NewTrie(header.Root, state.Database().TrieDB(), nil)
+	accountProof, err := proveAccount(ctx, b, state.Database().TrieDB(), header.Root, address)
 	if err != nil {
 		return nil, err
 	}
-	var accountProof proofList
-	if err := trie.Prove(crypto.Keccak256(address.Bytes()), 0, &accountProof); err != nil {
-		return nil, err
-	}
 
 	return &EthAccountResult{
 		Address:      address,
@@ -508,6 +547,75 @@ func doGetProof(ctx context.Context, b Backend, address common.Address, storageK
 	}, state.Error()
 }
 
+// snapshotStateAt returns a read-only state of the given block read from the
+// snapshot. It's used when the state trie of the block has been pruned, and
+// fails if the snapshot layers don't cover the block anymore.
+func snapshotStateAt(b Backend, header *types.Header) (*state.StateDB, error) {
+	snaps := b.Snapshots()
+	if snaps == nil {
+		return nil, fmt.Errorf("state of block %d is pruned and the snapshot is disabled", header.Number)
+	}
+	snap := snaps.Snapshot(header.Root)
+	if snap == nil {
+		return nil, fmt.Errorf("state of block %d is pruned and not covered by the snapshot", header.Number)
+	}
+	return state.NewWithSnapshot(header.Root, state.NewDatabase(b.ChainDB()), snap)
+}
+
+// proveAccount returns the Merkle proof of the account in the state of root. If
+// the trie nodes have been pruned, the proof is made from the snapshot instead.
+func proveAccount(ctx context.Context, b Backend, triedb *statedb.Database, root common.Hash, address common.Address) (proofList, error) {
+	var proof proofList
+	trie, err := statedb.NewTrie(root, triedb, nil)
+	if err == nil {
+		err = trie.Prove(crypto.Keccak256(address.Bytes()), 0, &proof)
+	}
+	if !isMissingTrieNode(err) || b.Snapshots() == nil {
+		return proof, err
+	}
+	proof = nil
+	if snapErr := b.Snapshots().AccountProof(ctx, root, crypto.Keccak256Hash(address.Bytes()), &proof); snapErr != nil {
+		logger.Debug("Failed to prove the account from the snapshot", "root", root, "address", address, "err", snapErr)
+		return nil, fmt.Errorf("%w (proving from the snapshot failed: %v)", err, snapErr)
+	}
+	return proof, nil
+}
+
+// proveStorage returns the Merkle proofs of the storage slots of the account in
+// the state of root. If the trie nodes have been pruned, the proofs are made from
+// the snapshot instead.
+func proveStorage(ctx context.Context, b Backend, triedb *statedb.Database, root common.Hash, address common.Address, storageRoot common.Hash, keys []common.Hash) ([]proofList, error) {
+	proofs := make([]proofList, len(keys))
+	storageTrie, err := statedb.NewTrie(storageRoot, triedb, nil)
+	for i := 0; err == nil && i < len(keys); i++ {
+		err = storageTrie.Prove(crypto.Keccak256(keys[i].Bytes()), 0, &proofs[i])
+	}
+	if !isMissingTrieNode(err) || b.Snapshots() == nil {
+		return proofs, err
+	}
+	var (
+		slotHashes = make([]common.Hash, len(keys))
+		proofDbs   = make([]statedb.ProofDBWriter, len(keys))
+	)
+	for i, key := range keys {
+		proofs[i] = nil
+		slotHashes[i] = crypto.Keccak256Hash(key.Bytes())
+		proofDbs[i] = &proofs[i]
+	}
+	if snapErr := b.Snapshots().StorageProof(ctx, root, crypto.Keccak256Hash(address.Bytes()), storageRoot, slotHashes, proofDbs); snapErr != nil {
+		logger.Debug("Failed to prove the storage from the snapshot", "root", root, "address", address, "err", snapErr)
+		return nil, fmt.Errorf("%w (proving from the snapshot failed: %v)", err, snapErr)
+	}
+	return proofs, nil
+}
+
+// isMissingTrieNode returns true if the error is caused by a trie node missing
+// in the database.
+func isMissingTrieNode(err error) bool {
+	var missing *statedb.MissingNodeError
+	return errors.As(err, &missing)
+}
+
 // GetProof returns the Merkle-proof for a given account and optionally some storage keys
 func (api *EthereumAPI) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNrOrHash rpc.BlockNumberOrHash) (*EthAccountResult, error) {
 	return doGetProof(ctx, api.publicBlockChainAPI.b, address, storageKeys, blockNrOrHash)
@@ -691,6 +799,42 @@ func (diff *EthStateOverride) Apply(state *state.StateDB) error {
 	return nil
 }
 
+// BlockOverrides is a set of header fields to override, used by both the traced
+// calls and the simulated blocks.
+type BlockOverrides struct {
+	Number        *hexutil.Big    `json:"number"`
+	BlockScore    *hexutil.Big    `json:"blockScore"`
+	Time          *hexutil.Uint64 `json:"time"`
+	FeeRecipient  *common.Address `json:"feeRecipient"`
+	PrevRandao    *common.Hash    `json:"prevRandao"`
+	BaseFeePerGas *hexutil.Big    `json:"baseFeePerGas"`
+}
+
+// Apply overrides the given block context.
+func (diff *BlockOverrides) Apply(blockCtx *vm.BlockContext) {
+	if diff == nil {
+		return
+	}
+	if diff.Number != nil {
+		blockCtx.BlockNumber = diff.Number.ToInt()
+	}
+	if diff.BlockScore != nil {
+		blockCtx.BlockScore = diff.BlockScore.ToInt()
+	}
+	if diff.Time != nil {
+		blockCtx.Time = new(big.Int).SetUint64(uint64(*diff.Time))
+	}
+	if diff.FeeRecipient != nil {
+		blockCtx.Coinbase = *diff.FeeRecipient
+	}
+	if diff.PrevRandao != nil {
+		blockCtx.Random = *diff.PrevRandao
+	}
+	if diff.BaseFeePerGas != nil {
+		blockCtx.BaseFee = diff.BaseFeePerGas.ToInt()
+	}
+}
+
 // Call executes the given transaction on the state for the given block number.
 //
 // Additionally, the caller can specify a batch of contract for fields overriding.
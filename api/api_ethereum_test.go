@@ -30,7 +30,9 @@ import (
 	"github.com/klaytn/klaytn/networks/rpc"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/snapshot"
 	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -2594,3 +2596,100 @@ func TestEthereumAPI_EstimateGas(t *testing.T) {
 		return api.EstimateGas(context.Background(), args, nil)
 	})
 }
+
+// TestGetProofPrunedState tests that doGetProof makes the proofs of a block whose
+// state trie is pruned from the snapshot, and that it fails once the snapshot
+// doesn't cover the block.
+func TestGetProofPrunedState(t *testing.T) {
+	mockCtrl, mockBackend, _ := testInitForEthApi(t)
+	defer mockCtrl.Finish()
+
+	var (
+		contract = common.HexToAddress("0x2222222222222222222222222222222222222222")
+		slot     = common.HexToHash("0x0")
+		trieDB   = database.NewMemoryDBManager() // the unpruned state trie
+		chainDB  = database.NewMemoryDBManager() // the node database without the trie
+	)
+	sdb, err := state.New(common.Hash{}, state.NewDatabase(trieDB), nil, nil)
+	require.NoError(t, err)
+	sdb.CreateSmartContractAccount(contract, params.CodeFormatEVM, params.Rules{IsIstanbul: true})
+	sdb.SetNonce(contract, 3)
+	sdb.SetCode(contract, []byte{0x60, 0x00})
+	sdb.SetState(contract, slot, common.BigToHash(big.NewInt(42)))
+	root, err := sdb.Commit(false)
+	require.NoError(t, err)
+	require.NoError(t, sdb.Database().TrieDB().Commit(root, false, 0))
+	chainDB.WriteCode(crypto.Keccak256Hash([]byte{0x60, 0x00}), []byte{0x60, 0x00})
+
+	snaps, err := snapshot.New(chainDB, statedb.NewDatabase(trieDB), 16, root, false, true, false)
+	require.NoError(t, err)
+
+	header := &types.Header{Number: big.NewInt(1), Root: root}
+	mockBackend.EXPECT().HeaderByNumberOrHash(gomock.Any(), gomock.Any()).Return(header, nil).AnyTimes()
+	mockBackend.EXPECT().StateAndHeaderByNumberOrHash(gomock.Any(), gomock.Any()).
+		Return(nil, nil, &statedb.MissingNodeError{NodeHash: root}).AnyTimes()
+	mockBackend.EXPECT().Snapshots().Return(snaps).AnyTimes()
+	mockBackend.EXPECT().ChainDB().Return(chainDB).AnyTimes()
+	mockBackend.EXPECT().RPCEVMTimeout().Return(5 * time.Second).AnyTimes()
+
+	accTrie, err := statedb.NewTrie(root, statedb.NewDatabase(trieDB), nil)
+	require.NoError(t, err)
+
+	// The account and storage proofs of a contract.
+	result, err := doGetProof(context.Background(), mockBackend, contract, []string{"0x0"}, rpc.NewBlockNumberOrHashWithNumber(1))
+	require.NoError(t, err)
+	var wantAccProof proofList
+	require.NoError(t, accTrie.Prove(crypto.Keccak256(contract.Bytes()), 0, &wantAccProof))
+	assert.Equal(t, []string(wantAccProof), result.AccountProof)
+	stTrie, err := statedb.NewTrie(result.StorageHash, statedb.NewDatabase(trieDB), nil)
+	require.NoError(t, err)
+	var wantStProof proofList
+	require.NoError(t, stTrie.Prove(crypto.Keccak256(slot.Bytes()), 0, &wantStProof))
+	require.Len(t, result.StorageProof, 1)
+	assert.Equal(t, []string(wantStProof), result.StorageProof[0].Proof)
+	assert.Equal(t, big.NewInt(42), result.StorageProof[0].Value.ToInt())
+	assert.Equal(t, hexutil.Uint64(3), result.Nonce)
+
+	// A block which isn't covered by the snapshot anymore.
+	header.Root = common.HexToHash("0x1234")
+	_, err = doGetProof(context.Background(), mockBackend, contract, nil, rpc.NewBlockNumberOrHashWithNumber(1))
+	assert.ErrorContains(t, err, "not covered by the snapshot")
+}
+
+// TestGetProofTimeout tests that doGetProof bounds the regeneration of the proofs
+// by the RPC timeout even if the backend reads the state of a pruned trie from
+// the state histories.
+func TestGetProofTimeout(t *testing.T) {
+	mockCtrl, mockBackend, _ := testInitForEthApi(t)
+	defer mockCtrl.Finish()
+
+	var (
+		trieDB  = database.NewMemoryDBManager() // the unpruned state trie
+		chainDB = database.NewMemoryDBManager() // the node database without the trie
+	)
+	sdb, err := state.New(common.Hash{}, state.NewDatabase(trieDB), nil, nil)
+	require.NoError(t, err)
+	contract := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	sdb.CreateSmartContractAccount(contract, params.CodeFormatEVM, params.Rules{IsIstanbul: true})
+	// Enough accounts to check the context while regenerating the trie.
+	for i := 0; i < 2048; i++ {
+		sdb.SetNonce(common.BigToAddress(big.NewInt(int64(i+1))), 1)
+	}
+	root, err := sdb.Commit(false)
+	require.NoError(t, err)
+	require.NoError(t, sdb.Database().TrieDB().Commit(root, false, 0))
+
+	snaps, err := snapshot.New(chainDB, statedb.NewDatabase(trieDB), 16, root, false, true, false)
+	require.NoError(t, err)
+	historical, err := state.NewWithSnapshot(root, state.NewDatabase(chainDB), snaps.Snapshot(root))
+	require.NoError(t, err)
+
+	header := &types.Header{Number: big.NewInt(1), Root: root}
+	mockBackend.EXPECT().HeaderByNumberOrHash(gomock.Any(), gomock.Any()).Return(header, nil).AnyTimes()
+	mockBackend.EXPECT().StateAndHeaderByNumberOrHash(gomock.Any(), gomock.Any()).Return(historical, header, nil).AnyTimes()
+	mockBackend.EXPECT().Snapshots().Return(snaps).AnyTimes()
+	mockBackend.EXPECT().RPCEVMTimeout().Return(time.Nanosecond).AnyTimes()
+
+	_, err = doGetProof(context.Background(), mockBackend, contract, nil, rpc.NewBlockNumberOrHashWithNumber(1))
+	assert.ErrorContains(t, err, context.DeadlineExceeded.Error())
+}
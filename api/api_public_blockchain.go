@@ -606,14 +606,7 @@ func (s *PublicBlockChainAPI) rpcOutputBlock(b *types.Block, inclTx bool, fullTx
 }
 
 func getFrom(tx *types.Transaction) common.Address {
-	var from common.Address
-	if tx.IsEthereumTransaction() {
-		signer := types.LatestSignerForChainID(tx.ChainId())
-		from, _ = types.Sender(signer, tx)
-	} else {
-		from, _ = tx.From()
-	}
-	return from
+	return tx.RPCSender()
 }
 
 func NewRPCTransaction(b *types.Block, tx *types.Transaction, blockHash common.Hash, blockNumber uint64, index uint64) map[string]interface{} {
@@ -623,23 +616,7 @@ func NewRPCTransaction(b *types.Block, tx *types.Transaction, blockHash common.H
 // newRPCTransaction returns a transaction that will serialize to the RPC
 // representation, with the given location metadata set (if available).
 func newRPCTransaction(b *types.Block, tx *types.Transaction, blockHash common.Hash, blockNumber uint64, index uint64) map[string]interface{} {
-	output := tx.MakeRPCOutput()
-	output["senderTxHash"] = tx.SenderTxHashAll()
-	output["blockHash"] = blockHash
-	output["blockNumber"] = (*hexutil.Big)(new(big.Int).SetUint64(blockNumber))
-	output["from"] = getFrom(tx)
-	output["hash"] = tx.Hash()
-	output["transactionIndex"] = hexutil.Uint(index)
-	if tx.Type() == types.TxTypeEthereumDynamicFee {
-		if b != nil {
-			output["gasPrice"] = (*hexutil.Big)(tx.EffectiveGasPrice(b.Header()))
-		} else {
-			// transaction is not processed yet
-			output["gasPrice"] = (*hexutil.Big)(tx.EffectiveGasPrice(nil))
-		}
-	}
-
-	return output
+	return tx.MakeRPCOutputWithLocation(b, blockHash, blockNumber, index)
 }
 
 // newRPCPendingTransaction returns a pending transaction that will serialize to the RPC representation
@@ -0,0 +1,547 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+)
+
+const (
+	// maxSimulateBlocks is the maximum number of blocks simulated in a request.
+	maxSimulateBlocks = 256
+
+	// maxSimulateCalls is the maximum number of calls in a simulated block.
+	maxSimulateCalls = 1000
+
+	// simErrCodeReverted is the error code of the simulated calls reverted.
+	simErrCodeReverted = 3
+
+	// simErrCodeVMError is the error code of the simulated calls failed with the other EVM errors.
+	simErrCodeVMError = -32015
+)
+
+var (
+	errSimNoBlocks      = errors.New("empty input")
+	errSimTooManyBlocks = fmt.Errorf("too many blocks, the limit is %d", maxSimulateBlocks)
+	errSimTooManyCalls  = fmt.Errorf("too many calls in a block, the limit is %d", maxSimulateCalls)
+)
+
+// EthSimBlock is a simulated block of the calls in the Ethereum format.
+type EthSimBlock struct {
+	BlockOverrides *BlockOverrides      `json:"blockOverrides"`
+	StateOverrides *EthStateOverride    `json:"stateOverrides"`
+	Calls          []EthTransactionArgs `json:"calls"`
+}
+
+// EthSimOpts are the inputs of eth_simulateV1.
+type EthSimOpts struct {
+	BlockStateCalls []EthSimBlock `json:"blockStateCalls"`
+	Validation      bool          `json:"validation"`
+}
+
+// KlaySimCallArgs are the arguments of a simulated call in the Kaia format. Any
+// transaction type can be simulated, and the signatures are validated against the
+// account keys if the validation is enabled.
+type KlaySimCallArgs struct {
+	SendTxArgs
+	FeePayerSignatures types.TxSignaturesJSON `json:"feePayerSignatures"`
+}
+
+// KlaySimBlock is a simulated block of the calls in the Kaia format.
+type KlaySimBlock struct {
+	BlockOverrides *BlockOverrides   `json:"blockOverrides"`
+	StateOverrides *EthStateOverride `json:"stateOverrides"`
+	Calls          []KlaySimCallArgs `json:"calls"`
+}
+
+// KlaySimOpts are the inputs of klay_simulate.
+type KlaySimOpts struct {
+	BlockStateCalls []KlaySimBlock `json:"blockStateCalls"`
+	Validation      bool           `json:"validation"`
+}
+
+// SimCallError is the error of a simulated call failed in the EVM.
+type SimCallError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
+// SimCallResult is the result of a simulated call.
+type SimCallResult struct {
+	ReturnData hexutil.Bytes  `json:"returnData"`
+	Logs       []*types.Log   `json:"logs"`
+	GasUsed    hexutil.Uint64 `json:"gasUsed"`
+	Status     hexutil.Uint64 `json:"status"`
+	Error      *SimCallError  `json:"error,omitempty"`
+}
+
+// SimBlockResult is the result of a simulated block.
+type SimBlockResult struct {
+	Number        *hexutil.Big     `json:"number"`
+	Hash          common.Hash      `json:"hash"`
+	ParentHash    common.Hash      `json:"parentHash"`
+	StateRoot     common.Hash      `json:"stateRoot"`
+	Timestamp     hexutil.Uint64   `json:"timestamp"`
+	GasUsed       hexutil.Uint64   `json:"gasUsed"`
+	BaseFeePerGas *hexutil.Big     `json:"baseFeePerGas,omitempty"`
+	FeeRecipient  common.Address   `json:"miner"`
+	Calls         []*SimCallResult `json:"calls"`
+}
+
+// simBlock is a simulated block whose calls are converted to messages on execution.
+type simBlock struct {
+	overrides *BlockOverrides
+	state     *EthStateOverride
+	calls     []simCall
+}
+
+// simCall converts a simulated call into the message executed on the given header.
+type simCall func(s *simulator, header *types.Header) (*types.Transaction, error)
+
+// simulator executes the calls of the simulated blocks one after another on
+// top of the state of a base block.
+type simulator struct {
+	b          Backend
+	state      *state.StateDB
+	base       *types.Header
+	coinbase   common.Address
+	gasLimit   uint64 // gas limit of a simulated block
+	gasLeft    uint64 // gas left in the simulated block being executed
+	logIndex   uint   // index of the next log in the simulated block being executed
+	validation bool
+}
+
+// newSimulator returns a simulator on top of the state of the given block.
+func newSimulator(ctx context.Context, b Backend, blockNrOrHash *rpc.BlockNumberOrHash, validation bool) (*simulator, error) {
+	bNrOrHash := rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	state, base, err := b.StateAndHeaderByNumberOrHash(ctx, bNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	// The headers have no gas limit, so the gas of the calls in a simulated block
+	// is bounded by the gas cap of a call.
+	gasLimit := params.UpperGasLimit
+	if rpcGasCap := b.RPCGasCap(); rpcGasCap != nil && rpcGasCap.Uint64() != 0 {
+		gasLimit = rpcGasCap.Uint64()
+	}
+	coinbase, _ := b.Engine().Author(base)
+	return &simulator{b: b, state: state, base: base, coinbase: coinbase, gasLimit: gasLimit, validation: validation}, nil
+}
+
+// simulate executes the blocks and returns their results.
+func (s *simulator) simulate(ctx context.Context, blocks []simBlock) ([]*SimBlockResult, error) {
+	defer func(start time.Time) { logger.Debug("Simulating blocks finished", "runtime", time.Since(start)) }(time.Now())
+
+	if len(blocks) == 0 {
+		return nil, errSimNoBlocks
+	}
+	if len(blocks) > maxSimulateBlocks {
+		return nil, errSimTooManyBlocks
+	}
+	for i, block := range blocks {
+		if len(block.calls) > maxSimulateCalls {
+			return nil, fmt.Errorf("block %d: %w", i, errSimTooManyCalls)
+		}
+	}
+	// The whole simulation is bounded by the timeout of a call.
+	var cancel context.CancelFunc
+	if timeout := s.b.RPCEVMTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	var (
+		results = make([]*SimBlockResult, 0, len(blocks))
+		parent  = s.base
+	)
+	for i, block := range blocks {
+		header, coinbase, err := s.makeHeader(parent, block.overrides)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		if err := block.state.Apply(s.state); err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		s.gasLeft = s.gasLimit
+		s.logIndex = 0
+		calls := make([]*SimCallResult, 0, len(block.calls))
+		for j, call := range block.calls {
+			result, err := s.applyCall(ctx, header, coinbase, j, call)
+			if err != nil {
+				return nil, fmt.Errorf("block %d, call %d: %w", i, j, err)
+			}
+			header.GasUsed += uint64(result.GasUsed)
+			s.gasLeft -= uint64(result.GasUsed)
+			calls = append(calls, result)
+		}
+		header.Root = s.state.IntermediateRoot(true)
+
+		// The logs are finalized with the hash of the block after all the calls are executed.
+		hash := header.Hash()
+		for _, call := range calls {
+			for _, log := range call.Logs {
+				log.BlockHash = hash
+			}
+		}
+		results = append(results, &SimBlockResult{
+			Number:        (*hexutil.Big)(header.Number),
+			Hash:          hash,
+			ParentHash:    header.ParentHash,
+			StateRoot:     header.Root,
+			Timestamp:     hexutil.Uint64(header.Time.Uint64()),
+			GasUsed:       hexutil.Uint64(header.GasUsed),
+			BaseFeePerGas: (*hexutil.Big)(header.BaseFee),
+			FeeRecipient:  coinbase,
+			Calls:         calls,
+		})
+		parent = header
+	}
+	return results, nil
+}
+
+// makeHeader returns the header of the simulated block following the parent,
+// and the coinbase of the block.
+func (s *simulator) makeHeader(parent *types.Header, overrides *BlockOverrides) (*types.Header, common.Address, error) {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Rewardbase: parent.Rewardbase,
+		BlockScore: common.Big1,
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Time:       new(big.Int).Add(parent.Time, common.Big1),
+	}
+	if parent.BaseFee != nil {
+		header.BaseFee = new(big.Int).Set(parent.BaseFee)
+	}
+	coinbase := s.coinbase
+	if overrides == nil {
+		return header, coinbase, nil
+	}
+	if overrides.Number != nil {
+		if overrides.Number.ToInt().Cmp(parent.Number) <= 0 {
+			return nil, coinbase, fmt.Errorf("block number %v is not greater than the parent %v", overrides.Number.ToInt(), parent.Number)
+		}
+		header.Number = new(big.Int).Set(overrides.Number.ToInt())
+	}
+	if overrides.Time != nil {
+		if uint64(*overrides.Time) <= parent.Time.Uint64() {
+			return nil, coinbase, fmt.Errorf("block timestamp %d is not greater than the parent %v", *overrides.Time, parent.Time)
+		}
+		header.Time = new(big.Int).SetUint64(uint64(*overrides.Time))
+	}
+	if overrides.BlockScore != nil {
+		header.BlockScore = new(big.Int).Set(overrides.BlockScore.ToInt())
+	}
+	if overrides.PrevRandao != nil {
+		header.MixHash = overrides.PrevRandao.Bytes()
+	}
+	if overrides.BaseFeePerGas != nil {
+		header.BaseFee = new(big.Int).Set(overrides.BaseFeePerGas.ToInt())
+	}
+	if overrides.FeeRecipient != nil {
+		coinbase = *overrides.FeeRecipient
+		header.Rewardbase = coinbase
+	}
+	return header, coinbase, nil
+}
+
+// applyCall executes a call on the state and returns its result. The error is
+// returned only if the call cannot be included in the block.
+func (s *simulator) applyCall(ctx context.Context, header *types.Header, coinbase common.Address, index int, call simCall) (*SimCallResult, error) {
+	msg, err := call(s, header)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Gas() > s.gasLeft {
+		return nil, fmt.Errorf("block gas limit reached: gas %d, left %d", msg.Gas(), s.gasLeft)
+	}
+	takeBackGas := func() {}
+	if s.validation {
+		if header.BaseFee != nil && msg.GasPrice().Cmp(header.BaseFee) < 0 {
+			return nil, fmt.Errorf("gas price %v is less than the base fee %v", msg.GasPrice(), header.BaseFee)
+		}
+	} else {
+		// Add gas fee to the payers so that the calls by the accounts with insufficient balance can be simulated.
+		takeBackGas = s.prefundGas(header, msg)
+	}
+	evm, vmError, err := s.b.GetEVM(ctx, msg, s.state, header, vm.Config{ComputationCostLimit: params.OpcodeComputationCostLimitInfinite})
+	if err != nil {
+		return nil, err
+	}
+	evm.Context.Coinbase = coinbase
+
+	// Wait for the context to be done and cancel the evm. Even if the
+	// EVM has finished, cancelling may be done (repeatedly)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			evm.Cancel(vm.CancelByCtxDone)
+		case <-done:
+		}
+	}()
+
+	txHash := msg.Hash()
+	s.state.SetTxContext(txHash, common.Hash{}, index)
+	prevLogs := len(s.state.GetLogs(txHash))
+
+	result, err := blockchain.ApplyMessage(evm, msg)
+	if err := vmError(); err != nil {
+		return nil, err
+	}
+	if evm.Cancelled() {
+		return nil, fmt.Errorf("execution aborted (timeout = %v)", s.b.RPCEVMTimeout())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("err: %w (supplied gas %d)", err, msg.Gas())
+	}
+	takeBackGas()
+	s.state.Finalise(true, false)
+
+	// The state numbers the logs across the simulated blocks, so they are
+	// numbered again from the start of the block.
+	logs := s.state.GetLogs(txHash)[prevLogs:]
+	for _, log := range logs {
+		log.Index = s.logIndex
+		s.logIndex++
+	}
+	callResult := &SimCallResult{
+		ReturnData: result.Return(),
+		Logs:       logs,
+		GasUsed:    hexutil.Uint64(result.UsedGas),
+		Status:     hexutil.Uint64(types.ReceiptStatusSuccessful),
+	}
+	if callResult.Logs == nil {
+		callResult.Logs = []*types.Log{}
+	}
+	if result.Failed() {
+		callResult.Status = hexutil.Uint64(types.ReceiptStatusFailed)
+		if revert := result.Revert(); revert != nil {
+			callResult.ReturnData = revert
+			callResult.Error = &SimCallError{Code: simErrCodeReverted, Message: blockchain.NewRevertError(result).Error(), Data: hexutil.Encode(revert)}
+		} else {
+			callResult.Error = &SimCallError{Code: simErrCodeVMError, Message: result.Unwrap().Error()}
+		}
+	}
+	return callResult, nil
+}
+
+// prefundGas adds the gas fee of the message to the sender and the fee payer, and
+// returns the function taking it back after the message is executed.
+func (s *simulator) prefundGas(header *types.Header, msg *types.Transaction) func() {
+	gasPrice := msg.GasPrice()
+	if header.BaseFee != nil {
+		gasPrice = header.BaseFee
+	}
+	return PrefundGas(s.state, msg, gasPrice)
+}
+
+// PrefundGas adds the gas fee of the message at the given gas price to the sender
+// and the fee payer, so that the calls by the accounts with insufficient balance
+// can be executed, and returns the function taking it back after the execution.
+// The payers are left with their balances less the fee actually paid, or zero if
+// they had less than that, so the prefund doesn't carry over to the following calls.
+func PrefundGas(state *state.StateDB, msg *types.Transaction, gasPrice *big.Int) func() {
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(msg.Gas()), gasPrice)
+	payers := []common.Address{msg.ValidatedSender()}
+	if feePayer := msg.ValidatedFeePayer(); feePayer != msg.ValidatedSender() {
+		payers = append(payers, feePayer)
+	}
+	for _, payer := range payers {
+		state.AddBalance(payer, fee)
+	}
+	return func() {
+		for _, payer := range payers {
+			if balance := state.GetBalance(payer); balance.Cmp(fee) < 0 {
+				state.SubBalance(payer, balance)
+			} else {
+				state.SubBalance(payer, fee)
+			}
+		}
+	}
+}
+
+// simBaseFee returns the base fee of the header, which is zero before the Magma hardfork.
+func simBaseFee(header *types.Header) *big.Int {
+	if header.BaseFee != nil {
+		return header.BaseFee
+	}
+	return new(big.Int).SetUint64(params.ZeroBaseFee)
+}
+
+// ethSimCall returns the simulated call of the arguments in the Ethereum format.
+func ethSimCall(args EthTransactionArgs) simCall {
+	return func(s *simulator, header *types.Header) (*types.Transaction, error) {
+		intrinsicGas, err := types.IntrinsicGas(args.data(), nil, args.To == nil, s.b.ChainConfig().Rules(header.Number))
+		if err != nil {
+			return nil, err
+		}
+		// The gas left in the block is used by default, as the RPC gas cap bounds it.
+		if args.Gas == nil {
+			gas := hexutil.Uint64(s.gasLeft)
+			args.Gas = &gas
+		}
+		msg, err := args.ToMessage(s.gasLimit, simBaseFee(header), intrinsicGas)
+		if err != nil {
+			return nil, err
+		}
+		if msg.Gas() < intrinsicGas {
+			return nil, fmt.Errorf("%w: msg.gas %d, want %d", blockchain.ErrIntrinsicGas, msg.Gas(), intrinsicGas)
+		}
+		if !s.validation {
+			return msg, nil
+		}
+		// The nonce is checked on validation, which is the one of the sender if not given.
+		nonce := s.state.GetNonce(args.from())
+		if args.Nonce != nil {
+			nonce = uint64(*args.Nonce)
+		}
+		return types.NewMessage(args.from(), args.To, nonce, msg.Value(), msg.Gas(), msg.GasPrice(), msg.Data(), true, intrinsicGas, msg.AccessList()), nil
+	}
+}
+
+// klaySimCall returns the simulated call of the arguments in the Kaia format.
+func klaySimCall(args KlaySimCallArgs) simCall {
+	return func(s *simulator, header *types.Header) (*types.Transaction, error) {
+		if err := args.setSimDefaults(s, header); err != nil {
+			return nil, err
+		}
+		tx, err := args.toTransaction()
+		if err != nil {
+			return nil, err
+		}
+		blockNumber := header.Number.Uint64()
+		if !s.validation {
+			feePayer := args.From
+			if tx.IsFeeDelegatedTransaction() {
+				if args.FeePayer == nil {
+					return nil, errTxArgInvalidFeePayer
+				}
+				feePayer = *args.FeePayer
+			}
+			return tx.AsMessageWithSender(args.From, feePayer, blockNumber)
+		}
+		// The signatures are validated against the account keys in the simulated state.
+		if args.TxSignatures == nil {
+			return nil, errTxArgNilSenderSig
+		}
+		tx.SetSignature(args.TxSignatures.ToTxSignatures())
+		if tx.IsFeeDelegatedTransaction() {
+			if err := tx.SetFeePayerSignatures(args.FeePayerSignatures.ToTxSignatures()); err != nil {
+				return nil, err
+			}
+		}
+		if err := tx.Validate(s.state, blockNumber); err != nil {
+			return nil, err
+		}
+		return tx.AsMessageWithAccountKeyPicker(types.MakeSigner(s.b.ChainConfig(), header.Number), s.state, blockNumber)
+	}
+}
+
+// setSimDefaults fills in the default values of the simulated call from the
+// simulated state and block instead of the current ones.
+func (args *KlaySimCallArgs) setSimDefaults(s *simulator, header *types.Header) error {
+	if args.TypeInt == nil {
+		args.TypeInt = new(types.TxType)
+		*args.TypeInt = types.TxTypeLegacyTransaction
+	}
+	if args.GasLimit == nil {
+		gas := hexutil.Uint64(s.gasLeft)
+		args.GasLimit = &gas
+	}
+	if args.TypeInt.IsEthTypedTransaction() && args.ChainID == nil {
+		args.ChainID = (*hexutil.Big)(s.b.ChainConfig().ChainID)
+	}
+	baseFee := simBaseFee(header)
+	if *args.TypeInt == types.TxTypeEthereumDynamicFee {
+		if args.MaxPriorityFeePerGas == nil {
+			args.MaxPriorityFeePerGas = (*hexutil.Big)(baseFee)
+		}
+		if args.MaxFeePerGas == nil {
+			args.MaxFeePerGas = (*hexutil.Big)(baseFee)
+		}
+	} else if args.Price == nil {
+		args.Price = (*hexutil.Big)(baseFee)
+	}
+	if args.AccountNonce == nil {
+		nonce := hexutil.Uint64(s.state.GetNonce(args.From))
+		args.AccountNonce = &nonce
+	}
+	return nil
+}
+
+// SimulateV1 executes the calls in the sequence of the simulated blocks on top of the
+// given block, with the block and state overrides of each block applied, and returns
+// the results of the blocks. If the validation is enabled, the calls are validated
+// as the transactions in a block, e.g. the nonce, the balance and the base fee.
+//
+// Note, this function doesn't make any changes in the state/blockchain.
+func (api *EthereumAPI) SimulateV1(ctx context.Context, opts EthSimOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]*SimBlockResult, error) {
+	blocks := make([]simBlock, len(opts.BlockStateCalls))
+	for i, block := range opts.BlockStateCalls {
+		blocks[i] = simBlock{overrides: block.BlockOverrides, state: block.StateOverrides}
+		for _, call := range block.Calls {
+			blocks[i].calls = append(blocks[i].calls, ethSimCall(call))
+		}
+	}
+	s, err := newSimulator(ctx, api.publicBlockChainAPI.b, blockNrOrHash, opts.Validation)
+	if err != nil {
+		return nil, err
+	}
+	return s.simulate(ctx, blocks)
+}
+
+// Simulate executes the calls of any transaction type in the sequence of the simulated
+// blocks on top of the given block, with the block and state overrides of each block
+// applied, and returns the results of the blocks. If the validation is enabled, the
+// calls are validated as the transactions in a block, including their signatures
+// against the account keys.
+//
+// Note, this function doesn't make any changes in the state/blockchain.
+func (s *PublicBlockChainAPI) Simulate(ctx context.Context, opts KlaySimOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]*SimBlockResult, error) {
+	blocks := make([]simBlock, len(opts.BlockStateCalls))
+	for i, block := range opts.BlockStateCalls {
+		blocks[i] = simBlock{overrides: block.BlockOverrides, state: block.StateOverrides}
+		for _, call := range block.Calls {
+			blocks[i].calls = append(blocks[i].calls, klaySimCall(call))
+		}
+	}
+	sim, err := newSimulator(ctx, s.b, blockNrOrHash, opts.Validation)
+	if err != nil {
+		return nil, err
+	}
+	return sim.simulate(ctx, blocks)
+}
@@ -0,0 +1,337 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mock_api "github.com/klaytn/klaytn/api/mocks"
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// codeLogAndReturn emits a log of 0x2a and returns it.
+var codeLogAndReturn = "0x602a60005260206000a060206000f3"
+
+var (
+	simAccount1 = common.HexToAddress("0xaaaa")
+	simAccount2 = common.HexToAddress("0xbbbb")
+	simReverter = common.HexToAddress("0xcccc")
+	simLogger   = common.HexToAddress("0xdddd")
+)
+
+func setupSimulateBackend(t *testing.T, mockBackend *mock_api.MockBackend) (*params.ChainConfig, *types.Header) {
+	chainConfig := &params.ChainConfig{ChainID: big.NewInt(111111)}
+	chainConfig.IstanbulCompatibleBlock = common.Big0
+	chainConfig.LondonCompatibleBlock = common.Big0
+	chainConfig.EthTxTypeCompatibleBlock = common.Big0
+	var (
+		gspec = &blockchain.Genesis{Alloc: blockchain.GenesisAlloc{
+			simAccount1: {Balance: big.NewInt(params.KLAY * 2)},
+			simReverter: {Balance: common.Big0, Code: hexutil.MustDecode(codeRevertHello)},
+			simLogger:   {Balance: common.Big0, Code: hexutil.MustDecode(codeLogAndReturn)},
+		}, Config: chainConfig}
+		dbm    = database.NewMemoryDBManager()
+		db     = state.NewDatabase(dbm)
+		block  = gspec.MustCommit(dbm)
+		header = block.Header()
+		chain  = &testChainContext{header: header}
+	)
+
+	any := gomock.Any()
+	getStateAndHeader := func(...interface{}) (*state.StateDB, *types.Header, error) {
+		state, err := state.New(block.Root(), db, nil, nil)
+		return state, header, err
+	}
+	getEVM := func(_ context.Context, msg blockchain.Message, state *state.StateDB, header *types.Header, vmConfig vm.Config) (*vm.EVM, func() error, error) {
+		vmError := func() error { return nil }
+		txContext := blockchain.NewEVMTxContext(msg, header)
+		blockContext := blockchain.NewEVMBlockContext(header, chain, nil)
+		return vm.NewEVM(blockContext, txContext, state, chainConfig, &vmConfig), vmError, nil
+	}
+	mockBackend.EXPECT().ChainConfig().Return(chainConfig).AnyTimes()
+	mockBackend.EXPECT().Engine().Return(gxhash.NewFaker()).AnyTimes()
+	mockBackend.EXPECT().RPCGasCap().Return(common.Big0).AnyTimes()
+	mockBackend.EXPECT().RPCEVMTimeout().Return(5 * time.Second).AnyTimes()
+	mockBackend.EXPECT().StateAndHeaderByNumberOrHash(any, any).DoAndReturn(getStateAndHeader).AnyTimes()
+	mockBackend.EXPECT().GetEVM(any, any, any, any, any).DoAndReturn(getEVM).AnyTimes()
+	return chainConfig, header
+}
+
+func TestEthereumAPI_SimulateV1(t *testing.T) {
+	mockCtrl, mockBackend, api := testInitForEthApi(t)
+	defer mockCtrl.Finish()
+	_, header := setupSimulateBackend(t, mockBackend)
+
+	var (
+		KLAY     = hexutil.Big(*big.NewInt(params.KLAY))
+		balance  = (*hexutil.Big)(big.NewInt(params.KLAY * 5))
+		number   = (*hexutil.Big)(big.NewInt(10))
+		time     = hexutil.Uint64(header.Time.Uint64() + 100)
+		coinbase = common.HexToAddress("0xeeee")
+		nonce    = hexutil.Uint64(1)
+	)
+	opts := EthSimOpts{BlockStateCalls: []EthSimBlock{
+		{
+			StateOverrides: &EthStateOverride{simAccount2: EthOverrideAccount{Balance: &balance}},
+			Calls: []EthTransactionArgs{
+				{From: &simAccount2, To: &simAccount1, Value: &KLAY},
+				{From: &simAccount1, To: &simLogger},
+			},
+		},
+		{
+			BlockOverrides: &BlockOverrides{Number: number, Time: &time, FeeRecipient: &coinbase},
+			Calls: []EthTransactionArgs{
+				{From: &simAccount1, To: &simReverter},
+			},
+		},
+	}}
+	results, err := api.SimulateV1(context.Background(), opts, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	// The first block follows the base block.
+	block := results[0]
+	assert.Equal(t, new(big.Int).Add(header.Number, common.Big1), block.Number.ToInt())
+	assert.Equal(t, header.Hash(), block.ParentHash)
+	require.Len(t, block.Calls, 2)
+	assert.Equal(t, hexutil.Uint64(types.ReceiptStatusSuccessful), block.Calls[0].Status)
+	assert.Equal(t, hexutil.Uint64(params.TxGas), block.Calls[0].GasUsed)
+	assert.Empty(t, block.Calls[0].Logs)
+
+	logCall := block.Calls[1]
+	assert.Equal(t, hexutil.Uint64(types.ReceiptStatusSuccessful), logCall.Status)
+	assert.Equal(t, common.LeftPadBytes([]byte{0x2a}, 32), []byte(logCall.ReturnData))
+	require.Len(t, logCall.Logs, 1)
+	assert.Equal(t, simLogger, logCall.Logs[0].Address)
+	assert.Equal(t, block.Hash, logCall.Logs[0].BlockHash)
+	assert.Equal(t, block.Number.ToInt().Uint64(), logCall.Logs[0].BlockNumber)
+	assert.Equal(t, uint(1), logCall.Logs[0].TxIndex)
+	assert.Equal(t, block.Calls[0].GasUsed+logCall.GasUsed, block.GasUsed)
+
+	// The second block has the overridden header fields, and the revert is returned per call.
+	block = results[1]
+	assert.Equal(t, number.ToInt(), block.Number.ToInt())
+	assert.Equal(t, time, block.Timestamp)
+	assert.Equal(t, coinbase, block.FeeRecipient)
+	assert.Equal(t, results[0].Hash, block.ParentHash)
+	require.Len(t, block.Calls, 1)
+	assert.Equal(t, hexutil.Uint64(types.ReceiptStatusFailed), block.Calls[0].Status)
+	require.NotNil(t, block.Calls[0].Error)
+	assert.Equal(t, simErrCodeReverted, block.Calls[0].Error.Code)
+	assert.Equal(t, "execution reverted: hello", block.Calls[0].Error.Message)
+
+	// The block numbers should increase.
+	opts.BlockStateCalls[1].BlockOverrides.Number = (*hexutil.Big)(header.Number)
+	_, err = api.SimulateV1(context.Background(), opts, nil)
+	assert.ErrorContains(t, err, "block 1")
+
+	// The nonce and the balance are checked on validation.
+	_, err = api.SimulateV1(context.Background(), EthSimOpts{
+		BlockStateCalls: []EthSimBlock{{Calls: []EthTransactionArgs{{From: &simAccount1, To: &simAccount2, Nonce: &nonce}}}},
+		Validation:      true,
+	}, nil)
+	assert.ErrorIs(t, err, blockchain.ErrNonceTooHigh)
+	_, err = api.SimulateV1(context.Background(), EthSimOpts{
+		BlockStateCalls: []EthSimBlock{{Calls: []EthTransactionArgs{{From: &simAccount2, To: &simAccount1, Value: &KLAY}}}},
+		Validation:      true,
+	}, nil)
+	assert.Error(t, err)
+
+	_, err = api.SimulateV1(context.Background(), EthSimOpts{}, nil)
+	assert.Equal(t, errSimNoBlocks, err)
+}
+
+func TestPublicBlockChainAPI_Simulate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockBackend := mock_api.NewMockBackend(mockCtrl)
+	chainConfig, header := setupSimulateBackend(t, mockBackend)
+	api := NewPublicBlockChainAPI(mockBackend)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	var (
+		txType   = types.TxTypeValueTransfer
+		gas      = hexutil.Uint64(params.TxGas)
+		price    = (*hexutil.Big)(common.Big0)
+		amount   = (*hexutil.Big)(big.NewInt(params.KLAY))
+		nonce    = hexutil.Uint64(0)
+		balance  = (*hexutil.Big)(big.NewInt(params.KLAY * 5))
+		override = &EthStateOverride{from: EthOverrideAccount{Balance: &balance}}
+	)
+	args := KlaySimCallArgs{SendTxArgs: SendTxArgs{
+		TypeInt:      &txType,
+		From:         from,
+		Recipient:    &simAccount2,
+		GasLimit:     &gas,
+		Price:        price,
+		Amount:       amount,
+		AccountNonce: &nonce,
+	}}
+
+	// The unsigned value transfer transaction is simulated without validation.
+	results, err := api.Simulate(context.Background(), KlaySimOpts{BlockStateCalls: []KlaySimBlock{
+		{StateOverrides: override, Calls: []KlaySimCallArgs{args}},
+	}}, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Calls, 1)
+	assert.Equal(t, hexutil.Uint64(types.ReceiptStatusSuccessful), results[0].Calls[0].Status)
+
+	// The signatures are required and validated on validation.
+	validate := func(args KlaySimCallArgs) error {
+		_, err := api.Simulate(context.Background(), KlaySimOpts{
+			BlockStateCalls: []KlaySimBlock{{StateOverrides: override, Calls: []KlaySimCallArgs{args}}},
+			Validation:      true,
+		}, nil)
+		return err
+	}
+	assert.ErrorIs(t, validate(args), errTxArgNilSenderSig)
+
+	sign := func(key []byte) types.TxSignaturesJSON {
+		tx, err := args.toTransaction()
+		require.NoError(t, err)
+		prv, err := crypto.ToECDSA(key)
+		require.NoError(t, err)
+		require.NoError(t, tx.Sign(types.MakeSigner(chainConfig, new(big.Int).Add(header.Number, common.Big1)), prv))
+		return tx.RawSignatureValues().ToJSON()
+	}
+	args.TxSignatures = sign(crypto.FromECDSA(key))
+	assert.NoError(t, validate(args))
+
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	args.TxSignatures = sign(crypto.FromECDSA(otherKey))
+	assert.Error(t, validate(args))
+}
+
+// TestSimulatorPrefundGas tests that the gas fee prefunded without validation
+// doesn't carry over to the following calls.
+func TestSimulatorPrefundGas(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockBackend := mock_api.NewMockBackend(mockCtrl)
+	setupSimulateBackend(t, mockBackend)
+
+	s, err := newSimulator(context.Background(), mockBackend, nil, false)
+	require.NoError(t, err)
+
+	var (
+		gas   = hexutil.Uint64(30000)
+		price = (*hexutil.Big)(big.NewInt(params.Ston))
+		fee   = new(big.Int).Mul(big.NewInt(int64(params.TxGas)), price.ToInt())
+	)
+	_, err = s.simulate(context.Background(), []simBlock{{calls: []simCall{
+		ethSimCall(EthTransactionArgs{From: &simAccount2, To: &simAccount1, Gas: &gas, GasPrice: price}),
+		ethSimCall(EthTransactionArgs{From: &simAccount1, To: &simAccount2, Gas: &gas, GasPrice: price}),
+	}}})
+	require.NoError(t, err)
+
+	// The account without balance is left with nothing, and the others pay the fee as in a block.
+	assert.Zero(t, s.state.GetBalance(simAccount2).Sign())
+	assert.Equal(t, new(big.Int).Sub(big.NewInt(params.KLAY*2), fee), s.state.GetBalance(simAccount1))
+}
+
+// TestSimulatorBlockLimits tests that the gas and the number of the calls in a
+// simulated block are limited.
+func TestSimulatorBlockLimits(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockBackend := mock_api.NewMockBackend(mockCtrl)
+	setupSimulateBackend(t, mockBackend)
+
+	s, err := newSimulator(context.Background(), mockBackend, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, params.UpperGasLimit, s.gasLimit)
+
+	// The calls use the gas left in the block by default.
+	s.gasLimit = 50000
+	gas := hexutil.Uint64(30000)
+	results, err := s.simulate(context.Background(), []simBlock{{calls: []simCall{
+		ethSimCall(EthTransactionArgs{From: &simAccount1, To: &simAccount2}),
+		ethSimCall(EthTransactionArgs{From: &simAccount1, To: &simAccount2}),
+	}}})
+	require.NoError(t, err)
+	assert.Equal(t, hexutil.Uint64(2*params.TxGas), results[0].GasUsed)
+
+	_, err = s.simulate(context.Background(), []simBlock{{calls: []simCall{
+		ethSimCall(EthTransactionArgs{From: &simAccount1, To: &simAccount2}),
+		ethSimCall(EthTransactionArgs{From: &simAccount1, To: &simAccount2, Gas: &gas}),
+	}}})
+	assert.ErrorContains(t, err, "block gas limit reached")
+
+	// The gas limit applies to each block.
+	_, err = s.simulate(context.Background(), []simBlock{
+		{calls: []simCall{ethSimCall(EthTransactionArgs{From: &simAccount1, To: &simAccount2, Gas: &gas})}},
+		{calls: []simCall{ethSimCall(EthTransactionArgs{From: &simAccount1, To: &simAccount2, Gas: &gas})}},
+	})
+	assert.NoError(t, err)
+
+	calls := make([]simCall, maxSimulateCalls+1)
+	_, err = s.simulate(context.Background(), []simBlock{{calls: calls}})
+	assert.ErrorIs(t, err, errSimTooManyCalls)
+}
+
+// TestSimulatorLogIndex tests that the logs are numbered from the start of each
+// simulated block.
+func TestSimulatorLogIndex(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockBackend := mock_api.NewMockBackend(mockCtrl)
+	setupSimulateBackend(t, mockBackend)
+
+	s, err := newSimulator(context.Background(), mockBackend, nil, false)
+	require.NoError(t, err)
+
+	results, err := s.simulate(context.Background(), []simBlock{
+		{calls: []simCall{
+			ethSimCall(EthTransactionArgs{From: &simAccount1, To: &simLogger}),
+			ethSimCall(EthTransactionArgs{From: &simAccount1, To: &simLogger}),
+		}},
+		{calls: []simCall{ethSimCall(EthTransactionArgs{From: &simAccount1, To: &simLogger})}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	var indexes [][]uint
+	for _, block := range results {
+		var blockIndexes []uint
+		for _, call := range block.Calls {
+			require.Len(t, call.Logs, 1)
+			blockIndexes = append(blockIndexes, call.Logs[0].Index)
+		}
+		indexes = append(indexes, blockIndexes)
+	}
+	assert.Equal(t, [][]uint{{0, 1}, {0}}, indexes)
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/klaytn/klaytn/event"
 	"github.com/klaytn/klaytn/networks/rpc"
 	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/snapshot"
 	"github.com/klaytn/klaytn/storage/database"
 )
 
@@ -52,6 +53,7 @@ type Backend interface {
 	UpperBoundGasPrice(ctx context.Context) *big.Int
 	LowerBoundGasPrice(ctx context.Context) *big.Int
 	ChainDB() database.DBManager
+	Snapshots() *snapshot.Tree
 	EventMux() *event.TypeMux
 	AccountManager() accounts.AccountManager
 	RPCEVMTimeout() time.Duration // global timeout for eth/klay_call/estimateGas/estimateComputationCost
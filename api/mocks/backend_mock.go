@@ -22,6 +22,7 @@ import (
 	event "github.com/klaytn/klaytn/event"
 	rpc "github.com/klaytn/klaytn/networks/rpc"
 	params "github.com/klaytn/klaytn/params"
+	snapshot "github.com/klaytn/klaytn/snapshot"
 	database "github.com/klaytn/klaytn/storage/database"
 )
 
@@ -551,6 +552,20 @@ func (mr *MockBackendMockRecorder) SetHead(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHead", reflect.TypeOf((*MockBackend)(nil).SetHead), arg0)
 }
 
+// Snapshots mocks base method.
+func (m *MockBackend) Snapshots() *snapshot.Tree {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshots")
+	ret0, _ := ret[0].(*snapshot.Tree)
+	return ret0
+}
+
+// Snapshots indicates an expected call of Snapshots.
+func (mr *MockBackendMockRecorder) Snapshots() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshots", reflect.TypeOf((*MockBackend)(nil).Snapshots))
+}
+
 // StateAndHeaderByNumber mocks base method.
 func (m *MockBackend) StateAndHeaderByNumber(arg0 context.Context, arg1 rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
 	m.ctrl.T.Helper()
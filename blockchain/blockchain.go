@@ -99,6 +99,7 @@ var (
 // TODO-Kaia: Below should be handled by ini or other configurations.
 const (
 	maxFutureBlocks     = 256
+	maxStateHistories   = 1024 // Maximum number of decoded state histories to cache
 	maxTimeFutureBlocks = 30
 	// TODO-Klaytn-Issue1911  This flag needs to be adjusted to the appropriate value.
 	//  Currently, this value is taken to cache all 10 million accounts
@@ -117,22 +118,31 @@ const (
 	// - Version 4
 	// The following incompatible database changes were added:
 	//   * New scheme for contract code in order to separate the codes and trie nodes
-	BlockChainVersion = 4
+	// - Version 5
+	// The following database changes were added, migrated in the background:
+	//   * Compact encoding for receipts leaving out the fields derivable from the block
+	//     (the version is stored once the receipts migration completes, and the
+	//     receipts in the ancient store are not converted)
+	BlockChainVersion = 5
+
+	// compactReceiptsVersion is the first version storing the receipts in the compact encoding.
+	compactReceiptsVersion = 5
 )
 
 // CacheConfig contains the configuration values for the 1) stateDB caching and
 // 2) trie caching/pruning resident in a blockchain.
 type CacheConfig struct {
 	// TODO-Klaytn-Issue1666 Need to check the benefit of trie caching.
-	ArchiveMode          bool                         // If true, state trie is not pruned and always written to database
-	CacheSize            int                          // Size of in-memory cache of a trie (MiB) to flush matured singleton trie nodes to disk
-	BlockInterval        uint                         // Block interval to flush the trie. Each interval state trie will be flushed into disk
-	TriesInMemory        uint64                       // Maximum number of recent state tries according to its block number
-	LivePruningRetention uint64                       // Number of blocks before trie nodes in pruning marks to be deleted. If zero, obsolete nodes are not deleted.
-	SenderTxHashIndexing bool                         // Enables saving senderTxHash to txHash mapping information to database and cache
-	TrieNodeCacheConfig  *statedb.TrieNodeCacheConfig // Configures trie node cache
-	SnapshotCacheSize    int                          // Memory allowance (MB) to use for caching snapshot entries in memory
-	SnapshotAsyncGen     bool                         // Enables snapshot data generation asynchronously
+	ArchiveMode           bool                         // If true, state trie is not pruned and always written to database
+	CacheSize             int                          // Size of in-memory cache of a trie (MiB) to flush matured singleton trie nodes to disk
+	BlockInterval         uint                         // Block interval to flush the trie. Each interval state trie will be flushed into disk
+	TriesInMemory         uint64                       // Maximum number of recent state tries according to its block number
+	LivePruningRetention  uint64                       // Number of blocks before trie nodes in pruning marks to be deleted. If zero, obsolete nodes are not deleted.
+	SenderTxHashIndexing  bool                         // Enables saving senderTxHash to txHash mapping information to database and cache
+	TrieNodeCacheConfig   *statedb.TrieNodeCacheConfig // Configures trie node cache
+	SnapshotCacheSize     int                          // Memory allowance (MB) to use for caching snapshot entries in memory
+	SnapshotAsyncGen      bool                         // Enables snapshot data generation asynchronously
+	StateHistoryRetention uint64                       // Number of recent blocks whose state histories are kept. If zero, state histories are not recorded.
 }
 
 // gcBlock is used for priority queue for GC.
@@ -183,6 +193,8 @@ type BlockChain struct {
 	stateCache   state.Database // State database to reuse between imports (contains state cache)
 	futureBlocks *lru.Cache     // future blocks are blocks added for later processing
 
+	stateHistories *lru.Cache // decoded state histories of the recent blocks, keyed by block hash
+
 	quit    chan struct{} // blockchain quit channel
 	running int32         // running must be called atomically
 	// procInterrupt must be atomically called
@@ -245,6 +257,7 @@ func NewBlockChain(db database.DBManager, cacheConfig *CacheConfig, chainConfig
 	state.EnabledExpensive = db.GetDBConfig().EnableDBPerfMetrics
 
 	futureBlocks, _ := lru.New(maxFutureBlocks)
+	stateHistories, _ := lru.New(maxStateHistories)
 
 	bc := &BlockChain{
 		chainConfig:        chainConfig,
@@ -256,6 +269,7 @@ func NewBlockChain(db database.DBManager, cacheConfig *CacheConfig, chainConfig
 		stateCache:         state.NewDatabaseWithNewCache(db, cacheConfig.TrieNodeCacheConfig),
 		quit:               make(chan struct{}),
 		futureBlocks:       futureBlocks,
+		stateHistories:     stateHistories,
 		engine:             engine,
 		vmConfig:           vmConfig,
 		parallelDBWrite:    db.IsParallelDBWrite(),
@@ -360,6 +374,7 @@ func NewBlockChain(db database.DBManager, cacheConfig *CacheConfig, chainConfig
 	bc.gcCachedNodeLoop()
 	bc.pruneTrieNodeLoop()
 	bc.restartStateMigration()
+	bc.restartReceiptsMigration()
 
 	if cacheConfig.TrieNodeCacheConfig.DumpPeriodically() {
 		logger.Info("LocalCache is used for trie node cache, start saving cache to file periodically",
@@ -867,6 +882,46 @@ func (bc *BlockChain) ExportN(w io.Writer, first uint64, last uint64) error {
 	return nil
 }
 
+// ExportedReceipts is the RLP entry of the receipts of a block written by ExportReceiptsN.
+type ExportedReceipts struct {
+	Number   uint64
+	Hash     common.Hash
+	Receipts []*types.ReceiptForStorage
+}
+
+// ExportReceiptsN writes the receipts of a subset of the active chain to the given writer.
+func (bc *BlockChain) ExportReceiptsN(w io.Writer, first uint64, last uint64) error {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	if first > last {
+		return fmt.Errorf("export failed: first (%d) is greater than last (%d)", first, last)
+	}
+	logger.Info("Exporting receipts of batch of blocks", "count", last-first+1)
+
+	start, reported := time.Now(), time.Now()
+	for nr := first; nr <= last; nr++ {
+		hash := bc.db.ReadCanonicalHash(nr)
+		if hash == (common.Hash{}) {
+			return fmt.Errorf("export failed on #%d: not found", nr)
+		}
+		receipts := bc.db.ReadReceipts(hash, nr)
+		entry := ExportedReceipts{Number: nr, Hash: hash, Receipts: make([]*types.ReceiptForStorage, len(receipts))}
+		for i, receipt := range receipts {
+			entry.Receipts[i] = (*types.ReceiptForStorage)(receipt)
+		}
+		if err := rlp.Encode(w, &entry); err != nil {
+			return err
+		}
+		if time.Since(reported) >= log.StatsReportLimit {
+			logger.Info("Exporting receipts", "exported", nr-first, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+
+	return nil
+}
+
 // insert injects a new head block into the current block chain. This method
 // assumes that the block is indeed a true head. It will also reset the head
 // header and the head fast sync block to this very same block if they are older
@@ -1294,10 +1349,11 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 	} else {
 		bytes += totalBytes
 	}
+	head := blockChain[len(blockChain)-1]
+	bc.db.ExtendLogIndex(blockChain[0].NumberU64(), head.NumberU64())
 
 	// Update the head fast sync block if better
 	bc.mu.Lock()
-	head := blockChain[len(blockChain)-1]
 	if td := bc.GetTd(head.Hash(), head.NumberU64()); td != nil { // Rewind may have occurred, skip in that case
 		currentFastBlock := bc.CurrentFastBlock()
 		if bc.GetTd(currentFastBlock.Hash(), currentFastBlock.NumberU64()).Cmp(td) < 0 {
@@ -1356,6 +1412,8 @@ func (bc *BlockChain) writeStateTrie(block *types.Block, state *state.StateDB) e
 	if err != nil {
 		return err
 	}
+	bc.writeStateHistory(block, root)
+
 	trieDB := bc.stateCache.TrieDB()
 	trieDB.UpdateMetricNodes()
 
@@ -2723,6 +2781,63 @@ func (bc *BlockChain) SaveTrieNodeCacheToDisk() error {
 	return nil
 }
 
+// ExportTrieNodeCache saves the local trie node cache to the given path.
+func (bc *BlockChain) ExportTrieNodeCache(filePath string) error {
+	return bc.stateCache.TrieDB().ExportTrieNodeCache(filePath, runtime.NumCPU()/2)
+}
+
+// ImportTrieNodeCache replaces the local trie node cache with the one exported
+// to the given path.
+func (bc *BlockChain) ImportTrieNodeCache(filePath string) error {
+	return bc.stateCache.TrieDB().ImportTrieNodeCache(filePath)
+}
+
+// Backup takes a consistent backup of the chain data into the given directory,
+// which can be restored by the restore command. Block insertion is paused only
+// while the checkpoints of the databases are taken, and they are written to
+// the directory afterwards.
+func (bc *BlockChain) Backup(dir string) (*database.BackupManifest, error) {
+	start := time.Now()
+
+	bc.mu.Lock()
+	head := bc.CurrentBlock()
+	if !bc.isArchiveMode() {
+		// The state of the head block may be kept only in memory.
+		if err := bc.stateCache.TrieDB().Commit(head.Root(), false, head.NumberU64()); err != nil {
+			bc.mu.Unlock()
+			return nil, err
+		}
+	}
+	cp, err := bc.db.Checkpoint(dir)
+	bc.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	defer cp.Release()
+
+	logger.Info("Took the checkpoint of the chain data", "number", head.NumberU64(), "hash", head.Hash(),
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	if err := cp.Save(); err != nil {
+		return nil, err
+	}
+
+	dbc := bc.db.GetDBConfig()
+	manifest := &database.BackupManifest{
+		DBType:             dbc.DBType,
+		SingleDB:           dbc.SingleDB,
+		NumStateTrieShards: dbc.NumStateTrieShards,
+		HeadNumber:         head.NumberU64(),
+		HeadHash:           head.Hash(),
+		Time:               start,
+	}
+	if err := database.WriteBackupManifest(dir, manifest); err != nil {
+		return nil, err
+	}
+	logger.Info("Saved the backup of the chain data", "dir", dir, "number", head.NumberU64(),
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return manifest, nil
+}
+
 // ApplyTransaction attempts to apply a transaction to the given state database
 // and uses the input parameters for its environment. It returns the receipt
 // for the transaction, gas used and an error if the transaction failed,
@@ -2805,12 +2920,23 @@ func CheckBlockChainVersion(chainDB database.DBManager) error {
 	if bcVersion != nil && *bcVersion > BlockChainVersion {
 		return fmt.Errorf("database version is v%d, Klaytn %s only supports v%d", *bcVersion, params.Version, BlockChainVersion)
 	} else if bcVersion == nil || *bcVersion < BlockChainVersion {
+		// The receipts in the legacy encoding are converted by the receipts migration,
+		// which stores compactReceiptsVersion once it completes. A new database has
+		// nothing to convert if the compact receipts are enabled.
+		version := uint64(BlockChainVersion)
+		if bcVersion != nil || !chainDB.GetDBConfig().CompactReceipts {
+			chainDB.ScheduleReceiptsMigration()
+			version = compactReceiptsVersion - 1
+		}
+		if bcVersion != nil && *bcVersion >= version {
+			return nil
+		}
 		bcVersionStr := "N/A"
 		if bcVersion != nil {
 			bcVersionStr = strconv.Itoa(int(*bcVersion))
 		}
-		logger.Warn("Upgrade database version", "from", bcVersionStr, "to", BlockChainVersion)
-		chainDB.WriteDatabaseVersion(BlockChainVersion)
+		logger.Warn("Upgrade database version", "from", bcVersionStr, "to", version)
+		chainDB.WriteDatabaseVersion(version)
 	}
 	return nil
 }
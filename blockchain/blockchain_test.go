@@ -1716,27 +1716,63 @@ func BenchmarkBlockChain_1x1000Executions(b *testing.B) {
 
 // TestCheckBlockChainVersion tests the functionality of CheckBlockChainVersion function.
 func TestCheckBlockChainVersion(t *testing.T) {
-	memDB := database.NewMemoryDBManager()
-
-	// 1. If DatabaseVersion is not stored yet,
+	// 1. If DatabaseVersion is not stored yet and the compact receipts are enabled,
 	// calling CheckBlockChainVersion stores BlockChainVersion to DatabaseVersion.
+	memDB := database.NewDBManager(&database.DBConfig{DBType: database.MemoryDB, CompactReceipts: true})
 	assert.Nil(t, memDB.ReadDatabaseVersion())
 	assert.NoError(t, CheckBlockChainVersion(memDB))
 	assert.Equal(t, uint64(BlockChainVersion), *memDB.ReadDatabaseVersion())
+	assert.Nil(t, memDB.ReadReceiptsMigration())
 
-	// 2. If DatabaseVersion is stored but less than BlockChainVersion,
-	// calling CheckBlockChainVersion stores BlockChainVersion to DatabaseVersion.
-	memDB.WriteDatabaseVersion(BlockChainVersion - 1)
+	// 2. If the compact receipts are disabled, the version before the compact receipts
+	// is stored, and the receipts migration is scheduled to upgrade it later.
+	memDB = database.NewMemoryDBManager()
 	assert.NoError(t, CheckBlockChainVersion(memDB))
-	assert.Equal(t, uint64(BlockChainVersion), *memDB.ReadDatabaseVersion())
+	assert.Equal(t, uint64(compactReceiptsVersion-1), *memDB.ReadDatabaseVersion())
+	assert.NotNil(t, memDB.ReadReceiptsMigration())
+
+	// 3. If DatabaseVersion is stored but less than the compact receipts version,
+	// calling CheckBlockChainVersion schedules the receipts migration, which upgrades
+	// DatabaseVersion once it completes.
+	memDB = database.NewDBManager(&database.DBConfig{DBType: database.MemoryDB, CompactReceipts: true})
+	memDB.WriteDatabaseVersion(compactReceiptsVersion - 2)
+	assert.NoError(t, CheckBlockChainVersion(memDB))
+	assert.Equal(t, uint64(compactReceiptsVersion-1), *memDB.ReadDatabaseVersion())
+	assert.NotNil(t, memDB.ReadReceiptsMigration())
 
-	// 3. If DatabaseVersion is stored but greater than BlockChainVersion,
+	// 4. If DatabaseVersion is stored but greater than BlockChainVersion,
 	// calling CheckBlockChainVersion returns an error and does not change the value.
 	memDB.WriteDatabaseVersion(BlockChainVersion + 1)
 	assert.Error(t, CheckBlockChainVersion(memDB))
 	assert.Equal(t, uint64(BlockChainVersion+1), *memDB.ReadDatabaseVersion())
 }
 
+// TestBlockChain_ReceiptsMigration tests that the receipts migration scheduled by
+// the version upgrade runs in background only if the compact receipts are enabled,
+// and that it upgrades the database version once it completes.
+func TestBlockChain_ReceiptsMigration(t *testing.T) {
+	for _, compact := range []bool{false, true} {
+		db := database.NewDBManager(&database.DBConfig{DBType: database.MemoryDB, CompactReceipts: compact})
+		new(Genesis).MustCommit(db)
+		db.WriteDatabaseVersion(compactReceiptsVersion - 1)
+		require.NoError(t, CheckBlockChainVersion(db))
+
+		chain, err := NewBlockChain(db, nil, params.TestChainConfig, gxhash.NewFaker(), vm.Config{})
+		require.NoError(t, err)
+		if compact {
+			assert.Eventually(t, func() bool { return db.ReadReceiptsMigration() == nil }, time.Second, 10*time.Millisecond)
+		}
+		chain.Stop()
+		assert.Equal(t, !compact, db.ReadReceiptsMigration() != nil)
+		if compact {
+			assert.Equal(t, uint64(compactReceiptsVersion), *db.ReadDatabaseVersion())
+		} else {
+			assert.Equal(t, uint64(compactReceiptsVersion-1), *db.ReadDatabaseVersion())
+		}
+		db.Close()
+	}
+}
+
 var (
 	internalTxContractCode string
 	internalTxContractAbi  string
@@ -57,6 +57,10 @@ var (
 	// configured for the transaction pool.
 	ErrUnderpriced = errors.New("transaction underpriced")
 
+	// ErrLargeTxPoolFull is returned if there is no room for a large transaction
+	// in the large transaction sub-pool, even after evicting the cheaper ones.
+	ErrLargeTxPoolFull = errors.New("large transaction pool is full")
+
 	// ErrReplaceUnderpriced is returned if a transaction is attempted to be replaced
 	// with a different one without the required price bump.
 	ErrReplaceUnderpriced = errors.New("replacement transaction underpriced")
@@ -31,6 +31,28 @@ import (
 // NewTxsEvent is posted when a batch of transactions enter the transaction pool.
 type NewTxsEvent struct{ Txs []*types.Transaction }
 
+// TxDropReason is the reason why transactions are removed from the transaction
+// pool without being executed.
+type TxDropReason string
+
+const (
+	TxDropReplaced     TxDropReason = "replaced"     // replaced by another transaction with the same nonce
+	TxDropUnderpriced  TxDropReason = "underpriced"  // discarded for better priced transactions when the pool is full, or for the pending one with the same nonce
+	TxDropCapacity     TxDropReason = "capacity"     // evicted by the slot limits of the pool or of the account
+	TxDropExpired      TxDropReason = "expired"      // queued for longer than the lifetime of the pool
+	TxDropNonceTooLow  TxDropReason = "nonceTooLow"  // another transaction with the same nonce is executed
+	TxDropUnexecutable TxDropReason = "unexecutable" // the sender cannot pay for it or it exceeds the block gas limit
+	TxDropGasPrice     TxDropReason = "gasPrice"     // discarded with all the others when the gas price of the pool is changed
+)
+
+// DroppedTxsEvent is posted when a batch of transactions are removed from the
+// transaction pool without being executed.
+type DroppedTxsEvent struct {
+	Txs         []*types.Transaction
+	Reason      TxDropReason
+	Replacement *types.Transaction // The transaction replacing Txs if Reason is TxDropReplaced
+}
+
 // PendingLogsEvent is posted pre mining and notifies of pending logs.
 type PendingLogsEvent struct {
 	Logs []*types.Log
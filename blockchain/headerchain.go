@@ -434,6 +434,11 @@ func (hc *HeaderChain) SetHead(head uint64, updateFn UpdateHeadBlocksCallback, d
 		hc.currentHeaderHash = parentHash
 	}
 
+	// Rewound blocks may have been moved to the ancient store already.
+	if err := hc.chainDB.TruncateAncients(head + 1); err != nil {
+		return err
+	}
+
 	// Clear out any stale content from the caches
 	hc.chainDB.ClearHeaderChainCache()
 	return nil
@@ -0,0 +1,98 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+// receiptsMigrationLogInterval is the interval of the progress logs of the
+// receipts migration.
+const receiptsMigrationLogInterval = 8 * time.Second
+
+// restartReceiptsMigration resumes the receipts migration scheduled by the
+// database version upgrade in the background. It waits until the compact
+// receipts are enabled, as the migration re-encodes the receipts into them.
+// The database version is upgraded to compactReceiptsVersion once it completes.
+func (bc *BlockChain) restartReceiptsMigration() {
+	next := bc.db.ReadReceiptsMigration()
+	if next == nil {
+		return
+	}
+	if !bc.db.GetDBConfig().CompactReceipts {
+		logger.Info("Receipts migration is postponed until the compact receipts are enabled")
+		return
+	}
+	bc.wg.Add(1)
+	go func() {
+		defer bc.wg.Done()
+		bc.migrateReceipts(next)
+	}()
+}
+
+// migrateReceipts re-encodes the receipts stored in the legacy encoding into the
+// compact one in batches, from the given key. Each batch holds the chain lock,
+// so that the receipts deleted by a reorg or SetHead are not written back.
+func (bc *BlockChain) migrateReceipts(next []byte) {
+	var (
+		start     = time.Now()
+		logged    = time.Now()
+		processed int
+		migrated  int
+	)
+	logger.Info("Receipts migration started", "from", next)
+	for next != nil {
+		select {
+		case <-bc.quit:
+			logger.Info("Receipts migration stopped", "processed", processed, "migrated", migrated, "elapsed", common.PrettyDuration(time.Since(start)))
+			return
+		default:
+		}
+
+		bc.mu.Lock()
+		var (
+			n, m int
+			err  error
+		)
+		next, n, m, err = bc.db.MigrateReceipts(next)
+		bc.mu.Unlock()
+
+		if errors.Is(err, database.ErrReceiptsMigrationUnsupported) {
+			logger.Warn("Receipts migration is skipped, the receipts stored before the upgrade are kept in the legacy encoding",
+				"DBType", bc.db.GetDBConfig().DBType)
+			return
+		}
+		if err != nil {
+			logger.Error("Receipts migration failed", "err", err)
+			return
+		}
+		processed, migrated = processed+n, migrated+m
+
+		if next != nil && time.Since(logged) > receiptsMigrationLogInterval {
+			logger.Info("Migrating receipts", "processed", processed, "migrated", migrated, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if version := bc.db.ReadDatabaseVersion(); version == nil || *version < compactReceiptsVersion {
+		bc.db.WriteDatabaseVersion(compactReceiptsVersion)
+	}
+	logger.Info("Receipts migration completed", "processed", processed, "migrated", migrated, "elapsed", common.PrettyDuration(time.Since(start)))
+}
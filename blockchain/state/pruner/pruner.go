@@ -0,0 +1,486 @@
+// Modifications Copyright 2024 The klaytn Authors
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+//
+// This file is derived from core/state/pruner/pruner.go (2021/10/21).
+// Modified and improved for the klaytn development.
+
+package pruner
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/snapshot"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+const (
+	// stateBloomFilePrefix is the filename prefix of state bloom filter.
+	stateBloomFilePrefix = "statebloom"
+
+	// stateBloomFileSuffix is the filename suffix of state bloom filter.
+	stateBloomFileSuffix = "bf.gz"
+
+	// stateBloomFileTempSuffix is the filename suffix of state bloom filter
+	// while it is being written out to detect write aborts.
+	stateBloomFileTempSuffix = ".tmp"
+
+	// rangeCompactionThreshold is the minimal deleted entry number for
+	// triggering range compaction. It's a quite arbitrary number but just
+	// to avoid triggering range compaction because of small deletion.
+	rangeCompactionThreshold = 100000
+
+	// snapshotLayers is the maximum number of the snapshot layers looked up
+	// for the pruning target. It's large enough to cover all the diff layers.
+	snapshotLayers = 128
+
+	// snapshotCacheSize is the size of the snapshot cache in megabytes.
+	snapshotCacheSize = 256
+)
+
+var logger = log.NewModuleLogger(log.BlockchainState)
+
+var (
+	errLivePruningEnabled = errors.New("live pruning is enabled, offline pruning is not allowed")
+	errInMigration        = errors.New("state migration is in progress, offline pruning is not allowed")
+)
+
+// Config includes all the configurations for pruning.
+type Config struct {
+	Datadir   string // The directory of the state bloom filter
+	BloomSize uint64 // The Megabytes of memory allocated to bloom-filter
+}
+
+// Pruner is an offline tool to prune the stale state with the
+// help of the snapshot. The workflow of pruner is very simple:
+//
+//   - iterate the snapshot, reconstruct the relevant state
+//   - iterate the database, delete all other state entries which
+//     don't belong to the target state and the genesis state
+//
+// It can take several hours(around 2 hours for mainnet) to finish
+// the whole pruning work. It's recommended to run this offline tool
+// periodically in order to release the disk usage and improve the
+// disk read performance to some extent.
+type Pruner struct {
+	config      Config
+	chainHeader *types.Header
+	db          database.DBManager
+	snaptree    *snapshot.Tree
+}
+
+// NewPruner creates the pruner instance.
+func NewPruner(db database.DBManager, config Config) (*Pruner, error) {
+	if err := checkPrunable(db); err != nil {
+		return nil, err
+	}
+	headBlock := readHeadBlock(db)
+	if headBlock == nil {
+		return nil, errors.New("failed to load head block")
+	}
+	snaptree, err := snapshot.New(db, statedb.NewDatabase(db), snapshotCacheSize, headBlock.Root(), false, false, false)
+	if err != nil {
+		return nil, err // The relevant snapshot(s) might not exist
+	}
+	// Sanitize the bloom filter size if it's too small.
+	if config.BloomSize < 256 {
+		logger.Warn("Sanitizing bloomfilter size", "provided(MB)", config.BloomSize, "updated(MB)", 256)
+		config.BloomSize = 256
+	}
+	return &Pruner{
+		config:      config,
+		chainHeader: headBlock.Header(),
+		db:          db,
+		snaptree:    snaptree,
+	}, nil
+}
+
+// checkPrunable returns an error if the state trie database can't be pruned offline.
+func checkPrunable(db database.DBManager) error {
+	// The trie nodes are stored with the extended hashes if live pruning is enabled,
+	// which is not compatible with the state bloom.
+	if db.ReadPruningEnabled() {
+		return errLivePruningEnabled
+	}
+	// The state trie database is being switched to another one.
+	if db.InMigration() {
+		return errInMigration
+	}
+	return nil
+}
+
+// readHeadBlock returns the head block of the canonical chain, or nil if missing.
+func readHeadBlock(db database.DBManager) *types.Block {
+	headBlockHash := db.ReadHeadBlockHash()
+	if headBlockHash == (common.Hash{}) {
+		return nil
+	}
+	return db.ReadBlockByHash(headBlockHash)
+}
+
+func prune(snaptree *snapshot.Tree, root common.Hash, db database.DBManager, stateBloom *stateBloom, bloomPath string, middleStateRoots map[common.Hash]struct{}, start time.Time) error {
+	// Delete all stale trie nodes in the disk. With the help of state bloom
+	// the trie nodes(and codes) belong to the active state will be filtered
+	// out. A very small part of stale tries will also be filtered because of
+	// the false-positive rate of bloom filter. But the assumption is held here
+	// that the false-positive is low enough(~0.05%). The probablity of the
+	// dangling node is the state root is super low. So the dangling nodes in
+	// theory will never ever be visited again.
+	var (
+		count  int
+		size   common.StorageSize
+		pstart = time.Now()
+		logged = time.Now()
+		triedb = db.GetStateTrieDB()
+		batch  = triedb.NewBatch()
+		iter   = triedb.NewIterator(nil, nil)
+	)
+	for iter.Next() {
+		key := iter.Key()
+
+		// All state entries don't belong to specific state and genesis are deleted here
+		// - trie node
+		// - legacy contract code
+		// - new-scheme contract code
+		isCode, codeKey := database.IsCodeKey(key)
+		if len(key) == common.HashLength || isCode {
+			checkKey := key
+			if isCode {
+				checkKey = codeKey
+			}
+			if _, exist := middleStateRoots[common.BytesToHash(checkKey)]; exist {
+				logger.Debug("Forcibly delete the middle state roots", "hash", common.BytesToHash(checkKey))
+			} else {
+				if ok, err := stateBloom.Contain(checkKey); err != nil {
+					iter.Release()
+					return err
+				} else if ok {
+					continue
+				}
+			}
+			count += 1
+			size += common.StorageSize(len(key) + len(iter.Value()))
+			batch.Delete(key)
+
+			var eta time.Duration // Realistically will never remain uninited
+			if done := binary.BigEndian.Uint64(key[:8]); done > 0 {
+				var (
+					left  = math.MaxUint64 - binary.BigEndian.Uint64(key[:8])
+					speed = done/uint64(time.Since(pstart)/time.Millisecond+1) + 1 // +1s to avoid division by zero
+				)
+				eta = time.Duration(left/speed) * time.Millisecond
+			}
+			if time.Since(logged) > 8*time.Second {
+				logger.Info("Pruning state data", "nodes", count, "size", size,
+					"elapsed", common.PrettyDuration(time.Since(pstart)), "eta", common.PrettyDuration(eta))
+				logged = time.Now()
+			}
+			// Recreate the iterator after every batch commit in order
+			// to allow the underlying compactor to delete the entries.
+			if batch.ValueSize() >= database.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					iter.Release()
+					return err
+				}
+				batch.Reset()
+
+				iter.Release()
+				iter = triedb.NewIterator(nil, key)
+			}
+		}
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	if batch.ValueSize() > 0 {
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+	}
+	batch.Release()
+	logger.Info("Pruned state data", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(pstart)))
+
+	// Pruning is done, now drop the "useless" layers from the snapshot.
+	// Firstly, flushing the target layer into the disk. After that all
+	// diff layers below the target will all be merged into the disk.
+	if snaptree.DiskRoot() != root {
+		if err := snaptree.Cap(root, 0); err != nil {
+			return err
+		}
+	}
+	// Secondly, flushing the snapshot journal into the disk. All diff
+	// layers upon the target are dropped silently. Eventually the entire
+	// snapshot tree is converted into a single disk layer with the pruning
+	// target as the root.
+	if _, err := snaptree.Journal(root); err != nil {
+		return err
+	}
+	// Delete the state bloom, it marks the entire pruning procedure is
+	// finished. If any crashes or manual exit happens before this,
+	// `RecoverPruning` will pick it up in the next restarts to redo all
+	// the things.
+	os.RemoveAll(bloomPath)
+
+	// Start compactions, will remove the deleted data from the disk immediately.
+	// Note for small pruning, the compaction is skipped.
+	if count >= rangeCompactionThreshold {
+		cstart := time.Now()
+		for b := 0x00; b <= 0xf0; b += 0x10 {
+			var (
+				start = []byte{byte(b)}
+				end   = []byte{byte(b + 0x10)}
+			)
+			if b == 0xf0 {
+				end = nil
+			}
+			logger.Info("Compacting database", "range", fmt.Sprintf("%#x-%#x", start, end), "elapsed", common.PrettyDuration(time.Since(cstart)))
+			if err := triedb.Compact(start, end); err != nil {
+				logger.Error("Database compaction failed", "error", err)
+				return err
+			}
+		}
+		logger.Info("Database compaction finished", "elapsed", common.PrettyDuration(time.Since(cstart)))
+	}
+	logger.Info("State pruning successful", "pruned", size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// Prune deletes all historical state nodes except the nodes belong to the
+// specified state version. If user doesn't specify the state version, use
+// the state of the head block as the target.
+func (p *Pruner) Prune(root common.Hash) error {
+	// If the state bloom filter is already committed previously,
+	// reuse it for pruning instead of generating a new one. It's
+	// mandatory because a part of state may already be deleted,
+	// the recovery procedure is necessary.
+	_, stateBloomRoot, err := findBloomFilter(p.config.Datadir)
+	if err != nil {
+		return err
+	}
+	if stateBloomRoot != (common.Hash{}) {
+		return RecoverPruning(p.config.Datadir, p.db)
+	}
+	// If the target state root is not specified, use the state of the head
+	// block. The state of the head block is always committed to the disk when
+	// the node is stopped, together with the base of the snapshot tree.
+	if root == (common.Hash{}) {
+		root = p.chainHeader.Root
+	}
+	// Ensure the root is really present. The weak assumption
+	// is the presence of root can indicate the presence of the
+	// entire trie.
+	if ok, _ := p.db.HasTrieNode(root.ExtendZero()); !ok {
+		return fmt.Errorf("associated state[%x] is not present", root)
+	}
+	// The layers above the target are "middle" layers whose state roots
+	// may be committed to the disk. They will be deleted forcibly so that
+	// the blockchain rewinds to the target state on the next start.
+	var (
+		found       bool
+		layers      = p.snaptree.Snapshots(p.chainHeader.Root, snapshotLayers, false)
+		middleRoots = make(map[common.Hash]struct{})
+	)
+	for _, layer := range layers {
+		if layer.Root() == root {
+			found = true
+			break
+		}
+		middleRoots[layer.Root()] = struct{}{}
+	}
+	if !found {
+		return fmt.Errorf("snapshot of the state[%x] is not present", root)
+	}
+	if root != p.chainHeader.Root {
+		logger.Info("Selecting user-specified state as the pruning target", "root", root)
+	} else {
+		logger.Info("Selecting head state as the pruning target", "root", root, "number", p.chainHeader.Number)
+	}
+	start := time.Now()
+	stateBloom, err := newStateBloomWithSize(p.config.BloomSize)
+	if err != nil {
+		return err
+	}
+	// Traverse the target state, re-construct the whole state trie and
+	// commit to the given bloom filter.
+	if err := snapshot.GenerateTrie(p.snaptree, root, p.db, stateBloom); err != nil {
+		return err
+	}
+	// Traverse the genesis, put all genesis state entries into the
+	// bloom filter too.
+	if err := extractGenesis(p.db, stateBloom); err != nil {
+		return err
+	}
+	filterName := bloomFilterName(p.config.Datadir, root)
+
+	logger.Info("Writing state bloom to disk", "name", filterName)
+	if err := stateBloom.Commit(filterName, filterName+stateBloomFileTempSuffix); err != nil {
+		return err
+	}
+	logger.Info("State bloom filter committed", "name", filterName)
+	return prune(p.snaptree, root, p.db, stateBloom, filterName, middleRoots, start)
+}
+
+// RecoverPruning will resume the pruning procedure during the system restart.
+// This function is used in this case: user tries to prune state data, but the
+// system was interrupted midway because of crash or manual-kill. In this case
+// if the bloom filter for filtering active state is already constructed, the
+// pruning can be resumed. What's more if the bloom filter is constructed, the
+// pruning **has to be resumed**. Otherwise a lot of dangling nodes may be left
+// in the disk.
+func RecoverPruning(datadir string, db database.DBManager) error {
+	stateBloomPath, stateBloomRoot, err := findBloomFilter(datadir)
+	if err != nil {
+		return err
+	}
+	if stateBloomPath == "" {
+		return nil // nothing to recover
+	}
+	if err := checkPrunable(db); err != nil {
+		return err
+	}
+	headBlock := readHeadBlock(db)
+	if headBlock == nil {
+		return errors.New("failed to load head block")
+	}
+	// Initialize the snapshot tree in recovery mode to handle this special case:
+	// - Users run the `prune-state` command multiple times
+	// - Neither these `prune-state` running is finished(e.g. interrupted manually)
+	// - The state bloom filter is already generated, a part of state is deleted,
+	//   so that resuming the pruning here is mandatory
+	// - The state HEAD is rewound already because of multiple incomplete `prune-state`
+	// In this case, even the state HEAD is not exactly matched with snapshot, it
+	// still feasible to recover the pruning correctly.
+	snaptree, err := snapshot.New(db, statedb.NewDatabase(db), snapshotCacheSize, headBlock.Root(), false, false, true)
+	if err != nil {
+		return err // The relevant snapshot(s) might not exist
+	}
+	stateBloom, err := NewStateBloomFromDisk(stateBloomPath)
+	if err != nil {
+		return err
+	}
+	logger.Info("Loaded state bloom filter", "path", stateBloomPath)
+
+	// All the state roots of the middle layers should be forcibly pruned,
+	// otherwise the dangling state will be left.
+	var (
+		found       bool
+		layers      = snaptree.Snapshots(headBlock.Root(), snapshotLayers, false)
+		middleRoots = make(map[common.Hash]struct{})
+	)
+	for _, layer := range layers {
+		if layer.Root() == stateBloomRoot {
+			found = true
+			break
+		}
+		middleRoots[layer.Root()] = struct{}{}
+	}
+	if !found {
+		logger.Error("Pruning target state is not existent")
+		return errors.New("non-existent target state")
+	}
+	logger.Info("Resuming the state pruning", "root", stateBloomRoot)
+	return prune(snaptree, stateBloomRoot, db, stateBloom, stateBloomPath, middleRoots, time.Now())
+}
+
+// extractGenesis loads the genesis state and commits all the state entries
+// into the given bloomfilter.
+func extractGenesis(db database.DBManager, stateBloom *stateBloom) error {
+	genesisHash := db.ReadCanonicalHash(0)
+	if genesisHash == (common.Hash{}) {
+		return errors.New("missing genesis hash")
+	}
+	genesis := db.ReadBlock(genesisHash, 0)
+	if genesis == nil {
+		return errors.New("missing genesis block")
+	}
+	// The genesis state may have been deleted by the state migration.
+	if ok, _ := db.HasTrieNode(genesis.Root().ExtendZero()); !ok {
+		logger.Warn("Genesis state is not present, skip preserving it", "root", genesis.Root())
+		return nil
+	}
+	genesisState, err := state.New(genesis.Root(), state.NewDatabase(db), nil, nil)
+	if err != nil {
+		return err
+	}
+	it := state.NewNodeIterator(genesisState)
+	for it.Next() {
+		if it.Hash == (common.Hash{}) {
+			continue // embedded nodes are stored in their parents
+		}
+		if it.Type == "code" {
+			stateBloom.WriteCode(it.Hash, nil)
+		} else {
+			stateBloom.WriteTrieNode(it.Hash.ExtendZero(), nil)
+		}
+	}
+	return it.Error
+}
+
+func bloomFilterName(datadir string, hash common.Hash) string {
+	return filepath.Join(datadir, fmt.Sprintf("%s.%s.%s", stateBloomFilePrefix, hash.Hex(), stateBloomFileSuffix))
+}
+
+func isBloomFilter(filename string) (bool, common.Hash) {
+	filename = filepath.Base(filename)
+	if strings.HasPrefix(filename, stateBloomFilePrefix) && strings.HasSuffix(filename, stateBloomFileSuffix) {
+		return true, common.HexToHash(filename[len(stateBloomFilePrefix)+1 : len(filename)-len(stateBloomFileSuffix)-1])
+	}
+	return false, common.Hash{}
+}
+
+func findBloomFilter(datadir string) (string, common.Hash, error) {
+	var (
+		stateBloomPath string
+		stateBloomRoot common.Hash
+	)
+	if datadir == "" {
+		return "", common.Hash{}, nil
+	}
+	if err := filepath.Walk(datadir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != datadir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ok, root := isBloomFilter(path)
+		if ok {
+			stateBloomPath = path
+			stateBloomRoot = root
+		}
+		return nil
+	}); err != nil && !os.IsNotExist(err) {
+		return "", common.Hash{}, err
+	}
+	return stateBloomPath, stateBloomRoot, nil
+}
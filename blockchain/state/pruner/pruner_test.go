@@ -0,0 +1,194 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/snapshot"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testChainLength = 300
+
+// newTestChainDB generates a chain of testChainLength blocks with value transfers
+// and contract deployments, and returns the database after the chain is stopped.
+// It also returns the state root of a block which is flushed to the disk
+// by the interval commit and becomes stale after pruning.
+func newTestChainDB(t *testing.T) (database.DBManager, common.Hash) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		genesis = &blockchain.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  blockchain.GenesisAlloc{address: {Balance: big.NewInt(params.KLAY)}},
+		}
+		signer = types.LatestSignerForChainID(genesis.Config.ChainID)
+	)
+	db := database.NewDBManager(&database.DBConfig{Dir: t.TempDir(), DBType: database.LevelDB, NumStateTrieShards: 1})
+	t.Cleanup(db.Close)
+	genesisBlock := genesis.MustCommit(db)
+
+	cacheConfig := &blockchain.CacheConfig{
+		CacheSize:           512,
+		BlockInterval:       blockchain.DefaultBlockInterval,
+		TriesInMemory:       blockchain.DefaultTriesInMemory,
+		TrieNodeCacheConfig: statedb.GetEmptyTrieNodeCacheConfig(),
+		SnapshotCacheSize:   512,
+	}
+	chain, err := blockchain.NewBlockChain(db, cacheConfig, genesis.Config, gxhash.NewFaker(), vm.Config{})
+	require.NoError(t, err)
+
+	blocks, _ := blockchain.GenerateChain(genesis.Config, genesisBlock, gxhash.NewFaker(), db, testChainLength, func(i int, block *blockchain.BlockGen) {
+		var tx *types.Transaction
+		if i%2 == 0 {
+			tx = types.NewTransaction(block.TxNonce(address), common.Address{byte(i)}, big.NewInt(1000), params.TxGas, nil, nil)
+		} else {
+			// PUSH1 i PUSH1 0 SSTORE, then return a single byte of runtime code.
+			code := []byte{0x60, byte(i), 0x60, 0x00, 0x55, 0x60, 0x01, 0x60, 0x00, 0xf3}
+			tx = types.NewContractCreation(block.TxNonce(address), common.Big0, 100000, nil, code)
+		}
+		signed, err := types.SignTx(tx, signer, key)
+		require.NoError(t, err)
+		block.AddTx(signed)
+	})
+	_, err = chain.InsertChain(blocks)
+	require.NoError(t, err)
+	chain.Stop()
+
+	staleRoot := blocks[blockchain.DefaultBlockInterval-1].Root()
+	ok, _ := db.HasTrieNode(staleRoot.ExtendZero())
+	require.True(t, ok, "the state of the interval commit should be on disk")
+	return db, staleRoot
+}
+
+// countStateEntries returns the number of entries in the state trie database.
+func countStateEntries(db database.DBManager) int {
+	it := db.GetStateTrieDB().NewIterator(nil, nil)
+	defer it.Release()
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+	return count
+}
+
+// checkStateIntegrity iterates all the nodes and codes of the given state.
+func checkStateIntegrity(t *testing.T, db database.DBManager, root common.Hash) {
+	sdb, err := state.New(root, state.NewDatabase(db), nil, nil)
+	require.NoError(t, err)
+
+	it := state.NewNodeIterator(sdb)
+	for it.Next() {
+	}
+	require.NoError(t, it.Error)
+}
+
+func TestPruner_Prune(t *testing.T) {
+	db, staleRoot := newTestChainDB(t)
+	head := readHeadBlock(db)
+	before := countStateEntries(db)
+
+	datadir := t.TempDir()
+	p, err := NewPruner(db, Config{Datadir: datadir, BloomSize: 256})
+	require.NoError(t, err)
+	p.config.BloomSize = 1 // A small bloom is enough for the test chain.
+	require.NoError(t, p.Prune(common.Hash{}))
+
+	// The stale state is deleted, and the head and the genesis states are left.
+	assert.Less(t, countStateEntries(db), before)
+	ok, _ := db.HasTrieNode(staleRoot.ExtendZero())
+	assert.False(t, ok)
+	checkStateIntegrity(t, db, head.Root())
+	checkStateIntegrity(t, db, db.ReadBlockByNumber(0).Root())
+
+	// The snapshot is flattened into the disk layer of the head state.
+	snaptree, err := snapshot.New(db, statedb.NewDatabase(db), 16, head.Root(), false, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, head.Root(), snaptree.DiskRoot())
+	require.NoError(t, snaptree.Verify(head.Root()))
+
+	// The bloom filter is deleted after pruning.
+	path, _, err := findBloomFilter(datadir)
+	require.NoError(t, err)
+	assert.Empty(t, path)
+
+	// The blockchain is restarted at the head block.
+	cacheConfig := &blockchain.CacheConfig{
+		CacheSize:           512,
+		BlockInterval:       blockchain.DefaultBlockInterval,
+		TriesInMemory:       blockchain.DefaultTriesInMemory,
+		TrieNodeCacheConfig: statedb.GetEmptyTrieNodeCacheConfig(),
+		SnapshotCacheSize:   512,
+	}
+	chain, err := blockchain.NewBlockChain(db, cacheConfig, params.TestChainConfig, gxhash.NewFaker(), vm.Config{})
+	require.NoError(t, err)
+	defer chain.Stop()
+	assert.Equal(t, head.Hash(), chain.CurrentBlock().Hash())
+}
+
+func TestPruner_RecoverPruning(t *testing.T) {
+	db, staleRoot := newTestChainDB(t)
+	head := readHeadBlock(db)
+	datadir := t.TempDir()
+
+	// Nothing to recover without the bloom filter.
+	require.NoError(t, RecoverPruning(datadir, db))
+	ok, _ := db.HasTrieNode(staleRoot.ExtendZero())
+	require.True(t, ok)
+
+	// Simulate a crash right after the bloom filter is committed.
+	p, err := NewPruner(db, Config{Datadir: datadir, BloomSize: 256})
+	require.NoError(t, err)
+	bloom, err := newStateBloomWithSize(1)
+	require.NoError(t, err)
+	require.NoError(t, snapshot.GenerateTrie(p.snaptree, head.Root(), db, bloom))
+	require.NoError(t, extractGenesis(db, bloom))
+	filterName := bloomFilterName(datadir, head.Root())
+	require.NoError(t, bloom.Commit(filterName, filterName+stateBloomFileTempSuffix))
+
+	// A partial deletion must not break the recovery.
+	db.DeleteTrieNode(staleRoot.ExtendZero())
+
+	require.NoError(t, RecoverPruning(datadir, db))
+	checkStateIntegrity(t, db, head.Root())
+	_, err = os.Stat(filterName)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestPruner_LivePruningEnabled(t *testing.T) {
+	db := database.NewDBManager(&database.DBConfig{Dir: t.TempDir(), DBType: database.LevelDB, NumStateTrieShards: 1})
+	defer db.Close()
+
+	db.WritePruningEnabled()
+	_, err := NewPruner(db, Config{Datadir: t.TempDir()})
+	assert.Equal(t, errLivePruningEnabled, err)
+}
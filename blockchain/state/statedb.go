@@ -69,6 +69,7 @@ type StateDB struct {
 	snapDestructs map[common.Hash]struct{}
 	snapAccounts  map[common.Hash][]byte
 	snapStorage   map[common.Hash]map[common.Hash][]byte
+	snapOnly      bool // If true, the state is read only from the snapshot and the trie is unavailable
 
 	// This map holds 'live' objects, which will get modified while processing a state transition.
 	stateObjects             map[common.Address]*stateObject
@@ -153,6 +154,25 @@ func New(root common.Hash, db Database, snaps *snapshot.Tree, opts *statedb.Trie
 	return sdb, nil
 }
 
+// NewWithSnapshot creates a new state of the given root which is read only from
+// the given snapshot. It's used to access a past state whose trie is unavailable,
+// so the state can't be committed.
+func NewWithSnapshot(root common.Hash, db Database, snap snapshot.Snapshot) (*StateDB, error) {
+	sdb, err := New(common.Hash{}, db, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if snap.Root() != root {
+		return nil, fmt.Errorf("snapshot root mismatch, want %x, have %x", root, snap.Root())
+	}
+	sdb.snap = snap
+	sdb.snapDestructs = make(map[common.Hash]struct{})
+	sdb.snapAccounts = make(map[common.Hash][]byte)
+	sdb.snapStorage = make(map[common.Hash]map[common.Hash][]byte)
+	sdb.snapOnly = true
+	return sdb, nil
+}
+
 // RLockGCCachedNode locks the GC lock of CachedNode.
 func (s *StateDB) LockGCCachedNode() {
 	s.db.RLockGCCachedNode()
@@ -640,6 +660,10 @@ func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
 			}
 		}
 	}
+	if s.snapOnly && err != nil {
+		s.setError(err)
+		return nil
+	}
 	// If snapshot unavailable or reading from it failed, load from the database
 	if s.snap == nil || err != nil {
 		// Track the amount of time wasted on loading the object from the database
@@ -874,7 +898,8 @@ func (s *StateDB) Copy() *StateDB {
 	// to not blow up if we ever decide copy it in the middle of a transaction
 	state.accessList = s.accessList.Copy()
 	state.transientStorage = s.transientStorage.Copy()
-	if s.snaps != nil {
+	state.snapOnly = s.snapOnly
+	if s.snaps != nil || s.snapOnly {
 		// In order for the miner to be able to use and make additions
 		// to the snapshot tree, we need to copy that aswell.
 		// Otherwise, any block mined by ourselves will cause gaps in the tree,
@@ -1024,6 +1049,9 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (root common.Hash, err error)
 	if s.dbErr != nil {
 		return common.Hash{}, fmt.Errorf("commit aborted due to earlier error: %v", s.dbErr)
 	}
+	if s.snapOnly {
+		return common.Hash{}, errSnapshotOnly
+	}
 
 	defer s.clearJournalAndRefund()
 
@@ -1115,6 +1143,7 @@ func (s *StateDB) GetTxHash() common.Hash {
 var (
 	errNotExistingAddress = fmt.Errorf("there is no account corresponding to the given address")
 	errNotContractAddress = fmt.Errorf("given address is not a contract address")
+	errSnapshotOnly       = fmt.Errorf("state read only from snapshot can't be committed")
 )
 
 func (s *StateDB) GetContractStorageRoot(contractAddr common.Address) (common.ExtHash, error) {
@@ -0,0 +1,166 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/snapshot"
+)
+
+var (
+	ErrStateHistoryDisabled    = errors.New("state history is disabled")
+	ErrStateHistoryUnavailable = errors.New("state history is unavailable")
+)
+
+// IsStateHistoryEnabled returns true if the state histories are recorded.
+// State histories require the snapshot.
+func (bc *BlockChain) IsStateHistoryEnabled() bool {
+	return bc.cacheConfig.StateHistoryRetention != 0 && bc.snaps != nil
+}
+
+// writeStateHistory stores the state history of the given block, which is the
+// reverse diff taken from the snapshot diff layer of the block, and deletes the
+// state histories older than the retention. It must be called after the state
+// of the block is committed.
+//
+// The state history can't be generated if the snapshot failed to be updated with
+// the block. The block insertion goes on in that case, and the state histories
+// up to the block are dropped, since the states of the older blocks can't be
+// rolled back across the missing one.
+func (bc *BlockChain) writeStateHistory(block *types.Block, root common.Hash) {
+	if !bc.IsStateHistoryEnabled() {
+		return
+	}
+	number := block.NumberU64()
+	history, err := bc.generateStateHistory(block, root)
+	if err != nil {
+		logger.Error("Failed to write state history, dropping the older ones", "number", number, "hash", block.Hash(), "err", err)
+		bc.resetStateHistories(number + 1)
+		return
+	}
+	enc, err := snapshot.EncodeStateHistory(history)
+	if err != nil {
+		logger.Error("Failed to encode state history, dropping the older ones", "number", number, "hash", block.Hash(), "err", err)
+		bc.resetStateHistories(number + 1)
+		return
+	}
+	bc.db.WriteStateHistory(number, block.Hash(), enc)
+	bc.stateHistories.Add(block.Hash(), history)
+
+	// Delete the state histories in [tail, number - retention]
+	tail := bc.db.ReadStateHistoryTail()
+	if tail == nil {
+		bc.db.WriteStateHistoryTail(number)
+		return
+	}
+	retention := bc.cacheConfig.StateHistoryRetention
+	if number < retention || number-retention < *tail {
+		return
+	}
+	limit := number - retention
+	for n := *tail; n <= limit; n++ {
+		bc.db.DeleteStateHistories(n)
+	}
+	bc.db.WriteStateHistoryTail(limit + 1)
+	logger.Trace("Deleted old state histories", "from", *tail, "to", limit)
+}
+
+// generateStateHistory takes the state history of the given block from the
+// snapshot diff layer of the block.
+func (bc *BlockChain) generateStateHistory(block *types.Block, root common.Hash) (*snapshot.StateHistory, error) {
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, errors.New("parent header missing")
+	}
+	return bc.snaps.StateHistory(root, parent.Root)
+}
+
+// resetStateHistories moves the tail of the state histories to the given block
+// and deletes the ones before it. The lookups of the blocks before the tail fail
+// with ErrStateHistoryUnavailable.
+func (bc *BlockChain) resetStateHistories(next uint64) {
+	tail := bc.db.ReadStateHistoryTail()
+	bc.db.WriteStateHistoryTail(next)
+	if tail == nil {
+		return
+	}
+	for n := *tail; n < next; n++ {
+		bc.db.DeleteStateHistories(n)
+	}
+}
+
+// readStateHistory returns the decoded state history of the given block, from
+// the cache if it has been recently decoded or written.
+func (bc *BlockChain) readStateHistory(number uint64, hash common.Hash) (*snapshot.StateHistory, error) {
+	if cached, ok := bc.stateHistories.Get(hash); ok {
+		return cached.(*snapshot.StateHistory), nil
+	}
+	enc := bc.db.ReadStateHistory(number, hash)
+	if len(enc) == 0 {
+		return nil, fmt.Errorf("%w: state history of block %d is missing", ErrStateHistoryUnavailable, number)
+	}
+	history, err := snapshot.DecodeStateHistory(enc)
+	if err != nil {
+		return nil, err
+	}
+	bc.stateHistories.Add(hash, history)
+	return history, nil
+}
+
+// HistoricalStateAt returns a read-only state of the given canonical block which
+// is read from the snapshot rolled back with the state histories. It's used to
+// access the state whose trie is pruned.
+func (bc *BlockChain) HistoricalStateAt(header *types.Header) (*state.StateDB, error) {
+	if !bc.IsStateHistoryEnabled() {
+		return nil, ErrStateHistoryDisabled
+	}
+	number := header.Number.Uint64()
+	if bc.db.ReadCanonicalHash(number) != header.Hash() {
+		return nil, fmt.Errorf("%w: block %d (%x) is not canonical", ErrStateHistoryUnavailable, number, header.Hash())
+	}
+	head := bc.CurrentBlock()
+	if number > head.NumberU64() {
+		return nil, fmt.Errorf("%w: block %d is beyond the head", ErrStateHistoryUnavailable, number)
+	}
+	// The state histories of the blocks after the given one are needed, and they
+	// may still be cached after being deleted beyond the retention.
+	if tail := bc.db.ReadStateHistoryTail(); number < head.NumberU64() && (tail == nil || number+1 < *tail) {
+		return nil, fmt.Errorf("%w: block %d is beyond the retention", ErrStateHistoryUnavailable, number)
+	}
+	base := bc.snaps.Snapshot(head.Root())
+	if base == nil {
+		return nil, fmt.Errorf("%w: snapshot of the head block is missing", ErrStateHistoryUnavailable)
+	}
+	histories := make([]*snapshot.StateHistory, 0, head.NumberU64()-number)
+	for n := number + 1; n <= head.NumberU64(); n++ {
+		history, err := bc.readStateHistory(n, bc.db.ReadCanonicalHash(n))
+		if err != nil {
+			return nil, err
+		}
+		histories = append(histories, history)
+	}
+	snap, err := snapshot.NewHistoricalSnapshot(base, header.Root, histories)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStateHistoryUnavailable, err)
+	}
+	return state.NewWithSnapshot(header.Root, bc.stateCache, snap)
+}
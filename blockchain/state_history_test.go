@@ -0,0 +1,194 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockChain_HistoricalStateAt(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{address: {Balance: big.NewInt(params.KLAY)}},
+		}
+		signer   = types.LatestSignerForChainID(gspec.Config.ChainID)
+		contract = crypto.CreateAddress(address, 0)
+
+		// The runtime code increments the slot 0 whenever it's called.
+		// PUSH1 0 SLOAD PUSH1 1 ADD PUSH1 0 SSTORE STOP
+		runtime  = []byte{0x60, 0x00, 0x54, 0x60, 0x01, 0x01, 0x60, 0x00, 0x55, 0x00}
+		initcode = append([]byte{0x60, 0x0a, 0x60, 0x0c, 0x60, 0x00, 0x39, 0x60, 0x0a, 0x60, 0x00, 0xf3}, runtime...)
+
+		numBlocks = 20
+		retention = uint64(10)
+	)
+	db := database.NewMemoryDBManager()
+	genesis := gspec.MustCommit(db)
+
+	cacheConfig := &CacheConfig{
+		ArchiveMode:           true, // Keep all the tries to compare with the historical states
+		CacheSize:             512,
+		BlockInterval:         DefaultBlockInterval,
+		TriesInMemory:         DefaultTriesInMemory,
+		TrieNodeCacheConfig:   statedb.GetEmptyTrieNodeCacheConfig(),
+		SnapshotCacheSize:     512,
+		StateHistoryRetention: retention,
+	}
+	chain, err := NewBlockChain(db, cacheConfig, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	require.NoError(t, err)
+	defer chain.Stop()
+	require.True(t, chain.IsStateHistoryEnabled())
+
+	blocks, _ := GenerateChain(gspec.Config, genesis, gxhash.NewFaker(), db, numBlocks, func(i int, block *BlockGen) {
+		var tx *types.Transaction
+		switch {
+		case i == 0:
+			tx = types.NewContractCreation(block.TxNonce(address), common.Big0, 100000, nil, initcode)
+		case i%3 == 0:
+			return // Empty block which doesn't modify the state
+		case i%3 == 1:
+			tx = types.NewTransaction(block.TxNonce(address), contract, common.Big0, 100000, nil, nil)
+		default:
+			tx = types.NewTransaction(block.TxNonce(address), common.Address{byte(i)}, big.NewInt(1000), params.TxGas, nil, nil)
+		}
+		signed, err := types.SignTx(tx, signer, key)
+		require.NoError(t, err)
+		block.AddTx(signed)
+	})
+	_, err = chain.InsertChain(blocks)
+	require.NoError(t, err)
+
+	head := chain.CurrentBlock().NumberU64()
+	require.Equal(t, uint64(numBlocks), head)
+	require.Equal(t, head-retention+1, *db.ReadStateHistoryTail())
+
+	// The contract is called 3 times until the oldest block of the retention.
+	oldest, err := chain.HistoricalStateAt(chain.GetHeaderByNumber(head - retention))
+	require.NoError(t, err)
+	assert.Equal(t, common.BigToHash(big.NewInt(3)), oldest.GetState(contract, common.Hash{}))
+
+	addrs := []common.Address{address, contract}
+	for i := 0; i < numBlocks; i++ {
+		addrs = append(addrs, common.Address{byte(i)})
+	}
+	for n := head - retention; n <= head; n++ {
+		header := chain.GetHeaderByNumber(n)
+		expected, err := chain.StateAt(header.Root)
+		require.NoError(t, err)
+		historical, err := chain.HistoricalStateAt(header)
+		require.NoError(t, err, "block %d", n)
+
+		for _, addr := range addrs {
+			assert.Equal(t, expected.GetBalance(addr), historical.GetBalance(addr), "block %d, address %x", n, addr)
+			assert.Equal(t, expected.GetNonce(addr), historical.GetNonce(addr), "block %d, address %x", n, addr)
+			assert.Equal(t, expected.GetCode(addr), historical.GetCode(addr), "block %d, address %x", n, addr)
+		}
+		assert.Equal(t, expected.GetState(contract, common.Hash{}), historical.GetState(contract, common.Hash{}), "block %d", n)
+		assert.NoError(t, historical.Error())
+
+		// The historical state is read-only.
+		_, err = historical.Commit(true)
+		assert.Error(t, err)
+	}
+
+	// The state histories beyond the retention are deleted.
+	_, err = chain.HistoricalStateAt(chain.GetHeaderByNumber(head - retention - 1))
+	assert.ErrorIs(t, err, ErrStateHistoryUnavailable)
+
+	// The decoded state histories are cached, so they are not read again.
+	for n := head - retention + 1; n <= head; n++ {
+		db.DeleteStateHistories(n)
+	}
+	_, err = chain.HistoricalStateAt(chain.GetHeaderByNumber(head - retention))
+	assert.NoError(t, err)
+
+	chain.stateHistories.Purge()
+	_, err = chain.HistoricalStateAt(chain.GetHeaderByNumber(head - retention))
+	assert.ErrorIs(t, err, ErrStateHistoryUnavailable)
+}
+
+// TestBlockChain_StateHistoryGap tests that the block insertion goes on if a state
+// history fails to be written, and that the states before the gap are unavailable.
+func TestBlockChain_StateHistoryGap(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{address: {Balance: big.NewInt(params.KLAY)}},
+		}
+		signer = types.LatestSignerForChainID(gspec.Config.ChainID)
+	)
+	db := database.NewMemoryDBManager()
+	genesis := gspec.MustCommit(db)
+
+	cacheConfig := &CacheConfig{
+		CacheSize:             512,
+		BlockInterval:         DefaultBlockInterval,
+		TriesInMemory:         DefaultTriesInMemory,
+		TrieNodeCacheConfig:   statedb.GetEmptyTrieNodeCacheConfig(),
+		SnapshotCacheSize:     512,
+		StateHistoryRetention: 100,
+	}
+	chain, err := NewBlockChain(db, cacheConfig, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	require.NoError(t, err)
+	defer chain.Stop()
+
+	blocks, _ := GenerateChain(gspec.Config, genesis, gxhash.NewFaker(), db, 10, func(i int, block *BlockGen) {
+		tx := types.NewTransaction(block.TxNonce(address), common.Address{byte(i)}, big.NewInt(1000), params.TxGas, nil, nil)
+		signed, err := types.SignTx(tx, signer, key)
+		require.NoError(t, err)
+		block.AddTx(signed)
+	})
+	_, err = chain.InsertChain(blocks[:5])
+	require.NoError(t, err)
+	_, err = chain.HistoricalStateAt(blocks[0].Header())
+	require.NoError(t, err)
+
+	// The state history of block 5 can't be generated from an unknown root.
+	chain.writeStateHistory(blocks[4], common.HexToHash("0x1234"))
+	assert.Equal(t, uint64(6), *db.ReadStateHistoryTail())
+	for n := uint64(1); n <= 5; n++ {
+		assert.Empty(t, db.ReadStateHistory(n, blocks[n-1].Hash()), "block %d", n)
+	}
+
+	_, err = chain.InsertChain(blocks[5:])
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6), *db.ReadStateHistoryTail())
+
+	for n := uint64(5); n <= 10; n++ {
+		_, err = chain.HistoricalStateAt(blocks[n-1].Header())
+		assert.NoError(t, err, "block %d", n)
+	}
+	_, err = chain.HistoricalStateAt(blocks[3].Header())
+	assert.ErrorIs(t, err, ErrStateHistoryUnavailable)
+}
@@ -303,6 +303,31 @@ func (l *txList) Overlaps(tx *types.Transaction) bool {
 	return l.txs.Get(tx.Nonce()) != nil
 }
 
+// Replaceable returns whether the given transaction can be inserted into the list,
+// replacing the transaction of the same nonce if there is one.
+func (l *txList) Replaceable(tx *types.Transaction, priceBump uint64, magmaHardforked bool) bool {
+	old := l.txs.Get(tx.Nonce())
+	if old == nil {
+		return true
+	}
+	// If tx is CancelTransaction, replace it even thought tx has lower gasPrice than previous tx.
+	if tx.Type().IsCancelTransaction() {
+		logger.Trace("New tx is a cancel transaction. replace it!", "old", old.String(), "new", tx.String())
+	} else if magmaHardforked {
+		if old.GasPrice().Cmp(tx.GasPrice()) >= 0 {
+			// If gas price of older is bigger than newer, abort.
+			logger.Trace("already nonce exist and the gasprice is lower then older", "nonce", tx.Nonce(), "with gasprice", old.GasPrice(), "priceBump", priceBump, "new tx.gasprice", tx.GasPrice())
+			return false
+		}
+		// Otherwise overwrite the old transaction with the current one.
+		logger.Trace("The transaction was substituted by competitive gas price", "old", old.String(), "new", tx.String())
+	} else {
+		logger.Trace("already nonce exist", "nonce", tx.Nonce(), "with gasprice", old.GasPrice(), "priceBump", priceBump, "new tx.gasprice", tx.GasPrice())
+		return false
+	}
+	return true
+}
+
 // Add tries to insert a new transaction into the list, returning whether the
 // transaction was accepted, and if yes, any previous transaction it replaced.
 //
@@ -310,25 +335,10 @@ func (l *txList) Overlaps(tx *types.Transaction) bool {
 // thresholds are also potentially updated.
 func (l *txList) Add(tx *types.Transaction, priceBump uint64, magmaHardforked bool) (bool, *types.Transaction) {
 	// If there's an older better transaction, abort
-	old := l.txs.Get(tx.Nonce())
-	if old != nil {
-		// If tx is CancelTransaction, replace it even thought tx has lower gasPrice than previous tx.
-		if tx.Type().IsCancelTransaction() {
-			logger.Trace("New tx is a cancel transaction. replace it!", "old", old.String(), "new", tx.String())
-		} else if magmaHardforked {
-			if old.GasPrice().Cmp(tx.GasPrice()) >= 0 {
-				// If gas price of older is bigger than newer, abort.
-				logger.Trace("already nonce exist and the gasprice is lower then older", "nonce", tx.Nonce(), "with gasprice", old.GasPrice(), "priceBump", priceBump, "new tx.gasprice", tx.GasPrice())
-				return false, nil
-			}
-			// Otherwise overwrite the old transaction with the current one.
-			logger.Trace("The transaction was substituted by competitive gas price", "old", old.String(), "new", tx.String())
-		} else {
-			logger.Trace("already nonce exist", "nonce", tx.Nonce(), "with gasprice", old.GasPrice(), "priceBump", priceBump, "new tx.gasprice", tx.GasPrice())
-			return false, nil
-		}
+	if !l.Replaceable(tx, priceBump, magmaHardforked) {
+		return false, nil
 	}
-
+	old := l.txs.Get(tx.Nonce())
 	l.txs.Put(tx)
 	return true, old
 }
@@ -622,7 +632,9 @@ func (l *txPricedList) Underpriced(tx *types.Transaction, local *accountSet) boo
 }
 
 // Discard finds a number of most underpriced transactions, removes them from the
-// priced list and returns them for further removal from the entire pool.
+// priced list and returns them for further removal from the entire pool. The
+// transactions of the large transaction sub-pool are kept since they don't use
+// any slot.
 func (l *txPricedList) Discard(slots int, local *accountSet) types.Transactions {
 	drop := make(types.Transactions, 0, slots) // Remote underpriced transactions to drop
 	save := make(types.Transactions, 0, 64)    // Local underpriced transactions to keep
@@ -634,8 +646,8 @@ func (l *txPricedList) Discard(slots int, local *accountSet) types.Transactions
 			l.stales--
 			continue
 		}
-		// Non stale transaction found, discard unless local
-		if local.containsTx(tx) {
+		// Non stale transaction found, discard unless local or large
+		if local.containsTx(tx) || l.all.IsLarge(tx) {
 			save = append(save, tx)
 		} else {
 			drop = append(drop, tx)
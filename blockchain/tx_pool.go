@@ -92,6 +92,16 @@ var (
 	underpricedTxCounter = metrics.NewRegisteredCounter("txpool/underpriced", nil)
 	refusedTxCounter     = metrics.NewRegisteredCounter("txpool/refuse", nil)
 	slotsGauge           = metrics.NewRegisteredGauge("txpool/slots", nil)
+	bytesGauge           = metrics.NewRegisteredGauge("txpool/bytes", nil)
+
+	// Metrics for the large transaction sub-pool
+	largeBytesGauge      = metrics.NewRegisteredGauge("txpool/large/bytes", nil)
+	largeRefusedCounter  = metrics.NewRegisteredCounter("txpool/large/refuse", nil)
+	largeEvictionCounter = metrics.NewRegisteredCounter("txpool/large/evict", nil)
+
+	// droppedEventDiscardCounter counts the dropped tx events discarded since the
+	// subscribers could not keep up with them.
+	droppedEventDiscardCounter = metrics.NewRegisteredCounter("txpool/dropped/discard", nil)
 )
 
 // TxStatus is the current status of a transaction as seen by the pool.
@@ -131,6 +141,9 @@ type TxPoolConfig struct {
 	NonExecSlotsAccount uint64 // Maximum number of non-executable transaction slots permitted per account
 	NonExecSlotsAll     uint64 // Maximum number of non-executable transaction slots for all accounts
 
+	LargeTxSize     uint64 // Size in bytes over which a transaction goes to the large transaction sub-pool (0 = disabled)
+	LargeTxPoolSize uint64 // Maximum size in bytes of all the transactions in the large transaction sub-pool
+
 	KeepLocals bool          // Disables removing timed-out local transactions
 	Lifetime   time.Duration // Maximum amount of time non-executable transaction are queued
 
@@ -152,6 +165,9 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	NonExecSlotsAccount: 64,
 	NonExecSlotsAll:     1024,
 
+	LargeTxSize:     0, // The large transaction sub-pool is opt-in
+	LargeTxPoolSize: 32 * 1024 * 1024,
+
 	KeepLocals: false,
 	Lifetime:   5 * time.Minute,
 }
@@ -172,9 +188,27 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		logger.Error("Sanitizing invalid txpool price bump", "provided", conf.PriceBump, "updated", DefaultTxPoolConfig.PriceBump)
 		conf.PriceBump = DefaultTxPoolConfig.PriceBump
 	}
+	if conf.LargeTxSize > 0 && conf.LargeTxPoolSize < MaxTxDataSize {
+		logger.Error("Sanitizing invalid txpool large tx pool size", "provided", conf.LargeTxPoolSize, "updated", uint64(MaxTxDataSize))
+		conf.LargeTxPoolSize = MaxTxDataSize
+	}
 	return conf
 }
 
+// IsLargeTx returns true if the transaction belongs to the large transaction
+// sub-pool. The large transactions are accounted by their sizes against
+// LargeTxPoolSize instead of the transaction slots, and are only announced to
+// the peers instead of being broadcast.
+func (config *TxPoolConfig) IsLargeTx(tx *types.Transaction) bool {
+	return isLargeTx(tx, config.LargeTxSize)
+}
+
+// isLargeTx returns true if the transaction is larger than the given size.
+// A largeTxSize of 0 disables the large transaction sub-pool.
+func isLargeTx(tx *types.Transaction, largeTxSize uint64) bool {
+	return largeTxSize > 0 && uint64(tx.Size()) > largeTxSize
+}
+
 // TxPool contains all currently known transactions. Transactions
 // enter the pool when they are received from the network or submitted
 // locally. They exit the pool when they are included in the blockchain.
@@ -188,6 +222,7 @@ type TxPool struct {
 	chain        blockChain
 	gasPrice     *big.Int
 	txFeed       event.Feed
+	droppedFeed  event.Feed
 	scope        event.SubscriptionScope
 	chainHeadCh  chan ChainHeadEvent
 	chainHeadSub event.Subscription
@@ -212,8 +247,9 @@ type TxPool struct {
 
 	wg sync.WaitGroup // for shutdown sync
 
-	txMsgCh  chan types.Transactions // A buffer for async tx intake via AddRemotes
-	txFeedCh chan types.Transactions // A buffer for async tx event emission via txFeed
+	txMsgCh       chan types.Transactions // A buffer for async tx intake via AddRemotes
+	txFeedCh      chan types.Transactions // A buffer for async tx event emission via txFeed
+	droppedFeedCh chan DroppedTxsEvent    // A buffer for async dropped tx event emission via droppedFeed
 
 	rules params.Rules // Fork indicator
 }
@@ -226,19 +262,20 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 
 	// Create the transaction pool with its initial settings
 	pool := &TxPool{
-		config:       config,
-		chainconfig:  chainconfig,
-		chain:        chain,
-		signer:       types.LatestSignerForChainID(chainconfig.ChainID),
-		pending:      make(map[common.Address]*txList),
-		queue:        make(map[common.Address]*txList),
-		beats:        make(map[common.Address]time.Time),
-		all:          newTxLookup(),
-		pendingNonce: make(map[common.Address]uint64),
-		chainHeadCh:  make(chan ChainHeadEvent, chainHeadChanSize),
-		gasPrice:     new(big.Int).SetUint64(chainconfig.UnitPrice),
-		txMsgCh:      make(chan types.Transactions, txMsgChSize),
-		txFeedCh:     make(chan types.Transactions, txFeedChSize),
+		config:        config,
+		chainconfig:   chainconfig,
+		chain:         chain,
+		signer:        types.LatestSignerForChainID(chainconfig.ChainID),
+		pending:       make(map[common.Address]*txList),
+		queue:         make(map[common.Address]*txList),
+		beats:         make(map[common.Address]time.Time),
+		all:           newTxLookup(config.LargeTxSize),
+		pendingNonce:  make(map[common.Address]uint64),
+		chainHeadCh:   make(chan ChainHeadEvent, chainHeadChanSize),
+		gasPrice:      new(big.Int).SetUint64(chainconfig.UnitPrice),
+		txMsgCh:       make(chan types.Transactions, txMsgChSize),
+		txFeedCh:      make(chan types.Transactions, txFeedChSize),
+		droppedFeedCh: make(chan DroppedTxsEvent, txFeedChSize),
 	}
 	pool.locals = newAccountSet(pool.signer)
 	pool.priced = newTxPricedList(pool.all)
@@ -259,10 +296,11 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 	pool.chainHeadSub = pool.chain.SubscribeChainHeadEvent(pool.chainHeadCh)
 
 	// Start the event loop and return
-	pool.wg.Add(3)
+	pool.wg.Add(4)
 	go pool.loop()
 	go pool.handleTxMsg()
 	go pool.handleTxFeed()
+	go pool.handleDroppedTxFeed()
 
 	if config.EnableSpamThrottlerAtRuntime {
 		if err := pool.StartSpamThrottler(DefaultSpamThrottlerConfig); err != nil {
@@ -344,9 +382,11 @@ func (pool *TxPool) loop() {
 				// Any non-locals old enough should be removed
 				if time.Since(beat) > pool.config.Lifetime {
 					if pool.queue[addr] != nil {
-						for _, tx := range pool.queue[addr].Flatten() {
+						expired := pool.queue[addr].Flatten()
+						for _, tx := range expired {
 							pool.removeTx(tx.Hash(), true)
 						}
+						pool.notifyDropped(TxDropExpired, nil, expired)
 					}
 					delete(pool.beats, addr)
 				}
@@ -517,6 +557,28 @@ func (pool *TxPool) SubscribeNewTxsEvent(ch chan<- NewTxsEvent) event.Subscripti
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SubscribeDroppedTxsEvent registers a subscription of DroppedTxsEvent and
+// starts sending event to the given channel.
+func (pool *TxPool) SubscribeDroppedTxsEvent(ch chan<- DroppedTxsEvent) event.Subscription {
+	return pool.scope.Track(pool.droppedFeed.Subscribe(ch))
+}
+
+// notifyDropped notifies subsystems of the transactions removed from the pool
+// without being executed. It never blocks since it is called with the pool lock
+// held, so the event is discarded if the buffer is full.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) notifyDropped(reason TxDropReason, replacement *types.Transaction, txs types.Transactions) {
+	if len(txs) == 0 {
+		return
+	}
+	select {
+	case pool.droppedFeedCh <- DroppedTxsEvent{Txs: txs, Reason: reason, Replacement: replacement}:
+	default:
+		droppedEventDiscardCounter.Inc(int64(len(txs)))
+	}
+}
+
 // GasPrice returns the current gas price enforced by the transaction pool.
 func (pool *TxPool) GasPrice() *big.Int {
 	pool.mu.RLock()
@@ -536,15 +598,25 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 
 		logger.Info("TxPool.SetGasPrice", "before", pool.gasPrice, "after", price)
 
+		var drops types.Transactions
+		for _, list := range pool.pending {
+			drops = append(drops, list.Flatten()...)
+		}
+		for _, list := range pool.queue {
+			drops = append(drops, list.Flatten()...)
+		}
+
 		pool.gasPrice = price
 		pool.pending = make(map[common.Address]*txList)
 		pool.queue = make(map[common.Address]*txList)
 		pool.beats = make(map[common.Address]time.Time)
-		pool.all = newTxLookup()
+		pool.all = newTxLookup(pool.config.LargeTxSize)
 		pool.pendingNonce = make(map[common.Address]uint64)
 		pool.locals = newAccountSet(pool.signer)
 		pool.priced = newTxPricedList(pool.all)
 
+		pool.notifyDropped(TxDropGasPrice, nil, drops)
+
 		pool.mu.Unlock()
 	}
 }
@@ -593,7 +665,9 @@ func (pool *TxPool) Content() (map[common.Address]types.Transactions, map[common
 
 // Pending retrieves all currently processable transactions, groupped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
-// freely modified by calling code.
+// freely modified by calling code. The accounts are not ordered here, since the
+// block building orders them by its ordering policy against the base fee of the
+// block being built.
 func (pool *TxPool) Pending() (map[common.Address]types.Transactions, error) {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
@@ -608,7 +682,8 @@ func (pool *TxPool) Pending() (map[common.Address]types.Transactions, error) {
 }
 
 // CachedPendingTxsByCount retrieves about number of currently processable transactions
-// by requested count, grouped by origin account and sorted by nonce.
+// by requested count, grouped by origin account and sorted by nonce. It only picks the
+// transactions resent to the peers, so it does not follow the block ordering policy.
 func (pool *TxPool) CachedPendingTxsByCount(count int) types.Transactions {
 	if count <= 0 {
 		return nil
@@ -884,12 +959,26 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		return false, err
 	}
 
+	// The large transactions are accounted by their sizes in a separate sub-pool,
+	// so that they don't evict the regular ones under memory pressure.
 	// If the transaction pool is full and new Tx is valid,
 	// (1) discard a new Tx if there is no room for the account of the Tx
 	// (2) remove an old Tx with the largest nonce from queue to make a room for a new Tx with missing nonce
 	// (3) discard a new Tx if the new Tx does not have a missing nonce
 	// (4) discard underpriced transactions
-	if uint64(pool.all.Slots()+numSlots(tx)) > pool.config.ExecSlotsAll+pool.config.NonExecSlotsAll {
+	if pool.all.IsLarge(tx) {
+		// Check the replacement first, not to evict the others for a transaction
+		// which is discarded anyway.
+		replaced, err := pool.replacedTx(tx)
+		if err != nil {
+			logger.Trace("Discarding a new large Tx, because it cannot replace the one of the same nonce", "hash", hash)
+			return false, err
+		}
+		if err := pool.makeRoomForLargeTx(tx, replaced, local); err != nil {
+			logger.Trace("Rejecting a new large Tx, because the large tx pool is full", "hash", hash, "size", tx.Size())
+			return false, err
+		}
+	} else if uint64(pool.all.Slots()+numSlots(tx)) > pool.config.ExecSlotsAll+pool.config.NonExecSlotsAll {
 		// (1) discard a new Tx if there is no room for the account of the Tx
 		from, _ := types.Sender(pool.signer, tx)
 		if pool.queue[from] == nil {
@@ -902,6 +991,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		if maxTx != tx {
 			// (2) remove an old Tx with the largest nonce from queue to make a room for a new Tx with missing nonce
 			pool.removeTx(maxTx.Hash(), true)
+			pool.notifyDropped(TxDropCapacity, nil, types.Transactions{maxTx})
 			logger.Trace("Removing an old Tx with the max nonce to insert a new Tx with missing nonce, because TxPool is full", "account", from, "new nonce(previously missing)", tx.Nonce(), "removed max nonce", maxTx.Nonce())
 		} else {
 			// (3) discard a new Tx if the new Tx does not have a missing nonce
@@ -924,6 +1014,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 			underpricedTxCounter.Inc(1)
 			pool.removeTx(tx.Hash(), false)
 		}
+		pool.notifyDropped(TxDropUnderpriced, nil, drop)
 	}
 	// If the transaction is replacing an already pending one, do directly
 	from, _ := types.Sender(pool.signer, tx) // already validated
@@ -939,6 +1030,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed()
 			pendingReplaceCounter.Inc(1)
+			pool.notifyDropped(TxDropReplaced, tx, types.Transactions{old})
 		}
 		pool.all.Add(tx)
 		pool.priced.Put(tx)
@@ -966,6 +1058,102 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 	return replace, nil
 }
 
+// makeRoomForLargeTx evicts transactions from the large transaction sub-pool
+// until the given large transaction fits into LargeTxPoolSize. Local transactions
+// are never evicted, and a remote transaction can only evict the remote ones paying
+// a lower gas price, the cheapest and the newest first. If there is not enough
+// room even after the eviction, nothing is evicted and ErrLargeTxPoolFull is
+// returned. The replaced transaction, if any, makes room for the given one and is
+// not evicted.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) makeRoomForLargeTx(tx, replaced *types.Transaction, local bool) error {
+	size, used := uint64(tx.Size()), pool.all.LargeBytes()
+	// The replaced transaction leaves the sub-pool for the new one
+	if replaced != nil && pool.all.IsLarge(replaced) {
+		used -= uint64(replaced.Size())
+	}
+	if used+size <= pool.config.LargeTxPoolSize {
+		return nil
+	}
+	local = local || pool.locals.containsTx(tx)
+
+	candidates := make(types.Transactions, 0, pool.all.LargeCount())
+	for _, large := range pool.all.LargeTxs() {
+		if pool.locals.containsTx(large) || (replaced != nil && large.Hash() == replaced.Hash()) {
+			continue
+		}
+		if !local && large.GasPrice().Cmp(tx.GasPrice()) >= 0 {
+			continue
+		}
+		candidates = append(candidates, large)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if cmp := candidates[i].GasPrice().Cmp(candidates[j].GasPrice()); cmp != 0 {
+			return cmp < 0
+		}
+		return candidates[i].Time().After(candidates[j].Time())
+	})
+
+	var drop types.Transactions
+	for _, candidate := range candidates {
+		if used+size <= pool.config.LargeTxPoolSize {
+			break
+		}
+		drop = append(drop, candidate)
+		used -= uint64(candidate.Size())
+	}
+	if used+size > pool.config.LargeTxPoolSize {
+		largeRefusedCounter.Inc(1)
+		refusedTxCounter.Inc(1)
+		return ErrLargeTxPoolFull
+	}
+	for _, large := range drop {
+		logger.Trace("Evicting a large transaction to make room for a new one", "hash", large.Hash(), "price", large.GasPrice(), "size", large.Size())
+		pool.removeTx(large.Hash(), true)
+	}
+	largeEvictionCounter.Inc(int64(len(drop)))
+	pool.notifyDropped(TxDropCapacity, nil, drop)
+	return nil
+}
+
+// replacedTx returns the transaction of the same sender and nonce which the given
+// transaction replaces, or nil if there is none. It returns ErrAlreadyNonceExistInPool
+// if the given transaction cannot replace it.
+func (pool *TxPool) replacedTx(tx *types.Transaction) (*types.Transaction, error) {
+	from, _ := types.Sender(pool.signer, tx) // already validated
+	if list := pool.pending[from]; list != nil && list.Overlaps(tx) {
+		if !list.Replaceable(tx, pool.config.PriceBump, pool.rules.IsMagma) {
+			pendingDiscardCounter.Inc(1)
+			return nil, ErrAlreadyNonceExistInPool
+		}
+		return list.txs.Get(tx.Nonce()), nil
+	}
+	if list := pool.queue[from]; list != nil && list.Overlaps(tx) {
+		if !list.Replaceable(tx, pool.config.PriceBump, pool.rules.IsMagma) {
+			queuedDiscardCounter.Inc(1)
+			return nil, ErrAlreadyNonceExistInPool
+		}
+		return list.txs.Get(tx.Nonce()), nil
+	}
+	return nil, nil
+}
+
+// numRegularTxs returns the number of the transactions in the list which are not
+// in the large transaction sub-pool, and thus count against the slot limits.
+func (pool *TxPool) numRegularTxs(list *txList) uint64 {
+	if pool.config.LargeTxSize == 0 {
+		return uint64(list.Len())
+	}
+	count := uint64(0)
+	for _, tx := range list.txs.items {
+		if !pool.all.IsLarge(tx) {
+			count++
+		}
+	}
+	return count
+}
+
 // enqueueTx inserts a new transaction into the non-executable transaction queue.
 //
 // Note, this method assumes the pool lock is held!
@@ -986,6 +1174,7 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed()
 		queuedReplaceCounter.Inc(1)
+		pool.notifyDropped(TxDropReplaced, tx, types.Transactions{old})
 	}
 	if pool.all.Get(hash) == nil {
 		pool.all.Add(tx)
@@ -1026,6 +1215,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.priced.Removed()
 
 		pendingDiscardCounter.Inc(1)
+		pool.notifyDropped(TxDropUnderpriced, nil, types.Transactions{tx})
 		return false
 	}
 	// Otherwise discard any previous transaction and mark this
@@ -1034,6 +1224,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.priced.Removed()
 
 		pendingReplaceCounter.Inc(1)
+		pool.notifyDropped(TxDropReplaced, tx, types.Transactions{old})
 	}
 	// Failsafe to work around direct pending inserts (tests)
 	if pool.all.Get(hash) == nil {
@@ -1190,6 +1381,21 @@ func (pool *TxPool) handleTxFeed() {
 	}
 }
 
+// handleDroppedTxFeed sends the dropped tx events apart from handleTxFeed, so that
+// slow subscribers of them do not delay the new tx events.
+func (pool *TxPool) handleDroppedTxFeed() {
+	defer pool.wg.Done()
+
+	for {
+		select {
+		case ev := <-pool.droppedFeedCh:
+			pool.droppedFeed.Send(ev)
+		case <-pool.chainHeadSub.Err():
+			return
+		}
+	}
+}
+
 // AddLocal enqueues a single transaction into the pool if it is valid, marking
 // the sender as a local one in the mean time, ensuring it goes around the local
 // pricing constraints.
@@ -1199,9 +1405,9 @@ func (pool *TxPool) AddLocal(tx *types.Transaction) error {
 	}
 
 	pool.mu.RLock()
-	poolSize := uint64(pool.all.Count())
+	poolSize := uint64(pool.all.Count() - pool.all.LargeCount())
 	pool.mu.RUnlock()
-	if poolSize >= pool.config.ExecSlotsAll+pool.config.NonExecSlotsAll {
+	if !pool.config.IsLargeTx(tx) && poolSize >= pool.config.ExecSlotsAll+pool.config.NonExecSlotsAll {
 		return fmt.Errorf("txpool is full: %d", poolSize)
 	}
 	return pool.addTx(tx, !pool.config.NoLocals)
@@ -1233,7 +1439,7 @@ func (pool *TxPool) AddRemotes(txs []*types.Transaction) []error {
 // so it can fit into TxPool's capacity.
 func (pool *TxPool) checkAndAddTxs(txs []*types.Transaction, local bool) []error {
 	pool.mu.RLock()
-	poolSize := uint64(pool.all.Count())
+	poolSize := uint64(pool.all.Count() - pool.all.LargeCount())
 	pool.mu.RUnlock()
 	poolCapacity := int(pool.config.ExecSlotsAll + pool.config.NonExecSlotsAll - poolSize)
 	numTxs := len(txs)
@@ -1391,8 +1597,11 @@ func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
 func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 	pool.txMu.Lock()
 	defer pool.txMu.Unlock()
-	// Track the promoted transactions to broadcast them at once
-	var promoted []*types.Transaction
+	// Track the promoted and dropped transactions to broadcast them at once
+	var (
+		promoted                    []*types.Transaction
+		stales, unpayables, cappeds types.Transactions
+	)
 
 	// Gather all the accounts potentially needing updates
 	if accounts == nil {
@@ -1409,12 +1618,14 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			continue // Just in case someone calls with a non existing account
 		}
 		// Drop all transactions that are deemed too old (low nonce)
-		for _, tx := range list.Forward(pool.getNonce(addr)) {
+		forwards := list.Forward(pool.getNonce(addr))
+		for _, tx := range forwards {
 			hash := tx.Hash()
 			logger.Trace("Removed old queued transaction", "hash", hash)
 			pool.all.Remove(hash)
 			pool.priced.Removed()
 		}
+		stales = append(stales, forwards...)
 		// Drop all transactions that are too costly (low balance)
 		drops, _ := list.Filter(addr, pool)
 		for _, tx := range drops {
@@ -1424,6 +1635,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			pool.priced.Removed()
 			queuedNofundsCounter.Inc(1)
 		}
+		unpayables = append(unpayables, drops...)
 
 		// Gather all executable transactions and promote them
 		var readyTxs types.Transactions
@@ -1442,13 +1654,15 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 
 		// Drop all transactions over the allowed limit
 		if !pool.locals.contains(addr) {
-			for _, tx := range list.Cap(int(pool.config.NonExecSlotsAccount)) {
+			caps := list.Cap(int(pool.config.NonExecSlotsAccount))
+			for _, tx := range caps {
 				hash := tx.Hash()
 				pool.all.Remove(hash)
 				pool.priced.Removed()
 				queuedRateLimitCounter.Inc(1)
 				logger.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
+			cappeds = append(cappeds, caps...)
 		}
 		// Delete the entire queue entry if it became empty.
 		if list.Empty() {
@@ -1462,7 +1676,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 	// If the pending limit is overflown, start equalizing allowances
 	pending := uint64(0)
 	for _, list := range pool.pending {
-		pending += uint64(list.Len())
+		pending += pool.numRegularTxs(list)
 	}
 
 	if pending > pool.config.ExecSlotsAll {
@@ -1500,8 +1714,11 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 							// Update the account nonce to the dropped transaction
 							pool.updatePendingNonce(offenders[i], tx.Nonce())
 							logger.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
+							cappeds = append(cappeds, tx)
+							if !pool.all.IsLarge(tx) {
+								pending--
+							}
 						}
-						pending--
 					}
 				}
 			}
@@ -1520,8 +1737,11 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 						// Update the account nonce to the dropped transaction
 						pool.updatePendingNonce(addr, tx.Nonce())
 						logger.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
+						cappeds = append(cappeds, tx)
+						if !pool.all.IsLarge(tx) {
+							pending--
+						}
 					}
-					pending--
 				}
 			}
 		}
@@ -1530,7 +1750,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 	// If we've queued more transactions than the hard limit, drop oldest ones
 	queued := uint64(0)
 	for _, list := range pool.queue {
-		queued += uint64(list.Len())
+		queued += pool.numRegularTxs(list)
 	}
 
 	if queued > pool.config.NonExecSlotsAll {
@@ -1551,9 +1771,10 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			addresses = addresses[:len(addresses)-1]
 
 			// Drop all transactions if they are less than the overflow
-			if size := uint64(list.Len()); size <= drop {
+			if size := pool.numRegularTxs(list); size <= drop {
 				for _, tx := range list.Flatten() {
 					pool.removeTx(tx.Hash(), true)
+					cappeds = append(cappeds, tx)
 				}
 				drop -= size
 				queuedRateLimitCounter.Inc(int64(size))
@@ -1563,11 +1784,17 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			txs := list.Flatten()
 			for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
 				pool.removeTx(txs[i].Hash(), true)
-				drop--
+				cappeds = append(cappeds, txs[i])
+				if !pool.all.IsLarge(txs[i]) {
+					drop--
+				}
 				queuedRateLimitCounter.Inc(1)
 			}
 		}
 	}
+	pool.notifyDropped(TxDropNonceTooLow, nil, stales)
+	pool.notifyDropped(TxDropUnexecutable, nil, unpayables)
+	pool.notifyDropped(TxDropCapacity, nil, cappeds)
 }
 
 // demoteUnexecutables removes invalid and processed transactions from the pools
@@ -1579,6 +1806,9 @@ func (pool *TxPool) demoteUnexecutables() {
 
 	// full-validation count. demoteUnexecutables does full-validation for a limited number of txs.
 	cnt := 0
+	// Track the dropped transactions to broadcast them at once. The old ones
+	// are not tracked since they are mostly included in the new block.
+	var unpayables types.Transactions
 	// Iterate over all accounts and demote any non-executable transactions
 	for addr, list := range pool.pending {
 		nonce := pool.getNonce(addr)
@@ -1609,6 +1839,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			pool.priced.Removed()
 			pendingNofundsCounter.Inc(1)
 		}
+		unpayables = append(unpayables, drops...)
 
 		for _, tx := range invalids {
 			hash := tx.Hash()
@@ -1648,6 +1879,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			delete(pool.pending, addr)
 		}
 	}
+	pool.notifyDropped(TxDropUnexecutable, nil, unpayables)
 }
 
 // getNonce returns the nonce of the account from the cache. If it is not in the cache, it gets the nonce from the stateDB.
@@ -1750,19 +1982,30 @@ func (as *accountSet) add(addr common.Address) {
 // TxPool.mu mutex.
 type txLookup struct {
 	all   map[common.Hash]*types.Transaction
-	slots int
-	lock  sync.RWMutex
+	slots int    // Number of slots used by the transactions not in the large sub-pool
+	bytes uint64 // Total size of all the transactions
+
+	largeTxSize uint64                             // Size over which a transaction is in the large sub-pool, 0 to disable
+	large       map[common.Hash]*types.Transaction // Transactions in the large sub-pool
+	largeBytes  uint64                             // Total size of the transactions in the large sub-pool
+
+	lock sync.RWMutex
 }
 
 // newTxLookup returns a new txLookup structure.
-func newTxLookup() *txLookup {
+func newTxLookup(largeTxSize uint64) *txLookup {
 	slotsGauge.Update(int64(0))
+	bytesGauge.Update(int64(0))
+	largeBytesGauge.Update(int64(0))
 	return &txLookup{
-		all: make(map[common.Hash]*types.Transaction),
+		all:         make(map[common.Hash]*types.Transaction),
+		largeTxSize: largeTxSize,
+		large:       make(map[common.Hash]*types.Transaction),
 	}
 }
 
-// Slots returns the current number of slots used in the lookup.
+// Slots returns the current number of slots used in the lookup. The transactions
+// in the large sub-pool don't use any slot.
 func (t *txLookup) Slots() int {
 	t.lock.RLock()
 	defer t.lock.RUnlock()
@@ -1770,6 +2013,47 @@ func (t *txLookup) Slots() int {
 	return t.slots
 }
 
+// Bytes returns the total size of the transactions in the lookup.
+func (t *txLookup) Bytes() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.bytes
+}
+
+// LargeBytes returns the total size of the transactions in the large sub-pool.
+func (t *txLookup) LargeBytes() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.largeBytes
+}
+
+// LargeCount returns the number of the transactions in the large sub-pool.
+func (t *txLookup) LargeCount() int {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return len(t.large)
+}
+
+// LargeTxs returns the transactions in the large sub-pool.
+func (t *txLookup) LargeTxs() types.Transactions {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	txs := make(types.Transactions, 0, len(t.large))
+	for _, tx := range t.large {
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// IsLarge returns true if the transaction belongs to the large sub-pool.
+func (t *txLookup) IsLarge(tx *types.Transaction) bool {
+	return isLargeTx(tx, t.largeTxSize)
+}
+
 // Range calls f on each key and value present in the map.
 func (t *txLookup) Range(f func(hash common.Hash, tx *types.Transaction) bool) {
 	t.lock.RLock()
@@ -1803,10 +2087,23 @@ func (t *txLookup) Add(tx *types.Transaction) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
-	t.slots += numSlots(tx)
-	slotsGauge.Update(int64(t.slots))
+	hash, size := tx.Hash(), uint64(tx.Size())
+	if _, ok := t.all[hash]; ok {
+		return
+	}
+	t.bytes += size
+	bytesGauge.Update(int64(t.bytes))
 
-	t.all[tx.Hash()] = tx
+	if t.IsLarge(tx) {
+		t.large[hash] = tx
+		t.largeBytes += size
+		largeBytesGauge.Update(int64(t.largeBytes))
+	} else {
+		t.slots += numSlots(tx)
+		slotsGauge.Update(int64(t.slots))
+	}
+
+	t.all[hash] = tx
 }
 
 // Remove removes a transaction from the lookup.
@@ -1814,8 +2111,22 @@ func (t *txLookup) Remove(hash common.Hash) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
-	t.slots -= numSlots(t.all[hash])
-	slotsGauge.Update(int64(t.slots))
+	tx, ok := t.all[hash]
+	if !ok {
+		return
+	}
+	size := uint64(tx.Size())
+	t.bytes -= size
+	bytesGauge.Update(int64(t.bytes))
+
+	if _, ok := t.large[hash]; ok {
+		delete(t.large, hash)
+		t.largeBytes -= size
+		largeBytesGauge.Update(int64(t.largeBytes))
+	} else {
+		t.slots -= numSlots(tx)
+		slotsGauge.Update(int64(t.slots))
+	}
 
 	delete(t.all, hash)
 }
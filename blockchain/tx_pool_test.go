@@ -214,6 +214,21 @@ func validateTxPoolInternals(pool *TxPool) error {
 	if priced := pool.priced.items.Len() - pool.priced.stales; priced != pending+queued {
 		return fmt.Errorf("total priced transaction count %d != %d pending + %d queued", priced, pending, queued)
 	}
+	// Ensure the slots and the sizes are accounted correctly
+	slots, bytes, largeBytes := 0, uint64(0), uint64(0)
+	pool.all.Range(func(hash common.Hash, tx *types.Transaction) bool {
+		bytes += uint64(tx.Size())
+		if pool.all.IsLarge(tx) {
+			largeBytes += uint64(tx.Size())
+		} else {
+			slots += numSlots(tx)
+		}
+		return true
+	})
+	if slots != pool.all.Slots() || bytes != pool.all.Bytes() || largeBytes != pool.all.LargeBytes() {
+		return fmt.Errorf("accounting mismatch: have slots %d bytes %d large bytes %d, want %d, %d and %d",
+			pool.all.Slots(), pool.all.Bytes(), pool.all.LargeBytes(), slots, bytes, largeBytes)
+	}
 	// Ensure the next nonce to assign is the correct one
 	for addr, txs := range pool.pending {
 		// Find the last transaction
@@ -783,6 +798,151 @@ func TestTransactionDropping(t *testing.T) {
 	}
 }
 
+// Tests that the transactions dropped from the pool are notified with the
+// reason of the removal.
+func TestTransactionDroppedEvents(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	drops := make(chan DroppedTxsEvent, 10)
+	sub := pool.SubscribeDroppedTxsEvent(drops)
+	defer sub.Unsubscribe()
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, account, big.NewInt(1000))
+
+	var (
+		tx0  = transaction(0, 100, key)
+		tx1  = transaction(1, 200, key)
+		tx10 = transaction(10, 100, key)
+		tx11 = transaction(11, 200, key)
+	)
+	pool.promoteTx(account, tx0.Hash(), tx0)
+	pool.promoteTx(account, tx1.Hash(), tx1)
+	pool.enqueueTx(tx10.Hash(), tx10)
+	pool.enqueueTx(tx11.Hash(), tx11)
+
+	// Reduce the balance of the account, and check that the invalidated
+	// transactions are notified as unexecutable
+	testAddBalance(pool, account, big.NewInt(-750))
+	pool.lockedReset(nil, nil)
+
+	dropped := make(map[common.Hash]TxDropReason)
+	for len(dropped) < 2 {
+		select {
+		case ev := <-drops:
+			for _, tx := range ev.Txs {
+				dropped[tx.Hash()] = ev.Reason
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("dropped transaction event timeout: have %d, want %d", len(dropped), 2)
+		}
+	}
+	assert.Equal(t, map[common.Hash]TxDropReason{
+		tx1.Hash():  TxDropUnexecutable,
+		tx11.Hash(): TxDropUnexecutable,
+	}, dropped)
+
+	select {
+	case ev := <-drops:
+		t.Fatalf("unexpected dropped transaction event: %v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Change the gas price, and check that all the remaining transactions are notified
+	pool.SetGasPrice(new(big.Int).Add(pool.GasPrice(), common.Big1))
+	select {
+	case ev := <-drops:
+		var hashes []common.Hash
+		for _, tx := range ev.Txs {
+			hashes = append(hashes, tx.Hash())
+		}
+		assert.Equal(t, TxDropGasPrice, ev.Reason)
+		assert.ElementsMatch(t, []common.Hash{tx0.Hash(), tx10.Hash()}, hashes)
+	case <-time.After(time.Second):
+		t.Fatal("dropped transaction event timeout")
+	}
+}
+
+// Tests that a transaction losing to the pending one of the same nonce on the
+// promotion is notified as underpriced, not as replaced.
+func TestTransactionDroppedEventsPromotion(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	drops := make(chan DroppedTxsEvent, 10)
+	sub := pool.SubscribeDroppedTxsEvent(drops)
+	defer sub.Unsubscribe()
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, account, big.NewInt(1000000))
+
+	var (
+		tx0      = pricedTransaction(0, 100000, big.NewInt(2), key)
+		tx0Cheap = pricedTransaction(0, 100000, big.NewInt(1), key)
+	)
+
+	pool.mu.Lock()
+	assert.True(t, pool.promoteTx(account, tx0.Hash(), tx0))
+	assert.False(t, pool.promoteTx(account, tx0Cheap.Hash(), tx0Cheap))
+	pool.mu.Unlock()
+
+	select {
+	case ev := <-drops:
+		assert.Equal(t, TxDropUnderpriced, ev.Reason)
+		assert.Nil(t, ev.Replacement)
+		assert.Equal(t, types.Transactions{tx0Cheap}, types.Transactions(ev.Txs))
+	case <-time.After(time.Second):
+		t.Fatal("dropped transaction event timeout")
+	}
+}
+
+// Tests that a subscriber not receiving the dropped transaction events stalls
+// neither the pool nor the new transaction events.
+func TestTransactionDroppedEventsSlowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	sub := pool.SubscribeDroppedTxsEvent(make(chan DroppedTxsEvent))
+	defer sub.Unsubscribe()
+
+	txs := make(chan NewTxsEvent, 1)
+	txSub := pool.SubscribeNewTxsEvent(txs)
+	defer txSub.Unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 2*txFeedChSize; i++ {
+			pool.mu.Lock()
+			pool.notifyDropped(TxDropCapacity, nil, types.Transactions{transaction(uint64(i), 100, key)})
+			pool.mu.Unlock()
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("dropped transaction events blocked the pool")
+	}
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, account, big.NewInt(1000000))
+	if err := pool.AddRemote(transaction(0, 100000, key)); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	select {
+	case <-txs:
+	case <-time.After(time.Second):
+		t.Fatal("new transaction event timeout")
+	}
+}
+
 // Tests that if a transaction is dropped from the current pending pool (e.g. out
 // of fund), all consecutive (still valid, but not executable) transactions are
 // postponed back into the future queue to prevent broadcasting them.
@@ -1376,6 +1536,159 @@ func TestTransactionAllowedTxSize(t *testing.T) {
 	}
 }
 
+// Tests that the large transactions are accounted in the large transaction
+// sub-pool, so that they neither use nor exhaust the slots of the regular ones.
+func TestTransactionLargeTxAccounting(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil, nil)
+	blockchain := &testBlockChain{statedb, 10000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.ExecSlotsAll = 2
+	config.NonExecSlotsAll = 0
+	config.LargeTxSize = txSlotSize
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	keys := make([]*ecdsa.PrivateKey, 5)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000000))
+	}
+
+	// The large transactions don't use any slot, even more than the slots
+	var largeBytes uint64
+	for i := 0; i < 3; i++ {
+		tx := pricedDataTransaction(0, 5000000, big.NewInt(1), keys[i], 40*1024)
+		assert.True(t, pool.config.IsLargeTx(tx))
+		assert.NoError(t, pool.AddRemote(tx))
+		largeBytes += uint64(tx.Size())
+	}
+	assert.Equal(t, 0, pool.all.Slots())
+	assert.Equal(t, largeBytes, pool.all.LargeBytes())
+
+	// The regular transactions still fit into the slots
+	for i := 3; i < 5; i++ {
+		tx := transaction(0, 100000, keys[i])
+		assert.False(t, pool.config.IsLargeTx(tx))
+		assert.NoError(t, pool.AddRemote(tx))
+	}
+	pending, queued := pool.Stats()
+	assert.Equal(t, 5, pending)
+	assert.Equal(t, 0, queued)
+	assert.Equal(t, 2, pool.all.Slots())
+	assert.Equal(t, largeBytes, pool.all.LargeBytes())
+	assert.Equal(t, largeBytes+uint64(pool.all.Get(transaction(0, 100000, keys[3]).Hash()).Size())*2, pool.all.Bytes())
+
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the large transaction sub-pool is capped by its size, evicting the
+// cheapest remote transactions for better paying or local newcomers.
+func TestTransactionLargeTxPoolEviction(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil, nil)
+	blockchain := &testBlockChain{statedb, 10000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.LargeTxSize = txSlotSize
+	config.LargeTxPoolSize = MaxTxDataSize // Room for three transactions of 40KB
+
+	// The gas prices can differ after the magma hardfork
+	pool := NewTxPool(config, kip71Config, blockchain)
+	pool.SetBaseFee(big.NewInt(1))
+	defer pool.Stop()
+
+	newLargeTx := func(price int64) *types.Transaction {
+		key, _ := crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000000))
+		return pricedDataTransaction(0, 5000000, big.NewInt(price), key, 40*1024)
+	}
+
+	txs := types.Transactions{newLargeTx(2), newLargeTx(3), newLargeTx(4)}
+	for _, tx := range txs {
+		assert.NoError(t, pool.AddRemote(tx))
+	}
+
+	// A remote transaction cannot evict the ones paying as much as it
+	assert.Equal(t, ErrLargeTxPoolFull, pool.AddRemote(newLargeTx(1)))
+	assert.Equal(t, ErrLargeTxPoolFull, pool.AddRemote(newLargeTx(2)))
+	assert.Equal(t, 3, pool.all.LargeCount())
+
+	// A better paying remote transaction evicts the cheapest one
+	better := newLargeTx(5)
+	assert.NoError(t, pool.AddRemote(better))
+	assert.Nil(t, pool.Get(txs[0].Hash()))
+	assert.NotNil(t, pool.Get(better.Hash()))
+
+	// A local transaction evicts the cheapest remote one regardless of its price
+	local := newLargeTx(1)
+	assert.NoError(t, pool.AddLocal(local))
+	assert.Nil(t, pool.Get(txs[1].Hash()))
+	assert.NotNil(t, pool.Get(local.Hash()))
+
+	// The local transactions are never evicted
+	assert.NoError(t, pool.AddRemote(newLargeTx(6)))
+	assert.NoError(t, pool.AddRemote(newLargeTx(7)))
+	assert.NotNil(t, pool.Get(local.Hash()))
+	assert.Equal(t, ErrLargeTxPoolFull, pool.AddRemote(newLargeTx(6)))
+	assert.LessOrEqual(t, pool.all.LargeBytes(), config.LargeTxPoolSize)
+
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that a large transaction replacing another one of the same nonce is checked
+// before evicting anything, and takes the room of the replaced one.
+func TestTransactionLargeTxReplacement(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(database.NewMemoryDBManager()), nil, nil)
+	blockchain := &testBlockChain{statedb, 10000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.LargeTxSize = txSlotSize
+	config.LargeTxPoolSize = MaxTxDataSize // Room for three transactions of 40KB
+
+	pool := NewTxPool(config, kip71Config, blockchain)
+	pool.SetBaseFee(big.NewInt(1))
+	defer pool.Stop()
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	txs := make(types.Transactions, 3)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000000000))
+		txs[i] = pricedDataTransaction(0, 5000000, big.NewInt(int64(i+2)), keys[i], 40*1024)
+		assert.NoError(t, pool.AddRemote(txs[i]))
+	}
+
+	// A replacement not paying more than the replaced one evicts nothing
+	assert.Equal(t, ErrAlreadyNonceExistInPool, pool.AddRemote(pricedDataTransaction(0, 5000000, big.NewInt(3), keys[2], 40*1024)))
+	for _, tx := range txs {
+		assert.NotNil(t, pool.Get(tx.Hash()))
+	}
+
+	// A better paying replacement takes the room of the replaced one
+	replacement := pricedDataTransaction(0, 5000000, big.NewInt(5), keys[0], 40*1024)
+	assert.NoError(t, pool.AddRemote(replacement))
+	assert.Nil(t, pool.Get(txs[0].Hash()))
+	assert.NotNil(t, pool.Get(replacement.Hash()))
+	assert.NotNil(t, pool.Get(txs[1].Hash()))
+	assert.NotNil(t, pool.Get(txs[2].Hash()))
+	assert.Equal(t, 3, pool.all.LargeCount())
+
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Tests that if transactions start being capped, transactions are also removed from 'all'
 func TestTransactionCapClearsFromAll(t *testing.T) {
 	t.Parallel()
@@ -21,6 +21,7 @@
 package types
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"unsafe"
@@ -125,6 +126,18 @@ type receiptStorageRLP struct {
 	GasUsed         uint64
 }
 
+// receiptCompactStorageRLP is the compact storage encoding of a receipt. The
+// fields derivable from the block are left out, see Receipts.DeriveFields.
+type receiptCompactStorageRLP struct {
+	Status  uint
+	GasUsed uint64
+	Logs    []*Log
+}
+
+// receiptCompactStorageFields is the number of fields of receiptCompactStorageRLP,
+// used to tell the compact storage encoding from the legacy one.
+const receiptCompactStorageFields = 3
+
 // NewReceipt creates a barebone transaction receipt, copying the init fields.
 func NewReceipt(status uint, txHash common.Hash, gasUsed uint64) *Receipt {
 	return &Receipt{
@@ -210,12 +223,127 @@ func (r *ReceiptForStorage) DecodeRLP(s *rlp.Stream) error {
 	return nil
 }
 
+// ReceiptForCompactStorage is a wrapper around a Receipt that encodes only the
+// fields which cannot be derived from the block: the status, the gas used and
+// the consensus fields of the logs.
+type ReceiptForCompactStorage Receipt
+
+// EncodeRLP implements rlp.Encoder, and flattens the non-derivable fields of a
+// receipt into an RLP stream.
+func (r *ReceiptForCompactStorage) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, &receiptCompactStorageRLP{r.Status, r.GasUsed, r.Logs})
+}
+
+// DecodeRLP implements rlp.Decoder, and loads the non-derivable fields of a
+// receipt from an RLP stream.
+func (r *ReceiptForCompactStorage) DecodeRLP(s *rlp.Stream) error {
+	var dec receiptCompactStorageRLP
+	if err := s.Decode(&dec); err != nil {
+		return err
+	}
+	r.Status, r.GasUsed, r.Logs = dec.Status, dec.GasUsed, dec.Logs
+	return nil
+}
+
+// DecodeStoredReceipts decodes the receipts of a block stored either in the
+// compact storage encoding or in the legacy one with all the fields. It also
+// reports whether the receipts are compact, whose derivable fields should be
+// filled by Receipts.DeriveFields.
+func DecodeStoredReceipts(data []byte) (Receipts, bool, error) {
+	compact, err := IsCompactStoredReceipts(data)
+	if err != nil {
+		return nil, false, err
+	}
+	if compact {
+		var storageReceipts []*ReceiptForCompactStorage
+		if err := rlp.DecodeBytes(data, &storageReceipts); err != nil {
+			return nil, false, err
+		}
+		receipts := make(Receipts, len(storageReceipts))
+		for i, receipt := range storageReceipts {
+			receipts[i] = (*Receipt)(receipt)
+		}
+		return receipts, true, nil
+	}
+	var storageReceipts []*ReceiptForStorage
+	if err := rlp.DecodeBytes(data, &storageReceipts); err != nil {
+		return nil, false, err
+	}
+	receipts := make(Receipts, len(storageReceipts))
+	for i, receipt := range storageReceipts {
+		receipts[i] = (*Receipt)(receipt)
+	}
+	return receipts, false, nil
+}
+
+// IsCompactStoredReceipts returns true if the receipts of a block are stored in
+// the compact storage encoding. An empty list is regarded as compact.
+func IsCompactStoredReceipts(data []byte) (bool, error) {
+	list, _, err := rlp.SplitList(data)
+	if err != nil {
+		return false, err
+	}
+	if len(list) == 0 {
+		return true, nil
+	}
+	first, _, err := rlp.SplitList(list)
+	if err != nil {
+		return false, err
+	}
+	fields, err := rlp.CountValues(first)
+	if err != nil {
+		return false, err
+	}
+	return fields == receiptCompactStorageFields, nil
+}
+
 // Receipts is a wrapper around a Receipt array to implement DerivableList.
 type Receipts []*Receipt
 
 // Len returns the number of receipts in this list.
 func (r Receipts) Len() int { return len(r) }
 
+// DeriveFields fills the receipts with the fields derivable from the block of
+// the given hash and number and its transactions: the transaction hash, the
+// contract address, the bloom and the derived fields of the logs.
+func (r Receipts) DeriveFields(hash common.Hash, number uint64, txs Transactions) error {
+	if len(txs) != len(r) {
+		return errors.New("transaction and receipt count mismatch")
+	}
+	logIndex := uint(0)
+	for i, receipt := range r {
+		tx := txs[i]
+
+		// The transaction hash can be retrieved from the transaction itself
+		receipt.TxHash = tx.Hash()
+
+		// The contract address can be derived from the transaction itself.
+		// Deriving the sender is expensive, only do if it's actually needed.
+		// The sender validated on execution is used if the transaction is still
+		// in memory, otherwise Sender recovers it once and caches it in the
+		// transaction, which stays in the body cache for the following reads.
+		if tx.To() == nil || tx.Type().IsContractDeploy() {
+			from := tx.ValidatedSender()
+			if from == (common.Address{}) {
+				from, _ = Sender(LatestSignerForChainID(tx.ChainId()), tx)
+			}
+			tx.FillContractAddress(from, receipt)
+		}
+
+		// The derived log fields can simply be set from the block and transaction
+		for _, log := range receipt.Logs {
+			log.BlockNumber = number
+			log.BlockHash = hash
+			log.TxHash = receipt.TxHash
+			log.TxIndex = uint(i)
+			log.Index = logIndex
+			logIndex++
+		}
+		receipt.Bloom = CreateBloom(Receipts{receipt})
+	}
+	return nil
+}
+
 // GetRlp returns the RLP encoding of one receipt from the list.
 func (r Receipts) GetRlp(i int) []byte {
 	bytes, err := rlp.EncodeToBytes(r[i])
@@ -0,0 +1,147 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// genDerivableReceipts returns the transactions of a block and their receipts
+// with all the fields filled as they are on execution.
+func genDerivableReceipts(t *testing.T, blockHash common.Hash, number uint64) (Transactions, Receipts) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	signer := LatestSignerForChainID(big.NewInt(1))
+
+	create, err := SignTx(NewContractCreation(0, big.NewInt(0), 100000, big.NewInt(1), []byte{0x60}), signer, key)
+	require.NoError(t, err)
+	transfer, err := SignTx(NewTransaction(1, common.Address{0x11}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+	require.NoError(t, err)
+	txs := Transactions{create, transfer}
+
+	receipts := Receipts{
+		{
+			Status:          ReceiptStatusSuccessful,
+			GasUsed:         50000,
+			ContractAddress: crypto.CreateAddress(from, 0),
+			Logs: []*Log{
+				{Address: common.Address{0x22}, Topics: []common.Hash{{0x01}}, Data: []byte{0x01}},
+				{Address: common.Address{0x33}, Topics: []common.Hash{}, Data: []byte{}},
+			},
+		},
+		{
+			Status:  ReceiptStatusFailed,
+			GasUsed: 21000,
+			Logs: []*Log{
+				{Address: common.Address{0x44}, Topics: []common.Hash{{0x02}, {0x03}}, Data: []byte{}},
+			},
+		},
+	}
+	logIndex := uint(0)
+	for i, receipt := range receipts {
+		receipt.TxHash = txs[i].Hash()
+		for _, log := range receipt.Logs {
+			log.BlockNumber, log.BlockHash = number, blockHash
+			log.TxHash, log.TxIndex, log.Index = receipt.TxHash, uint(i), logIndex
+			logIndex++
+		}
+		receipt.Bloom = CreateBloom(Receipts{receipt})
+	}
+	return txs, receipts
+}
+
+func encodeCompactReceipts(t *testing.T, receipts Receipts) []byte {
+	storageReceipts := make([]*ReceiptForCompactStorage, len(receipts))
+	for i, receipt := range receipts {
+		storageReceipts[i] = (*ReceiptForCompactStorage)(receipt)
+	}
+	enc, err := rlp.EncodeToBytes(storageReceipts)
+	require.NoError(t, err)
+	return enc
+}
+
+// TestReceipts_DeriveFields checks that the receipts stored in the compact
+// encoding are restored as they were on execution after deriving the fields.
+func TestReceipts_DeriveFields(t *testing.T) {
+	blockHash, number := common.Hash{0xbb}, uint64(7)
+	txs, receipts := genDerivableReceipts(t, blockHash, number)
+
+	decoded, compact, err := DecodeStoredReceipts(encodeCompactReceipts(t, receipts))
+	require.NoError(t, err)
+	assert.True(t, compact)
+	assert.NotEqual(t, receipts, decoded)
+
+	require.NoError(t, decoded.DeriveFields(blockHash, number, txs))
+	assert.Equal(t, receipts, decoded)
+
+	// The recovered sender is cached in the transaction for the following reads.
+	assert.NotNil(t, txs[0].from.Load())
+
+	// The transactions and the receipts should be paired.
+	assert.Error(t, decoded.DeriveFields(blockHash, number, txs[:1]))
+
+	// The sender validated on execution is used without recovering it.
+	from := common.Address{0x55}
+	unsigned := NewContractCreation(3, big.NewInt(0), 100000, big.NewInt(1), []byte{0x60})
+	unsigned.validatedSender = from
+	derived := Receipts{{Status: ReceiptStatusSuccessful, Logs: []*Log{}}}
+	require.NoError(t, derived.DeriveFields(blockHash, number, Transactions{unsigned}))
+	assert.Equal(t, crypto.CreateAddress(from, 3), derived[0].ContractAddress)
+}
+
+// TestDecodeStoredReceipts checks that the receipts stored in both the legacy
+// encoding and the compact encoding can be decoded.
+func TestDecodeStoredReceipts(t *testing.T) {
+	_, receipts := genDerivableReceipts(t, common.Hash{0xbb}, 7)
+
+	storageReceipts := make([]*ReceiptForStorage, len(receipts))
+	for i, receipt := range receipts {
+		storageReceipts[i] = (*ReceiptForStorage)(receipt)
+	}
+	legacy, err := rlp.EncodeToBytes(storageReceipts)
+	require.NoError(t, err)
+
+	decoded, compact, err := DecodeStoredReceipts(legacy)
+	require.NoError(t, err)
+	assert.False(t, compact)
+	assert.Equal(t, len(receipts), len(decoded))
+	for i, receipt := range receipts {
+		assert.Equal(t, receipt.TxHash, decoded[i].TxHash)
+		assert.Equal(t, receipt.ContractAddress, decoded[i].ContractAddress)
+		assert.Equal(t, receipt.Bloom, decoded[i].Bloom)
+		assert.Equal(t, receipt.GasUsed, decoded[i].GasUsed)
+	}
+
+	// The compact encoding is smaller than the legacy one.
+	assert.Less(t, len(encodeCompactReceipts(t, receipts)), len(legacy))
+
+	empty, compact, err := DecodeStoredReceipts(encodeCompactReceipts(t, nil))
+	require.NoError(t, err)
+	assert.True(t, compact)
+	assert.Empty(t, empty)
+
+	_, _, err = DecodeStoredReceipts([]byte{0x01, 0x02})
+	assert.Error(t, err)
+}
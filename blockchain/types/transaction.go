@@ -35,6 +35,7 @@ import (
 
 	"github.com/klaytn/klaytn/blockchain/types/accountkey"
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/klaytn/klaytn/common/math"
 	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/kerrors"
@@ -299,9 +300,13 @@ func (tx *Transaction) GasFeeCap() *big.Int {
 }
 
 // This function is disabled because Kaia has no gas tip
+// If baseFee is nil, the gas tip cap is returned.
 func (tx *Transaction) EffectiveGasTip(baseFee *big.Int) *big.Int {
 	if tx.Type() == TxTypeEthereumDynamicFee {
 		te := tx.GetTxInternalData().(TxInternalDataBaseFee)
+		if baseFee == nil {
+			return te.GetGasTipCap()
+		}
 		return math.BigMin(te.GetGasTipCap(), new(big.Int).Sub(te.GetGasFeeCap(), baseFee))
 	}
 	return tx.GasPrice()
@@ -379,6 +384,42 @@ func (tx *Transaction) ValidatedIntrinsicGas() uint64 {
 func (tx *Transaction) MakeRPCOutput() map[string]interface{} { return tx.data.MakeRPCOutput() }
 func (tx *Transaction) GetTxInternalData() TxInternalData     { return tx.data }
 
+// MakeRPCOutputWithLocation returns the RPC output of the transaction with its sender
+// and the given location metadata set. The block is nil if the transaction is not
+// processed yet.
+func (tx *Transaction) MakeRPCOutputWithLocation(b *Block, blockHash common.Hash, blockNumber uint64, index uint64) map[string]interface{} {
+	output := tx.MakeRPCOutput()
+	output["senderTxHash"] = tx.SenderTxHashAll()
+	output["blockHash"] = blockHash
+	output["blockNumber"] = (*hexutil.Big)(new(big.Int).SetUint64(blockNumber))
+	output["from"] = tx.RPCSender()
+	output["hash"] = tx.Hash()
+	output["transactionIndex"] = hexutil.Uint(index)
+	if tx.Type() == TxTypeEthereumDynamicFee {
+		if b != nil {
+			output["gasPrice"] = (*hexutil.Big)(tx.EffectiveGasPrice(b.Header()))
+		} else {
+			// transaction is not processed yet
+			output["gasPrice"] = (*hexutil.Big)(tx.EffectiveGasPrice(nil))
+		}
+	}
+
+	return output
+}
+
+// RPCSender returns the sender of the transaction shown in the RPC outputs. The sender
+// of an Ethereum transaction is recovered from the signature.
+func (tx *Transaction) RPCSender() common.Address {
+	var from common.Address
+	if tx.IsEthereumTransaction() {
+		signer := LatestSignerForChainID(tx.ChainId())
+		from, _ = Sender(signer, tx)
+	} else {
+		from, _ = tx.From()
+	}
+	return from
+}
+
 func (tx *Transaction) IntrinsicGas(currentBlockNumber uint64) (uint64, error) {
 	return tx.data.IntrinsicGas(currentBlockNumber)
 }
@@ -599,6 +640,25 @@ func (tx *Transaction) AsMessageWithAccountKeyPicker(s Signer, picker AccountKey
 	return tx, err
 }
 
+// AsMessageWithSender returns the transaction as a blockchain.Message sent by the given
+// sender and fee payer without validating the signatures.
+// It is used to simulate the execution of unsigned transactions.
+func (tx *Transaction) AsMessageWithSender(from, feePayer common.Address, currentBlockNumber uint64) (*Transaction, error) {
+	intrinsicGas, err := tx.IntrinsicGas(currentBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.mu.Lock()
+	tx.validatedSender = from
+	tx.validatedFeePayer = feePayer
+	tx.validatedIntrinsicGas = intrinsicGas
+	tx.checkNonce = false
+	tx.mu.Unlock()
+
+	return tx, nil
+}
+
 // WithSignature returns a new transaction with the given signature.
 // This signature needs to be formatted as described in the yellow paper (v+27).
 func (tx *Transaction) WithSignature(signer Signer, sig []byte) (*Transaction, error) {
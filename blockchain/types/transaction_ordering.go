@@ -0,0 +1,178 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"container/heap"
+	"math/big"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+// TransactionSet is a set of transactions which returns them in a nonce-honouring
+// order for the block building. The order of the transactions of different
+// accounts depends on the implementation.
+type TransactionSet interface {
+	// Peek returns the next transaction, or nil if there is no transaction left.
+	Peek() *Transaction
+
+	// Shift replaces the next transaction with the next one from the same account.
+	Shift()
+
+	// Pop removes the next transaction, *not* replacing it with the next one from
+	// the same account.
+	Pop()
+}
+
+var (
+	_ TransactionSet = (*TransactionsByTimeAndNonce)(nil)
+	_ TransactionSet = (*TransactionsByTipAndNonce)(nil)
+)
+
+// txWithTip is the head transaction of an account in TransactionsByTipAndNonce.
+type txWithTip struct {
+	tx   *Transaction
+	from common.Address
+	tip  *big.Int
+
+	// round is the number of times the account used up its cap. The transactions
+	// of a lower round come first.
+	round int
+}
+
+// txsByTipAndTime implements the heap interface. The transactions are sorted by
+// the round, the effective tip, the time they were first seen, and the hash.
+type txsByTipAndTime []*txWithTip
+
+func (s txsByTipAndTime) Len() int { return len(s) }
+func (s txsByTipAndTime) Less(i, j int) bool {
+	if s[i].round != s[j].round {
+		return s[i].round < s[j].round
+	}
+	if cmp := s[i].tip.Cmp(s[j].tip); cmp != 0 {
+		return cmp > 0
+	}
+	if !s[i].tx.time.Equal(s[j].tx.time) {
+		return s[i].tx.time.Before(s[j].tx.time)
+	}
+	hi, hj := s[i].tx.Hash(), s[j].tx.Hash()
+	return bytes.Compare(hi[:], hj[:]) < 0
+}
+func (s txsByTipAndTime) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func (s *txsByTipAndTime) Push(x interface{}) {
+	*s = append(*s, x.(*txWithTip))
+}
+
+func (s *txsByTipAndTime) Pop() interface{} {
+	old := *s
+	n := len(old)
+	x := old[n-1]
+	*s = old[0 : n-1]
+	return x
+}
+
+// TransactionsByTipAndNonce represents a set of transactions that can return
+// transactions in the order of the effective gas tip, while supporting removing
+// entire batches of transactions for non-executable accounts. The gas price of
+// the transactions without a tip cap is regarded as their tip.
+// The transactions with the same tip are sorted by the time they were first seen.
+//
+// If the sender cap is set, an account which had senderCap transactions returned
+// yields to the other accounts until they use up their caps or run out of
+// transactions, so that a few accounts paying high tips cannot fill a block.
+type TransactionsByTipAndNonce struct {
+	txs       map[common.Address]Transactions // Per account nonce-sorted list of transactions
+	heads     txsByTipAndTime                 // Next transaction for each unique account
+	baseFee   *big.Int                        // Base fee of the block the transactions are included in
+	senderCap int                             // Number of transactions of an account in a round, 0 for unlimited
+	counts    map[common.Address]int          // Number of transactions returned for each account
+}
+
+// NewTransactionsByTipAndNonce creates a transaction set that can retrieve
+// effective tip sorted transactions in a nonce-honouring way. baseFee may be nil
+// before the dynamic base fee is enabled.
+func NewTransactionsByTipAndNonce(signer Signer, txs map[common.Address]Transactions, baseFee *big.Int) *TransactionsByTipAndNonce {
+	return NewTransactionsByTipAndNonceWithSenderCap(signer, txs, baseFee, 0)
+}
+
+// NewTransactionsByTipAndNonceWithSenderCap creates a transaction set that can
+// retrieve effective tip sorted transactions in a nonce-honouring way, returning
+// at most senderCap transactions of an account in a round. A senderCap of 0
+// disables the cap.
+func NewTransactionsByTipAndNonceWithSenderCap(signer Signer, txs map[common.Address]Transactions, baseFee *big.Int, senderCap int) *TransactionsByTipAndNonce {
+	if senderCap < 0 {
+		senderCap = 0
+	}
+	heads := make(txsByTipAndTime, 0, len(txs))
+	rest := make(map[common.Address]Transactions, len(txs))
+	for _, accTxs := range txs {
+		if len(accTxs) == 0 {
+			continue
+		}
+		// Ensure the sender address is from the signer
+		acc, _ := Sender(signer, accTxs[0])
+		heads = append(heads, &txWithTip{tx: accTxs[0], from: acc, tip: accTxs[0].EffectiveGasTip(baseFee)})
+		rest[acc] = accTxs[1:]
+	}
+	heap.Init(&heads)
+
+	return &TransactionsByTipAndNonce{
+		txs:       rest,
+		heads:     heads,
+		baseFee:   baseFee,
+		senderCap: senderCap,
+		counts:    make(map[common.Address]int),
+	}
+}
+
+// Peek returns the next transaction by effective tip.
+func (t *TransactionsByTipAndNonce) Peek() *Transaction {
+	if len(t.heads) == 0 {
+		return nil
+	}
+	return t.heads[0].tx
+}
+
+// Shift replaces the current best head with the next one from the same account.
+func (t *TransactionsByTipAndNonce) Shift() {
+	if len(t.heads) == 0 {
+		return
+	}
+	head := t.heads[0]
+	t.counts[head.from]++
+
+	txs := t.txs[head.from]
+	if len(txs) == 0 {
+		heap.Pop(&t.heads)
+		return
+	}
+	head.tx, t.txs[head.from] = txs[0], txs[1:]
+	head.tip = head.tx.EffectiveGasTip(t.baseFee)
+	if t.senderCap > 0 {
+		head.round = t.counts[head.from] / t.senderCap
+	}
+	heap.Fix(&t.heads, 0)
+}
+
+// Pop removes the best transaction, *not* replacing it with the next one from
+// the same account. This should be used when a transaction cannot be executed
+// and hence all subsequent ones should be discarded from the same account.
+func (t *TransactionsByTipAndNonce) Pop() {
+	heap.Pop(&t.heads)
+}
@@ -0,0 +1,178 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orderingTestTxs returns the transactions of the accounts A, B and C used to
+// check the order of the transaction sets, keyed by their names.
+//
+//	name  first seen  with base fee 25        without base fee
+//	A0    1           legacy, gas price 30    30
+//	A1    2           legacy, gas price 100   100
+//	B0    3           tip cap 10              10
+//	B1    4           tip cap 9               9
+//	B2    5           tip cap 8               8
+//	C0    0           fee cap 30, tip 30-25=5 50
+func orderingTestTxs(t *testing.T, signer Signer, keys map[string]*ecdsa.PrivateKey) (map[common.Address]Transactions, map[common.Hash]string) {
+	legacy := func(nonce, price int64) TxInternalData {
+		return newTxInternalDataLegacyWithValues(uint64(nonce), &common.Address{}, big.NewInt(0), 21000, big.NewInt(price), nil)
+	}
+	dynamic := func(nonce, tipCap, feeCap int64) TxInternalData {
+		return &TxInternalDataEthereumDynamicFee{
+			ChainID: big.NewInt(1), AccountNonce: uint64(nonce), GasTipCap: big.NewInt(tipCap), GasFeeCap: big.NewInt(feeCap),
+			GasLimit: 21000, Recipient: &common.Address{}, Amount: big.NewInt(0),
+		}
+	}
+	defs := []struct {
+		account string
+		data    TxInternalData
+		seen    int64
+	}{
+		{"A", legacy(0, 30), 1},
+		{"A", legacy(1, 100), 2},
+		{"B", dynamic(0, 10, 100), 3},
+		{"B", dynamic(1, 9, 100), 4},
+		{"B", dynamic(2, 8, 100), 5},
+		{"C", dynamic(0, 50, 30), 0},
+	}
+
+	txs := make(map[common.Address]Transactions)
+	names := make(map[common.Hash]string)
+	for _, def := range defs {
+		key := keys[def.account]
+		tx, err := SignTx(NewTx(def.data), signer, key)
+		require.NoError(t, err)
+		tx.time = time.Unix(0, def.seen)
+
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		names[tx.Hash()] = fmt.Sprintf("%s%d", def.account, tx.Nonce())
+		txs[addr] = append(txs[addr], tx)
+	}
+	return txs, names
+}
+
+// TestTransactionSetOrdering checks the exact order of the transactions returned
+// by each transaction set, repeating it to check that the order is deterministic.
+func TestTransactionSetOrdering(t *testing.T) {
+	signer := LatestSignerForChainID(big.NewInt(1))
+	keys := make(map[string]*ecdsa.PrivateKey)
+	for _, account := range []string{"A", "B", "C"} {
+		keys[account], _ = crypto.GenerateKey()
+	}
+	baseFee := big.NewInt(25)
+
+	testcases := []struct {
+		name   string
+		newSet func(txs map[common.Address]Transactions) TransactionSet
+		want   []string
+	}{
+		{
+			"time and nonce",
+			func(txs map[common.Address]Transactions) TransactionSet {
+				return NewTransactionsByTimeAndNonce(signer, txs)
+			},
+			[]string{"C0", "A0", "A1", "B0", "B1", "B2"},
+		},
+		{
+			"tip and nonce",
+			func(txs map[common.Address]Transactions) TransactionSet {
+				return NewTransactionsByTipAndNonce(signer, txs, baseFee)
+			},
+			[]string{"A0", "A1", "B0", "B1", "B2", "C0"},
+		},
+		{
+			"tip and nonce without base fee",
+			func(txs map[common.Address]Transactions) TransactionSet {
+				return NewTransactionsByTipAndNonce(signer, txs, nil)
+			},
+			[]string{"C0", "A0", "A1", "B0", "B1", "B2"},
+		},
+		{
+			"tip and nonce with sender cap 1",
+			func(txs map[common.Address]Transactions) TransactionSet {
+				return NewTransactionsByTipAndNonceWithSenderCap(signer, txs, baseFee, 1)
+			},
+			[]string{"A0", "B0", "C0", "A1", "B1", "B2"},
+		},
+		{
+			"tip and nonce with sender cap 2",
+			func(txs map[common.Address]Transactions) TransactionSet {
+				return NewTransactionsByTipAndNonceWithSenderCap(signer, txs, baseFee, 2)
+			},
+			[]string{"A0", "A1", "B0", "B1", "C0", "B2"},
+		},
+	}
+
+	for _, tc := range testcases {
+		for i := 0; i < 10; i++ {
+			txs, names := orderingTestTxs(t, signer, keys)
+			set := tc.newSet(txs)
+
+			var have []string
+			for tx := set.Peek(); tx != nil; tx = set.Peek() {
+				have = append(have, names[tx.Hash()])
+				set.Shift()
+			}
+			assert.Equal(t, tc.want, have, tc.name)
+		}
+	}
+}
+
+// TestTransactionsByTipAndNonce_Pop checks that the transactions of an account are
+// discarded after Pop, and that the transactions with the same tip and time are
+// ordered by their hashes.
+func TestTransactionsByTipAndNonce_Pop(t *testing.T) {
+	signer := LatestSignerForChainID(big.NewInt(1))
+	seen := time.Unix(0, 1)
+
+	txs := make(map[common.Address]Transactions)
+	var heads []common.Hash
+	for i := 0; i < 5; i++ {
+		key, _ := crypto.GenerateKey()
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		for nonce := uint64(0); nonce < 2; nonce++ {
+			tx, err := SignTx(NewTransaction(nonce, common.Address{}, big.NewInt(0), 21000, big.NewInt(30), nil), signer, key)
+			require.NoError(t, err)
+			tx.time = seen
+			txs[addr] = append(txs[addr], tx)
+		}
+		heads = append(heads, txs[addr][0].Hash())
+	}
+
+	set := NewTransactionsByTipAndNonce(signer, txs, big.NewInt(25))
+	var have []common.Hash
+	for tx := set.Peek(); tx != nil; tx = set.Peek() {
+		have = append(have, tx.Hash())
+		set.Pop()
+	}
+	assert.ElementsMatch(t, heads, have)
+	for i := 1; i < len(have); i++ {
+		assert.Negative(t, have[i-1].Big().Cmp(have[i].Big()))
+	}
+}
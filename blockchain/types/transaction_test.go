@@ -400,6 +400,15 @@ func TestEffectiveGasTip(t *testing.T) {
 	want = big.NewInt(1000)
 	assert.Equal(t, want, have)
 
+	// without the base fee, the gas tip cap is returned
+	have = legacyTx.EffectiveGasTip(nil)
+	want = big.NewInt(1000)
+	assert.Equal(t, want, have)
+
+	have = dynamicTx.EffectiveGasTip(nil)
+	want = big.NewInt(1000)
+	assert.Equal(t, want, have)
+
 	a := new(big.Int)
 	assert.Equal(t, 0, a.BitLen())
 }
@@ -49,6 +49,11 @@ func init() {
 		// See utils/nodecmd/chaincmd.go:
 		nodecmd.InitCommand,
 		nodecmd.DumpGenesisCommand,
+		nodecmd.ImportCommand,
+		nodecmd.ExportCommand,
+		nodecmd.ImportPreimagesCommand,
+		nodecmd.ExportPreimagesCommand,
+		nodecmd.RestoreCommand,
 
 		// See utils/nodecmd/accountcmd.go
 		nodecmd.AccountCommand,
@@ -68,6 +73,9 @@ func init() {
 
 		// See utils/nodecmd/snapshot.go:
 		nodecmd.SnapshotCommand,
+
+		// See utils/nodecmd/dbcmd.go:
+		nodecmd.DBCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
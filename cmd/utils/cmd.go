@@ -22,6 +22,7 @@ package utils
 
 import (
 	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -31,9 +32,18 @@ import (
 
 	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/governance"
 	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/node"
+	"github.com/klaytn/klaytn/node/cn"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/reward"
 	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
 )
 
 const (
@@ -62,6 +72,82 @@ func StartNode(stack *node.Node) {
 	}()
 }
 
+// MakeChainDatabase opens the chain database with the configuration of the node.
+func MakeChainDatabase(cfg *KlayConfig) database.DBManager {
+	return cn.CreateDB(node.NewServiceContext(&cfg.Node, nil, nil, nil), &cfg.CN, "chaindata")
+}
+
+// MakeChain creates a chain manager on top of the given database with the
+// configuration of the node, without starting the node. The chain is set up
+// with the consensus engine and the governance so that the blocks can be verified.
+func MakeChain(cfg *KlayConfig, chainDB database.DBManager) (*blockchain.BlockChain, error) {
+	if chainDB.ReadCanonicalHash(0) == (common.Hash{}) {
+		return nil, errors.New("genesis block is not found, the database should be initialized by the init command first")
+	}
+	chainConfig, _, err := blockchain.SetupGenesisBlock(chainDB, nil, cfg.CN.NetworkId, cfg.CN.IsPrivate, false)
+	if _, ok := err.(*params.ConfigCompatError); err != nil && !ok {
+		return nil, err
+	}
+	if chainConfig.Clique != nil {
+		types.EngineType = types.Engine_Clique
+	}
+	if chainConfig.Istanbul != nil {
+		types.EngineType = types.Engine_IBFT
+	}
+	chainConfig.SetDefaults()
+	gov := governance.NewMixedEngine(chainConfig, chainDB)
+
+	ctx := node.NewServiceContext(&cfg.Node, nil, nil, nil)
+	engine := cn.CreateConsensusEngine(ctx, &cfg.CN, chainConfig, chainDB, gov, ctx.NodeType())
+
+	cacheConfig := &blockchain.CacheConfig{
+		ArchiveMode:           cfg.CN.NoPruning,
+		CacheSize:             cfg.CN.TrieCacheSize,
+		BlockInterval:         cfg.CN.TrieBlockInterval,
+		TriesInMemory:         cfg.CN.TriesInMemory,
+		LivePruningRetention:  cfg.CN.LivePruningRetention,
+		TrieNodeCacheConfig:   &cfg.CN.TrieNodeCacheConfig,
+		SnapshotCacheSize:     cfg.CN.SnapshotCacheSize,
+		SnapshotAsyncGen:      cfg.CN.SnapshotAsyncGen,
+		StateHistoryRetention: cfg.CN.StateHistoryRetention,
+	}
+	vmConfig := vm.Config{
+		EnablePreimageRecording: cfg.CN.EnablePreimageRecording,
+		EnableInternalTxTracing: cfg.CN.EnableInternalTxTracing,
+		EnableOpDebug:           cfg.CN.EnableOpDebug,
+	}
+	chain, err := blockchain.NewBlockChain(chainDB, cacheConfig, chainConfig, engine, vmConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	gov.SetBlockchain(chain)
+	if err := gov.UpdateParams(chain.CurrentBlock().NumberU64()); err != nil {
+		chain.Stop()
+		return nil, err
+	}
+	blockchain.InitDeriveShaWithGov(chainConfig, gov)
+
+	pset, err := gov.EffectiveParams(chain.CurrentBlock().NumberU64() + 1)
+	if err != nil {
+		chain.Stop()
+		return nil, err
+	}
+	if chainConfig.Istanbul != nil {
+		chainConfig.Istanbul.ProposerPolicy = pset.Policy()
+	}
+	if chainConfig.Governance.Reward != nil {
+		chainConfig.Governance.Reward.UseGiniCoeff = pset.UseGiniCoeff()
+	}
+	if pset.Policy() == uint64(istanbul.WeightedRandom) {
+		reward.NewStakingManager(chain, gov, chainDB)
+	}
+	if err := engine.CreateSnapshot(chain, chain.CurrentBlock().NumberU64(), chain.CurrentBlock().Hash(), nil); err != nil {
+		logger.Error("CreateSnapshot failed", "err", err)
+	}
+	return chain, nil
+}
+
 func ImportChain(chain *blockchain.BlockChain, fn string) error {
 	// Watch for Ctrl-C while the import is running.
 	// If a signal is received, the import will stop at the next batch.
@@ -214,77 +300,117 @@ func ExportAppendChain(blockchain *blockchain.BlockChain, fn string, first uint6
 	return nil
 }
 
-// TODO-Kaia Commented out due to mismatched interface.
-//// ImportPreimages imports a batch of exported hash preimages into the database.
-//func ImportPreimages(db *database.LevelDB, fn string) error {
-//	logger.Info("Importing preimages", "file", fn)
-//
-//	// Open the file handle and potentially unwrap the gzip stream
-//	fh, err := os.Open(fn)
-//	if err != nil {
-//		return err
-//	}
-//	defer fh.Close()
-//
-//	var reader io.Reader = fh
-//	if strings.HasSuffix(fn, ".gz") {
-//		if reader, err = gzip.NewReader(reader); err != nil {
-//			return err
-//		}
-//	}
-//	stream := rlp.NewStream(reader, 0)
-//
-//	// Import the preimages in batches to prevent disk trashing
-//	preimages := make(map[common.Hash][]byte)
-//
-//	for {
-//		// Read the next entry and ensure it's not junk
-//		var blob []byte
-//
-//		if err := stream.Decode(&blob); err != nil {
-//			if err == io.EOF {
-//				break
-//			}
-//			return err
-//		}
-//		// Accumulate the preimages and flush when enough ws gathered
-//		preimages[crypto.Keccak256Hash(blob)] = common.CopyBytes(blob)
-//		if len(preimages) > 1024 {
-//			rawdb.WritePreimages(db, 0, preimages)
-//			preimages = make(map[common.Hash][]byte)
-//		}
-//	}
-//	// Flush the last batch preimage data
-//	if len(preimages) > 0 {
-//		rawdb.WritePreimages(db, 0, preimages)
-//	}
-//	return nil
-//}
-//
-//// ExportPreimages exports all known hash preimages into the specified file,
-//// truncating any data already present in the file.
-//func ExportPreimages(db *database.LevelDB, fn string) error {
-//	logger.Info("Exporting preimages", "file", fn)
-//
-//	// Open the file handle and potentially wrap with a gzip stream
-//	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
-//	if err != nil {
-//		return err
-//	}
-//	defer fh.Close()
-//
-//	var writer io.Writer = fh
-//	if strings.HasSuffix(fn, ".gz") {
-//		writer = gzip.NewWriter(writer)
-//		defer writer.(*gzip.Writer).Close()
-//	}
-//	// Iterate over the preimages and export them
-//	it := db.NewIteratorWithPrefix([]byte("secure-key-"))
-//	for it.Next() {
-//		if err := rlp.Encode(writer, it.Value()); err != nil {
-//			return err
-//		}
-//	}
-//	logger.Info("Exported preimages", "file", fn)
-//	return nil
-//}
+// ExportReceipts exports the receipts of a range of blocks into the specified file,
+// truncating any data already present in the file.
+func ExportReceipts(chain *blockchain.BlockChain, fn string, first uint64, last uint64) error {
+	return exportReceipts(chain, fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, first, last)
+}
+
+// ExportAppendReceipts exports the receipts of a range of blocks into the specified
+// file, appending to the file if data already exists in it.
+func ExportAppendReceipts(chain *blockchain.BlockChain, fn string, first uint64, last uint64) error {
+	return exportReceipts(chain, fn, os.O_CREATE|os.O_APPEND|os.O_WRONLY, first, last)
+}
+
+func exportReceipts(chain *blockchain.BlockChain, fn string, flag int, first uint64, last uint64) error {
+	logger.Info("Exporting receipts", "file", fn)
+
+	// Open the file handle and potentially wrap with a gzip stream
+	fh, err := os.OpenFile(fn, flag, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var writer io.Writer = fh
+	if strings.HasSuffix(fn, ".gz") {
+		writer = gzip.NewWriter(writer)
+		defer writer.(*gzip.Writer).Close()
+	}
+	// Iterate over the blocks and export their receipts
+	if err := chain.ExportReceiptsN(writer, first, last); err != nil {
+		return err
+	}
+	logger.Info("Exported receipts", "file", fn)
+	return nil
+}
+
+// ImportPreimages imports a batch of exported hash preimages into the database.
+func ImportPreimages(db database.DBManager, fn string) error {
+	logger.Info("Importing preimages", "file", fn)
+
+	// Open the file handle and potentially unwrap the gzip stream
+	fh, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var reader io.Reader = fh
+	if strings.HasSuffix(fn, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return err
+		}
+	}
+	stream := rlp.NewStream(reader, 0)
+
+	// Import the preimages in batches to prevent disk trashing
+	preimages := make(map[common.Hash][]byte)
+
+	for {
+		// Read the next entry and ensure it's not junk
+		var blob []byte
+
+		if err := stream.Decode(&blob); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		// Accumulate the preimages and flush when enough ws gathered
+		preimages[crypto.Keccak256Hash(blob)] = common.CopyBytes(blob)
+		if len(preimages) > 1024 {
+			db.WritePreimages(0, preimages)
+			preimages = make(map[common.Hash][]byte)
+		}
+	}
+	// Flush the last batch preimage data
+	if len(preimages) > 0 {
+		db.WritePreimages(0, preimages)
+	}
+	logger.Info("Imported preimages", "file", fn)
+	return nil
+}
+
+// ExportPreimages exports all known hash preimages into the specified file,
+// truncating any data already present in the file.
+func ExportPreimages(db database.DBManager, fn string) error {
+	logger.Info("Exporting preimages", "file", fn)
+
+	// Open the file handle and potentially wrap with a gzip stream
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var writer io.Writer = fh
+	if strings.HasSuffix(fn, ".gz") {
+		writer = gzip.NewWriter(writer)
+		defer writer.(*gzip.Writer).Close()
+	}
+	// Iterate over the preimages and export them
+	it := db.NewPreimageIterator()
+	defer it.Release()
+
+	for it.Next() {
+		if err := rlp.Encode(writer, it.Value()); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	logger.Info("Exported preimages", "file", fn)
+	return nil
+}
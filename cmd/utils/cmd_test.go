@@ -0,0 +1,152 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"compress/gzip"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddress = crypto.PubkeyToAddress(testKey.PublicKey)
+	testGenesis = &blockchain.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  blockchain.GenesisAlloc{testAddress: {Balance: big.NewInt(params.KLAY)}},
+	}
+)
+
+// newTestChain returns a blockchain containing only the genesis block.
+func newTestChain(t *testing.T) *blockchain.BlockChain {
+	db := database.NewMemoryDBManager()
+	testGenesis.MustCommit(db)
+
+	chain, err := blockchain.NewBlockChain(db, nil, testGenesis.Config, gxhash.NewFaker(), vm.Config{})
+	require.NoError(t, err)
+	t.Cleanup(chain.Stop)
+	return chain
+}
+
+// newTestBlocks generates n blocks with a value transfer each on top of the genesis block.
+func newTestBlocks(t *testing.T, n int) []*types.Block {
+	db := database.NewMemoryDBManager()
+	genesisBlock := testGenesis.MustCommit(db)
+	signer := types.LatestSignerForChainID(testGenesis.Config.ChainID)
+
+	blocks, _ := blockchain.GenerateChain(testGenesis.Config, genesisBlock, gxhash.NewFaker(), db, n, func(i int, block *blockchain.BlockGen) {
+		tx := types.NewTransaction(block.TxNonce(testAddress), common.Address{byte(i)}, big.NewInt(1000), params.TxGas, nil, nil)
+		signed, err := types.SignTx(tx, signer, testKey)
+		require.NoError(t, err)
+		block.AddTx(signed)
+	})
+	return blocks
+}
+
+func TestExportImportChain(t *testing.T) {
+	src := newTestChain(t)
+	blocks := newTestBlocks(t, 10)
+	_, err := src.InsertChain(blocks)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	for _, fn := range []string{"chain.rlp", "chain.rlp.gz"} {
+		fn = filepath.Join(dir, fn)
+		require.NoError(t, ExportChain(src, fn))
+
+		dst := newTestChain(t)
+		require.NoError(t, ImportChain(dst, fn))
+		assert.Equal(t, src.CurrentBlock().Hash(), dst.CurrentBlock().Hash())
+	}
+
+	// Import the chain from the files exported by ranges.
+	var (
+		first = filepath.Join(dir, "first.rlp")
+		last  = filepath.Join(dir, "last.rlp")
+		dst   = newTestChain(t)
+	)
+	require.NoError(t, ExportAppendChain(src, first, 0, 4))
+	require.NoError(t, ExportAppendChain(src, last, 5, 7))
+	require.NoError(t, ExportAppendChain(src, last, 8, 10))
+
+	require.NoError(t, ImportChain(dst, first))
+	assert.Equal(t, uint64(4), dst.CurrentBlock().NumberU64())
+	require.NoError(t, ImportChain(dst, last))
+	assert.Equal(t, src.CurrentBlock().Hash(), dst.CurrentBlock().Hash())
+}
+
+func TestExportReceipts(t *testing.T) {
+	chain := newTestChain(t)
+	blocks := newTestBlocks(t, 5)
+	_, err := chain.InsertChain(blocks)
+	require.NoError(t, err)
+
+	fn := filepath.Join(t.TempDir(), "receipts.rlp.gz")
+	require.NoError(t, ExportReceipts(chain, fn, 1, 5))
+
+	fh, err := os.Open(fn)
+	require.NoError(t, err)
+	defer fh.Close()
+	reader, err := gzip.NewReader(fh)
+	require.NoError(t, err)
+
+	stream := rlp.NewStream(reader, 0)
+	for _, block := range blocks {
+		var exported blockchain.ExportedReceipts
+		require.NoError(t, stream.Decode(&exported))
+		assert.Equal(t, block.NumberU64(), exported.Number)
+		assert.Equal(t, block.Hash(), exported.Hash)
+		require.Len(t, exported.Receipts, 1)
+		assert.Equal(t, block.Transactions()[0].Hash(), exported.Receipts[0].TxHash)
+	}
+	var exported blockchain.ExportedReceipts
+	assert.Equal(t, io.EOF, stream.Decode(&exported))
+}
+
+func TestExportImportPreimages(t *testing.T) {
+	src := database.NewMemoryDBManager()
+	preimages := make(map[common.Hash][]byte)
+	for i := 0; i < 2000; i++ {
+		blob := common.BigToHash(big.NewInt(int64(i))).Bytes()
+		preimages[crypto.Keccak256Hash(blob)] = blob
+	}
+	src.WritePreimages(0, preimages)
+
+	fn := filepath.Join(t.TempDir(), "preimages.rlp.gz")
+	require.NoError(t, ExportPreimages(src, fn))
+
+	dst := database.NewMemoryDBManager()
+	require.NoError(t, ImportPreimages(dst, fn))
+	for hash, blob := range preimages {
+		assert.Equal(t, blob, dst.ReadPreimage(hash))
+	}
+}
@@ -62,6 +62,7 @@ import (
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/storage/database"
 	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/klaytn/klaytn/work"
 	"github.com/naoina/toml"
 	"github.com/urfave/cli/v2"
 )
@@ -374,6 +375,9 @@ func (kCfg *KlayConfig) SetNodeConfig(ctx *cli.Context) {
 	setHTTP(ctx, cfg)
 	setWS(ctx, cfg)
 	setgRPC(ctx, cfg)
+	if ctx.IsSet(RPCJWTSecretFlag.Name) {
+		cfg.JWTSecret = ctx.String(RPCJWTSecretFlag.Name)
+	}
 	setAPIConfig(ctx)
 	setNodeUserIdent(ctx, cfg)
 
@@ -422,6 +426,12 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 		rpc.ConcurrencyLimit = ctx.Int(RPCConcurrencyLimit.Name)
 		logger.Info("Set the concurrency limit of RPC-HTTP server", "limit", rpc.ConcurrencyLimit)
 	}
+	if ctx.IsSet(RPCBatchRequestLimitFlag.Name) {
+		rpc.BatchRequestLimit = ctx.Int(RPCBatchRequestLimitFlag.Name)
+	}
+	if ctx.IsSet(RPCBatchResponseMaxSizeFlag.Name) {
+		rpc.BatchResponseMaxSize = ctx.Int(RPCBatchResponseMaxSizeFlag.Name)
+	}
 	if ctx.IsSet(RPCReadTimeout.Name) {
 		cfg.HTTPTimeouts.ReadTimeout = time.Duration(ctx.Int(RPCReadTimeout.Name)) * time.Second
 	}
@@ -438,6 +448,9 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 		rpc.UpstreamArchiveEN = ctx.String(RPCUpstreamArchiveENFlag.Name)
 		cfg.UpstreamArchiveEN = rpc.UpstreamArchiveEN
 	}
+	if ctx.IsSet(GraphQLEnabledFlag.Name) {
+		cfg.GraphQLEnabled = ctx.Bool(GraphQLEnabledFlag.Name)
+	}
 }
 
 // setWS creates the WebSocket RPC listener interface string from the set
@@ -578,6 +591,10 @@ func (kCfg *KlayConfig) SetKlayConfig(ctx *cli.Context, stack *node.Node) {
 	cfg.RocksDBConfig.DisableMetrics = ctx.Bool(RocksDBDisableMetricsFlag.Name)
 	cfg.RocksDBConfig.CacheIndexAndFilter = ctx.Bool(RocksDBCacheIndexAndFilterFlag.Name)
 
+	cfg.PebbleDBConfig.CacheSize = ctx.Uint64(PebbleDBCacheSizeFlag.Name)
+	cfg.PebbleDBConfig.MaxOpenFiles = ctx.Int(PebbleDBMaxOpenFilesFlag.Name)
+	cfg.PebbleDBConfig.DisableMetrics = ctx.Bool(PebbleDBDisableMetricsFlag.Name)
+
 	cfg.DynamoDBConfig.TableName = ctx.String(DynamoDBTableNameFlag.Name)
 	cfg.DynamoDBConfig.Region = ctx.String(DynamoDBRegionFlag.Name)
 	cfg.DynamoDBConfig.IsProvisioned = ctx.Bool(DynamoDBIsProvisionedFlag.Name)
@@ -600,6 +617,7 @@ func (kCfg *KlayConfig) SetKlayConfig(ctx *cli.Context, stack *node.Node) {
 	cfg.TriesInMemory = ctx.Uint64(TriesInMemoryFlag.Name)
 	cfg.LivePruning = ctx.Bool(LivePruningFlag.Name)
 	cfg.LivePruningRetention = ctx.Uint64(LivePruningRetentionFlag.Name)
+	cfg.StateHistoryRetention = ctx.Uint64(StateHistoryRetentionFlag.Name)
 
 	if ctx.IsSet(CacheScaleFlag.Name) {
 		common.CacheScale = ctx.Int(CacheScaleFlag.Name)
@@ -629,6 +647,14 @@ func (kCfg *KlayConfig) SetKlayConfig(ctx *cli.Context, stack *node.Node) {
 
 	cfg.SenderTxHashIndexing = ctx.Bool(SenderTxHashIndexingFlag.Name)
 	cfg.ParallelDBWrite = !ctx.Bool(NoParallelDBWriteFlag.Name)
+	cfg.EnableAncient = ctx.Bool(AncientFlag.Name)
+	cfg.AncientThreshold = ctx.Uint64(AncientThresholdFlag.Name)
+	cfg.EnableLogIndex = ctx.Bool(LogIndexFlag.Name)
+	cfg.CompactReceipts = ctx.Bool(CompactReceiptsFlag.Name)
+	if ctx.Bool(TrieNodeCacheRedisClusterFlag.Name) && ctx.Bool(TrieNodeCacheRedisShardingFlag.Name) {
+		log.Fatalf("Flags --%s and --%s can't be enabled at the same time",
+			TrieNodeCacheRedisClusterFlag.Name, TrieNodeCacheRedisShardingFlag.Name)
+	}
 	cfg.TrieNodeCacheConfig = statedb.TrieNodeCacheConfig{
 		CacheType: statedb.TrieNodeCacheType(ctx.String(TrieNodeCacheTypeFlag.
 			Name)).ToValid(),
@@ -639,6 +665,9 @@ func (kCfg *KlayConfig) SetKlayConfig(ctx *cli.Context, stack *node.Node) {
 		FastCacheSavePeriod:       ctx.Duration(TrieNodeCacheSavePeriodFlag.Name),
 		RedisEndpoints:            ctx.StringSlice(TrieNodeCacheRedisEndpointsFlag.Name),
 		RedisClusterEnable:        ctx.Bool(TrieNodeCacheRedisClusterFlag.Name),
+		RedisShardingEnable:       ctx.Bool(TrieNodeCacheRedisShardingFlag.Name),
+		RedisTTL:                  ctx.Duration(TrieNodeCacheRedisTTLFlag.Name),
+		RedisEvictionPolicy:       ctx.String(TrieNodeCacheRedisEvictionPolicyFlag.Name),
 		RedisPublishBlockEnable:   ctx.Bool(TrieNodeCacheRedisPublishBlockFlag.Name),
 		RedisSubscribeBlockEnable: ctx.Bool(TrieNodeCacheRedisSubscribeBlockFlag.Name),
 	}
@@ -679,6 +708,19 @@ func (kCfg *KlayConfig) SetKlayConfig(ctx *cli.Context, stack *node.Node) {
 	if ctx.IsSet(BlockGenerationTimeLimitFlag.Name) {
 		params.BlockGenerationTimeLimit = ctx.Duration(BlockGenerationTimeLimitFlag.Name)
 	}
+	if ctx.IsSet(BlockGenerationTxOrderingFlag.Name) {
+		policy, err := work.ParseTxOrderingPolicy(ctx.String(BlockGenerationTxOrderingFlag.Name))
+		if err != nil {
+			log.Fatalf("Invalid --%s: %v", BlockGenerationTxOrderingFlag.Name, err)
+		}
+		cfg.TxOrdering.Policy = policy
+	}
+	if ctx.IsSet(BlockGenerationTxSenderCapFlag.Name) {
+		cfg.TxOrdering.SenderCap = ctx.Int(BlockGenerationTxSenderCapFlag.Name)
+		if cfg.TxOrdering.SenderCap < 1 {
+			logger.Crit("Transaction sender cap should be equal or larger than 1", "cap", cfg.TxOrdering.SenderCap)
+		}
+	}
 	if ctx.IsSet(OpcodeComputationCostLimitFlag.Name) {
 		params.OpcodeComputationCostLimitOverride = ctx.Uint64(OpcodeComputationCostLimitFlag.Name)
 	}
@@ -812,6 +854,12 @@ func setTxPool(ctx *cli.Context, cfg *blockchain.TxPoolConfig) {
 	if ctx.IsSet(TxPoolNonExecSlotsAllFlag.Name) {
 		cfg.NonExecSlotsAll = ctx.Uint64(TxPoolNonExecSlotsAllFlag.Name)
 	}
+	if ctx.IsSet(TxPoolLargeTxSizeFlag.Name) {
+		cfg.LargeTxSize = ctx.Uint64(TxPoolLargeTxSizeFlag.Name)
+	}
+	if ctx.IsSet(TxPoolLargeTxPoolSizeFlag.Name) {
+		cfg.LargeTxPoolSize = ctx.Uint64(TxPoolLargeTxPoolSizeFlag.Name)
+	}
 
 	cfg.KeepLocals = ctx.Bool(TxPoolKeepLocalsFlag.Name)
 
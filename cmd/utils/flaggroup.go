@@ -52,6 +52,8 @@ var FlagGroups = []FlagGroup{
 			StartBlockNumberFlag,
 			BlockGenerationIntervalFlag,
 			BlockGenerationTimeLimitFlag,
+			BlockGenerationTxOrderingFlag,
+			BlockGenerationTxSenderCapFlag,
 			OpcodeComputationCostLimitFlag,
 		},
 	},
@@ -78,6 +80,8 @@ var FlagGroups = []FlagGroup{
 			TxPoolExecSlotsAllFlag,
 			TxPoolNonExecSlotsAccountFlag,
 			TxPoolNonExecSlotsAllFlag,
+			TxPoolLargeTxSizeFlag,
+			TxPoolLargeTxPoolSizeFlag,
 			TxPoolLifetimeFlag,
 			TxPoolKeepLocalsFlag,
 			TxResendIntervalFlag,
@@ -102,6 +106,9 @@ var FlagGroups = []FlagGroup{
 			RocksDBDisableMetricsFlag,
 			RocksDBMaxOpenFilesFlag,
 			RocksDBCacheIndexAndFilterFlag,
+			PebbleDBCacheSizeFlag,
+			PebbleDBMaxOpenFilesFlag,
+			PebbleDBDisableMetricsFlag,
 			DynamoDBTableNameFlag,
 			DynamoDBRegionFlag,
 			DynamoDBIsProvisionedFlag,
@@ -111,6 +118,10 @@ var FlagGroups = []FlagGroup{
 			NoParallelDBWriteFlag,
 			SenderTxHashIndexingFlag,
 			DBNoPerformanceMetricsFlag,
+			AncientFlag,
+			AncientThresholdFlag,
+			LogIndexFlag,
+			CompactReceiptsFlag,
 		},
 	},
 	{
@@ -189,6 +200,9 @@ var FlagGroups = []FlagGroup{
 			DstRocksDBDisableMetricsFlag,
 			DstRocksDBMaxOpenFilesFlag,
 			DstRocksDBCacheIndexAndFilterFlag,
+			DstPebbleDBCacheSizeFlag,
+			DstPebbleDBMaxOpenFilesFlag,
+			DstPebbleDBDisableMetricsFlag,
 		},
 	},
 	{
@@ -199,6 +213,7 @@ var FlagGroups = []FlagGroup{
 			TriesInMemoryFlag,
 			LivePruningFlag,
 			LivePruningRetentionFlag,
+			StateHistoryRetentionFlag,
 		},
 	},
 	{
@@ -215,6 +230,9 @@ var FlagGroups = []FlagGroup{
 			TrieNodeCacheSavePeriodFlag,
 			TrieNodeCacheRedisEndpointsFlag,
 			TrieNodeCacheRedisClusterFlag,
+			TrieNodeCacheRedisShardingFlag,
+			TrieNodeCacheRedisTTLFlag,
+			TrieNodeCacheRedisEvictionPolicyFlag,
 			TrieNodeCacheRedisPublishBlockFlag,
 			TrieNodeCacheRedisSubscribeBlockFlag,
 		},
@@ -280,12 +298,16 @@ var FlagGroups = []FlagGroup{
 			RPCGlobalEVMTimeoutFlag,
 			RPCGlobalEthTxFeeCapFlag,
 			RPCConcurrencyLimit,
+			RPCBatchRequestLimitFlag,
+			RPCBatchResponseMaxSizeFlag,
 			RPCNonEthCompatibleFlag,
 			RPCExecutionTimeoutFlag,
 			RPCIdleTimeoutFlag,
 			RPCReadTimeout,
 			RPCWriteTimeoutFlag,
 			RPCUpstreamArchiveENFlag,
+			RPCJWTSecretFlag,
+			GraphQLEnabledFlag,
 			UnsafeDebugDisableFlag,
 			IPCDisabledFlag,
 			IPCPathFlag,
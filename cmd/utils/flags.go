@@ -38,10 +38,12 @@ import (
 	"github.com/klaytn/klaytn/node"
 	"github.com/klaytn/klaytn/node/cn"
 	"github.com/klaytn/klaytn/node/cn/filters"
+	"github.com/klaytn/klaytn/node/cn/graphql"
 	"github.com/klaytn/klaytn/node/sc"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/storage/database"
 	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/klaytn/klaytn/work"
 	"github.com/urfave/cli/v2"
 )
 
@@ -94,7 +96,7 @@ var (
 	}
 	DbTypeFlag = &cli.StringFlag{
 		Name:     "dbtype",
-		Usage:    `Blockchain storage database type ("LevelDB", "BadgerDB", "MemoryDB", "DynamoDBS3")`,
+		Usage:    `Blockchain storage database type ("LevelDB", "BadgerDB", "MemoryDB", "DynamoDBS3", "PebbleDB")`,
 		Value:    "LevelDB",
 		Aliases:  []string{"db.type", "migration.src.dbtype"},
 		EnvVars:  []string{"KLAYTN_DBTYPE"},
@@ -280,6 +282,22 @@ var (
 		EnvVars:  []string{"KLAYTN_TXPOOL_NONEXEC_SLOTS_ALL"},
 		Category: "TXPOOL",
 	}
+	TxPoolLargeTxSizeFlag = &cli.Uint64Flag{
+		Name:     "txpool.large-tx.size",
+		Usage:    "Size in bytes over which a transaction goes to the large transaction sub-pool (0 = disabled)",
+		Value:    cn.GetDefaultConfig().TxPool.LargeTxSize,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_TXPOOL_LARGE_TX_SIZE"},
+		Category: "TXPOOL",
+	}
+	TxPoolLargeTxPoolSizeFlag = &cli.Uint64Flag{
+		Name:     "txpool.large-tx.pool-size",
+		Usage:    "Maximum size in bytes of all the transactions in the large transaction sub-pool",
+		Value:    cn.GetDefaultConfig().TxPool.LargeTxPoolSize,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_TXPOOL_LARGE_TX_POOL_SIZE"},
+		Category: "TXPOOL",
+	}
 	TxPoolKeepLocalsFlag = &cli.BoolFlag{
 		Name:     "txpool.keeplocals",
 		Usage:    "Disables removing timed-out local transactions",
@@ -418,6 +436,29 @@ var (
 		EnvVars:  []string{"KLAYTN_DB_ROCKSDB_CACHE_INDEX_AND_FILTER"},
 		Category: "DATABASE",
 	}
+	PebbleDBCacheSizeFlag = &cli.Uint64Flag{
+		Name:     "db.pebble.cache-size",
+		Usage:    "Size of in-memory cache and memtables in PebbleDB (MiB)",
+		Value:    768,
+		Aliases:  []string{"migration.src.db.pebble.cache-size"},
+		EnvVars:  []string{"KLAYTN_DB_PEBBLE_CACHE_SIZE"},
+		Category: "DATABASE",
+	}
+	PebbleDBMaxOpenFilesFlag = &cli.IntFlag{
+		Name:     "db.pebble.max-open-files",
+		Usage:    "Set PebbleDB max open files. (the value should be greater than 16)",
+		Value:    database.GetDefaultPebbleDBConfig().MaxOpenFiles,
+		Aliases:  []string{"migration.src.db.pebble.max-open-files"},
+		EnvVars:  []string{"KLAYTN_DB_PEBBLE_MAX_OPEN_FILES"},
+		Category: "DATABASE",
+	}
+	PebbleDBDisableMetricsFlag = &cli.BoolFlag{
+		Name:     "db.pebble.disable-metrics",
+		Usage:    "Disable PebbleDB metrics",
+		Aliases:  []string{"migration.src.db.pebble.disable-metrics"},
+		EnvVars:  []string{"KLAYTN_DB_PEBBLE_DISABLE_METRICS"},
+		Category: "DATABASE",
+	}
 	DynamoDBTableNameFlag = &cli.StringFlag{
 		Name:     "db.dynamo.tablename",
 		Usage:    "Specifies DynamoDB table name. This is mandatory to use dynamoDB. (Set dbtype to use DynamoDBS3)",
@@ -470,6 +511,36 @@ var (
 		EnvVars:  []string{"KLAYTN_DB_NO_PARALLEL_WRITE"},
 		Category: "DATABASE",
 	}
+	AncientFlag = &cli.BoolFlag{
+		Name:     "db.ancient",
+		Usage:    "Move old blocks to the append-only ancient store instead of keeping them in the key-value databases",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_ANCIENT"},
+		Category: "DATABASE",
+	}
+	AncientThresholdFlag = &cli.Uint64Flag{
+		Name:     "db.ancient.threshold",
+		Usage:    "Number of recent blocks kept in the key-value databases when the ancient store is enabled",
+		Value:    database.DefaultAncientThreshold,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_ANCIENT_THRESHOLD"},
+		Category: "DATABASE",
+	}
+	LogIndexFlag = &cli.BoolFlag{
+		Name:     "db.log-index",
+		Usage:    "Index the block numbers by the addresses and topics of their logs to speed up the log filters over long ranges",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_LOG_INDEX"},
+		Category: "DATABASE",
+	}
+	CompactReceiptsFlag = &cli.BoolFlag{
+		Name:     "db.compact-receipts",
+		Usage:    "Store receipts without the fields derivable from the block body, and migrate the existing ones in background",
+		Value:    true,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_COMPACT_RECEIPTS"},
+		Category: "DATABASE",
+	}
 	DBNoPerformanceMetricsFlag = &cli.BoolFlag{
 		Name:     "db.no-perf-metrics",
 		Usage:    "Disables performance metrics of database's read and write operations",
@@ -540,6 +611,14 @@ var (
 		EnvVars:  []string{"KLAYTN_STATE_LIVE_PRUNING_RETENTION"},
 		Category: "STATE",
 	}
+	StateHistoryRetentionFlag = &cli.Uint64Flag{
+		Name:     "state.history-retention",
+		Usage:    "Number of recent blocks whose states are accessible from the state histories without the trie (0 = disabled, requires snapshot)",
+		Value:    0,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_STATE_HISTORY_RETENTION"},
+		Category: "STATE",
+	}
 	CacheTypeFlag = &cli.IntFlag{
 		Name:     "cache.type",
 		Usage:    "Cache Type: 0=LRUCache, 1=LRUShardCache, 2=FIFOCache",
@@ -607,6 +686,28 @@ var (
 		EnvVars:  []string{"KLAYTN_STATEDB_CACHE_REDIS_CLUSTER"},
 		Category: "CACHE",
 	}
+	TrieNodeCacheRedisShardingFlag = &cli.BoolFlag{
+		Name:     "statedb.cache.redis.sharding",
+		Usage:    "Shards redis trie node cache over the endpoints with consistent hashing. It can't be used with the cluster mode",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_STATEDB_CACHE_REDIS_SHARDING"},
+		Category: "CACHE",
+	}
+	TrieNodeCacheRedisTTLFlag = &cli.DurationFlag{
+		Name:     "statedb.cache.redis.ttl",
+		Usage:    "Expiration of the items in redis trie node cache, 0 means no expiration",
+		Value:    0,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_STATEDB_CACHE_REDIS_TTL"},
+		Category: "CACHE",
+	}
+	TrieNodeCacheRedisEvictionPolicyFlag = &cli.StringFlag{
+		Name:     "statedb.cache.redis.eviction-policy",
+		Usage:    "Eviction policy (maxmemory-policy) set to the redis servers of trie node cache, e.g. allkeys-lru or volatile-ttl. Empty means the server setting",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_STATEDB_CACHE_REDIS_EVICTION_POLICY"},
+		Category: "CACHE",
+	}
 	TrieNodeCacheRedisPublishBlockFlag = &cli.BoolFlag{
 		Name:     "statedb.cache.redis.publish",
 		Usage:    "Publishes every committed block to redis trie node cache",
@@ -857,6 +958,22 @@ var (
 		EnvVars:  []string{"KLAYTN_RPC_CONCURRENCYLIMIT"},
 		Category: "API AND CONSOLE",
 	}
+	RPCBatchRequestLimitFlag = &cli.IntFlag{
+		Name:     "rpc.batchrequestlimit",
+		Usage:    "Maximum number of requests in a batch of RPC server (0 = no limit)",
+		Value:    rpc.BatchRequestLimit,
+		Aliases:  []string{"http-rpc.batch-request-limit"},
+		EnvVars:  []string{"KLAYTN_RPC_BATCHREQUESTLIMIT"},
+		Category: "API AND CONSOLE",
+	}
+	RPCBatchResponseMaxSizeFlag = &cli.IntFlag{
+		Name:     "rpc.batchresponsemaxsize",
+		Usage:    "Maximum number of bytes of the responses to a batch of RPC server (0 = no limit)",
+		Value:    rpc.BatchResponseMaxSize,
+		Aliases:  []string{"http-rpc.batch-response-max-size"},
+		EnvVars:  []string{"KLAYTN_RPC_BATCHRESPONSEMAXSIZE"},
+		Category: "API AND CONSOLE",
+	}
 	RPCNonEthCompatibleFlag = &cli.BoolFlag{
 		Name:     "rpc.eth.noncompatible",
 		Usage:    "Disables the eth namespace API return formatting for compatibility",
@@ -903,6 +1020,21 @@ var (
 		EnvVars:  []string{"KLAYTN_RPC_UPSTREAM_EN"},
 		Category: "API AND CONSOLE",
 	}
+	GraphQLEnabledFlag = &cli.BoolFlag{
+		Name:     "graphql",
+		Usage:    "Enable the GraphQL service on the HTTP-RPC server. Note that GraphQL can only be started if the HTTP-RPC server is started as well.",
+		Aliases:  []string{"http-rpc.graphql"},
+		EnvVars:  []string{"KLAYTN_GRAPHQL"},
+		Category: "API AND CONSOLE",
+	}
+	RPCJWTSecretFlag = &cli.StringFlag{
+		Name:     "rpc.jwtsecret",
+		Usage:    "Path to the hex-encoded secret authenticating the HTTP-RPC and WebSocket callers with JWT (generated if missing, empty = disabled)",
+		Value:    "",
+		Aliases:  []string{"http-rpc.jwt-secret"},
+		EnvVars:  []string{"KLAYTN_RPC_JWTSECRET"},
+		Category: "API AND CONSOLE",
+	}
 
 	WSEnabledFlag = &cli.BoolFlag{
 		Name:     "ws",
@@ -1784,7 +1916,7 @@ var (
 	// db migration vars
 	DstDbTypeFlag = &cli.StringFlag{
 		Name:     "dst.dbtype",
-		Usage:    `Blockchain storage database type ("LevelDB", "BadgerDB", "DynamoDBS3")`,
+		Usage:    `Blockchain storage database type ("LevelDB", "BadgerDB", "DynamoDBS3", "PebbleDB")`,
 		Value:    "LevelDB",
 		Aliases:  []string{"migration.dst.dbtype"},
 		EnvVars:  []string{"KLAYTN_DST_DBTYPE"},
@@ -1934,6 +2066,51 @@ var (
 		EnvVars:  []string{"KLAYTN_DB_DST_ROCKSDB_CACHE_INDEX_AND_FILTER"},
 		Category: "DATABASE MIGRATION",
 	}
+	DstPebbleDBCacheSizeFlag = &cli.Uint64Flag{
+		Name:     "db.dst.pebble.cache-size",
+		Usage:    "Size of in-memory cache and memtables in PebbleDB (MiB)",
+		Value:    768,
+		Aliases:  []string{"migration.dst.db.pebble.cache-size"},
+		EnvVars:  []string{"KLAYTN_DB_DST_PEBBLE_CACHE_SIZE"},
+		Category: "DATABASE MIGRATION",
+	}
+	DstPebbleDBMaxOpenFilesFlag = &cli.IntFlag{
+		Name:     "db.dst.pebble.max-open-files",
+		Usage:    "Set PebbleDB max open files. (the value should be greater than 16)",
+		Value:    database.GetDefaultPebbleDBConfig().MaxOpenFiles,
+		Aliases:  []string{"migration.dst.db.pebble.max-open-files"},
+		EnvVars:  []string{"KLAYTN_DB_DST_PEBBLE_MAX_OPEN_FILES"},
+		Category: "DATABASE MIGRATION",
+	}
+	DstPebbleDBDisableMetricsFlag = &cli.BoolFlag{
+		Name:     "db.dst.pebble.disable-metrics",
+		Usage:    "Disable PebbleDB metrics",
+		Aliases:  []string{"migration.dst.db.pebble.disable-metrics"},
+		EnvVars:  []string{"KLAYTN_DB_DST_PEBBLE_DISABLE_METRICS"},
+		Category: "DATABASE MIGRATION",
+	}
+
+	// db inspect
+	DBInspectJSONFlag = &cli.BoolFlag{
+		Name:     "json",
+		Usage:    "Print the result of db inspect in JSON format",
+		Category: "MISC",
+	}
+
+	// snapshot prune-state
+	BloomFilterSizeFlag = &cli.Uint64Flag{
+		Name:     "bloomfilter.size",
+		Usage:    "Megabytes of memory allocated to bloom-filter for pruning",
+		Value:    2048,
+		Category: "MISC",
+	}
+
+	// export
+	ExportReceiptsFlag = &cli.StringFlag{
+		Name:     "receipts",
+		Usage:    "File to export the receipts of the exported blocks into (gzip-compressed if the name ends with .gz)",
+		Category: "MISC",
+	}
 
 	// Config
 	ConfigFileFlag = &cli.StringFlag{
@@ -1962,6 +2139,24 @@ var (
 		EnvVars:  []string{"KLAYTN_BLOCK_GENERATION_TIME_LIMIT"},
 		Category: "KLAY",
 	}
+	BlockGenerationTxOrderingFlag = &cli.StringFlag{
+		Name: "block-generation-tx-ordering",
+		Usage: "Set the ordering policy of the transactions in the generated blocks " +
+			"('time': first seen first, 'tip': highest effective tip first, 'hybrid': 'tip' with a per-sender cap). " +
+			"This flag is only applicable to CN",
+		Value:    string(work.DefaultTxOrderingConfig.Policy),
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_BLOCK_GENERATION_TX_ORDERING"},
+		Category: "KLAY",
+	}
+	BlockGenerationTxSenderCapFlag = &cli.IntFlag{
+		Name:     "block-generation-tx-sender-cap",
+		Usage:    "Set the number of the transactions of a sender included in a row with the 'hybrid' transaction ordering",
+		Value:    work.DefaultTxOrderingConfig.SenderCap,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_BLOCK_GENERATION_TX_SENDER_CAP"},
+		Category: "KLAY",
+	}
 	OpcodeComputationCostLimitFlag = &cli.Uint64Flag{
 		Name: "opcode-computation-cost-limit",
 		Usage: "(experimental option) Set the computation cost limit for a tx. " +
@@ -2099,6 +2294,22 @@ func RegisterDBSyncerService(stack *node.Node, cfg *dbsyncer.DBConfig) {
 	}
 }
 
+// RegisterGraphQLService adds a GraphQL service mounted on the HTTP-RPC server to the stack
+func RegisterGraphQLService(stack *node.Node, cfg *node.Config) {
+	if cfg.GraphQLEnabled {
+		if cfg.HTTPHost == "" {
+			logger.Warn("GraphQL service is not served since the HTTP-RPC server is disabled")
+			return
+		}
+		err := stack.RegisterSubService(func(ctx *node.ServiceContext) (node.Service, error) {
+			return graphql.New(stack), nil
+		})
+		if err != nil {
+			log.Fatalf("Failed to register the GraphQL service: %v", err)
+		}
+	}
+}
+
 // MakeConsolePreloads retrieves the absolute paths for the console JavaScript
 // scripts to preload before starting.
 func MakeConsolePreloads(ctx *cli.Context) []string {
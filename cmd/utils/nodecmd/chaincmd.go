@@ -23,12 +23,16 @@ package nodecmd
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/cmd/utils"
+	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/governance"
 	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/params"
@@ -67,6 +71,9 @@ var (
 			utils.RocksDBDisableMetricsFlag,
 			utils.RocksDBMaxOpenFilesFlag,
 			utils.RocksDBCacheIndexAndFilterFlag,
+			utils.PebbleDBCacheSizeFlag,
+			utils.PebbleDBMaxOpenFilesFlag,
+			utils.PebbleDBDisableMetricsFlag,
 			utils.OverwriteGenesisFlag,
 			utils.LivePruningFlag,
 		},
@@ -92,6 +99,93 @@ It expects the genesis file as argument.`,
 		Description: `
 The dumpgenesis command dumps the genesis block configuration in JSON format to stdout.`,
 	}
+
+	ImportCommand = &cli.Command{
+		Action:    utils.MigrateFlags(importChain),
+		Name:      "import",
+		Usage:     "Import a blockchain file",
+		ArgsUsage: "<filename> (<filename 2> ... <filename N>) ",
+		Flags:     chainDataFlags,
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The import command imports blocks from an RLP-encoded form. The form can be one file
+with several RLP-encoded blocks, or several files can be used. A file is read as
+gzip-compressed if its name ends with .gz. The blocks already in the database are
+skipped, so an interrupted import can be resumed by running the same command again.
+
+If only one file is used, import error will result in failure. If several files are used,
+processing will proceed even if an individual RLP-file import failure occurs.`,
+	}
+
+	ExportCommand = &cli.Command{
+		Action:    utils.MigrateFlags(exportChain),
+		Name:      "export",
+		Usage:     "Export blockchain into file",
+		ArgsUsage: "<filename> [<blockNumFirst> <blockNumLast>]",
+		Flags:     append(append([]cli.Flag{}, chainDataFlags...), utils.ExportReceiptsFlag),
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+Requires a first argument of the file to write to.
+Optional second and third arguments control the first and
+last block to write. In this mode, the file will be appended
+if already existing. If the file ends with .gz, the output will
+be gzipped. Use --receipts to export the receipts of the same
+blocks into another file.`,
+	}
+
+	ImportPreimagesCommand = &cli.Command{
+		Action:    utils.MigrateFlags(importPreimages),
+		Name:      "import-preimages",
+		Usage:     "Import the preimage database from an RLP stream",
+		ArgsUsage: "<datafile>",
+		Flags:     utils.SnapshotFlags,
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The import-preimages command imports hash preimages from an RLP encoded stream.
+A file is read as gzip-compressed if its name ends with .gz.`,
+	}
+
+	ExportPreimagesCommand = &cli.Command{
+		Action:    utils.MigrateFlags(exportPreimages),
+		Name:      "export-preimages",
+		Usage:     "Export the preimage database into an RLP stream",
+		ArgsUsage: "<dumpfile>",
+		Flags:     utils.SnapshotFlags,
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The export-preimages command exports hash preimages to an RLP encoded stream.
+If the file ends with .gz, the output will be gzipped.`,
+	}
+
+	RestoreCommand = &cli.Command{
+		Action:    utils.MigrateFlags(restoreBackup),
+		Name:      "restore",
+		Usage:     "Restore the chain data from a backup",
+		ArgsUsage: "<backupDir>",
+		Flags:     utils.SnapshotFlags,
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The restore command copies the backup taken by admin.backup into the chain data
+directory of the node, which should be empty or not exist. The database flags
+should be the same as the ones of the node the backup was taken from.`,
+	}
+)
+
+// chainDataFlags are the flags of the commands which open the chain of the node offline.
+var chainDataFlags = append(append([]cli.Flag{}, utils.SnapshotFlags...),
+	utils.NetworkIdFlag,
+	utils.GCModeFlag,
+	utils.TrieMemoryCacheSizeFlag,
+	utils.TrieBlockIntervalFlag,
+	utils.TriesInMemoryFlag,
+	utils.SnapshotFlag,
+	utils.SnapshotCacheSizeFlag,
+	utils.SnapshotAsyncGen,
+	utils.StateHistoryRetentionFlag,
+	utils.AncientFlag,
+	utils.AncientThresholdFlag,
+	utils.LogIndexFlag,
+	utils.CompactReceiptsFlag,
 )
 
 // initGenesis will initialise the given JSON format genesis file and writes it as
@@ -175,12 +269,20 @@ func initGenesis(ctx *cli.Context) error {
 			CacheIndexAndFilter:       ctx.Bool(utils.RocksDBCacheIndexAndFilterFlag.Name),
 		}
 	}
+	pebbleDBConfig := database.GetDefaultPebbleDBConfig()
+	if dbtype == database.PebbleDB {
+		pebbleDBConfig = &database.PebbleDBConfig{
+			CacheSize:      ctx.Uint64(utils.PebbleDBCacheSizeFlag.Name),
+			MaxOpenFiles:   ctx.Int(utils.PebbleDBMaxOpenFilesFlag.Name),
+			DisableMetrics: ctx.Bool(utils.PebbleDBDisableMetricsFlag.Name),
+		}
+	}
 
 	for _, name := range []string{"chaindata"} { // Removed "lightchaindata" since Kaia doesn't use it
 		dbc := &database.DBConfig{
 			Dir: name, DBType: dbtype, ParallelDBWrite: parallelDBWrite,
 			SingleDB: singleDB, NumStateTrieShards: numStateTrieShards,
-			LevelDBCacheSize: 0, OpenFilesLimit: 0, DynamoDBConfig: dynamoDBConfig, RocksDBConfig: rocksDBConfig,
+			LevelDBCacheSize: 0, OpenFilesLimit: 0, DynamoDBConfig: dynamoDBConfig, RocksDBConfig: rocksDBConfig, PebbleDBConfig: pebbleDBConfig,
 		}
 		chainDB := stack.OpenDatabase(dbc)
 
@@ -283,3 +385,157 @@ func ValidateGenesisConfig(g *blockchain.Genesis) error {
 	}
 	return nil
 }
+
+// importChain imports the blocks from the given files into the chain of the node.
+func importChain(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return errors.New("this command requires an argument")
+	}
+	_, cfg := utils.MakeConfigNode(ctx)
+	chainDB := utils.MakeChainDatabase(&cfg)
+	defer chainDB.Close()
+
+	chain, err := utils.MakeChain(&cfg, chainDB)
+	if err != nil {
+		return err
+	}
+	defer chain.Stop()
+
+	var (
+		start     = time.Now()
+		importErr error
+	)
+	if ctx.NArg() == 1 {
+		if err := utils.ImportChain(chain, ctx.Args().First()); err != nil {
+			importErr = err
+			logger.Error("Import error", "err", err)
+		}
+	} else {
+		for _, arg := range ctx.Args().Slice() {
+			if err := utils.ImportChain(chain, arg); err != nil {
+				importErr = err
+				logger.Error("Import error", "file", arg, "err", err)
+			}
+		}
+	}
+	head := chain.CurrentBlock()
+	logger.Info("Import done", "number", head.NumberU64(), "hash", head.Hash(), "elapsed", common.PrettyDuration(time.Since(start)))
+	return importErr
+}
+
+// exportChain exports the blocks, and optionally the receipts, of the chain of the node.
+func exportChain(ctx *cli.Context) error {
+	if ctx.NArg() != 1 && ctx.NArg() != 3 {
+		return errors.New("this command requires one or three arguments")
+	}
+	_, cfg := utils.MakeConfigNode(ctx)
+	chainDB := utils.MakeChainDatabase(&cfg)
+	defer chainDB.Close()
+
+	chain, err := utils.MakeChain(&cfg, chainDB)
+	if err != nil {
+		return err
+	}
+	defer chain.Stop()
+
+	var (
+		start        = time.Now()
+		fp           = ctx.Args().First()
+		receiptsFile = ctx.String(utils.ExportReceiptsFlag.Name)
+	)
+	if ctx.NArg() == 1 {
+		if err := utils.ExportChain(chain, fp); err != nil {
+			return err
+		}
+		if receiptsFile != "" {
+			if err := utils.ExportReceipts(chain, receiptsFile, 0, chain.CurrentBlock().NumberU64()); err != nil {
+				return err
+			}
+		}
+	} else {
+		// This can be improved to allow for numbers larger than 9223372036854775807
+		first, ferr := strconv.ParseInt(ctx.Args().Get(1), 10, 64)
+		last, lerr := strconv.ParseInt(ctx.Args().Get(2), 10, 64)
+		if ferr != nil || lerr != nil {
+			return errors.New("export error in parsing parameters: block number not an integer")
+		}
+		if first < 0 || last < 0 {
+			return errors.New("export error: block number must be greater than 0")
+		}
+		if head := chain.CurrentBlock().NumberU64(); uint64(last) > head {
+			last = int64(head)
+		}
+		if err := utils.ExportAppendChain(chain, fp, uint64(first), uint64(last)); err != nil {
+			return err
+		}
+		if receiptsFile != "" {
+			if err := utils.ExportAppendReceipts(chain, receiptsFile, uint64(first), uint64(last)); err != nil {
+				return err
+			}
+		}
+	}
+	logger.Info("Export done", "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// importPreimages imports preimage data from the specified file.
+func importPreimages(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return errors.New("this command requires an argument")
+	}
+	_, cfg := utils.MakeConfigNode(ctx)
+	chainDB := utils.MakeChainDatabase(&cfg)
+	defer chainDB.Close()
+
+	start := time.Now()
+	if err := utils.ImportPreimages(chainDB, ctx.Args().First()); err != nil {
+		return err
+	}
+	logger.Info("Import done", "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// restoreBackup restores the chain data of the node from the given backup.
+func restoreBackup(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("this command requires an argument")
+	}
+	_, cfg := utils.MakeConfigNode(ctx)
+
+	backupDir := ctx.Args().First()
+	manifest, err := database.ReadBackupManifest(backupDir)
+	if err != nil {
+		return err
+	}
+	if manifest.DBType != cfg.CN.DBType || manifest.SingleDB != cfg.CN.SingleDB ||
+		(!manifest.SingleDB && manifest.NumStateTrieShards != cfg.CN.NumStateTrieShards) {
+		return fmt.Errorf("database configuration mismatch: backup (dbtype: %v, singledb: %v, shards: %v), node (dbtype: %v, singledb: %v, shards: %v)",
+			manifest.DBType, manifest.SingleDB, manifest.NumStateTrieShards, cfg.CN.DBType, cfg.CN.SingleDB, cfg.CN.NumStateTrieShards)
+	}
+
+	start := time.Now()
+	chainDataDir := cfg.Node.ResolvePath("chaindata")
+	if _, err := database.RestoreBackup(backupDir, chainDataDir); err != nil {
+		return err
+	}
+	logger.Info("Restore done", "dir", chainDataDir, "number", manifest.HeadNumber, "hash", manifest.HeadHash,
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// exportPreimages dumps the preimage data to specified json file in streaming way.
+func exportPreimages(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return errors.New("this command requires an argument")
+	}
+	_, cfg := utils.MakeConfigNode(ctx)
+	chainDB := utils.MakeChainDatabase(&cfg)
+	defer chainDB.Close()
+
+	start := time.Now()
+	if err := utils.ExportPreimages(chainDB, ctx.Args().First()); err != nil {
+		return err
+	}
+	logger.Info("Export done", "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package nodecmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/klaytn/klaytn/cmd/utils"
+	istanbulCore "github.com/klaytn/klaytn/consensus/istanbul/core"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/urfave/cli/v2"
+)
+
+var DBCommand = &cli.Command{
+	Name:        "db",
+	Usage:       "A set of commands for the low level database operations",
+	Category:    "DATABASE COMMANDS",
+	Description: "",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "inspect",
+			Usage:  "Inspect the storage size for each type of data in the database",
+			Action: utils.MigrateFlags(inspectDB),
+			Flags:  append(append([]cli.Flag{}, utils.SnapshotFlags...), utils.DBInspectJSONFlag),
+			Description: `
+klay db inspect
+iterates all the databases of the node offline and reports the number of
+entries and the size of keys and values for each database and for each
+category of keys such as headers, bodies, receipts, tx lookups, trie nodes,
+snapshots and pruning marks. The blocks moved to the ancient store are also
+reported. Use --json to print the result in JSON format.
+`,
+		},
+		{
+			Name:   "index-logs",
+			Usage:  "Build the log index for the blocks written before it was enabled",
+			Action: utils.MigrateFlags(indexLogs),
+			Flags:  append([]cli.Flag{}, utils.SnapshotFlags...),
+			Description: `
+klay db index-logs
+indexes the block numbers by the addresses and topics of their logs, from the
+oldest block indexed down to the genesis block, so that the log filters can
+use the log index (--db.log-index) over the whole chain. It must be run while
+the node is stopped. If interrupted, it continues from where it stopped next
+time.
+`,
+		},
+		{
+			Name:      "consensus-trace",
+			Usage:     "Render the consensus trace of a block",
+			ArgsUsage: "<blockNumber>",
+			Action:    utils.MigrateFlags(consensusTrace),
+			Flags:     append([]cli.Flag{}, utils.SnapshotFlags...),
+			Description: `
+klay db consensus-trace <blockNumber>
+prints the consensus messages received and sent by the node for the block in
+the order of their arrival, with their validation results, followed by the
+summary of each round explaining why the round change happened. Only the
+traces of the latest blocks are kept. It must be run while the node is stopped.
+`,
+		},
+	},
+}
+
+// inspectDB opens the databases of the node and prints the storage usage of them.
+func inspectDB(ctx *cli.Context) error {
+	if ctx.NArg() > 0 {
+		return fmt.Errorf("too many arguments: %v", ctx.Args().Slice())
+	}
+	stack := MakeFullNode(ctx)
+	dbm := stack.OpenDatabase(getConfig(ctx))
+	defer dbm.Close()
+
+	result, err := database.InspectDatabase(dbm)
+	if err != nil {
+		logger.Error("Failed to inspect the database", "err", err)
+		return err
+	}
+	if ctx.Bool(utils.DBInspectJSONFlag.Name) {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	return printInspectResult(os.Stdout, result)
+}
+
+// indexLogs opens the databases of the node and backfills the log index.
+func indexLogs(ctx *cli.Context) error {
+	if ctx.NArg() > 0 {
+		return fmt.Errorf("too many arguments: %v", ctx.Args().Slice())
+	}
+	stack := MakeFullNode(ctx)
+	dbc := getConfig(ctx)
+	dbc.EnableLogIndex = true
+	dbm := stack.OpenDatabase(dbc)
+	defer dbm.Close()
+
+	// Stop at the next batch on Ctrl-C, so that the progress is kept.
+	interrupt := make(chan os.Signal, 1)
+	quit := make(chan struct{})
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+	go func() {
+		if _, ok := <-interrupt; ok {
+			close(quit)
+		}
+	}()
+	defer close(interrupt)
+
+	if err := dbm.BackfillLogIndex(quit); err != nil {
+		logger.Error("Failed to backfill the log index", "err", err)
+		return err
+	}
+	return nil
+}
+
+// consensusTrace opens the databases of the node and renders the consensus trace of the given block.
+func consensusTrace(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("expected a block number, got %d arguments", ctx.NArg())
+	}
+	number, err := strconv.ParseUint(ctx.Args().First(), 0, 64)
+	if err != nil {
+		return fmt.Errorf("invalid block number %q: %v", ctx.Args().First(), err)
+	}
+	stack := MakeFullNode(ctx)
+	dbm := stack.OpenDatabase(getConfig(ctx))
+	defer dbm.Close()
+
+	istanbulCore.RenderConsensusTrace(os.Stdout, number, istanbulCore.ReadConsensusTrace(dbm, number))
+	return nil
+}
+
+// printInspectResult prints the result of database.InspectDatabase in a table.
+func printInspectResult(out io.Writer, result *database.InspectResult) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "DATABASE\tCATEGORY\tCOUNT\tSIZE\t")
+
+	var prev string
+	for _, entry := range result.Entries {
+		if prev != "" && prev != entry.Database {
+			total := result.Databases[prev]
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t\n", prev, "Total", total.Count, total.Size)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t\n", entry.Database, entry.Category, entry.Count, entry.Size)
+		prev = entry.Database
+	}
+	if prev != "" {
+		total := result.Databases[prev]
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t\n", prev, "Total", total.Count, total.Size)
+	}
+	fmt.Fprintf(w, "%s\t%s\t%d\t%s\t\n", "", "Total", result.Total.Count, result.Total.Size)
+	return w.Flush()
+}
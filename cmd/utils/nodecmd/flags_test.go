@@ -228,6 +228,39 @@ var flagsWithValues = []struct {
 		flag:     "--db.no-parallel-write",
 		flagType: FlagTypeBoolean,
 	},
+	{
+		flag:        "--db.pebble.cache-size",
+		flagType:    FlagTypeArgument,
+		values:      []string{"768"},
+		wrongValues: commonTwoErrors,
+		errors:      []int{ErrorInvalidValue, ErrorInvalidValue},
+	},
+	{
+		flag:        "--db.pebble.max-open-files",
+		flagType:    FlagTypeArgument,
+		values:      []string{"1024"},
+		wrongValues: commonTwoErrors,
+		errors:      []int{ErrorInvalidValue, ErrorInvalidValue},
+	},
+	{
+		flag:     "--db.pebble.disable-metrics",
+		flagType: FlagTypeBoolean,
+	},
+	{
+		flag:     "--db.ancient",
+		flagType: FlagTypeBoolean,
+	},
+	{
+		flag:        "--db.ancient.threshold",
+		flagType:    FlagTypeArgument,
+		values:      []string{"172800"},
+		wrongValues: commonTwoErrors,
+		errors:      []int{ErrorInvalidValue, ErrorInvalidValue},
+	},
+	{
+		flag:     "--db.log-index",
+		flagType: FlagTypeBoolean,
+	},
 	{
 		flag:        "--state.cache-size",
 		flagType:    FlagTypeArgument,
@@ -125,6 +125,12 @@ func createDBConfigForMigration(ctx *cli.Context) (*database.DBConfig, *database
 			MaxOpenFiles:              ctx.Int(utils.RocksDBMaxOpenFilesFlag.Name),
 			CacheIndexAndFilter:       ctx.Bool(utils.RocksDBCacheIndexAndFilterFlag.Name),
 		},
+
+		PebbleDBConfig: &database.PebbleDBConfig{
+			CacheSize:      ctx.Uint64(utils.PebbleDBCacheSizeFlag.Name),
+			MaxOpenFiles:   ctx.Int(utils.PebbleDBMaxOpenFilesFlag.Name),
+			DisableMetrics: ctx.Bool(utils.PebbleDBDisableMetricsFlag.Name),
+		},
 	}
 	if len(srcDBC.DBType) == 0 { // changed to invalid type
 		return nil, nil, errors.New("srcDB is not specified or invalid : " + ctx.String(utils.DbTypeFlag.Name))
@@ -162,6 +168,12 @@ func createDBConfigForMigration(ctx *cli.Context) (*database.DBConfig, *database
 			MaxOpenFiles:              ctx.Int(utils.DstRocksDBMaxOpenFilesFlag.Name),
 			CacheIndexAndFilter:       ctx.Bool(utils.DstRocksDBCacheIndexAndFilterFlag.Name),
 		},
+
+		PebbleDBConfig: &database.PebbleDBConfig{
+			CacheSize:      ctx.Uint64(utils.DstPebbleDBCacheSizeFlag.Name),
+			MaxOpenFiles:   ctx.Int(utils.DstPebbleDBMaxOpenFilesFlag.Name),
+			DisableMetrics: ctx.Bool(utils.DstPebbleDBDisableMetricsFlag.Name),
+		},
 	}
 	if len(dstDBC.DBType) == 0 { // changed to invalid type
 		return nil, nil, errors.New("dstDB is not specified or invalid : " + ctx.String(utils.DstDbTypeFlag.Name))
@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/state/pruner"
 	"github.com/klaytn/klaytn/cmd/utils"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/snapshot"
@@ -41,6 +42,28 @@ var SnapshotCommand = &cli.Command{
 	Usage:       "A set of commands based on the snapshot",
 	Description: "",
 	Subcommands: []*cli.Command{
+		{
+			Name:      "prune-state",
+			Usage:     "Prune stale state data based on the snapshot",
+			ArgsUsage: "<root>",
+			Action:    utils.MigrateFlags(pruneState),
+			Flags:     append(append([]cli.Flag{}, utils.SnapshotFlags...), utils.BloomFilterSizeFlag),
+			Description: `
+klay snapshot prune-state <state-root>
+will prune historical state data with the help of the state snapshot.
+All trie nodes and contract codes that do not belong to the specified
+version state will be deleted from the database. After pruning, only
+the target state and the genesis state are left.
+
+The default pruning target is the state of the head block. A state root
+of a recent block that is still covered by the snapshot can be given
+instead, then the chain is rewound to that block on the next start.
+
+WARNING: it's only supported when live pruning is disabled. The pruning
+is resumable: if it is interrupted after the bloom filter is written
+to the data directory, run the command again to finish the pruning.
+`,
+		},
 		{
 			Name:      "verify-state",
 			Usage:     "Recalculate state hash based on the snapshot for verification",
@@ -125,6 +148,12 @@ func getConfig(ctx *cli.Context) *database.DBConfig {
 			MaxOpenFiles:              ctx.Int(utils.RocksDBMaxOpenFilesFlag.Name),
 			CacheIndexAndFilter:       ctx.Bool(utils.RocksDBCacheIndexAndFilterFlag.Name),
 		},
+
+		PebbleDBConfig: &database.PebbleDBConfig{
+			CacheSize:      ctx.Uint64(utils.PebbleDBCacheSizeFlag.Name),
+			MaxOpenFiles:   ctx.Int(utils.PebbleDBMaxOpenFilesFlag.Name),
+			DisableMetrics: ctx.Bool(utils.PebbleDBDisableMetricsFlag.Name),
+		},
 	}
 }
 
@@ -137,6 +166,40 @@ func parseRoot(input string) (common.Hash, error) {
 	return h, nil
 }
 
+// pruneState deletes the state data which doesn't belong to the given state root.
+// If a root hash isn't given, the state of the head block is kept.
+func pruneState(ctx *cli.Context) error {
+	if ctx.NArg() > 1 {
+		logger.Error("Too many arguments given")
+		return errors.New("too many arguments")
+	}
+	stack := MakeFullNode(ctx)
+	dbm := stack.OpenDatabase(getConfig(ctx))
+	defer dbm.Close()
+
+	p, err := pruner.NewPruner(dbm, pruner.Config{
+		Datadir:   stack.ResolvePath(""),
+		BloomSize: ctx.Uint64(utils.BloomFilterSizeFlag.Name),
+	})
+	if err != nil {
+		logger.Error("Failed to open snapshot tree", "err", err)
+		return err
+	}
+	var root common.Hash
+	if ctx.NArg() == 1 {
+		root, err = parseRoot(ctx.Args().First())
+		if err != nil {
+			logger.Error("Failed to resolve state root", "err", err)
+			return err
+		}
+	}
+	if err = p.Prune(root); err != nil {
+		logger.Error("Failed to prune state", "err", err)
+		return err
+	}
+	return nil
+}
+
 // verifyState verifies if the stored snapshot data is correct or not.
 // if a root hash isn't given, the root hash of current block is investigated.
 func verifyState(ctx *cli.Context) error {
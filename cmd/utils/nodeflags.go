@@ -186,6 +186,8 @@ var CommonNodeFlags = []cli.Flag{
 	altsrc.NewUint64Flag(TxPoolExecSlotsAllFlag),
 	altsrc.NewUint64Flag(TxPoolNonExecSlotsAccountFlag),
 	altsrc.NewUint64Flag(TxPoolNonExecSlotsAllFlag),
+	altsrc.NewUint64Flag(TxPoolLargeTxSizeFlag),
+	altsrc.NewUint64Flag(TxPoolLargeTxPoolSizeFlag),
 	altsrc.NewDurationFlag(TxPoolLifetimeFlag),
 	altsrc.NewBoolFlag(TxPoolKeepLocalsFlag),
 	NewWrappedTextMarshalerFlag(SyncModeFlag),
@@ -205,6 +207,9 @@ var CommonNodeFlags = []cli.Flag{
 	altsrc.NewBoolFlag(RocksDBDisableMetricsFlag),
 	altsrc.NewIntFlag(RocksDBMaxOpenFilesFlag),
 	altsrc.NewBoolFlag(RocksDBCacheIndexAndFilterFlag),
+	altsrc.NewUint64Flag(PebbleDBCacheSizeFlag),
+	altsrc.NewIntFlag(PebbleDBMaxOpenFilesFlag),
+	altsrc.NewBoolFlag(PebbleDBDisableMetricsFlag),
 	altsrc.NewStringFlag(DynamoDBTableNameFlag),
 	altsrc.NewStringFlag(DynamoDBRegionFlag),
 	altsrc.NewBoolFlag(DynamoDBIsProvisionedFlag),
@@ -214,11 +219,16 @@ var CommonNodeFlags = []cli.Flag{
 	altsrc.NewIntFlag(LevelDBCacheSizeFlag),
 	altsrc.NewBoolFlag(NoParallelDBWriteFlag),
 	altsrc.NewBoolFlag(SenderTxHashIndexingFlag),
+	altsrc.NewBoolFlag(AncientFlag),
+	altsrc.NewUint64Flag(AncientThresholdFlag),
+	altsrc.NewBoolFlag(LogIndexFlag),
+	altsrc.NewBoolFlag(CompactReceiptsFlag),
 	altsrc.NewIntFlag(TrieMemoryCacheSizeFlag),
 	altsrc.NewUintFlag(TrieBlockIntervalFlag),
 	altsrc.NewUint64Flag(TriesInMemoryFlag),
 	altsrc.NewBoolFlag(LivePruningFlag),
 	altsrc.NewUint64Flag(LivePruningRetentionFlag),
+	altsrc.NewUint64Flag(StateHistoryRetentionFlag),
 	altsrc.NewIntFlag(CacheTypeFlag),
 	altsrc.NewIntFlag(CacheScaleFlag),
 	altsrc.NewStringFlag(CacheUsageLevelFlag),
@@ -230,6 +240,9 @@ var CommonNodeFlags = []cli.Flag{
 	altsrc.NewDurationFlag(TrieNodeCacheSavePeriodFlag),
 	altsrc.NewStringSliceFlag(TrieNodeCacheRedisEndpointsFlag),
 	altsrc.NewBoolFlag(TrieNodeCacheRedisClusterFlag),
+	altsrc.NewBoolFlag(TrieNodeCacheRedisShardingFlag),
+	altsrc.NewDurationFlag(TrieNodeCacheRedisTTLFlag),
+	altsrc.NewStringFlag(TrieNodeCacheRedisEvictionPolicyFlag),
 	altsrc.NewBoolFlag(TrieNodeCacheRedisPublishBlockFlag),
 	altsrc.NewBoolFlag(TrieNodeCacheRedisSubscribeBlockFlag),
 	altsrc.NewIntFlag(ListenPortFlag),
@@ -292,6 +305,8 @@ var CommonRPCFlags = []cli.Flag{
 	altsrc.NewStringFlag(GRPCListenAddrFlag),
 	altsrc.NewIntFlag(GRPCPortFlag),
 	altsrc.NewIntFlag(RPCConcurrencyLimit),
+	altsrc.NewIntFlag(RPCBatchRequestLimitFlag),
+	altsrc.NewIntFlag(RPCBatchResponseMaxSizeFlag),
 	altsrc.NewStringFlag(WSApiFlag),
 	altsrc.NewStringFlag(WSAllowedOriginsFlag),
 	altsrc.NewIntFlag(WSMaxSubscriptionPerConn),
@@ -308,6 +323,8 @@ var CommonRPCFlags = []cli.Flag{
 	altsrc.NewIntFlag(HeavyDebugRequestLimitFlag),
 	altsrc.NewDurationFlag(StateRegenerationTimeLimitFlag),
 	altsrc.NewStringFlag(RPCUpstreamArchiveENFlag),
+	altsrc.NewStringFlag(RPCJWTSecretFlag),
+	altsrc.NewBoolFlag(GraphQLEnabledFlag),
 }
 
 var BNFlags = []cli.Flag{
@@ -333,6 +350,8 @@ var KCNFlags = []cli.Flag{
 	altsrc.NewBoolFlag(BaobabFlag),
 	altsrc.NewInt64Flag(BlockGenerationIntervalFlag),
 	altsrc.NewDurationFlag(BlockGenerationTimeLimitFlag),
+	altsrc.NewStringFlag(BlockGenerationTxOrderingFlag),
+	altsrc.NewIntFlag(BlockGenerationTxSenderCapFlag),
 }
 
 var KPNFlags = []cli.Flag{
@@ -379,6 +398,8 @@ var KSCNFlags = []cli.Flag{
 	altsrc.NewStringFlag(RewardbaseFlag),
 	altsrc.NewInt64Flag(BlockGenerationIntervalFlag),
 	altsrc.NewDurationFlag(BlockGenerationTimeLimitFlag),
+	altsrc.NewStringFlag(BlockGenerationTxOrderingFlag),
+	altsrc.NewIntFlag(BlockGenerationTxSenderCapFlag),
 	altsrc.NewStringFlag(ServiceChainSignerFlag),
 	altsrc.NewUint64Flag(AnchoringPeriodFlag),
 	altsrc.NewUint64Flag(SentChainTxsLimit),
@@ -505,6 +526,9 @@ var SnapshotFlags = []cli.Flag{
 	altsrc.NewBoolFlag(RocksDBDisableMetricsFlag),
 	altsrc.NewIntFlag(RocksDBMaxOpenFilesFlag),
 	altsrc.NewBoolFlag(RocksDBCacheIndexAndFilterFlag),
+	altsrc.NewUint64Flag(PebbleDBCacheSizeFlag),
+	altsrc.NewIntFlag(PebbleDBMaxOpenFilesFlag),
+	altsrc.NewBoolFlag(PebbleDBDisableMetricsFlag),
 }
 
 var DBMigrationSrcFlags = []cli.Flag{
@@ -529,6 +553,9 @@ var DBMigrationSrcFlags = []cli.Flag{
 	altsrc.NewBoolFlag(RocksDBDisableMetricsFlag),
 	altsrc.NewIntFlag(RocksDBMaxOpenFilesFlag),
 	altsrc.NewBoolFlag(RocksDBCacheIndexAndFilterFlag),
+	altsrc.NewUint64Flag(PebbleDBCacheSizeFlag),
+	altsrc.NewIntFlag(PebbleDBMaxOpenFilesFlag),
+	altsrc.NewBoolFlag(PebbleDBDisableMetricsFlag),
 }
 
 var DBMigrationDstFlags = []cli.Flag{
@@ -552,6 +579,9 @@ var DBMigrationDstFlags = []cli.Flag{
 	altsrc.NewStringFlag(DstRocksDBFilterPolicyFlag),
 	altsrc.NewIntFlag(DstRocksDBMaxOpenFilesFlag),
 	altsrc.NewBoolFlag(DstRocksDBCacheIndexAndFilterFlag),
+	altsrc.NewUint64Flag(DstPebbleDBCacheSizeFlag),
+	altsrc.NewIntFlag(DstPebbleDBMaxOpenFilesFlag),
+	altsrc.NewBoolFlag(DstPebbleDBDisableMetricsFlag),
 }
 
 var ChainDataFetcherFlags = []cli.Flag{
@@ -24,6 +24,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"reflect"
 
@@ -33,6 +34,7 @@ import (
 	"github.com/klaytn/klaytn/blockchain/system"
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/klaytn/klaytn/consensus"
 	"github.com/klaytn/klaytn/consensus/istanbul"
 	istanbulCore "github.com/klaytn/klaytn/consensus/istanbul/core"
@@ -559,6 +561,75 @@ func (api *API) GetTimeout() uint64 {
 	return istanbul.DefaultConfig.Timeout
 }
 
+// GetMisbehaviours retrieves the evidences of the validators which signed conflicting
+// consensus messages for the blocks in [from, to]. If from or to is omitted, the range
+// starts from the genesis block or includes the ongoing consensus respectively.
+func (api *API) GetMisbehaviours(from, to *rpc.BlockNumber) ([]map[string]interface{}, error) {
+	start, end := uint64(0), uint64(math.MaxUint64)
+	if from != nil {
+		n, err := api.blockNumberOf(*from)
+		if err != nil {
+			return nil, err
+		}
+		start = n
+	}
+	if to != nil {
+		n, err := api.blockNumberOf(*to)
+		if err != nil {
+			return nil, err
+		}
+		end = n
+	}
+	if start > end {
+		return nil, errStartLargerThanEnd
+	}
+
+	results := make([]map[string]interface{}, 0)
+	for _, m := range istanbulCore.ReadMisbehaviours(api.istanbul.db, start, end) {
+		messages := make([]hexutil.Bytes, len(m.Payloads))
+		for i, payload := range m.Payloads {
+			messages[i] = payload
+		}
+		results = append(results, map[string]interface{}{
+			"type":      m.Type(),
+			"validator": m.Validator,
+			"sequence":  m.Sequence,
+			"round":     m.Round,
+			"digests":   m.Digests,
+			"messages":  messages,
+		})
+	}
+	return results, nil
+}
+
+// GetConsensusTrace retrieves the consensus events of the given block: the consensus
+// messages received or sent by the node with their validation results, and the
+// round changes of the node with their causes. Only the traces of the latest
+// istanbulCore.ConsensusTraceBlocks blocks are kept.
+func (api *API) GetConsensusTrace(number rpc.BlockNumber) ([]*istanbulCore.TraceEvent, error) {
+	n, err := api.blockNumberOf(number)
+	if err != nil {
+		return nil, err
+	}
+	events := istanbulCore.ReadConsensusTrace(api.istanbul.db, n)
+	if events == nil {
+		events = make([]*istanbulCore.TraceEvent, 0)
+	}
+	return events, nil
+}
+
+// blockNumberOf returns the block number of the given rpc.BlockNumber.
+func (api *API) blockNumberOf(number rpc.BlockNumber) (uint64, error) {
+	switch number {
+	case rpc.LatestBlockNumber:
+		return api.chain.CurrentHeader().Number.Uint64(), nil
+	case rpc.PendingBlockNumber:
+		return 0, errPendingNotAllowed
+	default:
+		return uint64(number.Int64()), nil
+	}
+}
+
 // Retrieve the header at requested block number
 func headerByRpcNumber(chain consensus.ChainReader, number *rpc.BlockNumber) (*types.Header, error) {
 	var header *types.Header
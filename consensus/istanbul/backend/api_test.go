@@ -7,6 +7,7 @@ import (
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/consensus/istanbul"
 	istanbulCore "github.com/klaytn/klaytn/consensus/istanbul/core"
+	"github.com/klaytn/klaytn/networks/rpc"
 	"github.com/klaytn/klaytn/rlp"
 	"github.com/stretchr/testify/assert"
 )
@@ -48,3 +49,64 @@ func TestRecoverCommittedSeals(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, validators, expectedValidators)
 }
+
+func TestGetMisbehaviours(t *testing.T) {
+	b := newTestBackend()
+	api := &API{istanbul: b}
+
+	misbehaviours, err := api.GetMisbehaviours(nil, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, misbehaviours)
+
+	evidence := &istanbulCore.Misbehaviour{
+		Code:      1, // PREPARE
+		Validator: common.HexToAddress("0x1"),
+		Sequence:  3,
+		Round:     1,
+		Digests:   []common.Hash{common.HexToHash("0x2"), common.HexToHash("0x3")},
+		Payloads:  [][]byte{{0x4}, {0x5}},
+	}
+	blob, err := rlp.EncodeToBytes(evidence)
+	assert.Nil(t, err)
+	b.db.WriteIstanbulMisbehaviour(evidence.Sequence, common.HexToHash("0x6"), blob)
+
+	misbehaviours, err = api.GetMisbehaviours(nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(misbehaviours))
+	assert.Equal(t, "prepare", misbehaviours[0]["type"])
+	assert.Equal(t, evidence.Validator, misbehaviours[0]["validator"])
+	assert.Equal(t, evidence.Digests, misbehaviours[0]["digests"])
+
+	from, to := rpc.BlockNumber(4), rpc.BlockNumber(10)
+	misbehaviours, err = api.GetMisbehaviours(&from, &to)
+	assert.Nil(t, err)
+	assert.Empty(t, misbehaviours)
+
+	_, err = api.GetMisbehaviours(&to, &from)
+	assert.Equal(t, errStartLargerThanEnd, err)
+}
+
+func TestGetConsensusTrace(t *testing.T) {
+	b := newTestBackend()
+	api := &API{istanbul: b}
+
+	events, err := api.GetConsensusTrace(rpc.BlockNumber(3))
+	assert.Nil(t, err)
+	assert.Empty(t, events)
+
+	event := &istanbulCore.TraceEvent{
+		Type:   istanbulCore.TraceLocalRoundChange,
+		Sender: common.HexToAddress("0x1"),
+		Round:  1,
+		Time:   2,
+		Result: "timeout",
+	}
+	istanbulCore.WriteConsensusTrace(b.db, 3, []*istanbulCore.TraceEvent{event})
+
+	events, err = api.GetConsensusTrace(rpc.BlockNumber(3))
+	assert.Nil(t, err)
+	assert.Equal(t, []*istanbulCore.TraceEvent{event}, events)
+
+	_, err = api.GetConsensusTrace(rpc.PendingBlockNumber)
+	assert.Equal(t, errPendingNotAllowed, err)
+}
@@ -91,7 +91,7 @@ func New(opts *BackendOpts) consensus.Istanbul {
 	}
 
 	backend.currentView.Store(&istanbul.View{Sequence: big.NewInt(0), Round: big.NewInt(0)})
-	backend.core = istanbulCore.New(backend, backend.config)
+	backend.core = istanbulCore.New(backend, backend.config, opts.DB)
 	return backend
 }
 
@@ -111,7 +111,7 @@ func (c *core) handleCommit(msg *message, src istanbul.Validator) error {
 			c.sendCommit()
 		} else if c.current.GetPrepareOrCommitSize() >= RequiredMessageCount(c.valSet) {
 			logger.Info("received a quorum of the messages and change state to prepared", "msgType", msgCommit, "valSet", c.valSet.Size())
-			c.current.LockHash()
+			c.lockHash()
 			c.setState(StatePrepared)
 			c.sendCommit()
 		}
@@ -124,7 +124,7 @@ func (c *core) handleCommit(msg *message, src istanbul.Validator) error {
 	//logger.Error("### consensus check","len(commits)",c.current.Commits.Size(),"f(2/3)",2*c.valSet.F(),"state",c.state.Cmp(StateCommitted))
 	if c.state.Cmp(StateCommitted) < 0 && c.current.Commits.Size() >= RequiredMessageCount(c.valSet) {
 		// Still need to call LockHash here since state can skip Prepared state and jump directly to the Committed state.
-		c.current.LockHash()
+		c.lockHash()
 		c.commit()
 	}
 
@@ -34,13 +34,16 @@ import (
 	"github.com/klaytn/klaytn/consensus/istanbul"
 	"github.com/klaytn/klaytn/event"
 	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/storage/database"
 	"github.com/rcrowley/go-metrics"
 )
 
 var logger = log.NewModuleLogger(log.ConsensusIstanbulCore)
 
-// New creates an Istanbul consensus core
-func New(backend istanbul.Backend, config *istanbul.Config) Engine {
+// New creates an Istanbul consensus core. The write-ahead log of the signed
+// messages, the evidences of the misbehaving validators and the consensus traces
+// are stored in db. If db is nil, the write-ahead log is kept in memory only.
+func New(backend istanbul.Backend, config *istanbul.Config, db database.DBManager) Engine {
 	c := &core{
 		config:             config,
 		address:            backend.Address(),
@@ -53,6 +56,9 @@ func New(backend istanbul.Backend, config *istanbul.Config) Engine {
 		pendingRequests:    prque.New(),
 		pendingRequestsMu:  new(sync.Mutex),
 		consensusTimestamp: time.Time{},
+		wal:                newWAL(db),
+		misbehaviours:      newMisbehaviourCollector(db),
+		tracer:             newConsensusTracer(db),
 
 		roundMeter:         metrics.NewRegisteredMeter("consensus/istanbul/core/round", nil),
 		currentRoundGauge:  metrics.NewRegisteredGauge("consensus/istanbul/core/currentRound", nil),
@@ -95,6 +101,13 @@ type core struct {
 	pendingRequests   *prque.Prque
 	pendingRequestsMu *sync.Mutex
 
+	// the write-ahead log of the signed messages and the locked proposal
+	wal *wal
+	// the collector of the evidences of the validators signing conflicting messages
+	misbehaviours *misbehaviourCollector
+	// the tracer of the consensus events of the latest blocks
+	tracer *consensusTracer
+
 	consensusTimestamp time.Time
 	// the meter to record the round change rate
 	roundMeter metrics.Meter
@@ -149,6 +162,13 @@ func (c *core) finalizeMessage(msg *message) ([]byte, error) {
 func (c *core) broadcast(msg *message) {
 	logger := c.logger.NewWith("state", c.state)
 
+	// Record the message before signing it, so that a conflicting message is
+	// never signed for the same view even after a restart.
+	if err := c.recordMessage(msg); err != nil {
+		logger.Error("Refused to sign the message", "msg", msg, "err", err)
+		return
+	}
+
 	payload, err := c.finalizeMessage(msg)
 	if err != nil {
 		logger.Error("Failed to finalize message", "msg", msg, "err", err)
@@ -158,8 +178,10 @@ func (c *core) broadcast(msg *message) {
 	// Broadcast payload
 	if err = c.backend.Broadcast(msg.Hash, c.valSet, payload); err != nil {
 		logger.Error("Failed to broadcast message", "msg", msg, "err", err)
+		c.traceMessage(msg, err.Error())
 		return
 	}
+	c.traceMessage(msg, traceSent)
 }
 
 func (c *core) currentView() *istanbul.View {
@@ -189,7 +211,7 @@ func (c *core) commit() {
 		}
 
 		if err := c.backend.Commit(proposal, committedSeals); err != nil {
-			c.current.UnlockHash() // Unlock block when insertion fails
+			c.unlockHash() // Unlock block when insertion fails
 			c.sendNextRoundChange("commit failure")
 			return
 		}
@@ -200,7 +222,7 @@ func (c *core) commit() {
 	} else {
 		// TODO-Kaia never happen, but if proposal is nil, mining is not working.
 		logger.Error("istanbul.core current.Proposal is NULL")
-		c.current.UnlockHash() // Unlock block when insertion fails
+		c.unlockHash() // Unlock block when insertion fails
 		c.sendNextRoundChange("commit failure. proposal is nil")
 		return
 	}
@@ -259,6 +281,9 @@ func (c *core) startNewRound(round *big.Int) {
 			Round:    new(big.Int),
 		}
 		c.valSet = c.backend.Validators(lastProposal)
+		c.wal.prune(newView.Sequence)
+		c.misbehaviours.prune(newView.Sequence)
+		c.tracer.flush(newView.Sequence)
 
 		councilSize := int64(c.valSet.Size())
 		committeeSize := int64(c.valSet.SubGroupSize())
@@ -323,6 +348,14 @@ func (c *core) updateRoundState(view *istanbul.View, validatorSet istanbul.Valid
 		} else {
 			c.current = newRoundState(view, validatorSet, common.Hash{}, nil, c.current.pendingRequest, c.backend.HasBadProposal)
 		}
+	} else if c.current == nil {
+		// Restore the proposal locked before the restart
+		if preprepare := c.wal.lockedPreprepare(view.Sequence); preprepare != nil {
+			logger.Warn("Restored the locked proposal from the write-ahead log", "seq", view.Sequence, "hash", preprepare.Proposal.Hash())
+			c.current = newRoundState(view, validatorSet, preprepare.Proposal.Hash(), preprepare, nil, c.backend.HasBadProposal)
+		} else {
+			c.current = newRoundState(view, validatorSet, common.Hash{}, nil, nil, c.backend.HasBadProposal)
+		}
 	} else {
 		c.current = newRoundState(view, validatorSet, common.Hash{}, nil, nil, c.backend.HasBadProposal)
 	}
@@ -41,4 +41,7 @@ var (
 	errFailedDecodeMessageSet = errors.New("failed to decode message set")
 	// errInvalidSigner is returned when the message is signed by a validator different than message sender
 	errInvalidSigner = errors.New("message not signed by the sender")
+	// errConflictingMessage is returned when a message conflicts with the one
+	// signed for the same view before.
+	errConflictingMessage = errors.New("conflicting message is already signed")
 )
@@ -180,13 +180,22 @@ func (c *core) handleMsg(payload []byte) error {
 		logger.Error("Invalid address in message", "msg", msg)
 		return istanbul.ErrUnauthorizedAddress
 	}
+	c.checkMisbehaviour(msg, payload)
 
 	return c.handleCheckedMsg(msg, src)
 }
 
-func (c *core) handleCheckedMsg(msg *message, src istanbul.Validator) error {
+func (c *core) handleCheckedMsg(msg *message, src istanbul.Validator) (err error) {
 	logger := c.logger.NewWith("address", c.address, "from", src)
 
+	// Trace the result of the message. The future messages are traced when they
+	// are handled again from the backlog.
+	defer func() {
+		if err != errFutureMessage {
+			c.traceMessage(msg, traceResult(err))
+		}
+	}()
+
 	// Store the message if it's a future message
 	testBacklog := func(err error) error {
 		if err == errFutureMessage {
@@ -239,6 +248,7 @@ func (c *core) handleTimeoutMsg(nextView *istanbul.View) {
 		maxRound := c.roundChangeSet.MaxRound(c.valSet.F() + 1)
 		if maxRound != nil && maxRound.Cmp(c.current.Round()) > 0 {
 			logger.Warn("[RC] Send round change because of timeout event")
+			c.traceRoundChange(maxRound, "timeout, catching up the round of f+1 round change messages")
 			c.sendRoundChange(maxRound)
 			return
 		}
@@ -248,6 +258,7 @@ func (c *core) handleTimeoutMsg(nextView *istanbul.View) {
 		c.logger.Trace("round change timeout, catch up latest sequence", "number", lastProposal.Number().Uint64())
 		c.startNewRound(common.Big0)
 	} else {
+		c.traceRoundChange(nextView.Round, "timeout")
 		c.sendRoundChange(nextView.Round)
 	}
 }
@@ -249,7 +249,7 @@ func TestCore_handleEvents_scenario_invalidSender(t *testing.T) {
 	istConfig.ProposerPolicy = istanbul.WeightedRandom
 
 	// When the istanbul core started, a message handling loop in `handleEvents()` waits istanbul messages
-	istCore := New(mockBackend, istConfig).(*core)
+	istCore := New(mockBackend, istConfig, nil).(*core)
 	if err := istCore.Start(); err != nil {
 		t.Fatal(err)
 	}
@@ -427,7 +427,7 @@ func TestCore_handlerMsg(t *testing.T) {
 	istConfig := istanbul.DefaultConfig
 	istConfig.ProposerPolicy = istanbul.WeightedRandom
 
-	istCore := New(mockBackend, istConfig).(*core)
+	istCore := New(mockBackend, istConfig, nil).(*core)
 	if err := istCore.Start(); err != nil {
 		t.Fatal(err)
 	}
@@ -556,7 +556,7 @@ func simulateMaliciousCN(t *testing.T, numValidators int, numMalicious int) Stat
 	// Start istanbul core
 	istConfig := istanbul.DefaultConfig
 	istConfig.ProposerPolicy = istanbul.WeightedRandom
-	istCore := New(mockBackend, istConfig).(*core)
+	istCore := New(mockBackend, istConfig, nil).(*core)
 	err := istCore.Start()
 	require.Nil(t, err)
 	defer istCore.Stop()
@@ -645,9 +645,9 @@ func simulateChainSplit(t *testing.T, numValidators int) (State, State) {
 	// Start istanbul core
 	istConfig := istanbul.DefaultConfig
 	istConfig.ProposerPolicy = istanbul.WeightedRandom
-	coreProposer := New(mockBackend, istConfig).(*core)
-	coreA := New(mockBackend, istConfig).(*core)
-	coreB := New(mockBackend, istConfig).(*core)
+	coreProposer := New(mockBackend, istConfig, nil).(*core)
+	coreA := New(mockBackend, istConfig, nil).(*core)
+	coreB := New(mockBackend, istConfig, nil).(*core)
 	require.Nil(t,
 		coreProposer.Start(),
 		coreA.Start(),
@@ -770,7 +770,7 @@ func TestCore_handleTimeoutMsg_race(t *testing.T) {
 	istConfig := istanbul.DefaultConfig
 	istConfig.ProposerPolicy = istanbul.WeightedRandom
 
-	istCore := New(mockBackend, istConfig).(*core)
+	istCore := New(mockBackend, istConfig, nil).(*core)
 	if err := istCore.Start(); err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,233 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/rcrowley/go-metrics"
+)
+
+var (
+	misbehaviourPreprepareCounter = metrics.NewRegisteredCounter("consensus/istanbul/core/misbehaviour/preprepare", nil)
+	misbehaviourPrepareCounter    = metrics.NewRegisteredCounter("consensus/istanbul/core/misbehaviour/prepare", nil)
+	misbehaviourCommitCounter     = metrics.NewRegisteredCounter("consensus/istanbul/core/misbehaviour/commit", nil)
+)
+
+// Misbehaviour is the evidence that a validator signed two conflicting messages
+// for the same view: two different proposals as a proposer, or two PREPARE or
+// COMMIT messages of different proposals.
+type Misbehaviour struct {
+	Code      uint64
+	Validator common.Address
+	Sequence  uint64
+	Round     uint64
+	Digests   []common.Hash // Digests of the proposals the conflicting messages refer to
+	Payloads  [][]byte      // Conflicting messages with the signatures of the validator
+}
+
+// Type returns the name of the type of the conflicting messages.
+func (m *Misbehaviour) Type() string {
+	return msgTypeName(m.Code)
+}
+
+// ReadMisbehaviours returns the misbehaviours detected in the sequences [from, to].
+func ReadMisbehaviours(db database.DBManager, from, to uint64) []*Misbehaviour {
+	var misbehaviours []*Misbehaviour
+	for _, blob := range db.ReadIstanbulMisbehaviours(from, to) {
+		m := new(Misbehaviour)
+		if err := rlp.DecodeBytes(blob, m); err != nil {
+			logger.Error("Failed to decode the istanbul misbehaviour", "err", err)
+			continue
+		}
+		misbehaviours = append(misbehaviours, m)
+	}
+	return misbehaviours
+}
+
+// signedKey identifies the messages of a validator which must have the same digest.
+type signedKey struct {
+	code      uint64
+	validator common.Address
+	sequence  uint64
+	round     uint64
+}
+
+// less reports whether the view of the key is older than the one of the other key.
+func (key signedKey) less(other signedKey) bool {
+	if key.sequence != other.sequence {
+		return key.sequence < other.sequence
+	}
+	return key.round < other.round
+}
+
+// signedMessage is the first message seen for a signedKey.
+type signedMessage struct {
+	digest   common.Hash
+	payload  []byte
+	reported bool
+}
+
+// maxSignedMessages is the maximum number of the messages tracked for a validator.
+// It covers the three types of the messages of 10 rounds of the current and the
+// next sequence, so that a validator can't grow the collector by signing the
+// messages of arbitrary rounds.
+const maxSignedMessages = 3 * 10 * 2
+
+// misbehaviourCollector detects the validators signing conflicting messages and
+// stores the evidences in db. Only the messages of the current and the next
+// sequence are tracked, up to maxSignedMessages for a validator. When the limit
+// is reached, the message of the newest view is evicted for an older one, so that
+// a validator can't hide its conflicting messages of the early rounds by signing
+// the messages of many later rounds. If db is nil, the evidences are not stored.
+type misbehaviourCollector struct {
+	db       database.DBManager
+	messages map[signedKey]*signedMessage
+	keys     map[common.Address][]signedKey // Keys of the tracked messages of each validator
+	mu       sync.Mutex
+}
+
+func newMisbehaviourCollector(db database.DBManager) *misbehaviourCollector {
+	return &misbehaviourCollector{
+		db:       db,
+		messages: make(map[signedKey]*signedMessage),
+		keys:     make(map[common.Address][]signedKey),
+	}
+}
+
+// collect checks whether the given message conflicts with the one seen before,
+// and returns the evidence if so. The evidence of a signedKey is returned once.
+func (mc *misbehaviourCollector) collect(msg *message, payload []byte, current *big.Int) *Misbehaviour {
+	if msg.Code != msgPreprepare && msg.Code != msgPrepare && msg.Code != msgCommit {
+		return nil
+	}
+	view, digest, err := msg.viewAndDigest()
+	if err != nil || !view.Sequence.IsUint64() || !view.Round.IsUint64() {
+		return nil
+	}
+	if view.Sequence.Cmp(current) < 0 || view.Sequence.Cmp(new(big.Int).Add(current, common.Big1)) > 0 {
+		return nil
+	}
+	key := signedKey{
+		code:      msg.Code,
+		validator: msg.Address,
+		sequence:  view.Sequence.Uint64(),
+		round:     view.Round.Uint64(),
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	seen, ok := mc.messages[key]
+	if !ok {
+		mc.track(key, &signedMessage{digest: digest, payload: common.CopyBytes(payload)})
+		return nil
+	}
+	if seen.digest == digest || seen.reported {
+		return nil
+	}
+	seen.reported = true
+
+	m := &Misbehaviour{
+		Code:      key.code,
+		Validator: key.validator,
+		Sequence:  key.sequence,
+		Round:     key.round,
+		Digests:   []common.Hash{seen.digest, digest},
+		Payloads:  [][]byte{seen.payload, common.CopyBytes(payload)},
+	}
+	if mc.db != nil {
+		blob, err := rlp.EncodeToBytes(m)
+		if err != nil {
+			logger.Error("Failed to encode the istanbul misbehaviour", "err", err)
+			return m
+		}
+		mc.db.WriteIstanbulMisbehaviour(m.Sequence, crypto.Keccak256Hash(blob), blob)
+	}
+	return m
+}
+
+// track starts tracking the message of the given key. If the validator reached
+// the limit, the message of its newest view is evicted if it is newer than the
+// given one, otherwise the given message is not tracked.
+//
+// Note, this method assumes the lock is held!
+func (mc *misbehaviourCollector) track(key signedKey, msg *signedMessage) {
+	keys := mc.keys[key.validator]
+	if len(keys) >= maxSignedMessages {
+		newest := 0
+		for i := range keys {
+			if keys[newest].less(keys[i]) {
+				newest = i
+			}
+		}
+		if !key.less(keys[newest]) {
+			return
+		}
+		delete(mc.messages, keys[newest])
+		keys[newest] = keys[len(keys)-1]
+		keys = keys[:len(keys)-1]
+	}
+	mc.messages[key] = msg
+	mc.keys[key.validator] = append(keys, key)
+}
+
+// prune removes the messages of the sequences older than the given sequence.
+func (mc *misbehaviourCollector) prune(sequence *big.Int) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	for validator, keys := range mc.keys {
+		kept := keys[:0]
+		for _, key := range keys {
+			if new(big.Int).SetUint64(key.sequence).Cmp(sequence) < 0 {
+				delete(mc.messages, key)
+				continue
+			}
+			kept = append(kept, key)
+		}
+		if len(kept) == 0 {
+			delete(mc.keys, validator)
+		} else {
+			mc.keys[validator] = kept
+		}
+	}
+}
+
+// checkMisbehaviour collects the evidence if the sender of the given message
+// signed a conflicting message before, and raises an alert.
+func (c *core) checkMisbehaviour(msg *message, payload []byte) {
+	m := c.misbehaviours.collect(msg, payload, c.current.Sequence())
+	if m == nil {
+		return
+	}
+	switch m.Code {
+	case msgPreprepare:
+		misbehaviourPreprepareCounter.Inc(1)
+	case msgPrepare:
+		misbehaviourPrepareCounter.Inc(1)
+	case msgCommit:
+		misbehaviourCommitCounter.Inc(1)
+	}
+	c.logger.Error("[Misbehaviour] A validator signed conflicting messages", "validator", m.Validator, "type", m.Type(),
+		"sequence", m.Sequence, "round", m.Round, "digests", m.Digests)
+}
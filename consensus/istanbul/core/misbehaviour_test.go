@@ -0,0 +1,138 @@
+package core
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCore_misbehaviour posts conflicting messages signed by a validator, and checks
+// that the evidences are stored once for each view and message type.
+func TestCore_misbehaviour(t *testing.T) {
+	fork.SetHardForkBlockNumberConfig(&params.ChainConfig{})
+	defer fork.ClearHardForkBlockNumberConfig()
+
+	validatorAddrs, validatorKeyMap := genValidators(6)
+	mockBackend, mockCtrl := newMockBackend(t, validatorAddrs)
+	defer mockCtrl.Finish()
+
+	istConfig := istanbul.DefaultConfig
+	istConfig.ProposerPolicy = istanbul.WeightedRandom
+
+	db := database.NewMemoryDBManager()
+	istCore := New(mockBackend, istConfig, db).(*core)
+	require.NoError(t, istCore.Start())
+	defer istCore.Stop()
+
+	eventMux := mockBackend.EventMux()
+	lastProposal, _ := mockBackend.LastProposal()
+	lastBlock := lastProposal.(*types.Block)
+	proposer := mockBackend.Validators(lastBlock).GetProposer().Address()
+	validator := validatorAddrs[1]
+
+	var proposals []*types.Block
+	for i := 0; i < 3; i++ {
+		proposal, err := genBlockParams(lastBlock, validatorKeyMap[proposer], uint64(i), 1, 1)
+		require.NoError(t, err)
+		proposals = append(proposals, proposal)
+	}
+
+	post := func(code uint64, proposal *types.Block, signer common.Address) []byte {
+		msg, err := genIstanbulMsg(code, lastBlock.Hash(), proposal, signer, validatorKeyMap[signer])
+		require.NoError(t, err)
+		require.NoError(t, eventMux.Post(msg))
+		return msg.Payload
+	}
+
+	// The same message is not a misbehaviour
+	post(msgPrepare, proposals[0], validator)
+	post(msgPrepare, proposals[0], validator)
+	time.Sleep(500 * time.Millisecond)
+	assert.Empty(t, ReadMisbehaviours(db, 0, math.MaxUint64))
+
+	// Conflicting PREPARE messages and proposals
+	first := post(msgPreprepare, proposals[0], proposer)
+	second := post(msgPreprepare, proposals[1], proposer)
+	post(msgPrepare, proposals[1], validator)
+	post(msgPrepare, proposals[2], validator)
+	time.Sleep(500 * time.Millisecond)
+
+	misbehaviours := ReadMisbehaviours(db, 0, math.MaxUint64)
+	require.Len(t, misbehaviours, 2)
+
+	byType := map[string]*Misbehaviour{}
+	for _, m := range misbehaviours {
+		byType[m.Type()] = m
+		assert.Equal(t, uint64(1), m.Sequence)
+		assert.Equal(t, uint64(0), m.Round)
+	}
+	require.Contains(t, byType, "preprepare")
+	require.Contains(t, byType, "prepare")
+
+	preprepare := byType["preprepare"]
+	assert.Equal(t, proposer, preprepare.Validator)
+	assert.Equal(t, []common.Hash{proposals[0].Hash(), proposals[1].Hash()}, preprepare.Digests)
+	assert.Equal(t, [][]byte{first, second}, preprepare.Payloads)
+
+	prepare := byType["prepare"]
+	assert.Equal(t, validator, prepare.Validator)
+	assert.Equal(t, []common.Hash{proposals[0].Hash(), proposals[1].Hash()}, prepare.Digests)
+
+	// The evidences of the other sequences are not returned
+	assert.Empty(t, ReadMisbehaviours(db, 2, math.MaxUint64))
+}
+
+// TestMisbehaviourCollector_limit tests that the messages tracked for a validator
+// are limited, and that the limit is released by pruning.
+func TestMisbehaviourCollector_limit(t *testing.T) {
+	validator := common.HexToAddress("0x1")
+	prepare := func(sequence, round int64, digest common.Hash) *message {
+		subject, err := Encode(&istanbul.Subject{
+			View:   &istanbul.View{Sequence: big.NewInt(sequence), Round: big.NewInt(round)},
+			Digest: digest,
+		})
+		require.NoError(t, err)
+		return &message{Code: msgPrepare, Msg: subject, Address: validator}
+	}
+	current := big.NewInt(1)
+	mc := newMisbehaviourCollector(nil)
+
+	// The messages of the rounds beyond the limit are not tracked.
+	for round := int64(0); round < 2*maxSignedMessages; round++ {
+		assert.Nil(t, mc.collect(prepare(1, round, common.Hash{1}), nil, current))
+	}
+	assert.Len(t, mc.messages, maxSignedMessages)
+	assert.Nil(t, mc.collect(prepare(1, maxSignedMessages, common.Hash{2}), nil, current))
+	assert.NotNil(t, mc.collect(prepare(1, 0, common.Hash{2}), nil, current))
+
+	// The messages of the later rounds are evicted for the ones of the earlier rounds,
+	// so flooding the limit doesn't hide a conflict of an early round.
+	mc = newMisbehaviourCollector(nil)
+	for round := int64(100); round < 100+maxSignedMessages; round++ {
+		assert.Nil(t, mc.collect(prepare(1, round, common.Hash{1}), nil, current))
+	}
+	assert.Nil(t, mc.collect(prepare(2, 0, common.Hash{1}), nil, current))
+	assert.Len(t, mc.messages, maxSignedMessages)
+	assert.Nil(t, mc.collect(prepare(1, 1, common.Hash{1}), nil, current))
+	assert.Len(t, mc.messages, maxSignedMessages)
+	assert.NotNil(t, mc.collect(prepare(1, 1, common.Hash{2}), nil, current))
+	assert.Nil(t, mc.collect(prepare(1, 99+maxSignedMessages, common.Hash{2}), nil, current))
+
+	// Pruning the sequence releases the limit.
+	mc.prune(big.NewInt(2))
+	assert.Empty(t, mc.messages)
+	assert.Empty(t, mc.keys)
+	current = big.NewInt(2)
+	assert.Nil(t, mc.collect(prepare(2, maxSignedMessages, common.Hash{1}), nil, current))
+	assert.NotNil(t, mc.collect(prepare(2, maxSignedMessages, common.Hash{2}), nil, current))
+}
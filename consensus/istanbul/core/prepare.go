@@ -87,7 +87,7 @@ func (c *core) handlePrepare(msg *message, src istanbul.Validator) error {
 			c.sendCommit()
 		} else if c.current.GetPrepareOrCommitSize() >= RequiredMessageCount(c.valSet) {
 			logger.Info("received a quorum of the messages and change state to prepared", "msgType", msgPrepare, "prepareMsgNum", c.current.Prepares.Size(), "commitMsgNum", c.current.Commits.Size(), "valSet", c.valSet.Size())
-			c.current.LockHash()
+			c.lockHash()
 			c.setState(StatePrepared)
 			c.sendCommit()
 		}
@@ -34,7 +34,9 @@ func (c *core) sendNextRoundChange(loc string) {
 		return
 	}
 	logger.Warn("[RC] sendNextRoundChange happened", "where", loc)
-	c.sendRoundChange(new(big.Int).Add(c.currentView().Round, common.Big1))
+	round := new(big.Int).Add(c.currentView().Round, common.Big1)
+	c.traceRoundChange(round, loc)
+	c.sendRoundChange(round)
 }
 
 // sendRoundChange sends the ROUND CHANGE message with the given round
@@ -128,6 +130,7 @@ func (c *core) handleRoundChange(msg *message, src istanbul.Validator) error {
 			"len(commits)", c.current.Commits.Size(), "messages", c.current.Commits.GetMessages())
 		logger.Warn("[RC] Received 2f+1 Round Change Messages. Starting new round",
 			"currentRound", cv.Round.String(), "newRound", roundView.Round.String())
+		c.traceRoundChange(roundView.Round, "received 2f+1 round change messages")
 		c.startNewRound(roundView.Round)
 		return nil
 	} else if c.waitingForRoundChange && num == numCatchUp {
@@ -137,6 +140,7 @@ func (c *core) handleRoundChange(msg *message, src istanbul.Validator) error {
 		if cv.Round.Cmp(roundView.Round) < 0 {
 			logger.Warn("[RC] Send round change because we have f+1 round change messages",
 				"currentRound", cv.Round.String(), "newRound", roundView.Round.String())
+			c.traceRoundChange(roundView.Round, "received f+1 round change messages")
 			c.sendRoundChange(roundView.Round)
 		}
 		return nil
@@ -0,0 +1,289 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+const (
+	// ConsensusTraceBlocks is the number of the latest blocks whose consensus traces are kept.
+	ConsensusTraceBlocks = 1024
+
+	// maxTraceEvents is the maximum number of the events traced for a block.
+	maxTraceEvents = 4096
+
+	// TraceLocalRoundChange is the type of the events recording why the node moved to a new round.
+	TraceLocalRoundChange = "localRoundChange"
+
+	traceAccepted = "accepted"
+	traceSent     = "sent"
+)
+
+// TraceEvent is an event of the consensus on a block. It is either a consensus
+// message received or sent by the node, or a round change of the node.
+type TraceEvent struct {
+	Type   string         `json:"type"`   // Type of the message, or TraceLocalRoundChange
+	Sender common.Address `json:"sender"` // Sender of the message, or the node itself
+	Round  uint64         `json:"round"`  // Round of the message, or the new round of the node
+	Time   uint64         `json:"time"`   // Unix time in nanoseconds when the event happened
+	Result string         `json:"result"` // Result of the validation of the message, or the cause of the round change
+}
+
+// msgTypeName returns the name of the given message code.
+func msgTypeName(code uint64) string {
+	switch code {
+	case msgPreprepare:
+		return "preprepare"
+	case msgPrepare:
+		return "prepare"
+	case msgCommit:
+		return "commit"
+	case msgRoundChange:
+		return "roundChange"
+	default:
+		return "unknown"
+	}
+}
+
+// consensusTrace is the stored form of the consensus events of a block.
+type consensusTrace struct {
+	Number uint64
+	Events []*TraceEvent
+}
+
+// consensusTraceSlot returns the slot of the given block in the database. The
+// traces are kept in ConsensusTraceBlocks slots, so the trace of a block replaces
+// the one of the block ConsensusTraceBlocks before it.
+func consensusTraceSlot(number uint64) uint64 {
+	return number % ConsensusTraceBlocks
+}
+
+// ReadConsensusTrace returns the consensus events of the given block.
+func ReadConsensusTrace(db database.DBManager, number uint64) []*TraceEvent {
+	blob := db.ReadIstanbulTrace(consensusTraceSlot(number))
+	if len(blob) == 0 {
+		return nil
+	}
+	var trace consensusTrace
+	if err := rlp.DecodeBytes(blob, &trace); err != nil {
+		logger.Error("Failed to decode the consensus trace", "number", number, "err", err)
+		return nil
+	}
+	// The slot is taken by another block
+	if trace.Number != number {
+		return nil
+	}
+	return trace.Events
+}
+
+// WriteConsensusTrace stores the consensus events of the given block.
+func WriteConsensusTrace(db database.DBManager, number uint64, events []*TraceEvent) {
+	blob, err := rlp.EncodeToBytes(&consensusTrace{Number: number, Events: events})
+	if err != nil {
+		logger.Error("Failed to encode the consensus trace", "number", number, "err", err)
+		return
+	}
+	db.WriteIstanbulTrace(consensusTraceSlot(number), blob)
+}
+
+// consensusTracer buffers the consensus events of the current and the next
+// sequences, and stores them in db once the sequences are finished. At most
+// ConsensusTraceBlocks traces are kept in db, since each block takes the slot of
+// its number modulo ConsensusTraceBlocks. If db is nil, the events are discarded.
+type consensusTracer struct {
+	db     database.DBManager
+	events map[uint64][]*TraceEvent
+	mu     sync.Mutex
+}
+
+func newConsensusTracer(db database.DBManager) *consensusTracer {
+	return &consensusTracer{
+		db:     db,
+		events: make(map[uint64][]*TraceEvent),
+	}
+}
+
+// add buffers the event of the given sequence.
+func (t *consensusTracer) add(sequence, current *big.Int, ev *TraceEvent) {
+	if t.db == nil || current == nil || sequence == nil || !sequence.IsUint64() {
+		return
+	}
+	if sequence.Cmp(current) < 0 || sequence.Cmp(new(big.Int).Add(current, common.Big1)) > 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	number := sequence.Uint64()
+	if len(t.events[number]) < maxTraceEvents {
+		t.events[number] = append(t.events[number], ev)
+	}
+}
+
+// flush stores the events of the sequences older than the given sequence.
+func (t *consensusTracer) flush(sequence *big.Int) {
+	if t.db == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for number, events := range t.events {
+		if new(big.Int).SetUint64(number).Cmp(sequence) >= 0 {
+			continue
+		}
+		delete(t.events, number)
+
+		// Keep the events traced before the node restarted
+		events = append(ReadConsensusTrace(t.db, number), events...)
+		if len(events) > maxTraceEvents {
+			events = events[:maxTraceEvents]
+		}
+		WriteConsensusTrace(t.db, number, events)
+	}
+}
+
+// traceMessage traces the consensus message handled or sent by the node.
+func (c *core) traceMessage(msg *message, result string) {
+	if c.current == nil {
+		return
+	}
+	view, _, err := msg.viewAndDigest()
+	if err != nil || !view.Round.IsUint64() {
+		return
+	}
+	c.tracer.add(view.Sequence, c.current.Sequence(), &TraceEvent{
+		Type:   msgTypeName(msg.Code),
+		Sender: msg.Address,
+		Round:  view.Round.Uint64(),
+		Time:   uint64(time.Now().UnixNano()),
+		Result: result,
+	})
+}
+
+// traceResult returns the result of the validation of a message.
+func traceResult(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return traceAccepted
+}
+
+// traceRoundChange traces that the node moves to the given round with the cause.
+func (c *core) traceRoundChange(round *big.Int, cause string) {
+	if c.current == nil || !round.IsUint64() {
+		return
+	}
+	c.tracer.add(c.current.Sequence(), c.current.Sequence(), &TraceEvent{
+		Type:   TraceLocalRoundChange,
+		Sender: c.Address(),
+		Round:  round.Uint64(),
+		Time:   uint64(time.Now().UnixNano()),
+		Result: cause,
+	})
+}
+
+// RenderConsensusTrace writes the timeline of the consensus events of the given
+// block, followed by the summary of the rounds explaining why each round change
+// happened.
+func RenderConsensusTrace(w io.Writer, number uint64, events []*TraceEvent) {
+	if len(events) == 0 {
+		fmt.Fprintf(w, "No consensus trace of block %d\n", number)
+		return
+	}
+	events = append([]*TraceEvent(nil), events...)
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time < events[j].Time })
+
+	start := time.Unix(0, int64(events[0].Time))
+	fmt.Fprintf(w, "Consensus trace of block %d, started at %s\n\n", number, start.UTC().Format(time.RFC3339Nano))
+	for _, ev := range events {
+		elapsed := time.Unix(0, int64(ev.Time)).Sub(start)
+		fmt.Fprintf(w, "%12s  round %-3d %-16s %s  %s\n", elapsed, ev.Round, ev.Type, ev.Sender.Hex(), ev.Result)
+	}
+
+	// Summarize the rounds
+	type roundSummary struct {
+		preprepare   bool
+		prepares     map[common.Address]struct{}
+		commits      map[common.Address]struct{}
+		roundChanges map[common.Address]struct{}
+		causes       []string
+	}
+	rounds := make(map[uint64]*roundSummary)
+	get := func(round uint64) *roundSummary {
+		if rounds[round] == nil {
+			rounds[round] = &roundSummary{
+				prepares:     make(map[common.Address]struct{}),
+				commits:      make(map[common.Address]struct{}),
+				roundChanges: make(map[common.Address]struct{}),
+			}
+		}
+		return rounds[round]
+	}
+	for _, ev := range events {
+		s := get(ev.Round)
+		if ev.Type == TraceLocalRoundChange {
+			s.causes = append(s.causes, ev.Result)
+			continue
+		}
+		if ev.Result != traceAccepted && ev.Result != traceSent {
+			continue
+		}
+		switch ev.Type {
+		case msgTypeName(msgPreprepare):
+			s.preprepare = true
+		case msgTypeName(msgPrepare):
+			s.prepares[ev.Sender] = struct{}{}
+		case msgTypeName(msgCommit):
+			s.commits[ev.Sender] = struct{}{}
+		case msgTypeName(msgRoundChange):
+			s.roundChanges[ev.Sender] = struct{}{}
+		}
+	}
+
+	numbers := make([]uint64, 0, len(rounds))
+	for round := range rounds {
+		numbers = append(numbers, round)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	fmt.Fprintf(w, "\nSummary\n")
+	for _, round := range numbers {
+		s := rounds[round]
+		fmt.Fprintf(w, "  round %d: preprepare %t, prepares %d, commits %d\n", round, s.preprepare, len(s.prepares), len(s.commits))
+		if round == 0 {
+			continue
+		}
+		for _, cause := range s.causes {
+			fmt.Fprintf(w, "    entered the round: %s\n", cause)
+		}
+		if len(s.causes) == 0 {
+			fmt.Fprintf(w, "    entered the round: not traced\n")
+		}
+		fmt.Fprintf(w, "    round change messages from %d validators\n", len(s.roundChanges))
+	}
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsensusTracer(t *testing.T) {
+	db := database.NewMemoryDBManager()
+	tracer := newConsensusTracer(db)
+
+	ev := func(round uint64) *TraceEvent {
+		return &TraceEvent{Type: "prepare", Sender: common.HexToAddress("0x1"), Round: round, Result: traceAccepted}
+	}
+
+	// Only the events of the current and the next sequences are traced
+	current := big.NewInt(5)
+	tracer.add(big.NewInt(4), current, ev(0))
+	tracer.add(big.NewInt(5), current, ev(0))
+	tracer.add(big.NewInt(6), current, ev(1))
+	tracer.add(big.NewInt(7), current, ev(2))
+	assert.Nil(t, ReadConsensusTrace(db, 5))
+
+	tracer.flush(big.NewInt(6))
+	assert.Equal(t, []*TraceEvent{ev(0)}, ReadConsensusTrace(db, 5))
+	assert.Nil(t, ReadConsensusTrace(db, 6))
+
+	// The events traced before a restart are kept
+	tracer = newConsensusTracer(db)
+	tracer.add(big.NewInt(5), current, ev(1))
+	tracer.flush(big.NewInt(6))
+	assert.Equal(t, []*TraceEvent{ev(0), ev(1)}, ReadConsensusTrace(db, 5))
+
+	// Only the traces of the latest ConsensusTraceBlocks blocks are kept
+	current = big.NewInt(5 + ConsensusTraceBlocks)
+	tracer.add(current, current, ev(0))
+	tracer.flush(new(big.Int).Add(current, common.Big1))
+	assert.Nil(t, ReadConsensusTrace(db, 5))
+	assert.Equal(t, []*TraceEvent{ev(0)}, ReadConsensusTrace(db, current.Uint64()))
+
+	// The slot of a block is reused even if the sequences in between are skipped
+	skipped := new(big.Int).Add(current, big.NewInt(2*ConsensusTraceBlocks))
+	tracer.add(skipped, skipped, ev(1))
+	tracer.flush(new(big.Int).Add(skipped, common.Big1))
+	assert.Nil(t, ReadConsensusTrace(db, current.Uint64()))
+	assert.Equal(t, []*TraceEvent{ev(1)}, ReadConsensusTrace(db, skipped.Uint64()))
+}
+
+func TestRenderConsensusTrace(t *testing.T) {
+	proposer, validator := common.HexToAddress("0x1"), common.HexToAddress("0x2")
+	start := uint64(time.Now().UnixNano())
+	events := []*TraceEvent{
+		{Type: "roundChange", Sender: validator, Round: 1, Time: start + 3, Result: traceAccepted},
+		{Type: "preprepare", Sender: proposer, Round: 0, Time: start, Result: errInvalidMessage.Error()},
+		{Type: TraceLocalRoundChange, Sender: validator, Round: 1, Time: start + 2, Result: "timeout"},
+		{Type: "prepare", Sender: validator, Round: 0, Time: start + 1, Result: traceSent},
+	}
+
+	var buf bytes.Buffer
+	RenderConsensusTrace(&buf, 10, events)
+	out := buf.String()
+
+	assert.Contains(t, out, "Consensus trace of block 10")
+	assert.Contains(t, out, errInvalidMessage.Error())
+	assert.Contains(t, out, "round 0: preprepare false, prepares 1, commits 0")
+	assert.Contains(t, out, "round 1: preprepare false, prepares 0, commits 0")
+	assert.Contains(t, out, "entered the round: timeout")
+	assert.Contains(t, out, "round change messages from 1 validators")
+
+	// The events are rendered in the order of their arrival
+	assert.Less(t, strings.Index(out, errInvalidMessage.Error()), strings.Index(out, traceSent))
+	assert.Less(t, strings.Index(out, traceSent), strings.Index(out, traceAccepted))
+
+	buf.Reset()
+	RenderConsensusTrace(&buf, 10, nil)
+	assert.Equal(t, "No consensus trace of block 10\n", buf.String())
+}
+
+// TestCore_trace posts the messages of the current sequence, and checks that
+// they are traced with their validation results.
+func TestCore_trace(t *testing.T) {
+	fork.SetHardForkBlockNumberConfig(&params.ChainConfig{})
+	defer fork.ClearHardForkBlockNumberConfig()
+
+	validatorAddrs, validatorKeyMap := genValidators(6)
+	mockBackend, mockCtrl := newMockBackend(t, validatorAddrs)
+	defer mockCtrl.Finish()
+
+	istConfig := istanbul.DefaultConfig
+	istConfig.ProposerPolicy = istanbul.WeightedRandom
+
+	istCore := New(mockBackend, istConfig, database.NewMemoryDBManager()).(*core)
+	require.NoError(t, istCore.Start())
+	defer istCore.Stop()
+
+	eventMux := mockBackend.EventMux()
+	lastProposal, _ := mockBackend.LastProposal()
+	lastBlock := lastProposal.(*types.Block)
+	proposer := mockBackend.Validators(lastBlock).GetProposer().Address()
+
+	// A preprepare of the proposer, and a preprepare of a validator which is not the proposer
+	var notProposer common.Address
+	for _, addr := range validatorAddrs {
+		if addr != proposer {
+			notProposer = addr
+			break
+		}
+	}
+	for _, sender := range []common.Address{proposer, notProposer} {
+		block, err := genBlockParams(lastBlock, validatorKeyMap[sender], 0, 1, 1)
+		require.NoError(t, err)
+		msg, err := genIstanbulMsg(msgPreprepare, lastBlock.Hash(), block, sender, validatorKeyMap[sender])
+		require.NoError(t, err)
+		require.NoError(t, eventMux.Post(msg))
+	}
+	// A prepare of the next sequence is kept in the backlog, and is not traced until
+	// it is handled again from the backlog.
+	block, err := genBlockParams(lastBlock, validatorKeyMap[proposer], 0, 1, 1)
+	require.NoError(t, err)
+	nextBlock, err := genBlockParams(block, validatorKeyMap[proposer], 0, 1, 1)
+	require.NoError(t, err)
+	msg, err := genIstanbulMsg(msgPrepare, block.Hash(), nextBlock, notProposer, validatorKeyMap[notProposer])
+	require.NoError(t, err)
+	require.NoError(t, eventMux.Post(msg))
+	time.Sleep(time.Second)
+
+	istCore.tracer.mu.Lock()
+	defer istCore.tracer.mu.Unlock()
+
+	results := make(map[common.Address]string)
+	for _, ev := range istCore.tracer.events[1] {
+		if ev.Type == "preprepare" {
+			assert.Equal(t, uint64(0), ev.Round)
+			assert.NotZero(t, ev.Time)
+			results[ev.Sender] = ev.Result
+		}
+	}
+	assert.Equal(t, traceAccepted, results[proposer])
+	assert.Equal(t, errNotFromProposer.Error(), results[notProposer])
+	assert.Empty(t, istCore.tracer.events[2])
+}
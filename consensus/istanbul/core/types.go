@@ -191,6 +191,27 @@ func (m *message) GetView() (*istanbul.View, error) {
 	return msgView, nil
 }
 
+// viewAndDigest returns the view of the message and the digest of the proposal
+// it refers to. The digest of a ROUND CHANGE message is empty.
+func (m *message) viewAndDigest() (*istanbul.View, common.Hash, error) {
+	switch m.Code {
+	case msgPreprepare:
+		var preprepare *istanbul.Preprepare
+		if err := m.Decode(&preprepare); err != nil {
+			return nil, common.Hash{}, err
+		}
+		return preprepare.View, preprepare.Proposal.Hash(), nil
+	case msgPrepare, msgCommit, msgRoundChange:
+		var subject *istanbul.Subject
+		if err := m.Decode(&subject); err != nil {
+			return nil, common.Hash{}, err
+		}
+		return subject.View, subject.Digest, nil
+	default:
+		return nil, common.Hash{}, errInvalidMessage
+	}
+}
+
 // ==============================================
 //
 // helper functions
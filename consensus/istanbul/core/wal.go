@@ -0,0 +1,189 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+// walMessage is a consensus message signed by the node.
+type walMessage struct {
+	Code   uint64
+	View   *istanbul.View
+	Digest common.Hash
+}
+
+// walData is the persisted form of the write-ahead log. Locks has at most one
+// element, the preprepare of the locked proposal.
+type walData struct {
+	Messages []*walMessage
+	Locks    []*istanbul.Preprepare
+}
+
+// wal is the write-ahead log of the consensus core. The messages signed by the
+// node and the locked proposal are recorded before they are broadcast, so that
+// the node does not sign conflicting messages for the same view even after it
+// restarts in the middle of a round. Only the records of the current sequence
+// are kept, and they are written to the database before the message is signed.
+// The writes are synced to the disk only if the database supports it; on the
+// other backends the records survive a restart of the node but may be lost on
+// an OS crash. If db is nil, the records are kept in memory only.
+type wal struct {
+	db   database.DBManager
+	data walData
+	mu   sync.Mutex
+}
+
+// newWAL loads the write-ahead log stored in the given database.
+func newWAL(db database.DBManager) *wal {
+	w := &wal{db: db}
+	if db == nil {
+		return w
+	}
+	if !db.IstanbulWALSynced() {
+		logger.Warn("The database does not support synced writes, the istanbul write-ahead log may be lost on an OS crash")
+	}
+	if blob := db.ReadIstanbulWAL(); len(blob) > 0 {
+		if err := rlp.DecodeBytes(blob, &w.data); err != nil {
+			logger.Error("Failed to decode the istanbul write-ahead log, discarding it", "err", err)
+			w.data = walData{}
+		}
+	}
+	return w
+}
+
+// store persists the records. It must be called with the lock held.
+func (w *wal) store() {
+	if w.db == nil {
+		return
+	}
+	blob, err := rlp.EncodeToBytes(&w.data)
+	if err != nil {
+		logger.Crit("Failed to encode the istanbul write-ahead log", "err", err)
+	}
+	w.db.WriteIstanbulWAL(blob)
+}
+
+// recordMessage records the message to be signed. It returns errConflictingMessage
+// if a message of the same code was signed for the same view with a different digest.
+func (w *wal) recordMessage(code uint64, view *istanbul.View, digest common.Hash) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, m := range w.data.Messages {
+		if m.Code != code || m.View.Cmp(view) != 0 {
+			continue
+		}
+		if m.Digest != digest {
+			return errConflictingMessage
+		}
+		// The message was already recorded, so it can be signed again.
+		return nil
+	}
+	w.data.Messages = append(w.data.Messages, &walMessage{
+		Code:   code,
+		View:   &istanbul.View{Sequence: new(big.Int).Set(view.Sequence), Round: new(big.Int).Set(view.Round)},
+		Digest: digest,
+	})
+	w.store()
+	return nil
+}
+
+// recordLock records the preprepare of the locked proposal. A nil preprepare
+// records that the proposal is unlocked.
+func (w *wal) recordLock(preprepare *istanbul.Preprepare) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if preprepare == nil {
+		if len(w.data.Locks) == 0 {
+			return
+		}
+		w.data.Locks = nil
+	} else {
+		if len(w.data.Locks) > 0 && w.data.Locks[0].Proposal.Hash() == preprepare.Proposal.Hash() {
+			return
+		}
+		w.data.Locks = []*istanbul.Preprepare{preprepare}
+	}
+	w.store()
+}
+
+// lockedPreprepare returns the preprepare of the proposal locked in the given
+// sequence, or nil if there is no locked proposal.
+func (w *wal) lockedPreprepare(sequence *big.Int) *istanbul.Preprepare {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.data.Locks) == 0 || w.data.Locks[0].View.Sequence.Cmp(sequence) != 0 {
+		return nil
+	}
+	return w.data.Locks[0]
+}
+
+// prune removes the records of the sequences older than the given sequence.
+func (w *wal) prune(sequence *big.Int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	pruned := false
+	messages := w.data.Messages[:0]
+	for _, m := range w.data.Messages {
+		if m.View.Sequence.Cmp(sequence) < 0 {
+			pruned = true
+			continue
+		}
+		messages = append(messages, m)
+	}
+	w.data.Messages = messages
+	if len(w.data.Locks) > 0 && w.data.Locks[0].View.Sequence.Cmp(sequence) < 0 {
+		w.data.Locks = nil
+		pruned = true
+	}
+	if pruned {
+		w.store()
+	}
+}
+
+// recordMessage records the given message to the write-ahead log before it is signed.
+func (c *core) recordMessage(msg *message) error {
+	view, digest, err := msg.viewAndDigest()
+	if err != nil {
+		return err
+	}
+	return c.wal.recordMessage(msg.Code, view, digest)
+}
+
+// lockHash locks the current proposal and records the lock to the write-ahead log.
+func (c *core) lockHash() {
+	c.current.LockHash()
+	if !common.EmptyHash(c.current.GetLockedHash()) {
+		c.wal.recordLock(c.current.Preprepare)
+	}
+}
+
+// unlockHash unlocks the current proposal and records it to the write-ahead log.
+func (c *core) unlockHash() {
+	c.current.UnlockHash()
+	c.wal.recordLock(nil)
+}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	mock_istanbul "github.com/klaytn/klaytn/consensus/istanbul/mocks"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWAL_recordMessage(t *testing.T) {
+	db := database.NewMemoryDBManager()
+	w := newWAL(db)
+
+	view := &istanbul.View{Sequence: big.NewInt(1), Round: big.NewInt(0)}
+	digest1, digest2 := common.HexToHash("0x1"), common.HexToHash("0x2")
+
+	assert.NoError(t, w.recordMessage(msgPrepare, view, digest1))
+	assert.NoError(t, w.recordMessage(msgPrepare, view, digest1))
+	assert.NoError(t, w.recordMessage(msgCommit, view, digest1))
+	assert.Equal(t, errConflictingMessage, w.recordMessage(msgPrepare, view, digest2))
+	assert.Equal(t, errConflictingMessage, w.recordMessage(msgCommit, view, digest2))
+
+	// A different view is not a conflict
+	nextRound := &istanbul.View{Sequence: big.NewInt(1), Round: big.NewInt(1)}
+	assert.NoError(t, w.recordMessage(msgPrepare, nextRound, digest2))
+
+	// The records are replayed from the database
+	w = newWAL(db)
+	assert.Equal(t, errConflictingMessage, w.recordMessage(msgPrepare, view, digest2))
+	assert.Equal(t, errConflictingMessage, w.recordMessage(msgPrepare, nextRound, digest1))
+
+	// The records of the old sequences are pruned
+	w.prune(big.NewInt(2))
+	w = newWAL(db)
+	assert.Empty(t, w.data.Messages)
+	assert.NoError(t, w.recordMessage(msgPrepare, view, digest2))
+}
+
+// newWALTestBackend returns a mock backend whose owner is a member of the committee
+// of the first sequence, so that it signs the PREPARE and COMMIT messages.
+func newWALTestBackend(t *testing.T) (*mock_istanbul.MockBackend, *gomock.Controller, map[common.Address]*ecdsa.PrivateKey, istanbul.ValidatorSet, []istanbul.Validator) {
+	for {
+		validatorAddrs, validatorKeyMap := genValidators(6)
+		mockBackend, mockCtrl := newMockBackend(t, validatorAddrs)
+
+		lastProposal, lastProposer := mockBackend.LastProposal()
+		valSet := mockBackend.Validators(lastProposal)
+		view := &istanbul.View{Sequence: big.NewInt(1), Round: big.NewInt(0)}
+		valSet.CalcProposer(lastProposer, view.Round.Uint64())
+
+		if !valSet.CheckInSubList(lastProposal.Hash(), view, validatorAddrs[0]) {
+			mockCtrl.Finish()
+			continue
+		}
+		mockBackend.EXPECT().HasBadProposal(gomock.Any()).Return(false).AnyTimes()
+		return mockBackend, mockCtrl, validatorKeyMap, valSet, valSet.SubList(lastProposal.Hash(), view)
+	}
+}
+
+// TestCore_WAL_restart kills a validator after it locked a proposal in the middle
+// of a round, and checks that the restarted validator refuses a conflicting proposal
+// for the same view.
+func TestCore_WAL_restart(t *testing.T) {
+	fork.SetHardForkBlockNumberConfig(&params.ChainConfig{})
+	defer fork.ClearHardForkBlockNumberConfig()
+
+	mockBackend, mockCtrl, keys, valSet, committee := newWALTestBackend(t)
+	defer mockCtrl.Finish()
+
+	istConfig := istanbul.DefaultConfig
+	istConfig.ProposerPolicy = istanbul.WeightedRandom
+
+	db := database.NewMemoryDBManager()
+	eventMux := mockBackend.EventMux()
+	lastProposal, _ := mockBackend.LastProposal()
+	lastBlock := lastProposal.(*types.Block)
+	proposer := valSet.GetProposer()
+
+	proposal, err := genBlockParams(lastBlock, keys[proposer.Address()], 0, 1, 1)
+	require.NoError(t, err)
+	conflicting, err := genBlockParams(lastBlock, keys[proposer.Address()], 1, 1, 1)
+	require.NoError(t, err)
+
+	istCore := New(mockBackend, istConfig, db).(*core)
+	require.NoError(t, istCore.Start())
+
+	// Accept the proposal and lock it with the PREPARE messages of the committee
+	msg, err := genIstanbulMsg(msgPreprepare, lastBlock.Hash(), proposal, proposer.Address(), keys[proposer.Address()])
+	require.NoError(t, err)
+	require.NoError(t, eventMux.Post(msg))
+	time.Sleep(time.Second)
+
+	for _, val := range committee {
+		msg, err := genIstanbulMsg(msgPrepare, lastBlock.Hash(), proposal, val.Address(), keys[val.Address()])
+		require.NoError(t, err)
+		require.NoError(t, eventMux.Post(msg))
+	}
+	time.Sleep(time.Second)
+
+	require.Equal(t, StatePrepared, istCore.state)
+	require.Equal(t, proposal.Hash(), istCore.current.GetLockedHash())
+
+	// Kill the validator in the middle of the round
+	require.NoError(t, istCore.Stop())
+
+	// The restarted validator replays the write-ahead log
+	istCore = New(mockBackend, istConfig, db).(*core)
+	require.NoError(t, istCore.Start())
+	defer istCore.Stop()
+
+	view := &istanbul.View{Sequence: big.NewInt(1), Round: big.NewInt(0)}
+	assert.Equal(t, 0, istCore.currentView().Cmp(view))
+	assert.True(t, istCore.current.IsHashLocked())
+	assert.Equal(t, proposal.Hash(), istCore.current.GetLockedHash())
+	assert.Equal(t, errConflictingMessage, istCore.wal.recordMessage(msgPrepare, view, conflicting.Hash()))
+	assert.Equal(t, errConflictingMessage, istCore.wal.recordMessage(msgCommit, view, conflicting.Hash()))
+
+	// The conflicting proposal of the same view is refused
+	msg, err = genIstanbulMsg(msgPreprepare, lastBlock.Hash(), conflicting, proposer.Address(), keys[proposer.Address()])
+	require.NoError(t, err)
+	require.NoError(t, eventMux.Post(msg))
+	time.Sleep(time.Second)
+
+	assert.NotEqual(t, StatePreprepared, istCore.state)
+	assert.Equal(t, proposal.Hash(), istCore.current.GetLockedHash())
+}
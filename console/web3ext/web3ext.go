@@ -28,6 +28,7 @@ var Modules = map[string]string{
 	"personal":         Personal_JS,
 	"rpc":              RPC_JS,
 	"txpool":           TxPool_JS,
+	"trace":            Trace_JS,
 	"istanbul":         Istanbul_JS,
 	"mainbridge":       MainBridge_JS,
 	"subbridge":        SubBridge_JS,
@@ -333,6 +334,12 @@ web3._extend({
 			call: 'governance_getRewardsAccumulated',
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getConsensusTrace',
+			call: 'istanbul_getConsensusTrace',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
 		})
 	],
 	properties: [
@@ -455,6 +462,21 @@ web3._extend({
 			name: 'saveTrieNodeCacheToDisk',
 			call: 'admin_saveTrieNodeCacheToDisk',
 		}),
+		new web3._extend.Method({
+			name: 'exportTrieCache',
+			call: 'admin_exportTrieCache',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'importTrieCache',
+			call: 'admin_importTrieCache',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'backup',
+			call: 'admin_backup',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'setMaxSubscriptionPerWSConn',
 			call: 'admin_setMaxSubscriptionPerWSConn',
@@ -807,6 +829,12 @@ web3._extend({
 			params: 3,
 			inputFormatter: [null, null, null]
 		}),
+		new web3._extend.Method({
+			name: 'traceCallMany',
+			call: 'debug_traceCallMany',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'preimage',
 			call: 'debug_preimage',
@@ -1002,6 +1030,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputCallFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'simulate',
+			call: 'klay_simulate',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'getAccountKey',
 			call: 'klay_getAccountKey',
@@ -1260,6 +1294,37 @@ web3._extend({
 });
 `
 
+const Trace_JS = `
+web3._extend({
+	property: 'trace',
+	methods: [
+		new web3._extend.Method({
+			name: 'block',
+			call: 'trace_block',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'transaction',
+			call: 'trace_transaction',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'replayBlockTransactions',
+			call: 'trace_replayBlockTransactions',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'filter',
+			call: 'trace_filter',
+			params: 1
+		}),
+	],
+	properties: []
+});
+`
+
 const Istanbul_JS = `
 web3._extend({
 	property: 'istanbul',
@@ -1302,6 +1367,12 @@ web3._extend({
 			name: 'discard',
 			call: 'istanbul_discard',
 			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getMisbehaviours',
+			call: 'istanbul_getMisbehaviours',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
 		})
 	],
 	properties:
@@ -126,6 +126,7 @@ const (
 	KAS
 	FORK
 	NodeCnGasPrice
+	NodeCnGraphQL
 
 	// ModuleNameLen should be placed at the end of the list.
 	ModuleNameLen
@@ -203,4 +204,5 @@ var moduleNames = [ModuleNameLen]string{
 	"kas",
 	"fork",
 	"node/cn/gasprice",
+	"node/cn/graphql",
 }
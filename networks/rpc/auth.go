@@ -0,0 +1,275 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// jwtAlgorithm is the only signing algorithm accepted for the tokens.
+	jwtAlgorithm = "HS256"
+
+	// jwtIssuedAtWindow is the maximum difference between the issued time of a
+	// token and the current time, limiting how long a token can be replayed.
+	jwtIssuedAtWindow = 60 * time.Second
+
+	// jwtClaimsUserValue is the key of the verified claims in the user values
+	// of a fasthttp request.
+	jwtClaimsUserValue = "jwtClaims"
+)
+
+var (
+	errMissingToken    = errors.New("missing token")
+	errMalformedToken  = errors.New("malformed token")
+	errInvalidSigAlg   = fmt.Errorf("invalid signing algorithm, only %s is supported", jwtAlgorithm)
+	errInvalidTokenSig = errors.New("invalid token signature")
+	errMissingIssuedAt = errors.New("missing issued-at")
+	errStaleToken      = errors.New("stale token")
+	errFutureToken     = errors.New("future token")
+	errExpiredToken    = errors.New("token is expired")
+)
+
+// JWTClaims are the claims of the tokens authenticating the RPC callers.
+type JWTClaims struct {
+	// IssuedAt is the unix time the token is issued at. It is required and
+	// should be close to the time the token is verified.
+	IssuedAt int64 `json:"iat"`
+
+	// ExpiresAt is the unix time the token expires at. Zero means no expiry
+	// other than the one implied by IssuedAt.
+	ExpiresAt int64 `json:"exp,omitempty"`
+
+	// Modules is the list of API namespaces the caller may invoke. Empty means
+	// all the namespaces registered on the endpoint.
+	Modules []string `json:"modules,omitempty"`
+}
+
+// Allows returns true if the claims allow calling the methods of the namespace.
+// The metadata namespace is always allowed.
+func (c *JWTClaims) Allows(namespace string) bool {
+	if len(c.Modules) == 0 || namespace == MetadataApi {
+		return true
+	}
+	for _, module := range c.Modules {
+		if module == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+}
+
+// NewJWTToken returns a token of the claims signed with the secret.
+func NewJWTToken(secret []byte, claims *JWTClaims) (string, error) {
+	header, err := json.Marshal(&jwtHeader{Algorithm: jwtAlgorithm, Type: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(jwtSignature(secret, signingInput)), nil
+}
+
+func jwtSignature(secret []byte, signingInput string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return mac.Sum(nil)
+}
+
+// verifyJWTToken verifies the token signed with the secret at the given time
+// and returns its claims.
+func verifyJWTToken(secret []byte, token string, now time.Time) (*JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errMalformedToken
+	}
+	var header jwtHeader
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Algorithm != jwtAlgorithm {
+		return nil, errInvalidSigAlg
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errMalformedToken
+	}
+	if !hmac.Equal(sig, jwtSignature(secret, parts[0]+"."+parts[1])) {
+		return nil, errInvalidTokenSig
+	}
+	claims := new(JWTClaims)
+	if err := decodeJWTSegment(parts[1], claims); err != nil {
+		return nil, err
+	}
+	if claims.IssuedAt == 0 {
+		return nil, errMissingIssuedAt
+	}
+	issuedAt := time.Unix(claims.IssuedAt, 0)
+	if now.Sub(issuedAt) > jwtIssuedAtWindow {
+		return nil, errStaleToken
+	}
+	if issuedAt.Sub(now) > jwtIssuedAtWindow {
+		return nil, errFutureToken
+	}
+	if claims.ExpiresAt != 0 && !now.Before(time.Unix(claims.ExpiresAt, 0)) {
+		return nil, errExpiredToken
+	}
+	return claims, nil
+}
+
+func decodeJWTSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errMalformedToken
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errMalformedToken
+	}
+	return nil
+}
+
+// bearerToken extracts the token from the value of the Authorization header.
+func bearerToken(auth string) (string, error) {
+	const prefix = "Bearer "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", errMissingToken
+	}
+	return strings.TrimSpace(auth[len(prefix):]), nil
+}
+
+type jwtClaimsKey struct{}
+
+// withJWTClaims returns a copy of the context carrying the verified claims.
+func withJWTClaims(ctx context.Context, claims *JWTClaims) context.Context {
+	if claims == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, jwtClaimsKey{}, claims)
+}
+
+// JWTClaimsFromContext returns the verified claims of the caller, or nil if the
+// caller is not authenticated with a token.
+func JWTClaimsFromContext(ctx context.Context) *JWTClaims {
+	claims, _ := ctx.Value(jwtClaimsKey{}).(*JWTClaims)
+	return claims
+}
+
+// checkAuthorized returns an error if the caller authenticated with a token is
+// not allowed to call the methods of the namespace.
+func checkAuthorized(ctx context.Context, namespace string) error {
+	if claims := JWTClaimsFromContext(ctx); claims != nil && !claims.Allows(namespace) {
+		return &unauthorizedError{fmt.Sprintf("the %s namespace is not allowed for the token", namespace)}
+	}
+	return nil
+}
+
+// unauthorizedResponse returns the JSON-RPC error response for the request
+// failed to be authenticated.
+func unauthorizedResponse(err error) []byte {
+	resp, _ := json.Marshal(errorMessage(&unauthorizedError{"unauthorized: " + err.Error()}))
+	return resp
+}
+
+// jwtHandler is a handler which authenticates the incoming requests with the
+// token in the Authorization header. The verified claims are passed to the
+// next handler in the request context. The empty GET requests of the remote
+// health-checks are passed without a token, unlike the websocket handshakes.
+type jwtHandler struct {
+	secret []byte
+	next   http.Handler
+}
+
+// newJWTHandler returns the handler authenticating the requests with the
+// secret, or the next handler as it is if the secret is empty.
+func newJWTHandler(secret []byte, next http.Handler) http.Handler {
+	if len(secret) == 0 {
+		return next
+	}
+	return &jwtHandler{secret: secret, next: next}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *jwtHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if isHealthCheck(r) && r.Header.Get("Upgrade") == "" {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	token, err := bearerToken(r.Header.Get("Authorization"))
+	if err == nil {
+		var claims *JWTClaims
+		if claims, err = verifyJWTToken(h.secret, token, time.Now()); err == nil {
+			h.next.ServeHTTP(w, r.WithContext(withJWTClaims(r.Context(), claims)))
+			return
+		}
+	}
+	rpcErrorResponsesCounter.Inc(1)
+	w.Header().Set("content-type", contentType)
+	w.WriteHeader(http.StatusUnauthorized)
+	w.Write(unauthorizedResponse(err))
+}
+
+// newFastJWTHandler is the fasthttp version of newJWTHandler. The verified
+// claims are passed to the next handler in the user values of the request.
+func newFastJWTHandler(secret []byte, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	if len(secret) == 0 {
+		return next
+	}
+	return func(ctx *fasthttp.RequestCtx) {
+		if isFastHealthCheck(ctx) && len(ctx.Request.Header.Peek("Upgrade")) == 0 {
+			next(ctx)
+			return
+		}
+		token, err := bearerToken(string(ctx.Request.Header.Peek("Authorization")))
+		if err == nil {
+			var claims *JWTClaims
+			if claims, err = verifyJWTToken(secret, token, time.Now()); err == nil {
+				ctx.SetUserValue(jwtClaimsUserValue, claims)
+				next(ctx)
+				return
+			}
+		}
+		rpcErrorResponsesCounter.Inc(1)
+		ctx.Response.Header.SetContentType(contentType)
+		ctx.SetStatusCode(http.StatusUnauthorized)
+		ctx.Write(unauthorizedResponse(err))
+	}
+}
+
+// fastJWTClaims returns the verified claims in the user values of a fasthttp request.
+func fastJWTClaims(ctx *fasthttp.RequestCtx) *JWTClaims {
+	claims, _ := ctx.UserValue(jwtClaimsUserValue).(*JWTClaims)
+	return claims
+}
@@ -0,0 +1,159 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testJWTSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func newTestJWTToken(t *testing.T, secret []byte, claims *JWTClaims) string {
+	token, err := NewJWTToken(secret, claims)
+	require.NoError(t, err)
+	return token
+}
+
+func TestVerifyJWTToken(t *testing.T) {
+	now := time.Now()
+	noneHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+
+	testcases := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"valid", newTestJWTToken(t, testJWTSecret, &JWTClaims{IssuedAt: now.Unix()}), nil},
+		{"valid with expiry", newTestJWTToken(t, testJWTSecret, &JWTClaims{IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Minute).Unix()}), nil},
+		{"other secret", newTestJWTToken(t, []byte("other"), &JWTClaims{IssuedAt: now.Unix()}), errInvalidTokenSig},
+		{"no issued-at", newTestJWTToken(t, testJWTSecret, &JWTClaims{}), errMissingIssuedAt},
+		{"stale", newTestJWTToken(t, testJWTSecret, &JWTClaims{IssuedAt: now.Add(-2 * jwtIssuedAtWindow).Unix()}), errStaleToken},
+		{"future", newTestJWTToken(t, testJWTSecret, &JWTClaims{IssuedAt: now.Add(2 * jwtIssuedAtWindow).Unix()}), errFutureToken},
+		{"expired", newTestJWTToken(t, testJWTSecret, &JWTClaims{IssuedAt: now.Unix(), ExpiresAt: now.Unix()}), errExpiredToken},
+		{"alg none", noneHeader + "." + strings.Split(newTestJWTToken(t, testJWTSecret, &JWTClaims{IssuedAt: now.Unix()}), ".")[1] + ".", errInvalidSigAlg},
+		{"malformed", "not-a-token", errMalformedToken},
+	}
+	for _, tc := range testcases {
+		_, err := verifyJWTToken(testJWTSecret, tc.token, now)
+		assert.Equal(t, tc.err, err, tc.name)
+	}
+
+	claims, err := verifyJWTToken(testJWTSecret, newTestJWTToken(t, testJWTSecret, &JWTClaims{IssuedAt: now.Unix(), Modules: []string{"klay"}}), now)
+	require.NoError(t, err)
+	assert.True(t, claims.Allows("klay"))
+	assert.True(t, claims.Allows(MetadataApi))
+	assert.False(t, claims.Allows("admin"))
+}
+
+func TestHTTPJWTAuth(t *testing.T) {
+	srv := newTestServer("test", new(Service))
+	defer srv.Stop()
+	httpsrv := httptest.NewServer(NewHTTPServer(nil, []string{"*"}, DefaultHTTPTimeouts, testJWTSecret, srv).Handler)
+	defer httpsrv.Close()
+
+	// The requests without a valid token are rejected.
+	for _, auth := range []string{"", "Basic dGVzdA==", "Bearer invalid"} {
+		req, err := http.NewRequest(http.MethodPost, httpsrv.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"rpc_modules"}`))
+		require.NoError(t, err)
+		req.Header.Set("content-type", contentType)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, auth)
+		var msg jsonrpcMessage
+		require.NoError(t, json.Unmarshal(body, &msg))
+		require.NotNil(t, msg.Error)
+		assert.Equal(t, -32001, msg.Error.Code)
+	}
+
+	// The empty GET requests of the health-checks are allowed without a token.
+	resp, err := http.Get(httpsrv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// The token limited to the test namespace is allowed to call its methods only.
+	client, err := DialHTTP(httpsrv.URL)
+	require.NoError(t, err)
+	defer client.Close()
+	client.SetHeader("Authorization", "Bearer "+newTestJWTToken(t, testJWTSecret, &JWTClaims{IssuedAt: time.Now().Unix(), Modules: []string{"test"}}))
+
+	var result Result
+	require.NoError(t, client.Call(&result, "test_echo", "hello", 1, &Args{"world"}))
+	assert.Equal(t, "hello", result.String)
+
+	var modules map[string]string
+	assert.NoError(t, client.Call(&modules, "rpc_modules"))
+
+	srv.RegisterName("admin", new(Service))
+	err = client.Call(&result, "admin_echo", "hello", 1, &Args{"world"})
+	require.Error(t, err)
+	rpcErr, ok := err.(interface{ ErrorCode() int })
+	require.True(t, ok)
+	assert.Equal(t, -32001, rpcErr.ErrorCode())
+}
+
+func TestWebsocketJWTAuth(t *testing.T) {
+	srv := newTestServer("test", new(Service))
+	defer srv.Stop()
+	httpsrv := httptest.NewServer(NewWSServer([]string{"*"}, testJWTSecret, srv).Handler)
+	defer httpsrv.Close()
+	wsURL := "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+
+	// The connection without a token is rejected on the handshake.
+	_, err := DialWebsocket(context.Background(), wsURL, "")
+	assert.Error(t, err)
+
+	token := newTestJWTToken(t, testJWTSecret, &JWTClaims{IssuedAt: time.Now().Unix(), Modules: []string{"test"}})
+	header := http.Header{"Authorization": {"Bearer " + token}}
+	client, err := NewClient(context.Background(), func(ctx context.Context) (ServerCodec, error) {
+		conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, header)
+		if err != nil {
+			return nil, err
+		}
+		return newWebsocketCodec(conn), nil
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	var modules map[string]string
+	assert.NoError(t, client.Call(&modules, "rpc_modules"))
+
+	srv.RegisterName("admin", new(Service))
+	assert.Error(t, client.Call(&modules, "admin_noArgsRets"))
+
+	var result Result
+	require.NoError(t, client.Call(&result, "test_echo", "hello", 1, &Args{"world"}))
+	assert.Equal(t, "hello", result.String)
+}
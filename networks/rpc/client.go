@@ -83,6 +83,7 @@ type Client struct {
 	idgen func() ID // for subscriptions
 
 	services *serviceRegistry
+	connCtx  context.Context // parent of the contexts of the calls served on the connection
 
 	idCounter uint32
 	isHTTP    bool
@@ -117,7 +118,7 @@ type clientConn struct {
 }
 
 func (c *Client) newClientConn(conn ServerCodec) *clientConn {
-	ctx := context.WithValue(context.Background(), clientContextKey{}, c)
+	ctx := context.WithValue(c.connCtx, clientContextKey{}, c)
 	handler := newHandler(ctx, conn, c.idgen, c.services)
 	return &clientConn{conn, handler}
 }
@@ -210,8 +211,13 @@ func NewClient(initctx context.Context, connect reconnectFunc) (*Client, error)
 }
 
 func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry) *Client {
+	return initClientContext(context.Background(), conn, idgen, services)
+}
+
+func initClientContext(connCtx context.Context, conn ServerCodec, idgen func() ID, services *serviceRegistry) *Client {
 	_, isHTTP := conn.(*httpConn)
 	c := &Client{
+		connCtx:     connCtx,
 		idgen:       idgen,
 		isHTTP:      isHTTP,
 		services:    services,
@@ -22,10 +22,13 @@ package rpc
 
 import (
 	"net"
+	"net/http"
 )
 
-// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules
-func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts) (net.Listener, *Server, error) {
+// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules.
+// If jwtSecret is not empty, the requests are authenticated with the tokens signed with it.
+// The given handlers are served on their paths beside the JSON-RPC requests.
+func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts, jwtSecret []byte, handlers map[string]http.Handler) (net.Listener, *Server, error) {
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
 	for _, module := range modules {
@@ -49,12 +52,22 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 	if listener, err = net.Listen("tcp", endpoint); err != nil {
 		return nil, nil, err
 	}
-	go NewHTTPServer(cors, vhosts, timeouts, handler).Serve(listener)
+	var srv http.Handler = handler
+	if len(handlers) > 0 {
+		mux := http.NewServeMux()
+		mux.Handle("/", handler)
+		for path, h := range handlers {
+			mux.Handle(path, h)
+		}
+		srv = mux
+	}
+	go NewHTTPServer(cors, vhosts, timeouts, jwtSecret, srv).Serve(listener)
 	return listener, handler, err
 }
 
-// StartWSEndpoint starts a websocket endpoint
-func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []string, exposeAll bool) (net.Listener, *Server, error) {
+// StartWSEndpoint starts a websocket endpoint.
+// If jwtSecret is not empty, the connections are authenticated with the tokens signed with it.
+func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []string, exposeAll bool, jwtSecret []byte) (net.Listener, *Server, error) {
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
 	for _, module := range modules {
@@ -78,7 +91,7 @@ func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []
 	if listener, err = net.Listen("tcp", endpoint); err != nil {
 		return nil, nil, err
 	}
-	go NewWSServer(wsOrigins, handler).Serve(listener)
+	go NewWSServer(wsOrigins, jwtSecret, handler).Serve(listener)
 	return listener, handler, err
 }
 
@@ -71,6 +71,22 @@ func (e *callbackError) ErrorCode() int { return defaultErrorCode }
 
 func (e *callbackError) Error() string { return e.message }
 
+// the responses to a batch exceed the size limit
+type responseTooLargeError struct{}
+
+func (e *responseTooLargeError) ErrorCode() int { return -32003 }
+
+func (e *responseTooLargeError) Error() string {
+	return fmt.Sprintf("batch response exceeds the limit of %d bytes", BatchResponseMaxSize)
+}
+
+// the caller is not authorized to call the method
+type unauthorizedError struct{ message string }
+
+func (e *unauthorizedError) ErrorCode() int { return -32001 }
+
+func (e *unauthorizedError) Error() string { return e.message }
+
 // issued when a request is received after the server is issued to stop.
 type shutdownError struct{}
 
@@ -127,13 +127,47 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 		return
 	}
 
+	if BatchRequestLimit > 0 && len(calls) > BatchRequestLimit {
+		err := &invalidRequestError{fmt.Sprintf("batch exceeds the limit of %d requests", BatchRequestLimit)}
+		logger.Debug(fmt.Sprintf("request error %v\n", err))
+		h.startCallProc(func(cp *callProc) {
+			answers := make([]*jsonrpcMessage, 0, len(calls))
+			for _, msg := range calls {
+				if answer := batchErrorResponse(msg, err); answer != nil {
+					answers = append(answers, answer)
+				}
+			}
+			if len(answers) > 0 {
+				h.conn.writeJSON(cp.ctx, answers)
+			}
+		})
+		return
+	}
+
 	// Process calls on a goroutine because they may block indefinitely:
 	h.startCallProc(func(cp *callProc) {
-		answers := make([]*jsonrpcMessage, 0, len(msgs))
+		var (
+			answers  = make([]*jsonrpcMessage, 0, len(msgs))
+			respSize = 0
+		)
 		for _, msg := range calls {
-			if answer := h.handleCallMsg(cp, msg); answer != nil {
-				answers = append(answers, answer)
+			// Once the responses reach the limit, the remaining calls are
+			// answered with the error without being executed.
+			if BatchResponseMaxSize > 0 && respSize >= BatchResponseMaxSize {
+				if answer := batchErrorResponse(msg, &responseTooLargeError{}); answer != nil {
+					answers = append(answers, answer)
+				}
+				continue
+			}
+			answer := h.handleCallMsg(cp, msg)
+			if answer == nil {
+				continue
+			}
+			respSize += len(answer.Result)
+			if BatchResponseMaxSize > 0 && respSize > BatchResponseMaxSize {
+				answer = batchErrorResponse(msg, &responseTooLargeError{})
 			}
+			answers = append(answers, answer)
 		}
 		h.addSubscriptions(cp.notifiers)
 		if len(answers) > 0 {
@@ -145,6 +179,19 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	})
 }
 
+// batchErrorResponse returns the error response to a message in a batch which is
+// not executed, or nil if the message is a notification requiring no response.
+func batchErrorResponse(msg *jsonrpcMessage, err error) *jsonrpcMessage {
+	if msg.isNotification() {
+		return nil
+	}
+	rpcErrorResponsesCounter.Inc(1)
+	if msg.hasValidID() {
+		return msg.errorResponse(err)
+	}
+	return errorMessage(err)
+}
+
 // handleMsg handles a single message.
 func (h *handler) handleMsg(msg *jsonrpcMessage) {
 	rpcTotalRequestsCounter.Inc(1)
@@ -355,6 +402,10 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if err := checkAuthorized(cp.ctx, msg.namespace()); err != nil {
+		rpcErrorResponsesCounter.Inc(1)
+		return msg.errorResponse(err)
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
@@ -263,12 +263,14 @@ func (t *httpReadWriteNopCloser) Close() error {
 }
 
 // NewHTTPServer creates a new HTTP RPC server around an API provider.
+// If jwtSecret is not empty, the requests are authenticated with the tokens
+// signed with it.
 //
 // Deprecated: Server implements http.Handler
-func NewHTTPServer(cors []string, vhosts []string, timeouts HTTPTimeouts, srv http.Handler) *http.Server {
+func NewHTTPServer(cors []string, vhosts []string, timeouts HTTPTimeouts, jwtSecret []byte, srv http.Handler) *http.Server {
 	timeouts = sanitizeTimeouts(timeouts)
-	// Wrap the CORS-handler within a host-handler
-	handler := newCorsHandler(srv, cors)
+	// Wrap the JWT-handler within a CORS-handler, and the CORS-handler within a host-handler
+	handler := newCorsHandler(newJWTHandler(jwtSecret, srv), cors)
 	handler = newVHostHandler(vhosts, handler)
 	handler = http.TimeoutHandler(handler, timeouts.ExecutionTimeout, "timeout")
 
@@ -293,16 +295,17 @@ func NewHTTPServer(cors []string, vhosts []string, timeouts HTTPTimeouts, srv ht
 }
 
 // NewFastHTTPServer creates a new HTTP RPC server around an API provider based on fasthttp library.
+// If jwtSecret is not empty, the requests are authenticated with the tokens signed with it.
 //
 // Deprecated: fasthttp server type endpoint is no longer supported
-func NewFastHTTPServer(cors []string, vhosts []string, timeouts HTTPTimeouts, srv *Server) *fasthttp.Server {
+func NewFastHTTPServer(cors []string, vhosts []string, timeouts HTTPTimeouts, jwtSecret []byte, srv *Server) *fasthttp.Server {
 	timeouts = sanitizeTimeouts(timeouts)
 	if len(cors) == 0 {
 		for _, vhost := range vhosts {
 			if vhost == "*" {
 				return &fasthttp.Server{
 					Concurrency:        ConcurrencyLimit,
-					Handler:            fasthttp.TimeoutHandler(srv.HandleFastHTTP, timeouts.ExecutionTimeout, "timeout"),
+					Handler:            fasthttp.TimeoutHandler(newFastJWTHandler(jwtSecret, srv.HandleFastHTTP), timeouts.ExecutionTimeout, "timeout"),
 					ReadTimeout:        timeouts.ReadTimeout,
 					WriteTimeout:       timeouts.WriteTimeout,
 					IdleTimeout:        timeouts.IdleTimeout,
@@ -312,8 +315,8 @@ func NewFastHTTPServer(cors []string, vhosts []string, timeouts HTTPTimeouts, sr
 			}
 		}
 	}
-	// Wrap the CORS-handler within a host-handler
-	handler := newCorsHandler(srv, cors)
+	// Wrap the JWT-handler within a CORS-handler, and the CORS-handler within a host-handler
+	handler := newCorsHandler(newJWTHandler(jwtSecret, srv), cors)
 	handler = newVHostHandler(vhosts, handler)
 
 	// If os environment variables for NewRelic exist, register the NewRelicHTTPHandler
@@ -346,7 +349,7 @@ func NewFastHTTPServer(cors []string, vhosts []string, timeouts HTTPTimeouts, sr
 // ServeHTTP serves JSON-RPC requests over HTTP.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Permit dumb empty requests for remote health-checks (AWS)
-	if r.Method == http.MethodGet && r.ContentLength == 0 && r.URL.RawQuery == "" {
+	if isHealthCheck(r) {
 		return
 	}
 	if code, err := validateRequest(r); err != nil {
@@ -378,7 +381,7 @@ func (srv *Server) HandleFastHTTP(requestCtx *fasthttp.RequestCtx) {
 	w := &requestCtx.Response
 
 	// Permit dumb empty requests for remote health-checks (AWS)
-	if requestCtx.IsGet() && requestCtx.Request.Header.ContentLength() == 0 && string(requestCtx.URI().QueryString()) == "" {
+	if isFastHealthCheck(requestCtx) {
 		return
 	}
 	if code, err := validateFastRequest(requestCtx); err != nil {
@@ -396,6 +399,7 @@ func (srv *Server) HandleFastHTTP(requestCtx *fasthttp.RequestCtx) {
 	ctx = context.WithValue(ctx, "remote", requestCtx.RemoteAddr().String())
 	ctx = context.WithValue(ctx, "scheme", string(requestCtx.URI().Scheme()))
 	ctx = context.WithValue(ctx, "local", requestCtx.LocalAddr().String())
+	ctx = withJWTClaims(ctx, fastJWTClaims(requestCtx))
 
 	reader := bufio.NewReaderSize(bytes.NewReader(r.Body()), common.MaxRequestContentLength)
 	codec := NewCodec(&httpReadWriteNopCloser{reader, w.BodyWriter()})
@@ -405,6 +409,17 @@ func (srv *Server) HandleFastHTTP(requestCtx *fasthttp.RequestCtx) {
 	srv.ServeSingleRequest(ctx, codec)
 }
 
+// isHealthCheck returns true if the request is an empty GET request of the remote
+// health-checks, which is answered with an empty response.
+func isHealthCheck(r *http.Request) bool {
+	return r.Method == http.MethodGet && r.ContentLength == 0 && r.URL.RawQuery == ""
+}
+
+// isFastHealthCheck is the fasthttp version of isHealthCheck.
+func isFastHealthCheck(requestCtx *fasthttp.RequestCtx) bool {
+	return requestCtx.IsGet() && requestCtx.Request.Header.ContentLength() == 0 && len(requestCtx.URI().QueryString()) == 0
+}
+
 // validateRequest returns a non-zero response code and error message if the
 // request is invalid.
 func validateRequest(r *http.Request) (int, error) {
@@ -73,6 +73,14 @@ var (
 
 	// UpstreamArchiveEN is the upstream archive mode EN endpoint
 	UpstreamArchiveEN string
+
+	// BatchRequestLimit is a maximum number of requests in a batch. 0 means no limit.
+	// It can be overwritten by rpc.batchrequestlimit flag
+	BatchRequestLimit = 1000
+
+	// BatchResponseMaxSize is a maximum number of bytes of the responses to a batch. 0 means no limit.
+	// It can be overwritten by rpc.batchresponsemaxsize flag
+	BatchResponseMaxSize = 25 * 1000 * 1000
 )
 
 // Server is an RPC server.
@@ -134,6 +142,12 @@ func GetNullServices() service {
 //
 // Note that codec options are no longer supported.
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
+	s.serveCodec(context.Background(), codec)
+}
+
+// serveCodec is ServeCodec with the context of the connection, which carries
+// the values like the verified claims of the caller to the handler.
+func (s *Server) serveCodec(ctx context.Context, codec ServerCodec) {
 	defer codec.close()
 
 	// Don't serve if server is stopped.
@@ -145,7 +159,7 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	s.codecs.Add(codec)
 	defer s.codecs.Remove(codec)
 
-	c := initClient(codec, s.idgen, &s.services)
+	c := initClientContext(ctx, codec, s.idgen, &s.services)
 	<-codec.closed()
 	c.Close()
 }
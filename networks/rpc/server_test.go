@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/json"
 	"net"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -190,3 +191,108 @@ func TestServerShortLivedConn(t *testing.T) {
 		}
 	}
 }
+
+// This test checks that the requests in a batch over the limits are answered with
+// the errors per request.
+func TestServerBatchLimits(t *testing.T) {
+	defer func(limit, maxSize int) {
+		BatchRequestLimit, BatchResponseMaxSize = limit, maxSize
+	}(BatchRequestLimit, BatchResponseMaxSize)
+
+	server := newTestServer("test", new(Service))
+	defer server.Stop()
+
+	sendBatch := func(batch string) []*jsonrpcMessage {
+		clientConn, serverConn := net.Pipe()
+		defer clientConn.Close()
+		go server.ServeCodec(NewCodec(serverConn), 0)
+
+		clientConn.SetDeadline(time.Now().Add(10 * time.Second))
+		if _, err := clientConn.Write([]byte(batch)); err != nil {
+			t.Fatal(err)
+		}
+		var resps []*jsonrpcMessage
+		if err := json.NewDecoder(clientConn).Decode(&resps); err != nil {
+			t.Fatal(err)
+		}
+		return resps
+	}
+	batch := `[{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["a",1,{"S":"x"}]},` +
+		`{"jsonrpc":"2.0","method":"test_echo","params":["b",2,{"S":"y"}]},` +
+		`{"jsonrpc":"2.0","id":3,"method":"test_echo","params":["c",3,{"S":"z"}]}]`
+
+	// The batch over the request limit is not executed.
+	BatchRequestLimit, BatchResponseMaxSize = 2, 0
+	resps := sendBatch(batch)
+	if len(resps) != 2 {
+		t.Fatalf("wrong number of responses: %d", len(resps))
+	}
+	for i, resp := range resps {
+		if resp.Error == nil || resp.Error.Code != -32600 {
+			t.Fatalf("response %d: wrong error: %v", i, resp.Error)
+		}
+	}
+	if string(resps[0].ID) != "1" || string(resps[1].ID) != "3" {
+		t.Fatalf("wrong response IDs: %s, %s", resps[0].ID, resps[1].ID)
+	}
+
+	// The calls after the responses exceed the size limit are not executed.
+	BatchRequestLimit, BatchResponseMaxSize = 3, 40
+	resps = sendBatch(batch)
+	if len(resps) != 2 {
+		t.Fatalf("wrong number of responses: %d", len(resps))
+	}
+	if resps[0].Error != nil {
+		t.Fatalf("unexpected error: %v", resps[0].Error)
+	}
+	if resps[1].Error == nil || resps[1].Error.Code != -32003 {
+		t.Fatalf("wrong error: %v", resps[1].Error)
+	}
+
+	// The batch within the limits is executed.
+	BatchRequestLimit, BatchResponseMaxSize = 3, 1000
+	for _, resp := range sendBatch(batch) {
+		if resp.Error != nil {
+			t.Fatalf("unexpected error: %v", resp.Error)
+		}
+	}
+}
+
+type countService struct{ calls int32 }
+
+func (s *countService) Next() int32 { return atomic.AddInt32(&s.calls, 1) }
+
+// TestServerBatchResponseLimitReached tests that no more calls are executed once
+// the responses to a batch reach the size limit.
+func TestServerBatchResponseLimitReached(t *testing.T) {
+	defer func(maxSize int) { BatchResponseMaxSize = maxSize }(BatchResponseMaxSize)
+
+	service := new(countService)
+	server := newTestServer("count", service)
+	defer server.Stop()
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go server.ServeCodec(NewCodec(serverConn), 0)
+
+	// The response to the first call fills the limit exactly.
+	BatchResponseMaxSize = len("1")
+	clientConn.SetDeadline(time.Now().Add(10 * time.Second))
+	batch := `[{"jsonrpc":"2.0","id":1,"method":"count_next"},{"jsonrpc":"2.0","id":2,"method":"count_next"}]`
+	if _, err := clientConn.Write([]byte(batch)); err != nil {
+		t.Fatal(err)
+	}
+	var resps []*jsonrpcMessage
+	if err := json.NewDecoder(clientConn).Decode(&resps); err != nil {
+		t.Fatal(err)
+	}
+	if len(resps) != 2 || resps[0].Error != nil || string(resps[0].Result) != "1" {
+		t.Fatalf("wrong responses: %v", resps)
+	}
+	if resps[1].Error == nil || resps[1].Error.Code != -32003 {
+		t.Fatalf("wrong error: %v", resps[1].Error)
+	}
+	if calls := atomic.LoadInt32(&service.calls); calls != 1 {
+		t.Fatalf("wrong number of executed calls: %d", calls)
+	}
+}
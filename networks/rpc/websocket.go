@@ -86,7 +86,7 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			return
 		}
 		codec := newWebsocketCodec(conn)
-		srv.ServeCodec(codec, 0)
+		srv.serveCodec(withJWTClaims(context.Background(), JWTClaimsFromContext(r.Context())), codec)
 	})
 }
 
@@ -96,6 +96,8 @@ var upgrader = fastws.Upgrader{
 }
 
 func (srv *Server) FastWebsocketHandler(ctx *fasthttp.RequestCtx) {
+	claims := fastJWTClaims(ctx)
+
 	// TODO-Kaia handle websocket protocol
 	protocol := ctx.Request.Header.Peek("Sec-WebSocket-Protocol")
 	if protocol != nil {
@@ -141,7 +143,7 @@ func (srv *Server) FastWebsocketHandler(ctx *fasthttp.RequestCtx) {
 		}
 
 		reader := bufio.NewReaderSize(bytes.NewReader(ctx.Request.Body()), common.MaxRequestContentLength)
-		srv.ServeCodec(NewFuncCodec(&httpReadWriteNopCloser{reader, ctx.Response.BodyWriter()}, encoder, decoder), 0)
+		srv.serveCodec(withJWTClaims(context.Background(), claims), NewFuncCodec(&httpReadWriteNopCloser{reader, ctx.Response.BodyWriter()}, encoder, decoder))
 	})
 	if err != nil {
 		logger.Error("FastWebsocketHandler fail to upgrade message", "err", err)
@@ -150,22 +152,24 @@ func (srv *Server) FastWebsocketHandler(ctx *fasthttp.RequestCtx) {
 }
 
 // NewWSServer creates a new websocket RPC server around an API provider.
+// If jwtSecret is not empty, the connections are authenticated with the tokens
+// signed with it.
 //
 // Deprecated: use Server.WebsocketHandler
-func NewWSServer(allowedOrigins []string, srv *Server) *http.Server {
+func NewWSServer(allowedOrigins []string, jwtSecret []byte, srv *Server) *http.Server {
 	return &http.Server{
-		Handler: srv.WebsocketHandler(allowedOrigins),
+		Handler: newJWTHandler(jwtSecret, srv.WebsocketHandler(allowedOrigins)),
 	}
 }
 
-func NewFastWSServer(allowedOrigins []string, srv *Server) *fasthttp.Server {
+func NewFastWSServer(allowedOrigins []string, jwtSecret []byte, srv *Server) *fasthttp.Server {
 	upgrader.CheckOrigin = wsFastHandshakeValidator(allowedOrigins)
 
 	// TODO-Kaia concurreny default (256 * 1024), goroutine limit (8192)
 	return &fasthttp.Server{
 		Concurrency:        ConcurrencyLimit,
 		MaxRequestBodySize: common.MaxRequestContentLength,
-		Handler:            srv.FastWebsocketHandler,
+		Handler:            newFastJWTHandler(jwtSecret, srv.FastWebsocketHandler),
 	}
 }
 
@@ -175,7 +175,7 @@ func (api *PrivateAdminAPI) StartHTTP(host *string, port *int, cors *string, api
 
 	if err := api.node.startHTTP(
 		fmt.Sprintf("%s:%d", *host, *port),
-		api.node.rpcAPIs, modules, allowedOrigins, allowedVHosts, api.node.config.HTTPTimeouts); err != nil {
+		api.node.rpcAPIs, modules, allowedOrigins, allowedVHosts, api.node.config.HTTPTimeouts, api.node.jwtSecret); err != nil {
 		return false, err
 	}
 
@@ -246,7 +246,7 @@ func (api *PrivateAdminAPI) StartWS(host *string, port *int, allowedOrigins *str
 
 	if err := api.node.startWS(
 		fmt.Sprintf("%s:%d", *host, *port),
-		api.node.rpcAPIs, modules, origins, api.node.config.WSExposeAll); err != nil {
+		api.node.rpcAPIs, modules, origins, api.node.config.WSExposeAll, api.node.jwtSecret); err != nil {
 		return false, err
 	}
 
@@ -39,6 +39,7 @@ import (
 	"github.com/klaytn/klaytn/networks/rpc"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
 	"github.com/klaytn/klaytn/storage/statedb"
 	"github.com/klaytn/klaytn/work"
 )
@@ -213,6 +214,24 @@ func (api *PrivateAdminAPI) SaveTrieNodeCacheToDisk() error {
 	return api.cn.BlockChain().SaveTrieNodeCacheToDisk()
 }
 
+// ExportTrieCache saves the local trie node cache to the given path, so that a
+// new node can start with a warm cache by importing it.
+func (api *PrivateAdminAPI) ExportTrieCache(filePath string) error {
+	return api.cn.BlockChain().ExportTrieNodeCache(filePath)
+}
+
+// ImportTrieCache replaces the local trie node cache with the one exported to
+// the given path.
+func (api *PrivateAdminAPI) ImportTrieCache(filePath string) error {
+	return api.cn.BlockChain().ImportTrieNodeCache(filePath)
+}
+
+// Backup takes a consistent backup of the chain data into the given directory,
+// which must not exist. The backup can be restored with the restore command.
+func (api *PrivateAdminAPI) Backup(dir string) (*database.BackupManifest, error) {
+	return api.cn.BlockChain().Backup(dir)
+}
+
 func (api *PrivateAdminAPI) SpamThrottlerConfig(ctx context.Context) (*blockchain.ThrottlerConfig, error) {
 	throttler := blockchain.GetSpamThrottler()
 	if throttler == nil {
@@ -22,6 +22,7 @@ package cn
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"time"
@@ -41,6 +42,7 @@ import (
 	"github.com/klaytn/klaytn/node/cn/gasprice"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/reward"
+	"github.com/klaytn/klaytn/snapshot"
 	"github.com/klaytn/klaytn/storage/database"
 	"github.com/klaytn/klaytn/work"
 )
@@ -190,7 +192,7 @@ func (b *CNAPIBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.B
 	if header == nil || err != nil {
 		return nil, nil, err
 	}
-	stateDb, err := b.cn.BlockChain().StateAt(header.Root)
+	stateDb, err := b.stateAt(header)
 	return stateDb, header, err
 }
 
@@ -203,12 +205,27 @@ func (b *CNAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNr
 		if header == nil {
 			return nil, nil, fmt.Errorf("header for hash not found")
 		}
-		stateDb, err := b.cn.BlockChain().StateAt(header.Root)
+		stateDb, err := b.stateAt(header)
 		return stateDb, header, err
 	}
 	return nil, nil, fmt.Errorf("invalid arguments; neither block nor hash specified")
 }
 
+// stateAt returns the state of the given block. If the state trie is unavailable,
+// the state is read from the state histories if they are enabled.
+func (b *CNAPIBackend) stateAt(header *types.Header) (*state.StateDB, error) {
+	stateDb, err := b.cn.BlockChain().StateAt(header.Root)
+	if err == nil {
+		return stateDb, nil
+	}
+	if historical, historyErr := b.cn.BlockChain().HistoricalStateAt(header); historyErr == nil {
+		return historical, nil
+	} else if !errors.Is(historyErr, blockchain.ErrStateHistoryDisabled) {
+		logger.Debug("Failed to read the state from state histories", "number", header.Number, "err", historyErr)
+	}
+	return nil, err
+}
+
 func (b *CNAPIBackend) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
 	block := b.cn.blockchain.GetBlockByHash(hash)
 	if block == nil {
@@ -300,6 +317,10 @@ func (b *CNAPIBackend) SubscribeNewTxsEvent(ch chan<- blockchain.NewTxsEvent) ev
 	return b.cn.TxPool().SubscribeNewTxsEvent(ch)
 }
 
+func (b *CNAPIBackend) SubscribeDroppedTxsEvent(ch chan<- blockchain.DroppedTxsEvent) event.Subscription {
+	return b.cn.TxPool().SubscribeDroppedTxsEvent(ch)
+}
+
 func (b *CNAPIBackend) Progress() klaytn.SyncProgress {
 	return b.cn.Progress()
 }
@@ -350,6 +371,10 @@ func (b *CNAPIBackend) ChainDB() database.DBManager {
 	return b.cn.ChainDB()
 }
 
+func (b *CNAPIBackend) Snapshots() *snapshot.Tree {
+	return b.cn.blockchain.Snapshots()
+}
+
 func (b *CNAPIBackend) EventMux() *event.TypeMux {
 	return b.cn.EventMux()
 }
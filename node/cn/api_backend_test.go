@@ -554,6 +554,7 @@ func TestCNAPIBackend_SubscribeEvents(t *testing.T) {
 	csCh := make(chan<- blockchain.ChainSideEvent)
 	leCh := make(chan<- []*types.Log)
 	txCh := make(chan<- blockchain.NewTxsEvent)
+	dtCh := make(chan<- blockchain.DroppedTxsEvent)
 
 	sub := mocks3.NewMockSubscription(mockCtrl)
 
@@ -564,6 +565,7 @@ func TestCNAPIBackend_SubscribeEvents(t *testing.T) {
 	mockBlockChain.EXPECT().SubscribeLogsEvent(leCh).Return(sub).Times(1)
 
 	mockTxPool.EXPECT().SubscribeNewTxsEvent(txCh).Return(sub).Times(1)
+	mockTxPool.EXPECT().SubscribeDroppedTxsEvent(dtCh).Return(sub).Times(1)
 
 	assert.Equal(t, sub, api.SubscribeRemovedLogsEvent(rmCh))
 	assert.Equal(t, sub, api.SubscribeChainEvent(ceCh))
@@ -572,6 +574,7 @@ func TestCNAPIBackend_SubscribeEvents(t *testing.T) {
 	assert.Equal(t, sub, api.SubscribeLogsEvent(leCh))
 
 	assert.Equal(t, sub, api.SubscribeNewTxsEvent(txCh))
+	assert.Equal(t, sub, api.SubscribeDroppedTxsEvent(dtCh))
 }
 
 func TestCNAPIBackend_SendTx(t *testing.T) {
@@ -35,6 +35,7 @@ import (
 	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/blockchain/bloombits"
 	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/state/pruner"
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/hexutil"
@@ -246,18 +247,25 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 			return nil, err
 		}
 	}
+	// Resume the offline state pruning if it was interrupted. It only runs if the
+	// state bloom filter of an interrupted pruning is left in the datadir, and must
+	// be finished before starting, otherwise dangling trie nodes are left behind.
+	if err := pruner.RecoverPruning(ctx.ResolvePath(""), chainDB); err != nil {
+		return nil, fmt.Errorf("failed to resume the interrupted state pruning: %w", err)
+	}
 	var (
 		vmConfig    = config.getVMConfig()
 		cacheConfig = &blockchain.CacheConfig{
-			ArchiveMode:          config.NoPruning,
-			CacheSize:            config.TrieCacheSize,
-			BlockInterval:        config.TrieBlockInterval,
-			TriesInMemory:        config.TriesInMemory,
-			LivePruningRetention: config.LivePruningRetention,
-			TrieNodeCacheConfig:  &config.TrieNodeCacheConfig,
-			SenderTxHashIndexing: config.SenderTxHashIndexing,
-			SnapshotCacheSize:    config.SnapshotCacheSize,
-			SnapshotAsyncGen:     config.SnapshotAsyncGen,
+			ArchiveMode:           config.NoPruning,
+			CacheSize:             config.TrieCacheSize,
+			BlockInterval:         config.TrieBlockInterval,
+			TriesInMemory:         config.TriesInMemory,
+			LivePruningRetention:  config.LivePruningRetention,
+			TrieNodeCacheConfig:   &config.TrieNodeCacheConfig,
+			SenderTxHashIndexing:  config.SenderTxHashIndexing,
+			SnapshotCacheSize:     config.SnapshotCacheSize,
+			SnapshotAsyncGen:      config.SnapshotAsyncGen,
+			StateHistoryRetention: config.StateHistoryRetention,
 		}
 	)
 
@@ -287,6 +295,12 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 		logger.Info("Live pruning is disabled because retention is set to zero")
 	}
 
+	if bc.IsStateHistoryEnabled() {
+		logger.Info("State history is enabled", "retention", config.StateHistoryRetention)
+	} else if config.StateHistoryRetention != 0 {
+		logger.Warn("State history is disabled because snapshot is disabled")
+	}
+
 	cn.blockchain = bc
 	governance.SetBlockchain(cn.blockchain)
 	if err := governance.UpdateParams(cn.blockchain.CurrentBlock().NumberU64()); err != nil {
@@ -375,7 +389,7 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 		}
 	} else {
 		// TODO-Kaia improve to handle drop transaction on network traffic in PN and EN
-		cn.miner = work.New(cn, cn.chainConfig, cn.EventMux(), cn.engine, ctx.NodeType(), crypto.PubkeyToAddress(ctx.NodeKey().PublicKey), cn.config.TxResendUseLegacy)
+		cn.miner = work.New(cn, cn.chainConfig, cn.EventMux(), cn.engine, ctx.NodeType(), crypto.PubkeyToAddress(ctx.NodeKey().PublicKey), cn.config.TxResendUseLegacy, cn.config.TxOrdering)
 	}
 
 	// istanbul BFT
@@ -396,6 +410,7 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 	cn.addComponent(cn.APIs())
 	cn.addComponent(cn.ChainDB())
 	cn.addComponent(cn.engine)
+	cn.addComponent(cn.APIBackend)
 
 	if config.AutoRestartFlag {
 		daemonPath := config.DaemonPathFlag
@@ -490,7 +505,9 @@ func CreateDB(ctx *node.ServiceContext, config *Config, name string) database.DB
 	dbc := &database.DBConfig{
 		Dir: name, DBType: config.DBType, ParallelDBWrite: config.ParallelDBWrite, SingleDB: config.SingleDB, NumStateTrieShards: config.NumStateTrieShards,
 		LevelDBCacheSize: config.LevelDBCacheSize, OpenFilesLimit: database.GetOpenFilesLimit(), LevelDBCompression: config.LevelDBCompression,
-		LevelDBBufferPool: config.LevelDBBufferPool, EnableDBPerfMetrics: config.EnableDBPerfMetrics, RocksDBConfig: &config.RocksDBConfig, DynamoDBConfig: &config.DynamoDBConfig,
+		LevelDBBufferPool: config.LevelDBBufferPool, EnableDBPerfMetrics: config.EnableDBPerfMetrics, RocksDBConfig: &config.RocksDBConfig, PebbleDBConfig: &config.PebbleDBConfig, DynamoDBConfig: &config.DynamoDBConfig,
+		EnableAncient: config.EnableAncient, AncientThreshold: config.AncientThreshold, EnableLogIndex: config.EnableLogIndex,
+		CompactReceipts: config.CompactReceipts,
 	}
 	return ctx.OpenDatabase(dbc)
 }
@@ -576,6 +593,11 @@ func (s *CN) APIs() []rpc.API {
 			Service:   tracers.NewUnsafeAPI(s.APIBackend),
 			Public:    false,
 			IPCOnly:   s.config.DisableUnsafeDebug,
+		}, {
+			Namespace: "trace",
+			Version:   "1.0",
+			Service:   tracers.NewTraceAPI(s.APIBackend),
+			Public:    false,
 		}, {
 			Namespace: "net",
 			Version:   "1.0",
@@ -60,6 +60,8 @@ func NewChannelManager(channelSize int) *ChannelManager {
 	channelMgr.RegisterMsgCode(BlockChannel, NewBlockMsg)
 
 	channelMgr.RegisterMsgCode(TxChannel, TxMsg)
+	channelMgr.RegisterMsgCode(TxChannel, TxHashesMsg)
+	channelMgr.RegisterMsgCode(TxChannel, TxRequestMsg)
 
 	channelMgr.RegisterMsgCode(MiscChannel, ReceiptsRequestMsg)
 	channelMgr.RegisterMsgCode(MiscChannel, ReceiptsMsg)
@@ -38,6 +38,7 @@ import (
 	"github.com/klaytn/klaytn/node/cn/gasprice"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/work"
 )
 
 var logger = log.NewModuleLogger(log.NodeCN)
@@ -54,6 +55,9 @@ func GetDefaultConfig() *Config {
 		TrieNodeCacheConfig:  *statedb.GetEmptyTrieNodeCacheConfig(),
 		TriesInMemory:        blockchain.DefaultTriesInMemory,
 		LivePruningRetention: blockchain.DefaultLivePruningRetention,
+		AncientThreshold:     database.DefaultAncientThreshold,
+		CompactReceipts:      true,
+		PebbleDBConfig:       *database.GetDefaultPebbleDBConfig(),
 		GasPrice:             big.NewInt(18 * params.Ston),
 
 		TxPool: blockchain.DefaultTxPoolConfig,
@@ -68,6 +72,7 @@ func GetDefaultConfig() *Config {
 
 		Istanbul:      *istanbul.DefaultConfig,
 		RPCEVMTimeout: 5 * time.Second,
+		TxOrdering:    work.DefaultTxOrderingConfig,
 	}
 }
 
@@ -110,27 +115,33 @@ type Config struct {
 	StartBlockNumber uint64
 
 	// Database options
-	DBType               database.DBType
-	SkipBcVersionCheck   bool `toml:"-"`
-	SingleDB             bool
-	NumStateTrieShards   uint
-	EnableDBPerfMetrics  bool
-	LevelDBCompression   database.LevelDBCompressionType
-	LevelDBBufferPool    bool
-	LevelDBCacheSize     int
-	DynamoDBConfig       database.DynamoDBConfig
-	RocksDBConfig        database.RocksDBConfig
-	TrieCacheSize        int
-	TrieTimeout          time.Duration
-	TrieBlockInterval    uint
-	TriesInMemory        uint64
-	LivePruning          bool
-	LivePruningRetention uint64
-	SenderTxHashIndexing bool
-	ParallelDBWrite      bool
-	TrieNodeCacheConfig  statedb.TrieNodeCacheConfig
-	SnapshotCacheSize    int
-	SnapshotAsyncGen     bool
+	DBType                database.DBType
+	SkipBcVersionCheck    bool `toml:"-"`
+	SingleDB              bool
+	NumStateTrieShards    uint
+	EnableDBPerfMetrics   bool
+	LevelDBCompression    database.LevelDBCompressionType
+	LevelDBBufferPool     bool
+	LevelDBCacheSize      int
+	DynamoDBConfig        database.DynamoDBConfig
+	RocksDBConfig         database.RocksDBConfig
+	PebbleDBConfig        database.PebbleDBConfig
+	TrieCacheSize         int
+	TrieTimeout           time.Duration
+	TrieBlockInterval     uint
+	TriesInMemory         uint64
+	LivePruning           bool
+	LivePruningRetention  uint64
+	StateHistoryRetention uint64
+	SenderTxHashIndexing  bool
+	ParallelDBWrite       bool
+	TrieNodeCacheConfig   statedb.TrieNodeCacheConfig
+	SnapshotCacheSize     int
+	SnapshotAsyncGen      bool
+	EnableAncient         bool
+	AncientThreshold      uint64
+	EnableLogIndex        bool
+	CompactReceipts       bool
 
 	// Mining-related options
 	ServiceChainSigner common.Address `toml:",omitempty"`
@@ -166,6 +177,9 @@ type Config struct {
 	TxResendCount     int
 	TxResendUseLegacy bool
 
+	// Ordering policy of the transactions in the blocks built by the node
+	TxOrdering work.TxOrderingConfig
+
 	// Service Chain
 	NoAccountCreation bool
 
@@ -32,6 +32,7 @@ import (
 	"github.com/klaytn/klaytn/params"
 
 	"github.com/klaytn/klaytn"
+	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/hexutil"
@@ -126,8 +127,8 @@ func (api *PublicFilterAPI) timeoutLoop() {
 // `klay_getFilterChanges` polling method that is also used for log filters.
 func (api *PublicFilterAPI) NewPendingTransactionFilter() rpc.ID {
 	var (
-		pendingTxs   = make(chan []common.Hash)
-		pendingTxSub = api.events.SubscribePendingTxs(pendingTxs)
+		pendingTxs   = make(chan []*types.Transaction)
+		pendingTxSub = api.events.SubscribePendingTxs(nil, pendingTxs)
 	)
 
 	api.filtersMu.Lock()
@@ -137,10 +138,12 @@ func (api *PublicFilterAPI) NewPendingTransactionFilter() rpc.ID {
 	go func() {
 		for {
 			select {
-			case ph := <-pendingTxs:
+			case pTx := <-pendingTxs:
 				api.filtersMu.Lock()
 				if f, found := api.filters[pendingTxSub.ID]; found {
-					f.hashes = append(f.hashes, ph...)
+					for _, tx := range pTx {
+						f.hashes = append(f.hashes, tx.Hash())
+					}
 				}
 				api.filtersMu.Unlock()
 			case <-pendingTxSub.Err():
@@ -157,25 +160,32 @@ func (api *PublicFilterAPI) NewPendingTransactionFilter() rpc.ID {
 
 // NewPendingTransactions creates a subscription that is triggered each time a transaction
 // enters the transaction pool and was signed from one of the transactions this nodes manages.
-func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+// The transactions can be restricted by the given criteria, and are notified with their
+// bodies instead of their hashes if crit.FullTx is set.
+func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context, crit *PendingTxsCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
 
 	rpcSub := notifier.CreateSubscription()
+	fullTx := crit != nil && crit.FullTx
 
 	go func() {
-		txHashes := make(chan []common.Hash, 128)
-		pendingTxSub := api.events.SubscribePendingTxs(txHashes)
+		txs := make(chan []*types.Transaction, 128)
+		pendingTxSub := api.events.SubscribePendingTxs(crit, txs)
 
 		for {
 			select {
-			case hashes := <-txHashes:
+			case pTxs := <-txs:
 				// To keep the original behaviour, send a single tx hash in one notification.
 				// TODO(rjl493456442) Send a batch of tx hashes in one notification
-				for _, h := range hashes {
-					notifier.Notify(rpcSub.ID, h)
+				for _, tx := range pTxs {
+					if fullTx {
+						notifier.Notify(rpcSub.ID, tx.MakeRPCOutputWithLocation(nil, common.Hash{}, 0, 0))
+					} else {
+						notifier.Notify(rpcSub.ID, tx.Hash())
+					}
 				}
 			case <-rpcSub.Err():
 				pendingTxSub.Unsubscribe()
@@ -190,6 +200,51 @@ func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Su
 	return rpcSub, nil
 }
 
+// RPCDroppedTransaction is the notification of the droppedTransactions subscription.
+type RPCDroppedTransaction struct {
+	Hash        common.Hash             `json:"hash"`
+	Reason      blockchain.TxDropReason `json:"reason"`
+	Replacement *common.Hash            `json:"replacement,omitempty"`
+}
+
+// DroppedTransactions creates a subscription that is triggered each time a transaction
+// is removed from the transaction pool without being executed, with the reason of it.
+func (api *PublicFilterAPI) DroppedTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		drops := make(chan blockchain.DroppedTxsEvent, 128)
+		droppedTxSub := api.events.SubscribeDroppedTxs(drops)
+
+		for {
+			select {
+			case ev := <-drops:
+				var replacement *common.Hash
+				if ev.Replacement != nil {
+					hash := ev.Replacement.Hash()
+					replacement = &hash
+				}
+				for _, tx := range ev.Txs {
+					notifier.Notify(rpcSub.ID, &RPCDroppedTransaction{Hash: tx.Hash(), Reason: ev.Reason, Replacement: replacement})
+				}
+			case <-rpcSub.Err():
+				droppedTxSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				droppedTxSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
 // It is part of the filter package since polling goes with eth_getFilterChanges.
 func (api *PublicFilterAPI) NewBlockFilter() rpc.ID {
@@ -327,6 +382,66 @@ func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc
 	return rpcSub, nil
 }
 
+// PendingTxsCriteria restricts the transactions notified by the newPendingTransactions
+// subscription. The empty lists and the nil FeeDelegated do not restrict them.
+type PendingTxsCriteria struct {
+	FullTx       bool             `json:"fullTx"`       // If true, the bodies of the transactions are notified instead of the hashes
+	From         []common.Address `json:"from"`         // Senders of the transactions
+	To           []common.Address `json:"to"`           // Recipients of the transactions
+	TxTypes      []types.TxType   `json:"txTypes"`      // Types of the transactions, e.g. 8 for TxTypeValueTransfer
+	FeeDelegated *bool            `json:"feeDelegated"` // Whether the transactions are fee-delegated or not
+}
+
+// UnmarshalJSON sets *crit fields with given data. A bare boolean is taken as
+// FullTx, as the newPendingTransactions subscription of Ethereum accepts it.
+func (crit *PendingTxsCriteria) UnmarshalJSON(data []byte) error {
+	var fullTx bool
+	if err := json.Unmarshal(data, &fullTx); err == nil {
+		*crit = PendingTxsCriteria{FullTx: fullTx}
+		return nil
+	}
+	type input PendingTxsCriteria
+	var raw input
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*crit = PendingTxsCriteria(raw)
+	return nil
+}
+
+// filter returns the transactions matching the criteria.
+func (crit *PendingTxsCriteria) filter(txs []*types.Transaction) []*types.Transaction {
+	if crit == nil || (len(crit.From) == 0 && len(crit.To) == 0 && len(crit.TxTypes) == 0 && crit.FeeDelegated == nil) {
+		return txs
+	}
+	var ret []*types.Transaction
+	for _, tx := range txs {
+		if len(crit.From) > 0 && !includes(crit.From, tx.RPCSender()) {
+			continue
+		}
+		if len(crit.To) > 0 && (tx.To() == nil || !includes(crit.To, *tx.To())) {
+			continue
+		}
+		if len(crit.TxTypes) > 0 && !includesTxType(crit.TxTypes, tx.Type()) {
+			continue
+		}
+		if crit.FeeDelegated != nil && *crit.FeeDelegated != tx.IsFeeDelegatedTransaction() {
+			continue
+		}
+		ret = append(ret, tx)
+	}
+	return ret
+}
+
+func includesTxType(txTypes []types.TxType, t types.TxType) bool {
+	for _, txType := range txTypes {
+		if txType == t {
+			return true
+		}
+	}
+	return false
+}
+
 // FilterCriteria represents a request to create a new filter.
 // Same as Kaia.FilterQuery but with UnmarshalJSON() method.
 type FilterCriteria klaytn.FilterQuery
@@ -48,6 +48,7 @@ type Backend interface {
 	GetLogs(ctx context.Context, blockHash common.Hash) ([][]*types.Log, error)
 
 	SubscribeNewTxsEvent(chan<- blockchain.NewTxsEvent) event.Subscription
+	SubscribeDroppedTxsEvent(chan<- blockchain.DroppedTxsEvent) event.Subscription
 	SubscribeChainEvent(ch chan<- blockchain.ChainEvent) event.Subscription
 	SubscribeRemovedLogsEvent(ch chan<- blockchain.RemovedLogsEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
@@ -152,7 +153,35 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	if f.end == -1 {
 		end = head
 	}
-	// Gather all indexed logs, and finish with non indexed ones
+	// Use the log index in place of the bloom bits for the blocks it covers
+	if tail, next, ok := f.backend.ChainDB().ReadLogIndexRange(); ok && f.hasCriteria() && next > tail && next > uint64(f.begin) {
+		var (
+			logs []*types.Log
+			err  error
+		)
+		if tail > uint64(f.begin) {
+			if logs, err = f.bloomLogs(ctx, min(end, tail-1)); err != nil {
+				return logs, err
+			}
+		}
+		if uint64(f.begin) <= end {
+			found, err := f.logIndexLogs(ctx, min(end, next-1))
+			logs = append(logs, found...)
+			if err != nil {
+				return logs, err
+			}
+		}
+		rest, err := f.unindexedLogs(ctx, end)
+		logs = append(logs, rest...)
+		return logs, err
+	}
+	return f.bloomLogs(ctx, end)
+}
+
+// bloomLogs returns the logs matching the filter criteria up to the given
+// block, gathering the indexed logs with the bloom bits first and finishing
+// with the non indexed ones.
+func (f *Filter) bloomLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
 	var (
 		logs []*types.Log
 		err  error
@@ -173,6 +202,120 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	return logs, err
 }
 
+// hasCriteria returns true if the filter restricts the addresses or topics,
+// which is required to look up the log index.
+func (f *Filter) hasCriteria() bool {
+	if len(f.addresses) > 0 {
+		return true
+	}
+	for _, topicList := range f.topics {
+		if len(topicList) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// logIndexLogs returns the logs matching the filter criteria based on the log
+// index, which lists the blocks having logs of each address and topic.
+func (f *Filter) logIndexLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
+	var (
+		db         = f.backend.ChainDB()
+		begin      = uint64(f.begin)
+		candidates []uint64
+		first      = true
+	)
+	// Blocks must match any of each list, and all of the non-empty lists.
+	restrict := func(numbers []uint64) {
+		if first {
+			candidates, first = numbers, false
+		} else {
+			candidates = intersectNumbers(candidates, numbers)
+		}
+	}
+	if len(f.addresses) > 0 {
+		var numbers []uint64
+		for _, address := range f.addresses {
+			numbers = unionNumbers(numbers, db.ReadLogIndexByAddress(address, begin, end))
+		}
+		restrict(numbers)
+	}
+	for i, topicList := range f.topics {
+		if len(topicList) == 0 {
+			continue
+		}
+		var numbers []uint64
+		for _, topic := range topicList {
+			numbers = unionNumbers(numbers, db.ReadLogIndexByTopic(i, topic, begin, end))
+		}
+		restrict(numbers)
+	}
+
+	var logs []*types.Log
+
+	maxItems := getMaxItems(ctx)
+
+	for _, number := range candidates {
+		f.begin = int64(number) + 1
+
+		header, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if header == nil || err != nil {
+			return logs, err
+		}
+		found, err := f.checkMatches(ctx, header)
+		if err != nil {
+			return logs, err
+		}
+		logs = append(logs, found...)
+		if len(logs) > maxItems {
+			return logs, errors.New("query returned more than " + strconv.Itoa(maxItems) + " results")
+		}
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return logs, errors.New("query timeout exceeded")
+			}
+			return logs, errors.New("query is canceled. " + ctx.Err().Error())
+		default:
+		}
+	}
+	f.begin = int64(end) + 1
+	return logs, nil
+}
+
+// unionNumbers merges two ascending lists of block numbers.
+func unionNumbers(a, b []uint64) []uint64 {
+	merged := make([]uint64, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			merged, a = append(merged, a[0]), a[1:]
+		case a[0] > b[0]:
+			merged, b = append(merged, b[0]), b[1:]
+		default:
+			merged, a, b = append(merged, a[0]), a[1:], b[1:]
+		}
+	}
+	merged = append(merged, a...)
+	return append(merged, b...)
+}
+
+// intersectNumbers returns the block numbers in both of the ascending lists.
+func intersectNumbers(a, b []uint64) []uint64 {
+	var both []uint64
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			a = a[1:]
+		case a[0] > b[0]:
+			b = b[1:]
+		default:
+			both, a, b = append(both, a[0]), a[1:], b[1:]
+		}
+	}
+	return both
+}
+
 // blockLogs returns the logs matching the filter criteria within a single block.
 func (f *Filter) blockLogs(ctx context.Context, header *types.Header) (logs []*types.Log, err error) {
 	if bloomFilter(header.Bloom, f.addresses, f.topics) {
@@ -54,6 +54,9 @@ const (
 	PendingTransactionsSubscription
 	// BlocksSubscription queries hashes for blocks that are imported
 	BlocksSubscription
+	// DroppedTransactionsSubscription queries transactions removed from the
+	// transaction pool without being executed
+	DroppedTransactionsSubscription
 	// LastSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -63,6 +66,8 @@ const (
 	// txChanSize is the size of channel listening to NewTxsEvent.
 	// The number is referenced from the size of tx pool.
 	txChanSize = 4096
+	// droppedTxChanSize is the size of channel listening to DroppedTxsEvent.
+	droppedTxChanSize = 4096
 	// rmLogsChanSize is the size of channel listening to RemovedLogsEvent.
 	rmLogsChanSize = 10
 	// logsChanSize is the size of channel listening to LogsEvent.
@@ -81,8 +86,10 @@ type subscription struct {
 	typ       Type
 	created   time.Time
 	logsCrit  klaytn.FilterQuery
+	txsCrit   *PendingTxsCriteria
 	logs      chan []*types.Log
-	hashes    chan []common.Hash
+	txs       chan []*types.Transaction
+	drops     chan blockchain.DroppedTxsEvent
 	headers   chan *types.Header
 	installed chan struct{} // closed when the filter is installed
 	err       chan error    // closed when the filter is uninstalled
@@ -98,6 +105,7 @@ type EventSystem struct {
 
 	// Subscriptions
 	txsSub        event.Subscription         // Subscription for new transaction event
+	droppedTxsSub event.Subscription         // Subscription for dropped transaction event
 	logsSub       event.Subscription         // Subscription for new log event
 	rmLogsSub     event.Subscription         // Subscription for removed log event
 	chainSub      event.Subscription         // Subscription for new chain event
@@ -107,6 +115,7 @@ type EventSystem struct {
 	install   chan *subscription               // install filter for event notification
 	uninstall chan *subscription               // remove filter for event notification
 	txsCh     chan blockchain.NewTxsEvent      // Channel to receive new transactions event
+	droppedCh chan blockchain.DroppedTxsEvent  // Channel to receive dropped transactions event
 	logsCh    chan []*types.Log                // Channel to receive new log event
 	rmLogsCh  chan blockchain.RemovedLogsEvent // Channel to receive removed log event
 	chainCh   chan blockchain.ChainEvent       // Channel to receive new chain event
@@ -126,6 +135,7 @@ func NewEventSystem(mux *event.TypeMux, backend Backend, lightMode bool) *EventS
 		install:   make(chan *subscription),
 		uninstall: make(chan *subscription),
 		txsCh:     make(chan blockchain.NewTxsEvent, txChanSize),
+		droppedCh: make(chan blockchain.DroppedTxsEvent, droppedTxChanSize),
 		logsCh:    make(chan []*types.Log, logsChanSize),
 		rmLogsCh:  make(chan blockchain.RemovedLogsEvent, rmLogsChanSize),
 		chainCh:   make(chan blockchain.ChainEvent, chainEvChanSize),
@@ -133,6 +143,7 @@ func NewEventSystem(mux *event.TypeMux, backend Backend, lightMode bool) *EventS
 
 	// Subscribe events
 	m.txsSub = m.backend.SubscribeNewTxsEvent(m.txsCh)
+	m.droppedTxsSub = m.backend.SubscribeDroppedTxsEvent(m.droppedCh)
 	m.logsSub = m.backend.SubscribeLogsEvent(m.logsCh)
 	m.rmLogsSub = m.backend.SubscribeRemovedLogsEvent(m.rmLogsCh)
 	m.chainSub = m.backend.SubscribeChainEvent(m.chainCh)
@@ -140,7 +151,7 @@ func NewEventSystem(mux *event.TypeMux, backend Backend, lightMode bool) *EventS
 	m.pendingLogSub = m.mux.Subscribe(blockchain.PendingLogsEvent{})
 
 	// Make sure none of the subscriptions are empty
-	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil ||
+	if m.txsSub == nil || m.droppedTxsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil ||
 		m.pendingLogSub.Closed() {
 		logger.Crit("Subscribe for event system failed")
 	}
@@ -167,7 +178,7 @@ func (sub *Subscription) Unsubscribe() {
 	sub.unsubOnce.Do(func() {
 	uninstallLoop:
 		for {
-			// write uninstall request and consume logs/hashes. This prevents
+			// write uninstall request and consume logs/txs. This prevents
 			// the eventLoop broadcast method to deadlock when writing to the
 			// filter event channel while the subscription loop is waiting for
 			// this method to return (and thus not reading these events).
@@ -175,7 +186,8 @@ func (sub *Subscription) Unsubscribe() {
 			case sub.es.uninstall <- sub.f:
 				break uninstallLoop
 			case <-sub.f.logs:
-			case <-sub.f.hashes:
+			case <-sub.f.txs:
+			case <-sub.f.drops:
 			case <-sub.f.headers:
 			}
 		}
@@ -242,7 +254,8 @@ func (es *EventSystem) subscribeMinedPendingLogs(crit klaytn.FilterQuery, logs c
 		logsCrit:  crit,
 		created:   time.Now(),
 		logs:      logs,
-		hashes:    make(chan []common.Hash),
+		txs:       make(chan []*types.Transaction),
+		drops:     make(chan blockchain.DroppedTxsEvent),
 		headers:   make(chan *types.Header),
 		installed: make(chan struct{}),
 		err:       make(chan error),
@@ -259,7 +272,8 @@ func (es *EventSystem) subscribeLogs(crit klaytn.FilterQuery, logs chan []*types
 		logsCrit:  crit,
 		created:   time.Now(),
 		logs:      logs,
-		hashes:    make(chan []common.Hash),
+		txs:       make(chan []*types.Transaction),
+		drops:     make(chan blockchain.DroppedTxsEvent),
 		headers:   make(chan *types.Header),
 		installed: make(chan struct{}),
 		err:       make(chan error),
@@ -276,7 +290,8 @@ func (es *EventSystem) subscribePendingLogs(crit klaytn.FilterQuery, logs chan [
 		logsCrit:  crit,
 		created:   time.Now(),
 		logs:      logs,
-		hashes:    make(chan []common.Hash),
+		txs:       make(chan []*types.Transaction),
+		drops:     make(chan blockchain.DroppedTxsEvent),
 		headers:   make(chan *types.Header),
 		installed: make(chan struct{}),
 		err:       make(chan error),
@@ -292,7 +307,8 @@ func (es *EventSystem) SubscribeNewHeads(headers chan *types.Header) *Subscripti
 		typ:       BlocksSubscription,
 		created:   time.Now(),
 		logs:      make(chan []*types.Log),
-		hashes:    make(chan []common.Hash),
+		txs:       make(chan []*types.Transaction),
+		drops:     make(chan blockchain.DroppedTxsEvent),
 		headers:   headers,
 		installed: make(chan struct{}),
 		err:       make(chan error),
@@ -300,15 +316,35 @@ func (es *EventSystem) SubscribeNewHeads(headers chan *types.Header) *Subscripti
 	return es.subscribe(sub)
 }
 
-// SubscribePendingTxs creates a subscription that writes transaction hashes for
-// transactions that enter the transaction pool.
-func (es *EventSystem) SubscribePendingTxs(hashes chan []common.Hash) *Subscription {
+// SubscribePendingTxs creates a subscription that writes transactions that
+// enter the transaction pool and match the given criteria. A nil criteria
+// matches all transactions.
+func (es *EventSystem) SubscribePendingTxs(crit *PendingTxsCriteria, txs chan []*types.Transaction) *Subscription {
 	sub := &subscription{
 		id:        rpc.NewID(),
 		typ:       PendingTransactionsSubscription,
 		created:   time.Now(),
+		txsCrit:   crit,
 		logs:      make(chan []*types.Log),
-		hashes:    hashes,
+		txs:       txs,
+		drops:     make(chan blockchain.DroppedTxsEvent),
+		headers:   make(chan *types.Header),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+// SubscribeDroppedTxs creates a subscription that writes transactions removed
+// from the transaction pool without being executed.
+func (es *EventSystem) SubscribeDroppedTxs(drops chan blockchain.DroppedTxsEvent) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       DroppedTransactionsSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		txs:       make(chan []*types.Transaction),
+		drops:     drops,
 		headers:   make(chan *types.Header),
 		installed: make(chan struct{}),
 		err:       make(chan error),
@@ -351,12 +387,14 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 			}
 		}
 	case blockchain.NewTxsEvent:
-		hashes := make([]common.Hash, 0, len(e.Txs))
-		for _, tx := range e.Txs {
-			hashes = append(hashes, tx.Hash())
-		}
 		for _, f := range filters[PendingTransactionsSubscription] {
-			f.hashes <- hashes
+			if matchedTxs := f.txsCrit.filter(e.Txs); len(matchedTxs) > 0 {
+				f.txs <- matchedTxs
+			}
+		}
+	case blockchain.DroppedTxsEvent:
+		for _, f := range filters[DroppedTransactionsSubscription] {
+			f.drops <- e
 		}
 	case blockchain.ChainEvent:
 		for _, f := range filters[BlocksSubscription] {
@@ -450,6 +488,7 @@ func (es *EventSystem) eventLoop() {
 	defer func() {
 		es.pendingLogSub.Unsubscribe()
 		es.txsSub.Unsubscribe()
+		es.droppedTxsSub.Unsubscribe()
 		es.logsSub.Unsubscribe()
 		es.rmLogsSub.Unsubscribe()
 		es.chainSub.Unsubscribe()
@@ -465,6 +504,8 @@ func (es *EventSystem) eventLoop() {
 		// Handle subscribed events
 		case ev := <-es.txsCh:
 			es.broadcast(index, ev)
+		case ev := <-es.droppedCh:
+			es.broadcast(index, ev)
 		case ev := <-es.logsCh:
 			es.broadcast(index, ev)
 		case ev := <-es.rmLogsCh:
@@ -500,6 +541,8 @@ func (es *EventSystem) eventLoop() {
 			// System stopped
 		case <-es.txsSub.Err():
 			return
+		case <-es.droppedTxsSub.Err():
+			return
 		case <-es.logsSub.Err():
 			return
 		case <-es.rmLogsSub.Err():
@@ -51,6 +51,7 @@ type testBackend struct {
 	logsFeed    *event.Feed
 	chainFeed   *event.Feed
 	chainConfig *params.ChainConfig
+	droppedFeed *event.Feed
 }
 
 /*
@@ -121,6 +122,10 @@ func (b *testBackend) SubscribeNewTxsEvent(ch chan<- blockchain.NewTxsEvent) eve
 	return b.txFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeDroppedTxsEvent(ch chan<- blockchain.DroppedTxsEvent) event.Subscription {
+	return b.droppedFeed.Subscribe(ch)
+}
+
 func (b *testBackend) SubscribeRemovedLogsEvent(ch chan<- blockchain.RemovedLogsEvent) event.Subscription {
 	return b.rmLogsFeed.Subscribe(ch)
 }
@@ -183,7 +188,7 @@ func TestBlockSubscription(t *testing.T) {
 		rmLogsFeed  = new(event.Feed)
 		logsFeed    = new(event.Feed)
 		chainFeed   = new(event.Feed)
-		backend     = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig}
+		backend     = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig, new(event.Feed)}
 		api         = NewPublicFilterAPI(backend, false)
 		genesis     = new(blockchain.Genesis).MustCommit(db)
 		chain, _    = blockchain.GenerateChain(params.TestChainConfig, genesis, gxhash.NewFaker(), db, 10, func(i int, gen *blockchain.BlockGen) {})
@@ -240,7 +245,7 @@ func TestPendingTxFilter(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		transactions = []*types.Transaction{
@@ -290,6 +295,181 @@ func TestPendingTxFilter(t *testing.T) {
 	}
 }
 
+// TestPendingTxsCriteria tests whether the pending transactions are filtered
+// by the given criteria.
+func TestPendingTxsCriteria(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mux        = new(event.TypeMux)
+		db         = database.NewMemoryDBManager()
+		txFeed     = new(event.Feed)
+		rmLogsFeed = new(event.Feed)
+		logsFeed   = new(event.Feed)
+		chainFeed  = new(event.Feed)
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig, new(event.Feed)}
+		api        = NewPublicFilterAPI(backend, false)
+
+		to0          = common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268")
+		to1          = common.HexToAddress("0x7fa9385be102ac3eac297483dd6233d62b3e1496")
+		notDelegated = false
+
+		transactions = []*types.Transaction{
+			types.NewTransaction(0, to0, new(big.Int), 0, new(big.Int), nil),
+			types.NewTransaction(1, to1, new(big.Int), 0, new(big.Int), nil),
+			types.NewTransaction(2, to0, new(big.Int), 0, new(big.Int), nil),
+			types.NewContractCreation(3, new(big.Int), 0, new(big.Int), nil),
+		}
+	)
+
+	testCases := []struct {
+		crit     *PendingTxsCriteria
+		expected []*types.Transaction
+	}{
+		{nil, transactions},
+		{&PendingTxsCriteria{FullTx: true}, transactions},
+		{&PendingTxsCriteria{To: []common.Address{to0}}, []*types.Transaction{transactions[0], transactions[2]}},
+		{&PendingTxsCriteria{To: []common.Address{to0, to1}}, transactions[:3]},
+		{&PendingTxsCriteria{TxTypes: []types.TxType{types.TxTypeLegacyTransaction}}, transactions},
+		{&PendingTxsCriteria{TxTypes: []types.TxType{types.TxTypeValueTransfer}}, nil},
+		{&PendingTxsCriteria{FeeDelegated: &notDelegated}, transactions},
+	}
+
+	chans := make([]chan []*types.Transaction, len(testCases))
+	for i, tc := range testCases {
+		chans[i] = make(chan []*types.Transaction, 1)
+		sub := api.events.SubscribePendingTxs(tc.crit, chans[i])
+		defer sub.Unsubscribe()
+	}
+
+	time.Sleep(1 * time.Second)
+	txFeed.Send(blockchain.NewTxsEvent{Txs: transactions})
+
+	for i, tc := range testCases {
+		if len(tc.expected) == 0 {
+			select {
+			case txs := <-chans[i]:
+				t.Errorf("test %d: unexpected transactions %v", i, txs)
+			case <-time.After(100 * time.Millisecond):
+			}
+			continue
+		}
+		select {
+		case txs := <-chans[i]:
+			if !reflect.DeepEqual(txs, tc.expected) {
+				t.Errorf("test %d: invalid transactions, want %v, got %v", i, tc.expected, txs)
+			}
+		case <-time.After(1 * time.Second):
+			t.Errorf("test %d: pending transactions timeout", i)
+		}
+	}
+}
+
+// TestPendingTxsSubscriptionBool tests that the newPendingTransactions subscription
+// accepts a bare boolean as FullTx, as in Ethereum.
+func TestPendingTxsSubscriptionBool(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mux        = new(event.TypeMux)
+		db         = database.NewMemoryDBManager()
+		txFeed     = new(event.Feed)
+		rmLogsFeed = new(event.Feed)
+		logsFeed   = new(event.Feed)
+		chainFeed  = new(event.Feed)
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig, new(event.Feed)}
+		api        = NewPublicFilterAPI(backend, false)
+
+		tx = types.NewTransaction(0, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, new(big.Int), nil)
+	)
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", api); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	fullTxs := make(chan map[string]interface{}, 1)
+	fullSub, err := client.Subscribe(context.Background(), "eth", fullTxs, "newPendingTransactions", true)
+	if err != nil {
+		t.Fatalf("failed to subscribe with true: %v", err)
+	}
+	defer fullSub.Unsubscribe()
+
+	hashes := make(chan common.Hash, 1)
+	hashSub, err := client.Subscribe(context.Background(), "eth", hashes, "newPendingTransactions", false)
+	if err != nil {
+		t.Fatalf("failed to subscribe with false: %v", err)
+	}
+	defer hashSub.Unsubscribe()
+
+	time.Sleep(1 * time.Second)
+	txFeed.Send(blockchain.NewTxsEvent{Txs: []*types.Transaction{tx}})
+
+	select {
+	case fields := <-fullTxs:
+		if fields["hash"] != tx.Hash().Hex() {
+			t.Errorf("invalid transaction, want hash %x, got %v", tx.Hash(), fields)
+		}
+	case err := <-fullSub.Err():
+		t.Fatal(err)
+	case <-time.After(1 * time.Second):
+		t.Error("pending transaction timeout")
+	}
+	select {
+	case hash := <-hashes:
+		if hash != tx.Hash() {
+			t.Errorf("invalid transaction hash, want %x, got %x", tx.Hash(), hash)
+		}
+	case err := <-hashSub.Err():
+		t.Fatal(err)
+	case <-time.After(1 * time.Second):
+		t.Error("pending transaction hash timeout")
+	}
+}
+
+// TestDroppedTxs tests whether the transactions dropped from the pool are
+// delivered to the subscribers.
+func TestDroppedTxs(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mux         = new(event.TypeMux)
+		db          = database.NewMemoryDBManager()
+		txFeed      = new(event.Feed)
+		rmLogsFeed  = new(event.Feed)
+		logsFeed    = new(event.Feed)
+		chainFeed   = new(event.Feed)
+		droppedFeed = new(event.Feed)
+		backend     = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig, droppedFeed}
+		api         = NewPublicFilterAPI(backend, false)
+
+		ev = blockchain.DroppedTxsEvent{
+			Txs: []*types.Transaction{
+				types.NewTransaction(0, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, new(big.Int), nil),
+			},
+			Reason: blockchain.TxDropExpired,
+		}
+	)
+
+	drops := make(chan blockchain.DroppedTxsEvent)
+	sub := api.events.SubscribeDroppedTxs(drops)
+	defer sub.Unsubscribe()
+
+	time.Sleep(1 * time.Second)
+	droppedFeed.Send(ev)
+
+	select {
+	case received := <-drops:
+		if !reflect.DeepEqual(received, ev) {
+			t.Errorf("invalid dropped transactions event, want %v, got %v", ev, received)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("dropped transactions event timeout")
+	}
+}
+
 // TestLogFilterCreation test whether a given filter criteria makes sense.
 // If not it must return an error.
 func TestLogFilterCreation(t *testing.T) {
@@ -300,7 +480,7 @@ func TestLogFilterCreation(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		testCases = []struct {
@@ -349,7 +529,7 @@ func TestInvalidLogFilterCreation(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 	)
 
@@ -376,7 +556,7 @@ func TestInvalidGetLogsRequest(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 		blockHash  = common.HexToHash("0x1111111111111111111111111111111111111111111111111111111111111111")
 	)
@@ -406,7 +586,7 @@ func TestLogFilter(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		firstAddr      = common.HexToAddress("0x1111111111111111111111111111111111111111")
@@ -525,7 +705,7 @@ func TestPendingLogsSubscription(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		firstAddr      = common.HexToAddress("0x1111111111111111111111111111111111111111")
@@ -645,7 +825,7 @@ func TestPendingTxFilterDeadlock(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig, new(event.Feed)}
 		done       = make(chan struct{})
 	)
 
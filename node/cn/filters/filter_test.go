@@ -175,7 +175,7 @@ func BenchmarkFilters(b *testing.B) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig, new(event.Feed)}
 		key1, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 		addr1      = crypto.PubkeyToAddress(key1.PublicKey)
 		addr2      = common.BytesToAddress([]byte("jeff"))
@@ -231,14 +231,29 @@ func genReceipt(failed bool, cumulativeGasUsed uint64) *types.Receipt {
 }
 
 func TestFilters(t *testing.T) {
+	testFilters(t, database.NewMemoryDBManager())
+}
+
+// Tests that the filters return the same logs when the log index is used in
+// place of the bloom bits.
+func TestFilters_LogIndex(t *testing.T) {
+	db := database.NewDBManager(&database.DBConfig{DBType: database.MemoryDB, SingleDB: true, EnableLogIndex: true})
+	testFilters(t, db)
+
+	tail, next, ok := db.ReadLogIndexRange()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(0), tail)
+	assert.Equal(t, uint64(1001), next)
+}
+
+func testFilters(t *testing.T, db database.DBManager) {
 	var (
-		db         = database.NewMemoryDBManager()
 		mux        = new(event.TypeMux)
 		txFeed     = new(event.Feed)
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, params.TestChainConfig, new(event.Feed)}
 		key1, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 		addr       = crypto.PubkeyToAddress(key1.PublicKey)
 
@@ -184,6 +184,20 @@ func (mr *MockBackendMockRecorder) SubscribeChainEvent(ch interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeChainEvent", reflect.TypeOf((*MockBackend)(nil).SubscribeChainEvent), ch)
 }
 
+// SubscribeDroppedTxsEvent mocks base method.
+func (m *MockBackend) SubscribeDroppedTxsEvent(arg0 chan<- blockchain.DroppedTxsEvent) event.Subscription {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeDroppedTxsEvent", arg0)
+	ret0, _ := ret[0].(event.Subscription)
+	return ret0
+}
+
+// SubscribeDroppedTxsEvent indicates an expected call of SubscribeDroppedTxsEvent.
+func (mr *MockBackendMockRecorder) SubscribeDroppedTxsEvent(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeDroppedTxsEvent", reflect.TypeOf((*MockBackend)(nil).SubscribeDroppedTxsEvent), arg0)
+}
+
 // SubscribeLogsEvent mocks base method.
 func (m *MockBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	m.ctrl.T.Helper()
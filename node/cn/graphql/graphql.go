@@ -0,0 +1,758 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package graphql provides a GraphQL interface to Klaytn node data.
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/klaytn/klaytn/api"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// maxBlockRange is the maximum number of blocks a single blocks or logs query is
+// allowed to scan.
+const maxBlockRange = 1000
+
+var errBlockRange = errors.New("the start block must not be greater than the end block")
+
+// Account represents a Klaytn account at a particular block.
+type Account struct {
+	backend       api.Backend
+	address       common.Address
+	blockNrOrHash rpc.BlockNumberOrHash
+}
+
+// newAccount returns the account of the given address at the given block.
+// The latest block is used if the block is not given.
+func newAccount(backend api.Backend, address common.Address, block *hexutil.Uint64) *Account {
+	blockNrOrHash := rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if block != nil {
+		blockNrOrHash = rpc.NewBlockNumberOrHashWithNumber(rpc.BlockNumber(*block))
+	}
+	return &Account{backend: backend, address: address, blockNrOrHash: blockNrOrHash}
+}
+
+func (a *Account) Address(ctx context.Context) (common.Address, error) {
+	return a.address, nil
+}
+
+func (a *Account) Balance(ctx context.Context) (hexutil.Big, error) {
+	state, _, err := a.backend.StateAndHeaderByNumberOrHash(ctx, a.blockNrOrHash)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*state.GetBalance(a.address)), state.Error()
+}
+
+func (a *Account) TransactionCount(ctx context.Context) (hexutil.Uint64, error) {
+	state, _, err := a.backend.StateAndHeaderByNumberOrHash(ctx, a.blockNrOrHash)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(state.GetNonce(a.address)), state.Error()
+}
+
+func (a *Account) Code(ctx context.Context) (hexutil.Bytes, error) {
+	state, _, err := a.backend.StateAndHeaderByNumberOrHash(ctx, a.blockNrOrHash)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return state.GetCode(a.address), state.Error()
+}
+
+func (a *Account) Storage(ctx context.Context, args struct{ Slot common.Hash }) (common.Hash, error) {
+	state, _, err := a.backend.StateAndHeaderByNumberOrHash(ctx, a.blockNrOrHash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return state.GetState(a.address, args.Slot), state.Error()
+}
+
+func (a *Account) AccountKey(ctx context.Context) (*AccountKey, error) {
+	state, _, err := a.backend.StateAndHeaderByNumberOrHash(ctx, a.blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountKey{key: state.GetKey(a.address)}, state.Error()
+}
+
+// AccountKey represents the key of a Klaytn account.
+type AccountKey struct {
+	key accountkey.AccountKey
+}
+
+func (k *AccountKey) KeyType(ctx context.Context) int32 {
+	return int32(k.key.Type())
+}
+
+func (k *AccountKey) Raw(ctx context.Context) (hexutil.Bytes, error) {
+	return rlp.EncodeToBytes(accountkey.NewAccountKeySerializerWithAccountKey(k.key))
+}
+
+// Log represents an individual log message. All arguments are mandatory.
+type Log struct {
+	backend     api.Backend
+	transaction *Transaction
+	log         *types.Log
+}
+
+func (l *Log) Transaction(ctx context.Context) *Transaction {
+	return l.transaction
+}
+
+func (l *Log) Account(ctx context.Context, args struct{ Block *hexutil.Uint64 }) *Account {
+	return newAccount(l.backend, l.log.Address, args.Block)
+}
+
+func (l *Log) Index(ctx context.Context) int32 {
+	return int32(l.log.Index)
+}
+
+func (l *Log) Topics(ctx context.Context) []common.Hash {
+	return l.log.Topics
+}
+
+func (l *Log) Data(ctx context.Context) hexutil.Bytes {
+	return l.log.Data
+}
+
+// Transaction represents a Klaytn transaction. The block is nil if the
+// transaction is still in the transaction pool.
+type Transaction struct {
+	backend api.Backend
+	tx      *types.Transaction
+	block   *Block
+	index   uint64
+}
+
+func (t *Transaction) Hash(ctx context.Context) common.Hash {
+	return t.tx.Hash()
+}
+
+func (t *Transaction) Type(ctx context.Context) int32 {
+	return int32(t.tx.Type())
+}
+
+func (t *Transaction) TypeName(ctx context.Context) string {
+	return t.tx.Type().String()
+}
+
+func (t *Transaction) Nonce(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(t.tx.Nonce())
+}
+
+func (t *Transaction) Index(ctx context.Context) *int32 {
+	if t.block == nil {
+		return nil
+	}
+	index := int32(t.index)
+	return &index
+}
+
+func (t *Transaction) From(ctx context.Context, args struct{ Block *hexutil.Uint64 }) (*Account, error) {
+	from, err := t.sender()
+	if err != nil {
+		return nil, err
+	}
+	return newAccount(t.backend, from, args.Block), nil
+}
+
+func (t *Transaction) To(ctx context.Context, args struct{ Block *hexutil.Uint64 }) *Account {
+	to := t.tx.To()
+	if to == nil {
+		return nil
+	}
+	return newAccount(t.backend, *to, args.Block)
+}
+
+func (t *Transaction) Value(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*t.tx.Value())
+}
+
+func (t *Transaction) GasPrice(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*t.tx.GasPrice())
+}
+
+func (t *Transaction) Gas(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(t.tx.Gas())
+}
+
+func (t *Transaction) InputData(ctx context.Context) hexutil.Bytes {
+	return t.tx.Data()
+}
+
+func (t *Transaction) FeePayer(ctx context.Context, args struct{ Block *hexutil.Uint64 }) (*Account, error) {
+	if !t.tx.IsFeeDelegatedTransaction() {
+		return t.From(ctx, args)
+	}
+	feePayer, err := t.tx.FeePayer()
+	if err != nil {
+		return nil, err
+	}
+	return newAccount(t.backend, feePayer, args.Block), nil
+}
+
+func (t *Transaction) FeeRatio(ctx context.Context) *int32 {
+	feeRatio, ok := t.tx.FeeRatio()
+	if !ok {
+		return nil
+	}
+	ratio := int32(feeRatio)
+	return &ratio
+}
+
+func (t *Transaction) Block(ctx context.Context) *Block {
+	return t.block
+}
+
+func (t *Transaction) Status(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.receipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	status := hexutil.Uint64(types.ReceiptStatusFailed)
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		status = hexutil.Uint64(types.ReceiptStatusSuccessful)
+	}
+	return &status, nil
+}
+
+func (t *Transaction) GasUsed(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.receipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	gasUsed := hexutil.Uint64(receipt.GasUsed)
+	return &gasUsed, nil
+}
+
+func (t *Transaction) EffectiveGasPrice(ctx context.Context) *hexutil.Big {
+	if t.block == nil {
+		return nil
+	}
+	return (*hexutil.Big)(t.tx.EffectiveGasPrice(t.block.block.Header()))
+}
+
+func (t *Transaction) CreatedContract(ctx context.Context, args struct{ Block *hexutil.Uint64 }) (*Account, error) {
+	receipt, err := t.receipt(ctx)
+	if err != nil || receipt == nil || receipt.ContractAddress == (common.Address{}) {
+		return nil, err
+	}
+	return newAccount(t.backend, receipt.ContractAddress, args.Block), nil
+}
+
+func (t *Transaction) Logs(ctx context.Context) (*[]*Log, error) {
+	receipt, err := t.receipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	logs := make([]*Log, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		logs = append(logs, &Log{backend: t.backend, transaction: t, log: log})
+	}
+	return &logs, nil
+}
+
+func (t *Transaction) Raw(ctx context.Context) (hexutil.Bytes, error) {
+	return rlp.EncodeToBytes(t.tx)
+}
+
+// sender returns the sender of the transaction. The sender of an Ethereum
+// transaction is recovered from its signature.
+func (t *Transaction) sender() (common.Address, error) {
+	if t.tx.IsEthereumTransaction() {
+		signer := types.LatestSignerForChainID(t.tx.ChainId())
+		return types.Sender(signer, t.tx)
+	}
+	return t.tx.From()
+}
+
+// receipt returns the receipt of the transaction. It returns nil if the
+// transaction is not mined yet.
+func (t *Transaction) receipt(ctx context.Context) (*types.Receipt, error) {
+	if t.block == nil {
+		return nil, nil
+	}
+	receipts, err := t.block.resolveReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if t.index >= uint64(len(receipts)) {
+		return nil, fmt.Errorf("receipt of transaction %s not found", t.tx.Hash().String())
+	}
+	return receipts[t.index], nil
+}
+
+// Block represents a Klaytn block.
+type Block struct {
+	backend  api.Backend
+	block    *types.Block
+	receipts types.Receipts
+}
+
+func (b *Block) Number(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(b.block.NumberU64())
+}
+
+func (b *Block) Hash(ctx context.Context) common.Hash {
+	return b.block.Hash()
+}
+
+func (b *Block) Parent(ctx context.Context) (*Block, error) {
+	if b.block.NumberU64() == 0 {
+		return nil, nil
+	}
+	parent, err := b.backend.BlockByHash(ctx, b.block.ParentHash())
+	if err != nil {
+		return nil, err
+	}
+	return &Block{backend: b.backend, block: parent}, nil
+}
+
+func (b *Block) Rewardbase(ctx context.Context, args struct{ Block *hexutil.Uint64 }) *Account {
+	return newAccount(b.backend, b.block.Rewardbase(), args.Block)
+}
+
+func (b *Block) BlockScore(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*b.block.BlockScore())
+}
+
+func (b *Block) GasUsed(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(b.block.GasUsed())
+}
+
+func (b *Block) BaseFeePerGas(ctx context.Context) *hexutil.Big {
+	baseFee := b.block.Header().BaseFee
+	if baseFee == nil {
+		return nil
+	}
+	return (*hexutil.Big)(baseFee)
+}
+
+func (b *Block) Timestamp(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(b.block.Time().Uint64())
+}
+
+func (b *Block) TimestampFoS(ctx context.Context) int32 {
+	return int32(b.block.TimeFoS())
+}
+
+func (b *Block) ExtraData(ctx context.Context) hexutil.Bytes {
+	return b.block.Extra()
+}
+
+func (b *Block) GovernanceData(ctx context.Context) hexutil.Bytes {
+	return b.block.Header().Governance
+}
+
+func (b *Block) VoteData(ctx context.Context) hexutil.Bytes {
+	return b.block.Header().Vote
+}
+
+func (b *Block) LogsBloom(ctx context.Context) hexutil.Bytes {
+	return b.block.Bloom().Bytes()
+}
+
+func (b *Block) StateRoot(ctx context.Context) common.Hash {
+	return b.block.Root()
+}
+
+func (b *Block) TransactionsRoot(ctx context.Context) common.Hash {
+	return b.block.TxHash()
+}
+
+func (b *Block) ReceiptsRoot(ctx context.Context) common.Hash {
+	return b.block.ReceiptHash()
+}
+
+func (b *Block) TransactionCount(ctx context.Context) int32 {
+	return int32(len(b.block.Transactions()))
+}
+
+func (b *Block) Transactions(ctx context.Context) []*Transaction {
+	txs := make([]*Transaction, 0, len(b.block.Transactions()))
+	for i, tx := range b.block.Transactions() {
+		txs = append(txs, &Transaction{backend: b.backend, tx: tx, block: b, index: uint64(i)})
+	}
+	return txs
+}
+
+func (b *Block) TransactionAt(ctx context.Context, args struct{ Index int32 }) *Transaction {
+	txs := b.block.Transactions()
+	if args.Index < 0 || int(args.Index) >= len(txs) {
+		return nil
+	}
+	return &Transaction{backend: b.backend, tx: txs[args.Index], block: b, index: uint64(args.Index)}
+}
+
+// BlockFilterCriteria encapsulates criteria passed to a `logs` accessor inside
+// a block.
+type BlockFilterCriteria struct {
+	Addresses *[]common.Address // restricts matches to events created by specific contracts
+	Topics    *[][]common.Hash  // restricts matches to particular event topics
+}
+
+func (b *Block) Logs(ctx context.Context, args struct{ Filter BlockFilterCriteria }) ([]*Log, error) {
+	var (
+		addresses []common.Address
+		topics    [][]common.Hash
+	)
+	if args.Filter.Addresses != nil {
+		addresses = *args.Filter.Addresses
+	}
+	if args.Filter.Topics != nil {
+		topics = *args.Filter.Topics
+	}
+	return b.filterLogs(ctx, addresses, topics)
+}
+
+func (b *Block) Account(ctx context.Context, args struct{ Address common.Address }) *Account {
+	return &Account{
+		backend:       b.backend,
+		address:       args.Address,
+		blockNrOrHash: rpc.NewBlockNumberOrHashWithHash(b.block.Hash(), false),
+	}
+}
+
+// CallData encapsulates arguments to `call` or `estimateGas`.
+// All arguments are optional.
+type CallData struct {
+	From     *common.Address // The Klaytn address the call is from.
+	To       *common.Address // The Klaytn address the call is to.
+	Gas      *hexutil.Uint64 // The amount of gas provided for the call.
+	GasPrice *hexutil.Big    // The price of each unit of gas, in peb.
+	Value    *hexutil.Big    // The value sent along with the call.
+	Data     *hexutil.Bytes  // Any data sent with the call.
+}
+
+// toCallArgs converts the call data into the arguments of klay_call.
+func (d *CallData) toCallArgs() api.CallArgs {
+	args := api.CallArgs{To: d.To, GasPrice: d.GasPrice}
+	if d.From != nil {
+		args.From = *d.From
+	}
+	if d.Gas != nil {
+		args.Gas = *d.Gas
+	}
+	if d.Value != nil {
+		args.Value = *d.Value
+	}
+	if d.Data != nil {
+		args.Data = *d.Data
+	}
+	return args
+}
+
+// CallResult encapsulates the result of an invocation of the `call` accessor.
+type CallResult struct {
+	data    hexutil.Bytes  // The return data from the call
+	gasUsed hexutil.Uint64 // The amount of gas used
+	status  hexutil.Uint64 // The return status of the call - 0 for failure or 1 for success.
+}
+
+func (c *CallResult) Data() hexutil.Bytes {
+	return c.data
+}
+
+func (c *CallResult) GasUsed() hexutil.Uint64 {
+	return c.gasUsed
+}
+
+func (c *CallResult) Status() hexutil.Uint64 {
+	return c.status
+}
+
+func (b *Block) Call(ctx context.Context, args struct{ Data CallData }) (*CallResult, error) {
+	gasCap := big.NewInt(0)
+	if rpcGasCap := b.backend.RPCGasCap(); rpcGasCap != nil {
+		gasCap = rpcGasCap
+	}
+	blockNrOrHash := rpc.NewBlockNumberOrHashWithHash(b.block.Hash(), false)
+	result, _, err := api.DoCall(ctx, b.backend, args.Data.toCallArgs(), blockNrOrHash, vm.Config{ComputationCostLimit: params.OpcodeComputationCostLimitInfinite}, b.backend.RPCEVMTimeout(), gasCap)
+	if err != nil {
+		return nil, err
+	}
+	if result.Failed() {
+		return &CallResult{data: result.Revert(), gasUsed: hexutil.Uint64(result.UsedGas), status: hexutil.Uint64(types.ReceiptStatusFailed)}, nil
+	}
+	return &CallResult{data: result.Return(), gasUsed: hexutil.Uint64(result.UsedGas), status: hexutil.Uint64(types.ReceiptStatusSuccessful)}, nil
+}
+
+func (b *Block) Raw(ctx context.Context) (hexutil.Bytes, error) {
+	return rlp.EncodeToBytes(b.block)
+}
+
+// resolveReceipts returns the receipts of the block, loading them on first use.
+func (b *Block) resolveReceipts(ctx context.Context) (types.Receipts, error) {
+	if b.receipts == nil {
+		receipts := b.backend.GetBlockReceipts(ctx, b.block.Hash())
+		if receipts == nil && len(b.block.Transactions()) > 0 {
+			return nil, fmt.Errorf("receipts of block #%d not found", b.block.NumberU64())
+		}
+		b.receipts = receipts
+	}
+	return b.receipts, nil
+}
+
+// filterLogs returns the logs of the block matching the given criteria.
+func (b *Block) filterLogs(ctx context.Context, addresses []common.Address, topics [][]common.Hash) ([]*Log, error) {
+	if !bloomFilter(b.block.Bloom(), addresses, topics) {
+		return []*Log{}, nil
+	}
+	receipts, err := b.resolveReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	logs := []*Log{}
+	for i, receipt := range receipts {
+		tx := &Transaction{backend: b.backend, tx: b.block.Transactions()[i], block: b, index: uint64(i)}
+		for _, log := range receipt.Logs {
+			if matchLog(log, addresses, topics) {
+				logs = append(logs, &Log{backend: b.backend, transaction: tx, log: log})
+			}
+		}
+	}
+	return logs, nil
+}
+
+// Resolver is the root resolver of the GraphQL schema.
+type Resolver struct {
+	backend api.Backend
+}
+
+func (r *Resolver) Block(ctx context.Context, args struct {
+	Number *hexutil.Uint64
+	Hash   *common.Hash
+}) (*Block, error) {
+	var block *types.Block
+	switch {
+	case args.Hash != nil:
+		// The backend fails only if the block does not exist
+		if _, err := r.backend.HeaderByHash(ctx, *args.Hash); err != nil {
+			return nil, nil
+		}
+		b, err := r.backend.BlockByHash(ctx, *args.Hash)
+		if err != nil {
+			return nil, err
+		}
+		block = b
+	case args.Number != nil:
+		if uint64(*args.Number) > r.backend.CurrentBlock().NumberU64() {
+			return nil, nil
+		}
+		b, err := r.backend.BlockByNumber(ctx, rpc.BlockNumber(*args.Number))
+		if err != nil {
+			return nil, err
+		}
+		block = b
+	default:
+		block = r.backend.CurrentBlock()
+	}
+	return &Block{backend: r.backend, block: block}, nil
+}
+
+func (r *Resolver) Blocks(ctx context.Context, args struct {
+	From *hexutil.Uint64
+	To   *hexutil.Uint64
+}) ([]*Block, error) {
+	head := r.backend.CurrentBlock().NumberU64()
+	from, to := uint64(0), head
+	if args.From != nil {
+		from = uint64(*args.From)
+	}
+	if args.To != nil && uint64(*args.To) < head {
+		to = uint64(*args.To)
+	}
+	if from > to {
+		return []*Block{}, nil
+	}
+	if to-from >= maxBlockRange {
+		return nil, fmt.Errorf("block range exceeds the limit: %d", maxBlockRange)
+	}
+	blocks := make([]*Block, 0, to-from+1)
+	for number := from; number <= to; number++ {
+		block, err := r.backend.BlockByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, &Block{backend: r.backend, block: block})
+	}
+	return blocks, nil
+}
+
+func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash common.Hash }) (*Transaction, error) {
+	tx, blockHash, _, index := r.backend.GetTxAndLookupInfo(args.Hash)
+	if tx == nil {
+		// The transaction may be still in the transaction pool
+		if tx = r.backend.GetPoolTransaction(args.Hash); tx == nil {
+			return nil, nil
+		}
+		return &Transaction{backend: r.backend, tx: tx}, nil
+	}
+	block, err := r.backend.BlockByHash(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return &Transaction{backend: r.backend, tx: tx, block: &Block{backend: r.backend, block: block}, index: index}, nil
+}
+
+// FilterCriteria encapsulates the arguments to `logs` on the root resolver object.
+type FilterCriteria struct {
+	FromBlock *hexutil.Uint64   // beginning of the queried range, nil means latest block
+	ToBlock   *hexutil.Uint64   // end of the range, nil means latest block
+	Addresses *[]common.Address // restricts matches to events created by specific contracts
+	Topics    *[][]common.Hash  // restricts matches to particular event topics
+}
+
+func (r *Resolver) Logs(ctx context.Context, args struct{ Filter FilterCriteria }) ([]*Log, error) {
+	var (
+		head      = r.backend.CurrentBlock().NumberU64()
+		from, to  = head, head
+		addresses []common.Address
+		topics    [][]common.Hash
+	)
+	if args.Filter.FromBlock != nil {
+		from = uint64(*args.Filter.FromBlock)
+	}
+	if args.Filter.ToBlock != nil {
+		to = uint64(*args.Filter.ToBlock)
+	}
+	if from > to {
+		return nil, errBlockRange
+	}
+	if to-from >= maxBlockRange {
+		return nil, fmt.Errorf("block range exceeds the limit: %d", maxBlockRange)
+	}
+	if to > head {
+		to = head
+	}
+	if args.Filter.Addresses != nil {
+		addresses = *args.Filter.Addresses
+	}
+	if args.Filter.Topics != nil {
+		topics = *args.Filter.Topics
+	}
+	logs := []*Log{}
+	for number := from; number <= to; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block, err := r.backend.BlockByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		found, err := (&Block{backend: r.backend, block: block}).filterLogs(ctx, addresses, topics)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, found...)
+	}
+	return logs, nil
+}
+
+func (r *Resolver) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	price, err := r.backend.SuggestPrice(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*price), nil
+}
+
+func (r *Resolver) ChainID(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*r.backend.ChainConfig().ChainID)
+}
+
+func (r *Resolver) EstimateGas(ctx context.Context, args struct{ Data CallData }) (hexutil.Uint64, error) {
+	return api.NewPublicBlockChainAPI(r.backend).EstimateGas(ctx, args.Data.toCallArgs())
+}
+
+func (r *Resolver) SendRawTransaction(ctx context.Context, args struct{ Data hexutil.Bytes }) (common.Hash, error) {
+	return api.NewPublicTransactionPoolAPI(r.backend, new(api.AddrLocker)).SendRawTransaction(ctx, args.Data)
+}
+
+// bloomFilter reports whether the bloom may contain the logs matching the
+// given criteria.
+func bloomFilter(bloom types.Bloom, addresses []common.Address, topics [][]common.Hash) bool {
+	if len(addresses) > 0 {
+		included := false
+		for _, addr := range addresses {
+			if types.BloomLookup(bloom, addr) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	for _, sub := range topics {
+		included := len(sub) == 0 // empty rule set == wildcard
+		for _, topic := range sub {
+			if types.BloomLookup(bloom, topic) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	return true
+}
+
+// matchLog reports whether the log matches the given criteria.
+func matchLog(log *types.Log, addresses []common.Address, topics [][]common.Hash) bool {
+	if len(addresses) > 0 {
+		included := false
+		for _, addr := range addresses {
+			if log.Address == addr {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	if len(topics) > len(log.Topics) {
+		return false
+	}
+	for i, sub := range topics {
+		match := len(sub) == 0 // empty rule set == wildcard
+		for _, topic := range sub {
+			if log.Topics[i] == topic {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
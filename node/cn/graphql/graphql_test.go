@@ -0,0 +1,364 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mock_api "github.com/klaytn/klaytn/api/mocks"
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// codeLogAndReturn emits a log of 0x2a and returns it.
+var codeLogAndReturn = "0x602a60005260206000a060206000f3"
+
+var (
+	testKey, _      = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr        = crypto.PubkeyToAddress(testKey.PublicKey)
+	testPayerKey, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	testPayerAddr   = crypto.PubkeyToAddress(testPayerKey.PublicKey)
+	testRecipient   = common.HexToAddress("0xbbbb")
+	testLogger      = common.HexToAddress("0xdddd")
+)
+
+// newTestChain creates a chain of a single block containing a legacy transfer,
+// a partial fee-delegated transfer and a call emitting a log.
+func newTestChain(t *testing.T) (*blockchain.BlockChain, []*types.Transaction) {
+	var (
+		config  = params.TestChainConfig
+		engine  = gxhash.NewFaker()
+		signer  = types.LatestSignerForChainID(config.ChainID)
+		chainDB = database.NewMemoryDBManager()
+		genDB   = database.NewMemoryDBManager()
+		gspec   = &blockchain.Genesis{Config: config, Alloc: blockchain.GenesisAlloc{
+			testAddr:      {Balance: big.NewInt(params.KLAY)},
+			testPayerAddr: {Balance: big.NewInt(params.KLAY)},
+			testLogger:    {Balance: common.Big0, Code: hexutil.MustDecode(codeLogAndReturn)},
+		}}
+		genesis = gspec.MustCommit(genDB)
+		txs     []*types.Transaction
+	)
+	blocks, _ := blockchain.GenerateChain(config, genesis, engine, genDB, 1, func(i int, b *blockchain.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(0, testRecipient, big.NewInt(1000), params.TxGas, big.NewInt(0), nil), signer, testKey)
+		require.NoError(t, err)
+		txs = append(txs, tx)
+
+		tx, err = types.NewTransactionWithMap(types.TxTypeFeeDelegatedValueTransferWithRatio, map[types.TxValueKeyType]interface{}{
+			types.TxValueKeyNonce:              uint64(1),
+			types.TxValueKeyTo:                 testRecipient,
+			types.TxValueKeyAmount:             big.NewInt(2000),
+			types.TxValueKeyGasLimit:           uint64(100000),
+			types.TxValueKeyGasPrice:           big.NewInt(0),
+			types.TxValueKeyFrom:               testAddr,
+			types.TxValueKeyFeePayer:           testPayerAddr,
+			types.TxValueKeyFeeRatioOfFeePayer: types.FeeRatio(30),
+		})
+		require.NoError(t, err)
+		require.NoError(t, tx.SignWithKeys(signer, []*ecdsa.PrivateKey{testKey}))
+		require.NoError(t, tx.SignFeePayerWithKeys(signer, []*ecdsa.PrivateKey{testPayerKey}))
+		txs = append(txs, tx)
+
+		tx, err = types.SignTx(types.NewTransaction(2, testLogger, common.Big0, 100000, big.NewInt(0), nil), signer, testKey)
+		require.NoError(t, err)
+		txs = append(txs, tx)
+
+		for _, tx := range txs {
+			b.AddTx(tx)
+		}
+	})
+
+	gspec.MustCommit(chainDB)
+	cacheConfig := &blockchain.CacheConfig{
+		CacheSize:           512,
+		BlockInterval:       blockchain.DefaultBlockInterval,
+		TriesInMemory:       blockchain.DefaultTriesInMemory,
+		TrieNodeCacheConfig: statedb.GetEmptyTrieNodeCacheConfig(),
+		ArchiveMode:         true,
+	}
+	chain, err := blockchain.NewBlockChain(chainDB, cacheConfig, config, engine, vm.Config{})
+	require.NoError(t, err)
+	_, err = chain.InsertChain(blocks)
+	require.NoError(t, err)
+	return chain, txs
+}
+
+// newTestServer serves the GraphQL queries against a mock backend of the given chain.
+func newTestServer(t *testing.T, mockBackend *mock_api.MockBackend, chain *blockchain.BlockChain) *httptest.Server {
+	any := gomock.Any()
+	getBlock := func(_ context.Context, number rpc.BlockNumber) (*types.Block, error) {
+		if block := chain.GetBlockByNumber(uint64(number)); block != nil {
+			return block, nil
+		}
+		return nil, fmt.Errorf("the block does not exist (block number: %d)", number)
+	}
+	getBlockByHash := func(_ context.Context, hash common.Hash) (*types.Block, error) {
+		if block := chain.GetBlockByHash(hash); block != nil {
+			return block, nil
+		}
+		return nil, fmt.Errorf("the block does not exist (block hash: %s)", hash.String())
+	}
+	getHeaderByHash := func(_ context.Context, hash common.Hash) (*types.Header, error) {
+		if header := chain.GetHeaderByHash(hash); header != nil {
+			return header, nil
+		}
+		return nil, fmt.Errorf("the header does not exist (hash: %d)", hash)
+	}
+	getStateAndHeader := func(_ context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+		header := chain.CurrentHeader()
+		if hash, ok := blockNrOrHash.Hash(); ok {
+			header = chain.GetHeaderByHash(hash)
+		} else if number, ok := blockNrOrHash.Number(); ok && number >= 0 {
+			header = chain.GetHeaderByNumber(uint64(number))
+		}
+		if header == nil {
+			return nil, nil, fmt.Errorf("header not found")
+		}
+		state, err := chain.StateAt(header.Root)
+		return state, header, err
+	}
+	getEVM := func(_ context.Context, msg blockchain.Message, state *state.StateDB, header *types.Header, vmConfig vm.Config) (*vm.EVM, func() error, error) {
+		txContext := blockchain.NewEVMTxContext(msg, header)
+		blockContext := blockchain.NewEVMBlockContext(header, chain, nil)
+		return vm.NewEVM(blockContext, txContext, state, chain.Config(), &vmConfig), func() error { return nil }, nil
+	}
+	mockBackend.EXPECT().ChainConfig().Return(chain.Config()).AnyTimes()
+	mockBackend.EXPECT().CurrentBlock().DoAndReturn(chain.CurrentBlock).AnyTimes()
+	mockBackend.EXPECT().BlockByNumber(any, any).DoAndReturn(getBlock).AnyTimes()
+	mockBackend.EXPECT().BlockByHash(any, any).DoAndReturn(getBlockByHash).AnyTimes()
+	mockBackend.EXPECT().HeaderByHash(any, any).DoAndReturn(getHeaderByHash).AnyTimes()
+	mockBackend.EXPECT().StateAndHeaderByNumberOrHash(any, any).DoAndReturn(getStateAndHeader).AnyTimes()
+	mockBackend.EXPECT().GetBlockReceipts(any, any).DoAndReturn(func(_ context.Context, hash common.Hash) types.Receipts {
+		return chain.GetReceiptsByBlockHash(hash)
+	}).AnyTimes()
+	mockBackend.EXPECT().GetTxAndLookupInfo(any).DoAndReturn(chain.GetTxAndLookupInfo).AnyTimes()
+	mockBackend.EXPECT().GetPoolTransaction(any).Return(nil).AnyTimes()
+	mockBackend.EXPECT().GetEVM(any, any, any, any, any).DoAndReturn(getEVM).AnyTimes()
+	mockBackend.EXPECT().RPCGasCap().Return(common.Big0).AnyTimes()
+	mockBackend.EXPECT().RPCEVMTimeout().Return(5 * time.Second).AnyTimes()
+
+	handler, err := newHandler(mockBackend)
+	require.NoError(t, err)
+	return httptest.NewServer(handler)
+}
+
+// query posts the given query and returns the data and the errors of the response.
+func query(t *testing.T, server *httptest.Server, q string) (map[string]interface{}, []interface{}) {
+	body, err := json.Marshal(map[string]string{"query": q})
+	require.NoError(t, err)
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(string(body)))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Data   map[string]interface{} `json:"data"`
+		Errors []interface{}          `json:"errors"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	return result.Data, result.Errors
+}
+
+func TestGraphQLBlock(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	chain, txs := newTestChain(t)
+	server := newTestServer(t, mock_api.NewMockBackend(mockCtrl), chain)
+	defer server.Close()
+
+	block := chain.GetBlockByNumber(1)
+	data, errs := query(t, server, `{ block(number: 1) { number hash parent { number } rewardbase { address } transactionCount transactions { hash } logs(filter: {}) { data } } }`)
+	require.Empty(t, errs)
+	result := data["block"].(map[string]interface{})
+	assert.Equal(t, "0x1", result["number"])
+	assert.Equal(t, block.Hash().Hex(), result["hash"])
+	assert.Equal(t, "0x0", result["parent"].(map[string]interface{})["number"])
+	assert.Equal(t, float64(len(txs)), result["transactionCount"])
+	assert.Len(t, result["transactions"], len(txs))
+	assert.Equal(t, []interface{}{map[string]interface{}{"data": hexutil.Encode(common.LeftPadBytes([]byte{0x2a}, 32))}}, result["logs"])
+
+	// The latest block is returned by default
+	data, errs = query(t, server, `{ block { number } }`)
+	require.Empty(t, errs)
+	assert.Equal(t, "0x1", data["block"].(map[string]interface{})["number"])
+
+	// The unknown blocks are null
+	data, errs = query(t, server, fmt.Sprintf(`{ a: block(number: 2) { number } b: block(hash: "%s") { number } }`, common.Hash{}.Hex()))
+	require.Empty(t, errs)
+	assert.Nil(t, data["a"])
+	assert.Nil(t, data["b"])
+
+	data, errs = query(t, server, `{ blocks(from: 0) { number } }`)
+	require.Empty(t, errs)
+	assert.Equal(t, []interface{}{map[string]interface{}{"number": "0x0"}, map[string]interface{}{"number": "0x1"}}, data["blocks"])
+}
+
+func TestGraphQLTransaction(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	chain, txs := newTestChain(t)
+	server := newTestServer(t, mock_api.NewMockBackend(mockCtrl), chain)
+	defer server.Close()
+
+	const fields = `hash type typeName index from { address } to { address } value feePayer { address } feeRatio status gasUsed block { number }`
+
+	// Legacy transaction
+	data, errs := query(t, server, fmt.Sprintf(`{ transaction(hash: "%s") { %s } }`, txs[0].Hash().Hex(), fields))
+	require.Empty(t, errs)
+	result := data["transaction"].(map[string]interface{})
+	assert.Equal(t, txs[0].Hash().Hex(), result["hash"])
+	assert.Equal(t, float64(types.TxTypeLegacyTransaction), result["type"])
+	assert.Equal(t, types.TxTypeLegacyTransaction.String(), result["typeName"])
+	assert.Equal(t, float64(0), result["index"])
+	assert.Equal(t, strings.ToLower(testAddr.Hex()), result["from"].(map[string]interface{})["address"])
+	assert.Equal(t, strings.ToLower(testRecipient.Hex()), result["to"].(map[string]interface{})["address"])
+	assert.Equal(t, "0x3e8", result["value"])
+	assert.Equal(t, strings.ToLower(testAddr.Hex()), result["feePayer"].(map[string]interface{})["address"])
+	assert.Nil(t, result["feeRatio"])
+	assert.Equal(t, "0x1", result["status"])
+	assert.Equal(t, hexutil.EncodeUint64(params.TxGas), result["gasUsed"])
+	assert.Equal(t, "0x1", result["block"].(map[string]interface{})["number"])
+
+	// Partial fee-delegated transaction
+	data, errs = query(t, server, fmt.Sprintf(`{ transaction(hash: "%s") { %s } }`, txs[1].Hash().Hex(), fields))
+	require.Empty(t, errs)
+	result = data["transaction"].(map[string]interface{})
+	assert.Equal(t, float64(types.TxTypeFeeDelegatedValueTransferWithRatio), result["type"])
+	assert.Equal(t, types.TxTypeFeeDelegatedValueTransferWithRatio.String(), result["typeName"])
+	assert.Equal(t, strings.ToLower(testAddr.Hex()), result["from"].(map[string]interface{})["address"])
+	assert.Equal(t, strings.ToLower(testPayerAddr.Hex()), result["feePayer"].(map[string]interface{})["address"])
+	assert.Equal(t, float64(30), result["feeRatio"])
+
+	// Transaction emitting a log
+	data, errs = query(t, server, fmt.Sprintf(`{ transaction(hash: "%s") { logs { index account { address } topics data transaction { hash } } } }`, txs[2].Hash().Hex()))
+	require.Empty(t, errs)
+	logs := data["transaction"].(map[string]interface{})["logs"].([]interface{})
+	if assert.Len(t, logs, 1) {
+		log := logs[0].(map[string]interface{})
+		assert.Equal(t, strings.ToLower(testLogger.Hex()), log["account"].(map[string]interface{})["address"])
+		assert.Equal(t, []interface{}{}, log["topics"])
+		assert.Equal(t, txs[2].Hash().Hex(), log["transaction"].(map[string]interface{})["hash"])
+	}
+
+	// Unknown transaction
+	data, errs = query(t, server, fmt.Sprintf(`{ transaction(hash: "%s") { hash } }`, common.Hash{}.Hex()))
+	require.Empty(t, errs)
+	assert.Nil(t, data["transaction"])
+}
+
+func TestGraphQLAccountAndCall(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	chain, _ := newTestChain(t)
+	server := newTestServer(t, mock_api.NewMockBackend(mockCtrl), chain)
+	defer server.Close()
+
+	data, errs := query(t, server, fmt.Sprintf(`{
+		a: block(number: 0) { account(address: "%s") { balance transactionCount accountKey { keyType } } }
+		b: block { account(address: "%s") { transactionCount } }
+		c: block { account(address: "%s") { code } call(data: { to: "%s" }) { data status } }
+	}`, testAddr.Hex(), testAddr.Hex(), testLogger.Hex(), testLogger.Hex()))
+	require.Empty(t, errs)
+	account := data["a"].(map[string]interface{})["account"].(map[string]interface{})
+	assert.Equal(t, hexutil.EncodeBig(big.NewInt(params.KLAY)), account["balance"])
+	assert.Equal(t, "0x0", account["transactionCount"])
+	assert.Equal(t, float64(1), account["accountKey"].(map[string]interface{})["keyType"]) // AccountKeyLegacy
+	assert.Equal(t, "0x3", data["b"].(map[string]interface{})["account"].(map[string]interface{})["transactionCount"])
+
+	result := data["c"].(map[string]interface{})
+	assert.Equal(t, codeLogAndReturn, result["account"].(map[string]interface{})["code"])
+	call := result["call"].(map[string]interface{})
+	assert.Equal(t, hexutil.Encode(common.LeftPadBytes([]byte{0x2a}, 32)), call["data"])
+	assert.Equal(t, "0x1", call["status"])
+}
+
+func TestGraphQLLogs(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	chain, txs := newTestChain(t)
+	server := newTestServer(t, mock_api.NewMockBackend(mockCtrl), chain)
+	defer server.Close()
+
+	data, errs := query(t, server, fmt.Sprintf(`{
+		a: logs(filter: { fromBlock: 0, addresses: ["%s"] }) { transaction { hash } }
+		b: logs(filter: { fromBlock: 0, addresses: ["%s"] }) { transaction { hash } }
+	}`, testLogger.Hex(), testRecipient.Hex()))
+	require.Empty(t, errs)
+	assert.Equal(t, []interface{}{map[string]interface{}{"transaction": map[string]interface{}{"hash": txs[2].Hash().Hex()}}}, data["a"])
+	assert.Equal(t, []interface{}{}, data["b"])
+
+	_, errs = query(t, server, `{ logs(filter: { fromBlock: 1, toBlock: 0 }) { data } }`)
+	assert.NotEmpty(t, errs)
+	_, errs = query(t, server, fmt.Sprintf(`{ logs(filter: { fromBlock: 0, toBlock: %d }) { data } }`, maxBlockRange))
+	assert.NotEmpty(t, errs)
+}
+
+// TestGraphQLModules tests that the callers authenticated with a token are allowed
+// to query only if the modules claim of the token allows the GraphQL service.
+func TestGraphQLModules(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	handler, err := newHandler(mock_api.NewMockBackend(mockCtrl))
+	require.NoError(t, err)
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	server := httptest.NewServer(rpc.NewHTTPServer(nil, []string{"*"}, rpc.DefaultHTTPTimeouts, secret, handler).Handler)
+	defer server.Close()
+
+	for _, tc := range []struct {
+		modules []string
+		status  int
+	}{
+		{nil, http.StatusOK},
+		{[]string{"klay", module}, http.StatusOK},
+		{[]string{"klay"}, http.StatusForbidden},
+	} {
+		token, err := rpc.NewJWTToken(secret, &rpc.JWTClaims{IssuedAt: time.Now().Unix(), Modules: tc.modules})
+		require.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"query": "{ __typename }"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, tc.status, resp.StatusCode, "modules %v", tc.modules)
+	}
+}
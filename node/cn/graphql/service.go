@@ -0,0 +1,125 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/klaytn/klaytn/api"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/networks/p2p"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/node"
+)
+
+// Path is the path of the HTTP-RPC endpoint the GraphQL service is served on.
+const Path = "/graphql"
+
+// module is the name of the GraphQL service in the modules claim of the tokens
+// authenticating the callers of the HTTP-RPC endpoint.
+const module = "graphql"
+
+var (
+	logger = log.NewModuleLogger(log.NodeCnGraphQL)
+
+	errNoBackend = errors.New("the API backend of the GraphQL service is not set")
+)
+
+// Service serves the GraphQL queries on the HTTP-RPC server of the node. It is
+// registered as a sub-service, so that it can receive the API backend of the
+// core service.
+type Service struct {
+	stack   *node.Node
+	backend api.Backend
+}
+
+// New creates a GraphQL service which is mounted on the HTTP-RPC server of the
+// given node.
+func New(stack *node.Node) *Service {
+	return &Service{stack: stack}
+}
+
+// newHandler returns the HTTP handler serving the GraphQL queries against the
+// given backend.
+func newHandler(backend api.Backend) (http.Handler, error) {
+	s, err := graphql.ParseSchema(schema, &Resolver{backend: backend})
+	if err != nil {
+		return nil, err
+	}
+	return newAuthorizedHandler(&relay.Handler{Schema: s}), nil
+}
+
+// newAuthorizedHandler returns the handler rejecting the callers authenticated
+// with a token whose modules claim doesn't allow the GraphQL service, as the
+// JSON-RPC transports do for the namespaces of the APIs.
+func newAuthorizedHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if claims := rpc.JWTClaimsFromContext(r.Context()); claims != nil && !claims.Allows(module) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"errors": []map[string]string{{"message": "the graphql module is not allowed for the token"}},
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Service) Protocols() []p2p.Protocol {
+	return nil
+}
+
+func (s *Service) APIs() []rpc.API {
+	return nil
+}
+
+// Start mounts the GraphQL handler on the HTTP-RPC server. It must be done
+// before the node opens the HTTP-RPC endpoint.
+func (s *Service) Start(server p2p.Server) error {
+	if s.backend == nil {
+		return errNoBackend
+	}
+	handler, err := newHandler(s.backend)
+	if err != nil {
+		return err
+	}
+	s.stack.RegisterHTTPHandler(Path, handler)
+	logger.Info("GraphQL service is started", "path", Path)
+	return nil
+}
+
+func (s *Service) Stop() error {
+	return nil
+}
+
+func (s *Service) Components() []interface{} {
+	return nil
+}
+
+func (s *Service) SetComponents(components []interface{}) {
+	for _, component := range components {
+		switch v := component.(type) {
+		case api.Backend:
+			s.backend = v
+		}
+	}
+}
@@ -64,6 +64,10 @@ const (
 	concurrentPerPeer  = 3
 	channelSizePerPeer = 20
 
+	maxTxAnnounces   = 4096            // Maximum number of transaction hashes in an announcement or a request.
+	maxRequestedTxs  = 4096            // Maximum number of requested transactions to keep track of.
+	txRequestTimeout = 5 * time.Second // Time after which an announced transaction is requested again.
+
 	blockReceivingPNLimit  = 5 // maximum number of PNs that a CN broadcasts block.
 	minNumPeersToSendBlock = 3 // minimum number of peers that a node broadcasts block.
 
@@ -97,10 +101,12 @@ type ProtocolManager struct {
 	snapSync  uint32 // Flag whether fast sync should operate on top of the snap protocol
 	acceptTxs uint32 // Flag whether we're considered synchronised (enables transaction processing)
 
-	txpool      work.TxPool
-	blockchain  work.BlockChain
-	chainconfig *params.ChainConfig
-	maxPeers    int
+	txpool       work.TxPool
+	txPoolConfig blockchain.TxPoolConfig
+	requestedTxs common.Cache // Hashes of the announced transactions requested to a peer, with the request time
+	blockchain   work.BlockChain
+	chainconfig  *params.ChainConfig
+	maxPeers     int
 
 	downloader ProtocolManagerDownloader
 	fetcher    ProtocolManagerFetcher
@@ -147,6 +153,8 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 		networkId:         networkId,
 		eventMux:          mux,
 		txpool:            txpool,
+		txPoolConfig:      cnconfig.TxPool,
+		requestedTxs:      common.NewCache(common.FIFOCacheConfig{CacheSize: maxRequestedTxs}),
 		blockchain:        blockchain,
 		chainconfig:       config,
 		peers:             newPeerSet(),
@@ -752,6 +760,16 @@ func (pm *ProtocolManager) handleMsg(p Peer, addr common.Address, msg p2p.Msg) e
 			return err
 		}
 
+	case p.GetVersion() >= klay66 && msg.Code == TxHashesMsg:
+		if err := handleTxHashesMsg(pm, p, msg); err != nil {
+			return err
+		}
+
+	case p.GetVersion() >= klay66 && msg.Code == TxRequestMsg:
+		if err := handleTxRequestMsg(pm, p, msg); err != nil {
+			return err
+		}
+
 	default:
 		return errResp(ErrInvalidMsgCode, "%v", msg.Code)
 	}
@@ -1237,6 +1255,72 @@ func handleTxMsg(pm *ProtocolManager, p Peer, msg p2p.Msg) error {
 	return err
 }
 
+// handleTxHashesMsg handles transaction announcement message. The announced
+// transactions unknown to the pool are requested to the peer, unless they have
+// been recently requested to another peer.
+func handleTxHashesMsg(pm *ProtocolManager, p Peer, msg p2p.Msg) error {
+	if atomic.LoadUint32(&pm.acceptTxs) == 0 {
+		return nil
+	}
+	var hashes []common.Hash
+	if err := msg.Decode(&hashes); err != nil {
+		return errResp(ErrDecode, "msg %v: %v", msg, err)
+	}
+	if len(hashes) > maxTxAnnounces {
+		return errResp(ErrMsgTooLarge, "%d transaction hashes > %d", len(hashes), maxTxAnnounces)
+	}
+	request := make([]common.Hash, 0, len(hashes))
+	for _, hash := range hashes {
+		p.AddToKnownTxs(hash)
+		txAnnounceReceiveCounter.Inc(1)
+
+		if pm.txpool.Get(hash) != nil {
+			continue
+		}
+		if requested, ok := pm.requestedTxs.Get(hash); ok && time.Since(requested.(time.Time)) < txRequestTimeout {
+			continue
+		}
+		pm.requestedTxs.Add(hash, time.Now())
+		request = append(request, hash)
+	}
+	if len(request) == 0 {
+		return nil
+	}
+	txRequestSendCounter.Inc(int64(len(request)))
+	return p.RequestTransactions(request)
+}
+
+// handleTxRequestMsg handles transaction request message. The requested
+// transactions found in the pool are sent back with TxMsg.
+func handleTxRequestMsg(pm *ProtocolManager, p Peer, msg p2p.Msg) error {
+	var hashes []common.Hash
+	if err := msg.Decode(&hashes); err != nil {
+		return errResp(ErrDecode, "msg %v: %v", msg, err)
+	}
+	if len(hashes) > maxTxAnnounces {
+		return errResp(ErrMsgTooLarge, "%d transaction hashes > %d", len(hashes), maxTxAnnounces)
+	}
+	var (
+		bytes int
+		txs   types.Transactions
+	)
+	for _, hash := range hashes {
+		if bytes >= softResponseLimit {
+			break
+		}
+		tx := pm.txpool.Get(hash)
+		if tx == nil {
+			continue
+		}
+		txs = append(txs, tx)
+		bytes += int(tx.Size())
+	}
+	if len(txs) == 0 {
+		return nil
+	}
+	return p.SendTransactions(txs)
+}
+
 // sampleSize calculates the number of peers to send block.
 // If calcSampleSize is smaller than minNumPeersToSendBlock, it returns minNumPeersToSendBlock.
 // Otherwise, it returns calcSampleSize.
@@ -1299,6 +1383,15 @@ func (pm *ProtocolManager) BroadcastTxs(txs types.Transactions) {
 		sort.Sort(types.TxByTime(txs))
 	}
 
+	// The large transactions are only announced, and the peers request them if needed.
+	txs, largeTxs := pm.splitLargeTxs(txs)
+	if len(largeTxs) > 0 {
+		pm.announceTxs(largeTxs)
+	}
+	if len(txs) == 0 {
+		return
+	}
+
 	switch pm.nodetype {
 	case common.CONSENSUSNODE:
 		pm.broadcastTxsFromCN(txs)
@@ -1388,6 +1481,8 @@ func (pm *ProtocolManager) ReBroadcastTxs(txs types.Transactions) {
 		sort.Sort(types.TxByTime(txs))
 	}
 
+	txs, largeTxs := pm.splitLargeTxs(txs)
+
 	peersWithoutTxs := make(map[Peer]types.Transactions)
 	for _, tx := range txs {
 		peers := pm.peers.SampleResendPeersByType(pm.nodetype)
@@ -1397,8 +1492,88 @@ func (pm *ProtocolManager) ReBroadcastTxs(txs types.Transactions) {
 		txResendCounter.Inc(1)
 	}
 
+	// The large transactions are announced, but the peers not supporting the
+	// announcements receive them here since they are not broadcast to them.
+	peersWithoutLargeTxs := make(map[Peer]types.Transactions)
+	for _, tx := range largeTxs {
+		peers := pm.peers.SampleResendPeersByType(pm.nodetype)
+		for _, peer := range peers {
+			if peer.GetVersion() < klay66 {
+				peersWithoutTxs[peer] = append(peersWithoutTxs[peer], tx)
+			} else {
+				peersWithoutLargeTxs[peer] = append(peersWithoutLargeTxs[peer], tx)
+			}
+		}
+		txResendCounter.Inc(1)
+	}
+
 	propTxPeersGauge.Update(int64(len(peersWithoutTxs)))
 	sendTransactions(peersWithoutTxs)
+	announceTransactions(peersWithoutLargeTxs)
+}
+
+// splitLargeTxs splits the given transactions into the regular ones and the ones
+// belonging to the large transaction sub-pool.
+func (pm *ProtocolManager) splitLargeTxs(txs types.Transactions) (types.Transactions, types.Transactions) {
+	if pm.txPoolConfig.LargeTxSize == 0 {
+		return txs, nil
+	}
+	regularTxs := make(types.Transactions, 0, len(txs))
+	var largeTxs types.Transactions
+	for _, tx := range txs {
+		if pm.txPoolConfig.IsLargeTx(tx) {
+			largeTxs = append(largeTxs, tx)
+		} else {
+			regularTxs = append(regularTxs, tx)
+		}
+	}
+	return regularTxs, largeTxs
+}
+
+// announceTxs announces the hashes of the given transactions to the peers which
+// are not known to have them. The transactions are announced to the same types
+// of peers as they are broadcast to, without sampling since the announcements
+// are cheap.
+func (pm *ProtocolManager) announceTxs(txs types.Transactions) {
+	var peerTypes []common.ConnType
+	switch pm.nodetype {
+	case common.CONSENSUSNODE:
+		peerTypes = []common.ConnType{common.CONSENSUSNODE}
+	case common.PROXYNODE:
+		peerTypes = []common.ConnType{common.CONSENSUSNODE, common.PROXYNODE}
+	case common.ENDPOINTNODE:
+		peerTypes = []common.ConnType{common.CONSENSUSNODE, common.PROXYNODE, common.ENDPOINTNODE}
+	default:
+		logger.Error("Unexpected nodeType of ProtocolManager", "nodeType", pm.nodetype)
+		return
+	}
+
+	peersWithoutTxs := make(map[Peer]types.Transactions)
+	for _, tx := range txs {
+		for _, peerType := range peerTypes {
+			pm.peers.UpdateTypePeersWithoutTxs(tx, peerType, peersWithoutTxs)
+		}
+		txAnnounceSendCounter.Inc(1)
+	}
+	announceTransactions(peersWithoutTxs)
+}
+
+// announceTransactions iterates the given map with the key-value pair of Peer and Transactions
+// and announces the hashes of the paired transactions to the peer. The peers not supporting
+// the announcements are skipped, they receive the transactions by the resend or the sync instead.
+func announceTransactions(txsSet map[Peer]types.Transactions) {
+	for peer, txs := range txsSet {
+		if peer.GetVersion() < klay66 {
+			continue
+		}
+		hashes := make([]common.Hash, len(txs))
+		for i, tx := range txs {
+			hashes[i] = tx.Hash()
+		}
+		if err := peer.SendTransactionHashes(hashes); err != nil {
+			logger.Error("Failed to announce txs", "peer", peer.GetAddr(), "peerType", peer.ConnType(), "numTxs", len(txs), "err", err)
+		}
+	}
 }
 
 // sendTransactions iterates the given map with the key-value pair of Peer and Transactions
@@ -277,6 +277,74 @@ func TestHandleTxMsg(t *testing.T) {
 	}
 }
 
+func TestHandleTxHashesMsg(t *testing.T) {
+	pm := &ProtocolManager{requestedTxs: common.NewCache(common.FIFOCacheConfig{CacheSize: maxRequestedTxs})}
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockPeer := NewMockPeer(mockCtrl)
+	mockPeer.EXPECT().GetVersion().Return(klay66).AnyTimes()
+
+	unknownHash := common.HexToHash("0x1")
+	hashes := []common.Hash{tx1.Hash(), unknownHash}
+
+	// If pm.acceptTxs == 0, nothing happens.
+	{
+		assert.NoError(t, pm.handleMsg(mockPeer, addrs[0], generateMsg(t, TxHashesMsg, hashes)))
+	}
+	// If pm.acceptTxs == 1, only the transactions unknown to the pool are requested.
+	{
+		atomic.StoreUint32(&pm.acceptTxs, 1)
+		mockTxPool := mocks.NewMockTxPool(mockCtrl)
+		mockTxPool.EXPECT().Get(tx1.Hash()).Return(tx1).AnyTimes()
+		mockTxPool.EXPECT().Get(unknownHash).Return(nil).AnyTimes()
+		pm.txpool = mockTxPool
+
+		mockPeer.EXPECT().AddToKnownTxs(tx1.Hash()).Times(1)
+		mockPeer.EXPECT().AddToKnownTxs(unknownHash).Times(1)
+		mockPeer.EXPECT().RequestTransactions(gomock.Eq([]common.Hash{unknownHash})).Return(nil).Times(1)
+		assert.NoError(t, pm.handleMsg(mockPeer, addrs[0], generateMsg(t, TxHashesMsg, hashes)))
+	}
+	// The recently requested transactions are not requested again.
+	{
+		mockPeer.EXPECT().AddToKnownTxs(tx1.Hash()).Times(1)
+		mockPeer.EXPECT().AddToKnownTxs(unknownHash).Times(1)
+		assert.NoError(t, pm.handleMsg(mockPeer, addrs[0], generateMsg(t, TxHashesMsg, hashes)))
+	}
+	// Too many hashes in an announcement, an error is returned.
+	{
+		assert.Error(t, pm.handleMsg(mockPeer, addrs[0], generateMsg(t, TxHashesMsg, make([]common.Hash, maxTxAnnounces+1))))
+	}
+	// The announcements are not handled for the peers before klay66.
+	{
+		oldPeer := NewMockPeer(mockCtrl)
+		oldPeer.EXPECT().GetVersion().Return(klay65).AnyTimes()
+		assert.Error(t, pm.handleMsg(oldPeer, addrs[0], generateMsg(t, TxHashesMsg, hashes)))
+	}
+}
+
+func TestHandleTxRequestMsg(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockPeer := NewMockPeer(mockCtrl)
+	mockPeer.EXPECT().GetVersion().Return(klay66).AnyTimes()
+
+	unknownHash := common.HexToHash("0x1")
+	mockTxPool := mocks.NewMockTxPool(mockCtrl)
+	mockTxPool.EXPECT().Get(tx1.Hash()).Return(tx1).AnyTimes()
+	mockTxPool.EXPECT().Get(unknownHash).Return(nil).AnyTimes()
+	pm := &ProtocolManager{txpool: mockTxPool}
+
+	// Only the transactions in the pool are sent back.
+	{
+		mockPeer.EXPECT().SendTransactions(gomock.Eq(types.Transactions{tx1})).Return(nil).Times(1)
+		assert.NoError(t, pm.handleMsg(mockPeer, addrs[0], generateMsg(t, TxRequestMsg, []common.Hash{unknownHash, tx1.Hash()})))
+	}
+	// Nothing is sent if none of the transactions is in the pool.
+	{
+		assert.NoError(t, pm.handleMsg(mockPeer, addrs[0], generateMsg(t, TxRequestMsg, []common.Hash{unknownHash})))
+	}
+}
+
 func prepareTestHandleBlockHeaderFetchRequestMsg(t *testing.T) (*gomock.Controller, *MockPeer, *mocks.MockBlockChain, *ProtocolManager) {
 	mockCtrl := gomock.NewController(t)
 	mockPeer := NewMockPeer(mockCtrl)
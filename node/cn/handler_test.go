@@ -583,6 +583,44 @@ func TestBroadcastTxsFromEN_ALL_Exists(t *testing.T) {
 	pm.BroadcastTxs(txs)
 }
 
+func TestBroadcastTxsFromEN_LargeTxsAnnounced(t *testing.T) {
+	pm := &ProtocolManager{}
+	pm.nodetype = common.ENDPOINTNODE
+	pm.txPoolConfig.LargeTxSize = 1024
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	peers := newPeerSet()
+	pm.peers = peers
+	cnPeer, pnPeer, enPeer := createAndRegisterPeers(mockCtrl, peers)
+
+	cnPeer.EXPECT().ConnType().Return(common.CONSENSUSNODE).AnyTimes()
+	pnPeer.EXPECT().ConnType().Return(common.PROXYNODE).AnyTimes()
+	enPeer.EXPECT().ConnType().Return(common.ENDPOINTNODE).AnyTimes()
+
+	largeTx := types.NewTransaction(111, addrs[0], big.NewInt(111), 111, big.NewInt(111), make([]byte, 2048))
+	largeTxs := types.Transactions{largeTx}
+
+	cnPeer.EXPECT().KnowsTx(largeTx.Hash()).Return(false).Times(1)
+	pnPeer.EXPECT().KnowsTx(largeTx.Hash()).Return(false).Times(1)
+	enPeer.EXPECT().KnowsTx(largeTx.Hash()).Return(true).Times(1)
+
+	// The peers supporting the announcements only receive the hashes of the large transactions.
+	cnPeer.EXPECT().GetVersion().Return(klay66).AnyTimes()
+	cnPeer.EXPECT().SendTransactionHashes(gomock.Eq([]common.Hash{largeTx.Hash()})).Times(1)
+	cnPeer.EXPECT().SendTransactions(gomock.Any()).Times(0)
+
+	// The others are not pushed the large transactions, they receive them by the resend or the sync.
+	pnPeer.EXPECT().GetVersion().Return(klay65).AnyTimes()
+	pnPeer.EXPECT().SendTransactionHashes(gomock.Any()).Times(0)
+	pnPeer.EXPECT().SendTransactions(gomock.Any()).Times(0)
+
+	enPeer.EXPECT().SendTransactionHashes(gomock.Any()).Times(0)
+	enPeer.EXPECT().SendTransactions(gomock.Any()).Times(0)
+
+	pm.BroadcastTxs(largeTxs)
+}
+
 func TestBroadcastTxsFrom_DefaultCase(t *testing.T) {
 	pm := &ProtocolManager{}
 	pm.nodetype = common.BOOTNODE
@@ -695,6 +733,41 @@ func TestReBroadcastTxs_PN(t *testing.T) {
 	}
 }
 
+func TestReBroadcastTxs_LargeTxs(t *testing.T) {
+	pm := &ProtocolManager{}
+	pm.nodetype = common.PROXYNODE
+	pm.txPoolConfig.LargeTxSize = 1024
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	peers := newPeerSet()
+	pm.peers = peers
+
+	largeTx := types.NewTransaction(111, addrs[0], big.NewInt(111), 111, big.NewInt(111), make([]byte, 2048))
+	largeTxs := types.Transactions{largeTx}
+
+	// The peer supporting the announcements only receives the hash of the large transaction.
+	cnPeer66 := NewMockPeer(mockCtrl)
+	cnPeer66.EXPECT().ConnType().Return(common.CONSENSUSNODE).AnyTimes()
+	cnPeer66.EXPECT().GetVersion().Return(klay66).AnyTimes()
+	cnPeer66.EXPECT().SendTransactionHashes(gomock.Eq([]common.Hash{largeTx.Hash()})).Times(1)
+	cnPeer66.EXPECT().SendTransactions(gomock.Any()).Times(0)
+
+	// The other receives the large transaction.
+	cnPeer65 := NewMockPeer(mockCtrl)
+	cnPeer65.EXPECT().ConnType().Return(common.CONSENSUSNODE).AnyTimes()
+	cnPeer65.EXPECT().GetVersion().Return(klay65).AnyTimes()
+	cnPeer65.EXPECT().SendTransactionHashes(gomock.Any()).Times(0)
+	cnPeer65.EXPECT().SendTransactions(gomock.Eq(largeTxs)).Times(1)
+
+	peers.cnpeers[addrs[0]] = cnPeer66
+	peers.cnpeers[addrs[1]] = cnPeer65
+	peers.peers[fmt.Sprintf("%x", nodeids[0][:8])] = cnPeer66
+	peers.peers[fmt.Sprintf("%x", nodeids[1][:8])] = cnPeer65
+
+	pm.ReBroadcastTxs(largeTxs)
+}
+
 func TestReBroadcastTxs_EN(t *testing.T) {
 	// PN Peer=0, EN Peer=1
 	{
@@ -65,6 +65,9 @@ var (
 	txResendCounter                      = metrics.NewRegisteredCounter("klay/tx/resend/counter", nil)
 	txSendCounter                        = metrics.NewRegisteredCounter("klay/tx/send/counter", nil)
 	txResendRoutineGauge                 = metrics.NewRegisteredGauge("klay/tx/resend/routine/gauge", nil)
+	txAnnounceSendCounter                = metrics.NewRegisteredCounter("klay/tx/announce/send/counter", nil)
+	txAnnounceReceiveCounter             = metrics.NewRegisteredCounter("klay/tx/announce/recv/counter", nil)
+	txRequestSendCounter                 = metrics.NewRegisteredCounter("klay/tx/request/send/counter", nil)
 	cnPeerCountGauge                     = metrics.NewRegisteredGauge("p2p/CNPeerCountGauge", nil)
 	pnPeerCountGauge                     = metrics.NewRegisteredGauge("p2p/PNPeerCountGauge", nil)
 	enPeerCountGauge                     = metrics.NewRegisteredGauge("p2p/ENPeerCountGauge", nil)
@@ -124,6 +124,13 @@ type Peer interface {
 	// AsyncSendTransactions sends transactions asynchronously to the peer.
 	AsyncSendTransactions(txs types.Transactions)
 
+	// SendTransactionHashes announces the availability of a number of transactions
+	// through a hash notification, without sending their bodies.
+	SendTransactionHashes(hashes []common.Hash) error
+
+	// RequestTransactions fetches a batch of announced transactions from a remote node.
+	RequestTransactions(hashes []common.Hash) error
+
 	// SendNewBlockHashes announces the availability of a number of blocks through
 	// a hash notification.
 	SendNewBlockHashes(hashes []common.Hash, numbers []uint64) error
@@ -487,6 +494,21 @@ func (p *basePeer) AsyncSendTransactions(txs types.Transactions) {
 	}
 }
 
+// SendTransactionHashes announces the availability of a number of transactions
+// through a hash notification, without sending their bodies.
+func (p *basePeer) SendTransactionHashes(hashes []common.Hash) error {
+	for _, hash := range hashes {
+		p.AddToKnownTxs(hash)
+	}
+	return p2p.Send(p.rw, TxHashesMsg, hashes)
+}
+
+// RequestTransactions fetches a batch of announced transactions from a remote node.
+func (p *basePeer) RequestTransactions(hashes []common.Hash) error {
+	p.Log().Debug("Fetching batch of transactions", "count", len(hashes))
+	return p2p.Send(p.rw, TxRequestMsg, hashes)
+}
+
 // SendNewBlockHashes announces the availability of a number of blocks through
 // a hash notification.
 func (p *basePeer) SendNewBlockHashes(hashes []common.Hash, numbers []uint64) error {
@@ -877,6 +899,21 @@ func (p *multiChannelPeer) ReSendTransactions(txs types.Transactions) error {
 	return p.msgSender(TxMsg, txs)
 }
 
+// SendTransactionHashes announces the availability of a number of transactions
+// through a hash notification, without sending their bodies.
+func (p *multiChannelPeer) SendTransactionHashes(hashes []common.Hash) error {
+	for _, hash := range hashes {
+		p.AddToKnownTxs(hash)
+	}
+	return p.msgSender(TxHashesMsg, hashes)
+}
+
+// RequestTransactions fetches a batch of announced transactions from a remote node.
+func (p *multiChannelPeer) RequestTransactions(hashes []common.Hash) error {
+	p.Log().Debug("Fetching batch of transactions", "count", len(hashes))
+	return p.msgSender(TxRequestMsg, hashes)
+}
+
 // SendNewBlockHashes announces the availability of a number of blocks through
 // a hash notification.
 func (p *multiChannelPeer) SendNewBlockHashes(hashes []common.Hash, numbers []uint64) error {
@@ -499,6 +499,20 @@ func (mr *MockPeerMockRecorder) RequestStakingInfo(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestStakingInfo", reflect.TypeOf((*MockPeer)(nil).RequestStakingInfo), arg0)
 }
 
+// RequestTransactions mocks base method
+func (m *MockPeer) RequestTransactions(arg0 []common.Hash) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestTransactions", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestTransactions indicates an expected call of RequestTransactions
+func (mr *MockPeerMockRecorder) RequestTransactions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestTransactions", reflect.TypeOf((*MockPeer)(nil).RequestTransactions), arg0)
+}
+
 // RunningCap mocks base method
 func (m *MockPeer) RunningCap(arg0 string, arg1 []uint) bool {
 	m.ctrl.T.Helper()
@@ -667,6 +681,20 @@ func (mr *MockPeerMockRecorder) SendStakingInfoRLP(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendStakingInfoRLP", reflect.TypeOf((*MockPeer)(nil).SendStakingInfoRLP), arg0)
 }
 
+// SendTransactionHashes mocks base method
+func (m *MockPeer) SendTransactionHashes(arg0 []common.Hash) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendTransactionHashes", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendTransactionHashes indicates an expected call of SendTransactionHashes
+func (mr *MockPeerMockRecorder) SendTransactionHashes(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTransactionHashes", reflect.TypeOf((*MockPeer)(nil).SendTransactionHashes), arg0)
+}
+
 // SendTransactions mocks base method
 func (m *MockPeer) SendTransactions(arg0 types.Transactions) error {
 	m.ctrl.T.Helper()
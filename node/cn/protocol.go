@@ -42,16 +42,17 @@ const (
 	klay63 = 63
 	klay64 = 64
 	klay65 = 65
+	klay66 = 66
 )
 
 // ProtocolName is the official short name of the protocol used during capability negotiation.
 var ProtocolName = "klay"
 
 // ProtocolVersions are the upported versions of the klay protocol (first is primary).
-var ProtocolVersions = []uint{klay65, klay64, klay63, klay62}
+var ProtocolVersions = []uint{klay66, klay65, klay64, klay63, klay62}
 
 // ProtocolLengths are the number of implemented message corresponding to different protocol versions.
-var ProtocolLengths = []uint64{21, 19, 17, 8}
+var ProtocolLengths = []uint64{23, 21, 19, 17, 8}
 
 const ProtocolMaxMsgSize = 12 * 1024 * 1024 // Maximum cap on the size of a protocol message
 
@@ -86,7 +87,11 @@ const (
 	StakingInfoRequestMsg = 0x12
 	StakingInfoMsg        = 0x13
 
-	MsgCodeEnd = 0x14
+	// Protocol messages belonging to klay/66
+	TxHashesMsg  = 0x14 // Announces the hashes of large transactions instead of broadcasting them
+	TxRequestMsg = 0x15 // Requests the announced transactions, which are sent back with TxMsg
+
+	MsgCodeEnd = 0x16
 )
 
 type errCode int
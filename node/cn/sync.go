@@ -52,6 +52,15 @@ func (pm *ProtocolManager) syncTransactions(p Peer) {
 	for _, batch := range pending {
 		txs = append(txs, batch...)
 	}
+	// The large transactions are only announced to the new peer if it supports
+	// the announcements, otherwise they are synced with the others.
+	if p.GetVersion() >= klay66 {
+		var largeTxs types.Transactions
+		txs, largeTxs = pm.splitLargeTxs(txs)
+		if len(largeTxs) > 0 {
+			announceTransactions(map[Peer]types.Transactions{p: largeTxs})
+		}
+	}
 	if len(txs) == 0 {
 		return
 	}
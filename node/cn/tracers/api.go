@@ -24,6 +24,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -57,6 +58,10 @@ const (
 	// defaultLoggerTimeout is the amount of time a logger can aggregate trace logs
 	defaultLoggerTimeout = 1 * time.Second
 
+	// maxTraceCallBundleSize is the maximum number of calls traced by a single
+	// debug_traceCallMany request.
+	maxTraceCallBundleSize = 100
+
 	// defaultTraceReexec is the number of blocks the tracer is willing to go back
 	// and reexecute to produce missing historical state necessary to run a specific
 	// trace.
@@ -190,11 +195,19 @@ func (api *CommonAPI) blockByNumberAndHash(ctx context.Context, number rpc.Block
 type TraceConfig struct {
 	*vm.LogConfig
 	Tracer        *string
+	TracerConfig  json.RawMessage
 	Timeout       *string
 	LoggerTimeout *string
 	Reexec        *uint64
 }
 
+// TraceCallConfig holds extra parameters to trace calls.
+type TraceCallConfig struct {
+	TraceConfig
+	StateOverrides *klaytnapi.EthStateOverride
+	BlockOverrides *klaytnapi.BlockOverrides
+}
+
 // StdTraceConfig holds extra parameters to standard-json trace functions.
 type StdTraceConfig struct {
 	*vm.LogConfig
@@ -209,6 +222,12 @@ type txTraceResult struct {
 	Error  string      `json:"error,omitempty"`  // Trace failure produced by the tracer
 }
 
+// callTraceResult is the result of a single call trace in a bundle.
+type callTraceResult struct {
+	Result interface{} `json:"result,omitempty"` // Trace results produced by the tracer
+	Error  string      `json:"error,omitempty"`  // Trace failure produced by the tracer
+}
+
 // blockTraceTask represents a single block trace task when an entire chain is
 // being traced.
 type blockTraceTask struct {
@@ -319,7 +338,8 @@ func (api *CommonAPI) traceChain(start, end *types.Block, config *TraceConfig, n
 					txCtx := blockchain.NewEVMTxContext(msg, task.block.Header())
 					blockCtx := blockchain.NewEVMBlockContext(task.block.Header(), newChainContext(localctx, api.backend), nil)
 
-					res, err := api.traceTx(localctx, msg, blockCtx, txCtx, task.statedb, config)
+					txInfo := &Context{BlockHash: task.block.Hash(), TxIndex: i, TxHash: tx.Hash()}
+					res, err := api.traceTx(localctx, msg, txInfo, blockCtx, txCtx, task.statedb, config)
 					if err != nil {
 						task.results[i] = &txTraceResult{TxHash: tx.Hash(), Error: err.Error()}
 						logger.Warn("Tracing failed", "hash", tx.Hash(), "block", task.block.NumberU64(), "err", err)
@@ -631,7 +651,8 @@ func (api *CommonAPI) traceBlock(ctx context.Context, block *types.Block, config
 
 				txCtx := blockchain.NewEVMTxContext(msg, block.Header())
 				blockCtx := blockchain.NewEVMBlockContext(block.Header(), newChainContext(ctx, api.backend), nil)
-				res, err := api.traceTx(ctx, msg, blockCtx, txCtx, task.statedb, config)
+				txInfo := &Context{BlockHash: block.Hash(), TxIndex: task.index, TxHash: txs[task.index].Hash()}
+				res, err := api.traceTx(ctx, msg, txInfo, blockCtx, txCtx, task.statedb, config)
 				if err != nil {
 					results[task.index] = &txTraceResult{TxHash: txs[task.index].Hash(), Error: err.Error()}
 					continue
@@ -816,13 +837,14 @@ func (api *CommonAPI) TraceTransaction(ctx context.Context, hash common.Hash, co
 		return nil, err
 	}
 	// Trace the transaction and return
-	return api.traceTx(ctx, msg, blockCtx, txCtx, statedb, config)
+	txInfo := &Context{BlockHash: blockHash, TxIndex: int(index), TxHash: hash}
+	return api.traceTx(ctx, msg, txInfo, blockCtx, txCtx, statedb, config)
 }
 
 // TraceCall lets you trace a given klay_call. It collects the structured logs
 // created during the execution of EVM if the given transaction was added on
 // top of the provided block and returns them as a JSON object.
-func (api *CommonAPI) TraceCall(ctx context.Context, args klaytnapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (interface{}, error) {
+func (api *CommonAPI) TraceCall(ctx context.Context, args klaytnapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) (interface{}, error) {
 	if !api.unsafeTrace {
 		if atomic.LoadInt32(&heavyAPIRequestCount) >= HeavyAPIRequestLimit {
 			return nil, fmt.Errorf("heavy debug api requests exceed the limit: %d", int64(HeavyAPIRequestLimit))
@@ -830,6 +852,73 @@ func (api *CommonAPI) TraceCall(ctx context.Context, args klaytnapi.CallArgs, bl
 		atomic.AddInt32(&heavyAPIRequestCount, 1)
 		defer atomic.AddInt32(&heavyAPIRequestCount, -1)
 	}
+	header, blockCtx, statedb, err := api.callEnvironment(ctx, blockNrOrHash, config)
+	if err != nil {
+		return nil, err
+	}
+	var traceConfig *TraceConfig
+	if config != nil {
+		traceConfig = &config.TraceConfig
+	}
+	return api.traceCall(ctx, args, header, blockCtx, statedb, traceConfig)
+}
+
+// TraceCallMany lets you trace a given list of klay_calls. The calls are executed
+// in order on top of the provided block, and each call sees the state changes made
+// by the previous ones. It returns the trace results of the calls in the same order.
+// The configured timeout bounds the tracing of the whole bundle, not each call.
+func (api *CommonAPI) TraceCallMany(ctx context.Context, bundle []klaytnapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) ([]*callTraceResult, error) {
+	if !api.unsafeTrace {
+		if atomic.LoadInt32(&heavyAPIRequestCount) >= HeavyAPIRequestLimit {
+			return nil, fmt.Errorf("heavy debug api requests exceed the limit: %d", int64(HeavyAPIRequestLimit))
+		}
+		atomic.AddInt32(&heavyAPIRequestCount, 1)
+		defer atomic.AddInt32(&heavyAPIRequestCount, -1)
+	}
+	if len(bundle) == 0 {
+		return nil, errors.New("empty call bundle")
+	}
+	if len(bundle) > maxTraceCallBundleSize {
+		return nil, fmt.Errorf("too many calls in the bundle: %d > %d", len(bundle), maxTraceCallBundleSize)
+	}
+	timeout := defaultTraceTimeout
+	if config != nil && config.Timeout != nil {
+		var err error
+		if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
+			return nil, err
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	header, blockCtx, statedb, err := api.callEnvironment(ctx, blockNrOrHash, config)
+	if err != nil {
+		return nil, err
+	}
+	var traceConfig *TraceConfig
+	if config != nil {
+		traceConfig = &config.TraceConfig
+	}
+	results := make([]*callTraceResult, len(bundle))
+	for i, args := range bundle {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		res, err := api.traceCall(ctx, args, header, blockCtx, statedb, traceConfig)
+		if err != nil {
+			results[i] = &callTraceResult{Error: err.Error()}
+		} else {
+			results[i] = &callTraceResult{Result: res}
+		}
+		// Make the state changes of this call visible to the next one
+		statedb.Finalise(true, true)
+	}
+	return results, nil
+}
+
+// callEnvironment retrieves the block and its state for tracing calls on top of it,
+// and applies the state and block overrides in the given config.
+func (api *CommonAPI) callEnvironment(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) (*types.Header, vm.BlockContext, *state.StateDB, error) {
 	// Try to retrieve the specified block
 	var (
 		err   error
@@ -840,10 +929,10 @@ func (api *CommonAPI) TraceCall(ctx context.Context, args klaytnapi.CallArgs, bl
 	} else if number, ok := blockNrOrHash.Number(); ok {
 		block, err = api.blockByNumber(ctx, number)
 	} else {
-		return nil, errors.New("invalid arguments; neither block nor hash specified")
+		return nil, vm.BlockContext{}, nil, errors.New("invalid arguments; neither block nor hash specified")
 	}
 	if err != nil {
-		return nil, err
+		return nil, vm.BlockContext{}, nil, err
 	}
 	// try to recompute the state
 	reexec := defaultTraceReexec
@@ -852,18 +941,31 @@ func (api *CommonAPI) TraceCall(ctx context.Context, args klaytnapi.CallArgs, bl
 	}
 	statedb, err := api.backend.StateAtBlock(ctx, block, reexec, nil, true, false)
 	if err != nil {
-		return nil, err
+		return nil, vm.BlockContext{}, nil, err
 	}
 
-	// Execute the trace
-	intrinsicGas, err := types.IntrinsicGas(args.InputData(), nil, args.To == nil, api.backend.ChainConfig().Rules(block.Number()))
+	header := types.CopyHeader(block.Header())
+	blockCtx := blockchain.NewEVMBlockContext(header, newChainContext(ctx, api.backend), nil)
+	if config != nil {
+		if err := config.StateOverrides.Apply(statedb); err != nil {
+			return nil, vm.BlockContext{}, nil, err
+		}
+		config.BlockOverrides.Apply(&blockCtx)
+		if config.BlockOverrides != nil && config.BlockOverrides.BaseFeePerGas != nil {
+			// The transaction context takes the gas price from the header
+			header.BaseFee = blockCtx.BaseFee
+		}
+	}
+	return header, blockCtx, statedb, nil
+}
+
+// traceCall traces the given call in the provided environment.
+func (api *CommonAPI) traceCall(ctx context.Context, args klaytnapi.CallArgs, header *types.Header, blockCtx vm.BlockContext, statedb *state.StateDB, config *TraceConfig) (interface{}, error) {
+	intrinsicGas, err := types.IntrinsicGas(args.InputData(), nil, args.To == nil, api.backend.ChainConfig().Rules(blockCtx.BlockNumber))
 	if err != nil {
 		return nil, err
 	}
-	basefee := new(big.Int).SetUint64(params.ZeroBaseFee)
-	if block.Header().BaseFee != nil {
-		basefee = block.Header().BaseFee
-	}
+	basefee := blockCtx.BaseFee
 	gasCap := uint64(0)
 	if rpcGasCap := api.backend.RPCGasCap(); rpcGasCap != nil {
 		gasCap = rpcGasCap.Uint64()
@@ -874,18 +976,17 @@ func (api *CommonAPI) TraceCall(ctx context.Context, args klaytnapi.CallArgs, bl
 	}
 
 	// Add gas fee to sender for estimating gasLimit/computing cost or calling a function by insufficient balance sender.
-	statedb.AddBalance(msg.ValidatedSender(), new(big.Int).Mul(new(big.Int).SetUint64(msg.Gas()), basefee))
+	// It's taken back after the call not to be spent by the following calls of a bundle.
+	defer klaytnapi.PrefundGas(statedb, msg, basefee)()
 
-	txCtx := blockchain.NewEVMTxContext(msg, block.Header())
-	blockCtx := blockchain.NewEVMBlockContext(block.Header(), newChainContext(ctx, api.backend), nil)
-
-	return api.traceTx(ctx, msg, blockCtx, txCtx, statedb, config)
+	txCtx := blockchain.NewEVMTxContext(msg, header)
+	return api.traceTx(ctx, msg, new(Context), blockCtx, txCtx, statedb, config)
 }
 
 // traceTx configures a new tracer according to the provided configuration, and
 // executes the given message in the provided environment. The return value will
 // be tracer dependent.
-func (api *CommonAPI) traceTx(ctx context.Context, message blockchain.Message, blockCtx vm.BlockContext, txCtx vm.TxContext, statedb *state.StateDB, config *TraceConfig) (interface{}, error) {
+func (api *CommonAPI) traceTx(ctx context.Context, message blockchain.Message, txInfo *Context, blockCtx vm.BlockContext, txCtx vm.TxContext, statedb *state.StateDB, config *TraceConfig) (interface{}, error) {
 	// Assemble the structured logger or the JavaScript tracer
 	var (
 		tracer vm.Tracer
@@ -903,9 +1004,17 @@ func (api *CommonAPI) traceTx(ctx context.Context, message blockchain.Message, b
 
 		if *config.Tracer == fastCallTracer {
 			tracer = vm.NewInternalTxTracer()
+		} else if native, ok, err := NewNative(*config.Tracer, txInfo, config.TracerConfig); ok {
+			if err != nil {
+				return nil, err
+			}
+			if p, ok := native.(txPreparer); ok {
+				p.prepare(statedb, message)
+			}
+			tracer = native
 		} else {
 			// Construct the JavaScript tracer to execute with
-			if tracer, err = New(*config.Tracer, new(Context), api.unsafeTrace); err != nil {
+			if tracer, err = New(*config.Tracer, txInfo, api.unsafeTrace); err != nil {
 				return nil, err
 			}
 		}
@@ -919,6 +1028,8 @@ func (api *CommonAPI) traceTx(ctx context.Context, message blockchain.Message, b
 					t.Stop(errors.New("execution timeout"))
 				case *vm.InternalTxTracer:
 					t.Stop(errors.New("execution timeout"))
+				case NativeTracer:
+					t.Stop(errors.New("execution timeout"))
 				default:
 					logger.Warn("unknown tracer type", "type", reflect.TypeOf(t).String())
 				}
@@ -963,6 +1074,8 @@ func (api *CommonAPI) traceTx(ctx context.Context, message blockchain.Message, b
 		return tracer.GetResult()
 	case *vm.InternalTxTracer:
 		return tracer.GetResult()
+	case NativeTracer:
+		return tracer.GetResult()
 
 	default:
 		panic(fmt.Sprintf("bad tracer type %T", tracer))
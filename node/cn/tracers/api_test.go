@@ -206,7 +206,7 @@ func TestTraceCall(t *testing.T) {
 	testSuite := []struct {
 		blockNumber rpc.BlockNumber
 		call        klaytnapi.CallArgs
-		config      *TraceConfig
+		config      *TraceCallConfig
 		expectErr   error
 		expect      interface{}
 	}{
@@ -285,6 +285,27 @@ func TestTraceCall(t *testing.T) {
 				StructLogs:  []klaytnapi.StructLogRes{},
 			},
 		},
+		// Standard JSON trace upon the genesis, plain transfer with the overridden balance.
+		{
+			blockNumber: rpc.BlockNumber(0),
+			call: klaytnapi.CallArgs{
+				From:  accounts[0].addr,
+				To:    &accounts[1].addr,
+				Value: (hexutil.Big)(*big.NewInt(1000)),
+			},
+			config: &TraceCallConfig{
+				StateOverrides: &klaytnapi.EthStateOverride{
+					accounts[0].addr: klaytnapi.EthOverrideAccount{Balance: newRPCBalance(big.NewInt(1000))},
+				},
+			},
+			expectErr: nil,
+			expect: &klaytnapi.ExecutionResult{
+				Gas:         params.TxGas,
+				Failed:      false,
+				ReturnValue: "",
+				StructLogs:  []klaytnapi.StructLogRes{},
+			},
+		},
 	}
 	for _, testspec := range testSuite {
 		result, err := api.TraceCall(context.Background(), testspec.call, rpc.BlockNumberOrHash{BlockNumber: &testspec.blockNumber}, testspec.config)
@@ -293,6 +314,94 @@ func TestTraceCall(t *testing.T) {
 	}
 }
 
+func TestTraceCallMany(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts
+	accounts := newAccounts(2)
+	genesis := &blockchain.Genesis{Alloc: blockchain.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(0)},
+		accounts[1].addr: {Balance: big.NewInt(1000 * 10)},
+	}}
+	genBlocks := 10
+	signer := types.LatestSignerForChainID(params.TestChainConfig.ChainID)
+	api := NewAPI(newTestBackend(t, genBlocks, genesis, func(i int, b *blockchain.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(uint64(i), accounts[0].addr, big.NewInt(1000), params.TxGas, big.NewInt(0), nil), signer, accounts[1].key)
+		assert.NoError(t, err)
+		b.AddTx(tx)
+	}))
+
+	// The contract returns the block number: NUMBER PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	contract := common.HexToAddress("0xcccc")
+	code := hexutil.Bytes(common.FromHex("0x4360005260206000f3"))
+	config := &TraceCallConfig{
+		StateOverrides: &klaytnapi.EthStateOverride{
+			accounts[0].addr: klaytnapi.EthOverrideAccount{Balance: newRPCBalance(big.NewInt(1000))},
+			contract:         klaytnapi.EthOverrideAccount{Code: &code},
+		},
+		BlockOverrides: &klaytnapi.BlockOverrides{Number: (*hexutil.Big)(big.NewInt(100))},
+	}
+	transfer := klaytnapi.CallArgs{
+		From:  accounts[0].addr,
+		To:    &accounts[1].addr,
+		Value: (hexutil.Big)(*big.NewInt(1000)),
+	}
+	bundle := []klaytnapi.CallArgs{
+		transfer,
+		// The balance is spent by the previous call
+		transfer,
+		{From: accounts[0].addr, To: &contract},
+	}
+	genesisNumber := rpc.BlockNumber(0)
+	results, err := api.TraceCallMany(context.Background(), bundle, rpc.BlockNumberOrHash{BlockNumber: &genesisNumber}, config)
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+
+	assert.Empty(t, results[0].Error)
+	assert.Equal(t, &klaytnapi.ExecutionResult{
+		Gas:         params.TxGas,
+		Failed:      false,
+		ReturnValue: "",
+		StructLogs:  []klaytnapi.StructLogRes{},
+	}, results[0].Result)
+
+	assert.Equal(t, "tracing failed: insufficient balance for transfer", results[1].Error)
+	assert.Nil(t, results[1].Result)
+
+	assert.Empty(t, results[2].Error)
+	if assert.IsType(t, &klaytnapi.ExecutionResult{}, results[2].Result) {
+		result := results[2].Result.(*klaytnapi.ExecutionResult)
+		assert.False(t, result.Failed)
+		assert.Equal(t, fmt.Sprintf("%x", common.LeftPadBytes([]byte{100}, 32)), result.ReturnValue)
+	}
+
+	// The gas fee prefunded to the sender is taken back after each call, so the
+	// balance left by the first transfer can't pay for the second one.
+	config.BlockOverrides.BaseFeePerGas = (*hexutil.Big)(big.NewInt(1))
+	results, err = api.TraceCallMany(context.Background(), bundle[:2], rpc.BlockNumberOrHash{BlockNumber: &genesisNumber}, config)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Empty(t, results[0].Error)
+	assert.Equal(t, "tracing failed: insufficient balance for transfer", results[1].Error)
+
+	_, err = api.TraceCallMany(context.Background(), nil, rpc.BlockNumberOrHash{BlockNumber: &genesisNumber}, nil)
+	assert.Error(t, err)
+
+	// The bundle is rejected up front if it has too many calls.
+	_, err = api.TraceCallMany(context.Background(), make([]klaytnapi.CallArgs, maxTraceCallBundleSize+1), rpc.BlockNumberOrHash{BlockNumber: &genesisNumber}, nil)
+	assert.EqualError(t, err, fmt.Sprintf("too many calls in the bundle: %d > %d", maxTraceCallBundleSize+1, maxTraceCallBundleSize))
+
+	// The timeout bounds the whole bundle.
+	timeout := "0s"
+	_, err = api.TraceCallMany(context.Background(), bundle, rpc.BlockNumberOrHash{BlockNumber: &genesisNumber}, &TraceCallConfig{TraceConfig: TraceConfig{Timeout: &timeout}})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func newRPCBalance(balance *big.Int) **hexutil.Big {
+	rpcBalance := (*hexutil.Big)(balance)
+	return &rpcBalance
+}
+
 func TestTraceTransaction(t *testing.T) {
 	t.Parallel()
 
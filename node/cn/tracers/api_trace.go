@@ -0,0 +1,322 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
+)
+
+const (
+	// flatCallTracerName is the name of the native tracer producing the Parity
+	// style call traces.
+	flatCallTracerName = "flatCallTracer"
+
+	// maxTraceFilterBlockRange is the maximum number of blocks a single
+	// trace_filter request is allowed to scan.
+	maxTraceFilterBlockRange = 1000
+)
+
+// flatCallTracerConfigJSON makes the flatCallTracer report the errors as Parity does.
+var flatCallTracerConfigJSON = json.RawMessage(`{"convertParityErrors":true}`)
+
+// TraceAPI provides the Parity compatible trace namespace. The calls of the
+// transactions are reported as flat call traces. Since every method re-executes
+// transactions, the methods are limited by HeavyAPIRequestLimit.
+type TraceAPI struct {
+	commonAPI *CommonAPI
+}
+
+// NewTraceAPI creates a new TraceAPI definition
+func NewTraceAPI(backend Backend) *TraceAPI {
+	return &TraceAPI{
+		commonAPI: &CommonAPI{backend: backend, unsafeTrace: false},
+	}
+}
+
+// TraceFilterArgs holds the criteria of trace_filter.
+type TraceFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`
+	FromAddress []common.Address `json:"fromAddress"`
+	ToAddress   []common.Address `json:"toAddress"`
+	After       *uint64          `json:"after"`
+	Count       *uint64          `json:"count"`
+}
+
+// replayTrace is a call trace of trace_replayBlockTransactions. Unlike the other
+// methods, it does not contain the block and the transaction of the call.
+type replayTrace struct {
+	Action       flatCallAction  `json:"action"`
+	Error        string          `json:"error,omitempty"`
+	Result       *flatCallResult `json:"result,omitempty"`
+	Subtraces    int             `json:"subtraces"`
+	TraceAddress []int           `json:"traceAddress"`
+	Type         string          `json:"type"`
+}
+
+// replayResult is the result of replaying a single transaction.
+type replayResult struct {
+	Output          hexutil.Bytes  `json:"output"`
+	StateDiff       interface{}    `json:"stateDiff"`
+	Trace           []*replayTrace `json:"trace"`
+	VmTrace         interface{}    `json:"vmTrace"`
+	TransactionHash common.Hash    `json:"transactionHash"`
+}
+
+// Block returns the call traces of all the transactions in the given block.
+func (api *TraceAPI) Block(ctx context.Context, number rpc.BlockNumber) ([]*flatCallFrame, error) {
+	block, err := api.commonAPI.blockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	txFrames, err := api.traceBlock(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	frames := []*flatCallFrame{}
+	for _, txFrame := range txFrames {
+		frames = append(frames, txFrame...)
+	}
+	return frames, nil
+}
+
+// Transaction returns the call traces of the given transaction.
+func (api *TraceAPI) Transaction(ctx context.Context, hash common.Hash) ([]*flatCallFrame, error) {
+	tracer := flatCallTracerName
+	result, err := api.commonAPI.TraceTransaction(ctx, hash, &TraceConfig{Tracer: &tracer, TracerConfig: flatCallTracerConfigJSON})
+	if err != nil {
+		return nil, err
+	}
+	return decodeFlatCallFrames(result)
+}
+
+// ReplayBlockTransactions replays all the transactions in the given block and
+// returns the requested traces of them. Only the "trace" type is supported.
+func (api *TraceAPI) ReplayBlockTransactions(ctx context.Context, number rpc.BlockNumber, traceTypes []string) ([]*replayResult, error) {
+	withTrace := false
+	for _, traceType := range traceTypes {
+		if traceType != "trace" {
+			return nil, fmt.Errorf("unsupported trace type: %s", traceType)
+		}
+		withTrace = true
+	}
+	block, err := api.commonAPI.blockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	txFrames, err := api.traceBlock(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*replayResult, len(txFrames))
+	for i, frames := range txFrames {
+		result := &replayResult{Output: hexutil.Bytes{}, TransactionHash: block.Transactions()[i].Hash()}
+		if len(frames) > 0 && frames[0].Result != nil {
+			if frames[0].Result.Output != nil {
+				result.Output = *frames[0].Result.Output
+			} else if frames[0].Result.Code != nil {
+				result.Output = *frames[0].Result.Code
+			}
+		}
+		if withTrace {
+			result.Trace = make([]*replayTrace, len(frames))
+			for j, frame := range frames {
+				result.Trace[j] = &replayTrace{
+					Action:       frame.Action,
+					Error:        frame.Error,
+					Result:       frame.Result,
+					Subtraces:    frame.Subtraces,
+					TraceAddress: frame.TraceAddress,
+					Type:         frame.Type,
+				}
+			}
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// Filter returns the call traces in the given block range which match the
+// given from and to addresses. The blocks are traced one at a time, so that a
+// single request holds at most one slot of HeavyAPIRequestLimit.
+func (api *TraceAPI) Filter(ctx context.Context, args TraceFilterArgs) ([]*flatCallFrame, error) {
+	from, to, err := api.filterRange(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		fromAddresses = make(map[common.Address]struct{})
+		toAddresses   = make(map[common.Address]struct{})
+		skipped       uint64
+		frames        = []*flatCallFrame{}
+	)
+	for _, addr := range args.FromAddress {
+		fromAddresses[addr] = struct{}{}
+	}
+	for _, addr := range args.ToAddress {
+		toAddresses[addr] = struct{}{}
+	}
+	// The genesis block has no transactions to trace
+	if from == 0 {
+		from = 1
+	}
+	for number := from; number <= to; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block, err := api.commonAPI.blockByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		if block.Transactions().Len() == 0 {
+			continue
+		}
+		txFrames, err := api.traceBlock(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		for _, txFrame := range txFrames {
+			for _, frame := range txFrame {
+				if !frame.matches(fromAddresses, toAddresses) {
+					continue
+				}
+				if args.After != nil && skipped < *args.After {
+					skipped++
+					continue
+				}
+				frames = append(frames, frame)
+				if args.Count != nil && uint64(len(frames)) >= *args.Count {
+					return frames, nil
+				}
+			}
+		}
+	}
+	return frames, nil
+}
+
+// filterRange resolves the block range of trace_filter.
+func (api *TraceAPI) filterRange(ctx context.Context, args TraceFilterArgs) (uint64, uint64, error) {
+	resolve := func(number *rpc.BlockNumber, fallback rpc.BlockNumber) (uint64, error) {
+		if number == nil {
+			number = &fallback
+		}
+		header, err := api.commonAPI.backend.HeaderByNumber(ctx, *number)
+		if err != nil {
+			return 0, err
+		}
+		if header == nil {
+			return 0, fmt.Errorf("block #%d not found", *number)
+		}
+		return header.Number.Uint64(), nil
+	}
+	from, err := resolve(args.FromBlock, rpc.EarliestBlockNumber)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := resolve(args.ToBlock, rpc.LatestBlockNumber)
+	if err != nil {
+		return 0, 0, err
+	}
+	if from > to {
+		return 0, 0, fmt.Errorf("end block #%d needs to come after start block #%d", to, from)
+	}
+	if to-from >= maxTraceFilterBlockRange {
+		return 0, 0, fmt.Errorf("block range exceeds the limit: %d", maxTraceFilterBlockRange)
+	}
+	return from, to, nil
+}
+
+// traceBlock returns the call traces of each transaction in the given block.
+func (api *TraceAPI) traceBlock(ctx context.Context, block *types.Block) ([][]*flatCallFrame, error) {
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis is not traceable")
+	}
+	tracer := flatCallTracerName
+	results, err := api.commonAPI.traceBlock(ctx, block, &TraceConfig{Tracer: &tracer, TracerConfig: flatCallTracerConfigJSON})
+	if err != nil {
+		return nil, err
+	}
+	txFrames := make([][]*flatCallFrame, len(results))
+	for i, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("failed to trace transaction %#x: %v", result.TxHash, result.Error)
+		}
+		if txFrames[i], err = decodeFlatCallFrames(result.Result); err != nil {
+			return nil, err
+		}
+	}
+	return txFrames, nil
+}
+
+// decodeFlatCallFrames decodes the result of the flatCallTracer.
+func decodeFlatCallFrames(result interface{}) ([]*flatCallFrame, error) {
+	encoded, ok := result.(json.RawMessage)
+	if !ok {
+		return nil, fmt.Errorf("unexpected trace result type: %T", result)
+	}
+	var frames []*flatCallFrame
+	if err := json.Unmarshal(encoded, &frames); err != nil {
+		return nil, err
+	}
+	return frames, nil
+}
+
+// matches reports whether the call is sent from one of the from addresses and
+// to one of the to addresses. An empty set of addresses matches any address.
+func (frame *flatCallFrame) matches(fromAddresses, toAddresses map[common.Address]struct{}) bool {
+	var from, to *common.Address
+	switch frame.Type {
+	case "create":
+		from = frame.Action.From
+		if frame.Result != nil {
+			to = frame.Result.Address
+		}
+	case "suicide":
+		from, to = frame.Action.SelfDestructed, frame.Action.RefundAddress
+	default:
+		from, to = frame.Action.From, frame.Action.To
+	}
+	return containsAddress(fromAddresses, from) && containsAddress(toAddresses, to)
+}
+
+func containsAddress(addresses map[common.Address]struct{}, addr *common.Address) bool {
+	if len(addresses) == 0 {
+		return true
+	}
+	if addr == nil {
+		return false
+	}
+	_, ok := addresses[*addr]
+	return ok
+}
@@ -0,0 +1,148 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceAPI(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts
+	accounts := newAccounts(3)
+	genesis := &blockchain.Genesis{Alloc: blockchain.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.KLAY)},
+		accounts[1].addr: {Balance: big.NewInt(params.KLAY)},
+		accounts[2].addr: {Balance: big.NewInt(params.KLAY)},
+	}}
+	genBlocks := 5
+	signer := types.LatestSignerForChainID(params.TestChainConfig.ChainID)
+	var txHashes []common.Hash
+	api := NewTraceAPI(newTestBackend(t, genBlocks, genesis, func(i int, b *blockchain.BlockGen) {
+		// Transfer from account[0] to account[1] in every block
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), accounts[1].addr, big.NewInt(1000), params.TxGas, big.NewInt(0), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+		txHashes = append(txHashes, tx.Hash())
+
+		// Transfer from account[2] to account[0] in the third block
+		if i == 2 {
+			tx, _ := types.SignTx(types.NewTransaction(0, accounts[0].addr, big.NewInt(2000), params.TxGas, big.NewInt(0), nil), signer, accounts[2].key)
+			b.AddTx(tx)
+			txHashes = append(txHashes, tx.Hash())
+		}
+	}))
+	ctx := context.Background()
+
+	// trace_block
+	frames, err := api.Block(ctx, rpc.BlockNumber(3))
+	assert.NoError(t, err)
+	if assert.Len(t, frames, 2) {
+		assert.Equal(t, "call", frames[0].Type)
+		assert.Equal(t, "call", frames[0].Action.CallType)
+		assert.Equal(t, accounts[0].addr, *frames[0].Action.From)
+		assert.Equal(t, accounts[1].addr, *frames[0].Action.To)
+		assert.Equal(t, big.NewInt(1000), frames[0].Action.Value.ToInt())
+		assert.Equal(t, uint64(3), frames[0].BlockNumber)
+		assert.Equal(t, txHashes[2], *frames[0].TransactionHash)
+		assert.Equal(t, uint64(0), *frames[0].TransactionPosition)
+		assert.Equal(t, []int{}, frames[0].TraceAddress)
+		assert.Equal(t, txHashes[3], *frames[1].TransactionHash)
+		assert.Equal(t, uint64(1), *frames[1].TransactionPosition)
+	}
+	_, err = api.Block(ctx, rpc.BlockNumber(0))
+	assert.EqualError(t, err, "genesis is not traceable")
+
+	// trace_transaction
+	frames, err = api.Transaction(ctx, txHashes[3])
+	assert.NoError(t, err)
+	if assert.Len(t, frames, 1) {
+		assert.Equal(t, accounts[2].addr, *frames[0].Action.From)
+		assert.Equal(t, accounts[0].addr, *frames[0].Action.To)
+		assert.Equal(t, big.NewInt(2000), frames[0].Action.Value.ToInt())
+	}
+
+	// trace_replayBlockTransactions
+	replays, err := api.ReplayBlockTransactions(ctx, rpc.BlockNumber(3), []string{"trace"})
+	assert.NoError(t, err)
+	if assert.Len(t, replays, 2) {
+		assert.Equal(t, txHashes[2], replays[0].TransactionHash)
+		assert.Len(t, replays[0].Trace, 1)
+		assert.Equal(t, txHashes[3], replays[1].TransactionHash)
+		assert.Len(t, replays[1].Trace, 1)
+	}
+	_, err = api.ReplayBlockTransactions(ctx, rpc.BlockNumber(3), []string{"vmTrace"})
+	assert.EqualError(t, err, "unsupported trace type: vmTrace")
+
+	// trace_filter
+	testSuite := []struct {
+		args   TraceFilterArgs
+		expect []common.Hash
+	}{
+		{
+			args:   TraceFilterArgs{},
+			expect: txHashes,
+		},
+		{
+			args:   TraceFilterArgs{FromAddress: []common.Address{accounts[2].addr}},
+			expect: []common.Hash{txHashes[3]},
+		},
+		{
+			args:   TraceFilterArgs{ToAddress: []common.Address{accounts[0].addr, accounts[2].addr}},
+			expect: []common.Hash{txHashes[3]},
+		},
+		{
+			args:   TraceFilterArgs{FromBlock: newRPCBlockNumber(2), ToBlock: newRPCBlockNumber(4), ToAddress: []common.Address{accounts[1].addr}},
+			expect: []common.Hash{txHashes[1], txHashes[2], txHashes[4]},
+		},
+		{
+			args:   TraceFilterArgs{After: newUint64(1), Count: newUint64(2)},
+			expect: []common.Hash{txHashes[1], txHashes[2]},
+		},
+	}
+	for i, tc := range testSuite {
+		frames, err := api.Filter(ctx, tc.args)
+		if !assert.NoError(t, err, "test %d", i) {
+			continue
+		}
+		var hashes []common.Hash
+		for _, frame := range frames {
+			hashes = append(hashes, *frame.TransactionHash)
+		}
+		assert.Equal(t, tc.expect, hashes, "test %d", i)
+	}
+	_, err = api.Filter(ctx, TraceFilterArgs{FromBlock: newRPCBlockNumber(4), ToBlock: newRPCBlockNumber(2)})
+	assert.Error(t, err)
+}
+
+func newRPCBlockNumber(n int64) *rpc.BlockNumber {
+	number := rpc.BlockNumber(n)
+	return &number
+}
+
+func newUint64(n uint64) *uint64 {
+	return &n
+}
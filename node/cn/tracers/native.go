@@ -0,0 +1,62 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/vm"
+)
+
+// NativeTracer is a transaction tracer implemented in Go. It is selected by the
+// same name as the JavaScript tracer it replaces, and produces the same output.
+type NativeTracer interface {
+	vm.Tracer
+	GetResult() (json.RawMessage, error)
+	Stop(err error)
+}
+
+// txPreparer is implemented by the native tracers which need to see the state
+// before the message is applied.
+type txPreparer interface {
+	prepare(statedb vm.StateDB, msg blockchain.Message)
+}
+
+// nativeCtor creates a native tracer with the given context and tracer config.
+type nativeCtor func(ctx *Context, cfg json.RawMessage) (NativeTracer, error)
+
+// natives contains all the built in native tracers by name.
+var natives = map[string]nativeCtor{
+	"prestateTracer": newPrestateTracer,
+	"4byteTracer":    newFourByteTracer,
+	"flatCallTracer": newFlatCallTracer,
+}
+
+// NewNative instantiates the native tracer of the given name. It returns false
+// if there is no native tracer of that name.
+func NewNative(name string, ctx *Context, cfg json.RawMessage) (NativeTracer, bool, error) {
+	ctor, ok := natives[name]
+	if !ok {
+		return nil, false, nil
+	}
+	if ctx == nil {
+		ctx = new(Context)
+	}
+	tracer, err := ctor(ctx, cfg)
+	return tracer, true, err
+}
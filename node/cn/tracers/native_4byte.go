@@ -0,0 +1,145 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+)
+
+// fourByteTracer is the native version of 4byte_tracer.js. It searches for
+// 4byte-identifiers, and collects them for post-processing. It collects the
+// methods identifiers along with the size of the supplied data, so a reversed
+// signature can be matched against the size of the data.
+//
+// Example:
+//
+//	> debug.traceTransaction( "0x214e597e35da083692f5386141e69f47e973b2c56e7a8073b1ea08fd7571e9de", {tracer: "4byteTracer"})
+//	{
+//	  0x27dc297e-128: 1,
+//	  0x38cc4831-0: 2,
+//	  0x524f3889-96: 1,
+//	  0xadf59f99-288: 1,
+//	  0xc281d19e-0: 1
+//	}
+type fourByteTracer struct {
+	ids         map[string]int   // ids aggregates the 4byte ids found
+	precompiles []common.Address // Addresses of the precompiled contracts
+	input       []byte           // Input of the outer call
+
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+func newFourByteTracer(ctx *Context, cfg json.RawMessage) (NativeTracer, error) {
+	return &fourByteTracer{ids: make(map[string]int)}, nil
+}
+
+// store saves the given identifier and datasize.
+func (t *fourByteTracer) store(id []byte, size int) {
+	key := hexutil.Encode(id) + "-" + strconv.Itoa(size)
+	t.ids[key] += 1
+}
+
+func (t *fourByteTracer) isPrecompiled(addr common.Address) bool {
+	for _, p := range t.precompiles {
+		if p == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *fourByteTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.precompiles = vm.ActivePrecompiles(env.ChainConfig().Rules(env.Context.BlockNumber))
+	t.input = common.CopyBytes(input)
+}
+
+func (t *fourByteTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		return
+	}
+	// Skip any opcodes that are not internal calls. The stack position of the
+	// input offset is returned for the calls.
+	var inPos int
+	switch op {
+	case vm.CALL, vm.CALLCODE:
+		// gas, addr, val, memin, meminsz, memout, memoutsz
+		inPos = 3
+	case vm.DELEGATECALL, vm.STATICCALL:
+		// gas, addr, memin, meminsz, memout, memoutsz
+		inPos = 2
+	default:
+		return
+	}
+	stack := scope.Stack
+	if len(stack.Data()) < inPos+2 {
+		return
+	}
+	// Skip any pre-compile invocations, those are just fancy opcodes
+	if t.isPrecompiled(common.Address(stack.Back(1).Bytes20())) {
+		return
+	}
+	// Gather internal call details
+	inSize := stack.Back(inPos + 1)
+	if !inSize.IsUint64() || inSize.Uint64() < 4 {
+		return
+	}
+	id, ok := memorySlice(scope.Memory, stack.Back(inPos).Uint64(), 4)
+	if !ok {
+		return
+	}
+	t.store(id, int(inSize.Uint64()-4))
+}
+
+func (t *fourByteTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func (t *fourByteTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+
+func (t *fourByteTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+func (t *fourByteTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+func (t *fourByteTracer) CaptureTxStart(gasLimit uint64) {}
+
+func (t *fourByteTracer) CaptureTxEnd(restGas uint64) {}
+
+// GetResult returns the collected 4byte ids with their counts.
+func (t *fourByteTracer) GetResult() (json.RawMessage, error) {
+	if t.reason != nil {
+		return nil, t.reason
+	}
+	// Save the outer calldata also
+	if len(t.input) >= 4 {
+		t.store(t.input[:4], len(t.input)-4)
+	}
+	return json.Marshal(t.ids)
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *fourByteTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
@@ -0,0 +1,264 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/kerrors"
+)
+
+// parityErrors maps the EVM errors to the error strings of Parity.
+var parityErrors = map[string]string{
+	kerrors.ErrOutOfGas.Error():            "Out of gas",
+	vm.ErrCodeStoreOutOfGas.Error():        "Out of gas",
+	vm.ErrDepth.Error():                    "Out of stack",
+	vm.ErrInsufficientBalance.Error():      "Insufficient balance for transfer",
+	vm.ErrContractAddressCollision.Error(): "Contract address collision",
+	vm.ErrExecutionReverted.Error():        "Reverted",
+	"execution reverted":                   "Reverted",
+	vm.ErrInvalidJump.Error():              "Bad jump destination",
+	vm.ErrWriteProtection.Error():          "Mutable call in static context",
+}
+
+// flatCallAction is the action of a call in the Parity trace format.
+type flatCallAction struct {
+	CallType       string          `json:"callType,omitempty"`
+	CreationMethod string          `json:"creationMethod,omitempty"`
+	From           *common.Address `json:"from,omitempty"`
+	To             *common.Address `json:"to,omitempty"`
+	Gas            *hexutil.Uint64 `json:"gas,omitempty"`
+	Input          *hexutil.Bytes  `json:"input,omitempty"`
+	Init           *hexutil.Bytes  `json:"init,omitempty"`
+	Value          *hexutil.Big    `json:"value,omitempty"`
+
+	// Fields of the self-destructs
+	SelfDestructed *common.Address `json:"address,omitempty"`
+	RefundAddress  *common.Address `json:"refundAddress,omitempty"`
+	Balance        *hexutil.Big    `json:"balance,omitempty"`
+}
+
+// flatCallResult is the result of a call in the Parity trace format.
+type flatCallResult struct {
+	Address *common.Address `json:"address,omitempty"`
+	Code    *hexutil.Bytes  `json:"code,omitempty"`
+	GasUsed hexutil.Uint64  `json:"gasUsed"`
+	Output  *hexutil.Bytes  `json:"output,omitempty"`
+}
+
+// flatCallFrame is a call in the Parity trace format.
+type flatCallFrame struct {
+	Action              flatCallAction  `json:"action"`
+	BlockHash           *common.Hash    `json:"blockHash"`
+	BlockNumber         uint64          `json:"blockNumber"`
+	Error               string          `json:"error,omitempty"`
+	Result              *flatCallResult `json:"result,omitempty"`
+	Subtraces           int             `json:"subtraces"`
+	TraceAddress        []int           `json:"traceAddress"`
+	TransactionHash     *common.Hash    `json:"transactionHash"`
+	TransactionPosition *uint64         `json:"transactionPosition"`
+	Type                string          `json:"type"`
+}
+
+type flatCallTracerConfig struct {
+	ConvertParityErrors bool `json:"convertParityErrors"` // If true, the EVM errors are reported as the Parity errors
+}
+
+// flatCallTracer reports the calls of a transaction as a flat list in the Parity
+// trace format. It collects the calls with the native call tracer.
+type flatCallTracer struct {
+	tracer *vm.InternalTxTracer
+	config flatCallTracerConfig
+	ctx    *Context
+
+	blockNumber  uint64
+	gasLimit     uint64
+	intrinsicGas uint64
+}
+
+func newFlatCallTracer(ctx *Context, cfg json.RawMessage) (NativeTracer, error) {
+	var config flatCallTracerConfig
+	if cfg != nil {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, err
+		}
+	}
+	return &flatCallTracer{tracer: vm.NewInternalTxTracer(), config: config, ctx: ctx}, nil
+}
+
+func (t *flatCallTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.blockNumber = env.Context.BlockNumber.Uint64()
+	if t.gasLimit > gas {
+		t.intrinsicGas = t.gasLimit - gas
+	}
+	t.tracer.CaptureStart(env, from, to, create, input, gas, value)
+}
+
+func (t *flatCallTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+	t.tracer.CaptureState(env, pc, op, gas, cost, ccLeft, ccOpcode, scope, depth, err)
+}
+
+func (t *flatCallTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+	t.tracer.CaptureFault(env, pc, op, gas, cost, ccLeft, ccOpcode, scope, depth, err)
+}
+
+func (t *flatCallTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	t.tracer.CaptureEnd(output, gasUsed, err)
+}
+
+func (t *flatCallTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.tracer.CaptureEnter(typ, from, to, input, gas, value)
+}
+
+func (t *flatCallTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	t.tracer.CaptureExit(output, gasUsed, err)
+}
+
+func (t *flatCallTracer) CaptureTxStart(gasLimit uint64) {
+	t.gasLimit = gasLimit
+	t.tracer.CaptureTxStart(gasLimit)
+}
+
+func (t *flatCallTracer) CaptureTxEnd(restGas uint64) {
+	t.tracer.CaptureTxEnd(restGas)
+}
+
+// GetResult returns the calls of the transaction in the Parity trace format.
+func (t *flatCallTracer) GetResult() (json.RawMessage, error) {
+	trace, err := t.tracer.GetResult()
+	if err != nil {
+		return nil, err
+	}
+	// The gas used of the outer call does not include the intrinsic gas in Parity
+	if trace.GasUsed >= t.intrinsicGas {
+		trace.GasUsed -= t.intrinsicGas
+	}
+	frames := t.flatten(trace, []int{}, nil)
+	return json.Marshal(frames)
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *flatCallTracer) Stop(err error) {
+	t.tracer.Stop(err)
+}
+
+// flatten converts the call and its nested calls into the flat call frames.
+func (t *flatCallTracer) flatten(call *vm.InternalTxTrace, traceAddress []int, parent *vm.InternalTxTrace) []flatCallFrame {
+	frame := t.newFrame(call, parent)
+	frame.Subtraces = len(call.Calls)
+	frame.TraceAddress = traceAddress
+
+	frames := []flatCallFrame{frame}
+	for i, child := range call.Calls {
+		childAddress := make([]int, len(traceAddress)+1)
+		copy(childAddress, traceAddress)
+		childAddress[len(traceAddress)] = i
+		frames = append(frames, t.flatten(child, childAddress, call)...)
+	}
+	return frames
+}
+
+// newFrame converts a single call into a flat call frame.
+func (t *flatCallTracer) newFrame(call *vm.InternalTxTrace, parent *vm.InternalTxTrace) flatCallFrame {
+	frame := flatCallFrame{BlockNumber: t.blockNumber}
+	if t.ctx.BlockHash != (common.Hash{}) {
+		blockHash := t.ctx.BlockHash
+		frame.BlockHash = &blockHash
+	}
+	if t.ctx.TxHash != (common.Hash{}) {
+		txHash, txIndex := t.ctx.TxHash, uint64(t.ctx.TxIndex)
+		frame.TransactionHash = &txHash
+		frame.TransactionPosition = &txIndex
+	}
+
+	value := parseHexBig(call.Value)
+	if value == nil && parent != nil && call.Type == vm.DELEGATECALL.String() {
+		// The delegated call carries the value of its parent
+		value = parseHexBig(parent.Value)
+	}
+	if value == nil {
+		value = new(hexutil.Big)
+	}
+	var (
+		gas     = hexutil.Uint64(call.Gas)
+		input   = hexutil.Bytes(common.FromHex(call.Input))
+		output  = hexutil.Bytes(common.FromHex(call.Output))
+		gasUsed = hexutil.Uint64(call.GasUsed)
+	)
+	switch call.Type {
+	case vm.CREATE.String(), vm.CREATE2.String():
+		frame.Type = "create"
+		frame.Action = flatCallAction{
+			CreationMethod: strings.ToLower(call.Type),
+			From:           call.From,
+			Gas:            &gas,
+			Init:           &input,
+			Value:          value,
+		}
+		frame.Result = &flatCallResult{Address: call.To, Code: &output, GasUsed: gasUsed}
+
+	case vm.OpCode(vm.SELFDESTRUCT).String():
+		frame.Type = "suicide"
+		frame.Action = flatCallAction{
+			SelfDestructed: call.From,
+			RefundAddress:  call.To,
+			Balance:        value,
+		}
+
+	default:
+		frame.Type = "call"
+		frame.Action = flatCallAction{
+			CallType: strings.ToLower(call.Type),
+			From:     call.From,
+			To:       call.To,
+			Gas:      &gas,
+			Input:    &input,
+			Value:    value,
+		}
+		frame.Result = &flatCallResult{GasUsed: gasUsed, Output: &output}
+	}
+
+	if call.Error != nil {
+		frame.Error = call.Error.Error()
+		if t.config.ConvertParityErrors {
+			if parityErr, ok := parityErrors[frame.Error]; ok {
+				frame.Error = parityErr
+			}
+		}
+		// Parity reports no result for the failed calls
+		frame.Result = nil
+	}
+	return frame
+}
+
+// parseHexBig parses the hex encoded value of a call. It returns nil if the
+// value is empty.
+func parseHexBig(s string) *hexutil.Big {
+	if s == "" {
+		return nil
+	}
+	v, ok := new(big.Int).SetString(strings.TrimPrefix(s, "0x"), 16)
+	if !ok {
+		return nil
+	}
+	return (*hexutil.Big)(v)
+}
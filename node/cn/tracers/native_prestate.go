@@ -0,0 +1,351 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+)
+
+// prestateAccount is an account of the prestate. It has the same form as the
+// account reported by prestate_tracer.js.
+type prestateAccount struct {
+	Balance *hexutil.Big                `json:"balance"`
+	Nonce   uint64                      `json:"nonce"`
+	Code    hexutil.Bytes               `json:"code"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+// diffAccount is an account of the state diff. Only the fields which have been
+// changed by the transaction are reported.
+type diffAccount struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Nonce   uint64                      `json:"nonce,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+type prestateDiff struct {
+	Pre  map[common.Address]*diffAccount `json:"pre"`
+	Post map[common.Address]*diffAccount `json:"post"`
+}
+
+type prestateTracerConfig struct {
+	DiffMode bool `json:"diffMode"` // If true, the tracer reports the state changes instead of the prestate
+}
+
+// prestateTracer is the native version of prestate_tracer.js. It outputs sufficient
+// information to create a local execution of the transaction from a custom assembled
+// genesis block. In the diff mode, it outputs the accounts and the storage slots
+// modified by the transaction, with their values before and after the execution.
+type prestateTracer struct {
+	config   prestateTracerConfig
+	env      *vm.EVM
+	prestate map[common.Address]*prestateAccount
+
+	from   common.Address
+	to     common.Address
+	create bool
+	value  *big.Int
+
+	// existed records whether the accounts of the prestate existed before the
+	// message is applied, used in the diff mode
+	existed map[common.Address]bool
+	diff    *prestateDiff
+
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+func newPrestateTracer(ctx *Context, cfg json.RawMessage) (NativeTracer, error) {
+	var config prestateTracerConfig
+	if cfg != nil {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, err
+		}
+	}
+	return &prestateTracer{
+		config:   config,
+		prestate: make(map[common.Address]*prestateAccount),
+		existed:  make(map[common.Address]bool),
+	}, nil
+}
+
+// prepare records the accounts changed before the execution starts, namely the
+// payers of the fee and the recipient of the value, before the message is applied.
+// The diff mode compares them with the values after the execution.
+func (t *prestateTracer) prepare(statedb vm.StateDB, msg blockchain.Message) {
+	if !t.config.DiffMode {
+		return
+	}
+	sender := msg.ValidatedSender()
+	t.recordAccount(statedb, sender)
+	t.recordAccount(statedb, msg.ValidatedFeePayer())
+	if to := msg.To(); to != nil {
+		t.recordAccount(statedb, *to)
+	} else {
+		t.recordAccount(statedb, crypto.CreateAddress(sender, statedb.GetNonce(sender)))
+	}
+}
+
+func (t *prestateTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.env = env
+	t.from, t.to, t.create, t.value = from, to, create, value
+	if t.config.DiffMode {
+		t.lookupAccount(from)
+		t.lookupAccount(to)
+		t.lookupAccount(env.Context.Coinbase)
+		t.lookupAccount(env.Context.Rewardbase)
+	}
+}
+
+func (t *prestateTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		return
+	}
+	stack := scope.Stack
+	caller := scope.Contract.Address()
+
+	// Add the current account if we just started tracing
+	if len(t.prestate) == 0 {
+		t.lookupAccount(caller)
+	}
+	// Whenever new state is accessed, add it to the prestate
+	switch op {
+	case vm.EXTCODECOPY, vm.EXTCODESIZE, vm.BALANCE:
+		if len(stack.Data()) >= 1 {
+			t.lookupAccount(common.Address(stack.Back(0).Bytes20()))
+		}
+	case vm.CREATE:
+		t.lookupAccount(crypto.CreateAddress(caller, env.StateDB.GetNonce(caller)))
+	case vm.CREATE2:
+		// stack: endowment, offset, size, salt
+		if len(stack.Data()) >= 4 {
+			offset, size := stack.Back(1), stack.Back(2)
+			code, ok := memorySlice(scope.Memory, offset.Uint64(), size.Uint64())
+			if ok {
+				salt := stack.Back(3).Bytes32()
+				t.lookupAccount(crypto.CreateAddress2(caller, salt, crypto.Keccak256(code)))
+			}
+		}
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		if len(stack.Data()) >= 2 {
+			t.lookupAccount(common.Address(stack.Back(1).Bytes20()))
+		}
+	case vm.SSTORE, vm.SLOAD:
+		if len(stack.Data()) >= 1 {
+			t.lookupStorage(caller, common.Hash(stack.Back(0).Bytes32()))
+		}
+	}
+}
+
+func (t *prestateTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func (t *prestateTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+
+func (t *prestateTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+func (t *prestateTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+func (t *prestateTracer) CaptureTxStart(gasLimit uint64) {}
+
+// CaptureTxEnd builds the state diff after the fees have been settled.
+func (t *prestateTracer) CaptureTxEnd(restGas uint64) {
+	if t.config.DiffMode && t.env != nil {
+		t.diff = t.processDiffState()
+	}
+}
+
+// GetResult returns the prestate, or the state diff in the diff mode.
+func (t *prestateTracer) GetResult() (json.RawMessage, error) {
+	if t.reason != nil {
+		return nil, t.reason
+	}
+	if t.config.DiffMode {
+		if t.diff == nil {
+			t.diff = &prestateDiff{Pre: map[common.Address]*diffAccount{}, Post: map[common.Address]*diffAccount{}}
+		}
+		return json.Marshal(t.diff)
+	}
+	if t.env == nil {
+		return json.Marshal(t.prestate)
+	}
+	// At this point, we need to deduct the 'value' from the
+	// outer transaction, and move it back to the origin
+	t.lookupAccount(t.from)
+	t.lookupAccount(t.to)
+
+	value := t.value
+	if value == nil {
+		value = new(big.Int)
+	}
+	from, to := t.prestate[t.from], t.prestate[t.to]
+	to.Balance = (*hexutil.Big)(new(big.Int).Sub(to.Balance.ToInt(), value))
+	from.Balance = (*hexutil.Big)(new(big.Int).Add(from.Balance.ToInt(), value))
+
+	// Decrement the caller's nonce, and remove empty create targets
+	from.Nonce--
+	if t.create {
+		// We can blindly delete the contract prestate, as any existing state would
+		// have caused the transaction to be rejected as invalid in the first place.
+		delete(t.prestate, t.to)
+	}
+	return json.Marshal(t.prestate)
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *prestateTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// lookupAccount injects the specified account into the prestate.
+func (t *prestateTracer) lookupAccount(addr common.Address) {
+	t.recordAccount(t.env.StateDB, addr)
+}
+
+// recordAccount injects the specified account into the prestate with its values
+// in the given state, unless it is already recorded.
+func (t *prestateTracer) recordAccount(db vm.StateDB, addr common.Address) {
+	if _, ok := t.prestate[addr]; ok {
+		return
+	}
+	t.prestate[addr] = &prestateAccount{
+		Balance: (*hexutil.Big)(new(big.Int).Set(db.GetBalance(addr))),
+		Nonce:   db.GetNonce(addr),
+		Code:    common.CopyBytes(db.GetCode(addr)),
+		Storage: make(map[common.Hash]common.Hash),
+	}
+	if t.config.DiffMode {
+		t.existed[addr] = db.Exist(addr)
+	}
+}
+
+// lookupStorage injects the specified storage entry of the given account into
+// the prestate.
+func (t *prestateTracer) lookupStorage(addr common.Address, key common.Hash) {
+	t.lookupAccount(addr)
+	if _, ok := t.prestate[addr].Storage[key]; ok {
+		return
+	}
+	t.prestate[addr].Storage[key] = t.env.StateDB.GetState(addr, key)
+}
+
+// processDiffState compares the accounts of the prestate, recorded when they were
+// first touched, with the ones after the execution. Unmodified accounts and storage
+// slots are left out, and so are the slots which are empty before or after the
+// execution.
+func (t *prestateTracer) processDiffState() *prestateDiff {
+	diff := &prestateDiff{
+		Pre:  make(map[common.Address]*diffAccount),
+		Post: make(map[common.Address]*diffAccount),
+	}
+	post := t.env.StateDB
+	for addr, acc := range t.prestate {
+		var (
+			existed = t.existed[addr]
+			exists  = post.Exist(addr) && !post.HasSelfDestructed(addr)
+		)
+		if !existed && !exists {
+			continue
+		}
+		preAcc := &diffAccount{Storage: make(map[common.Hash]common.Hash)}
+		if existed {
+			preAcc.Balance = acc.Balance
+			preAcc.Nonce = acc.Nonce
+			preAcc.Code = acc.Code
+		}
+		if !exists {
+			// The account has been destructed, report the prestate only
+			for key, val := range acc.Storage {
+				if val != (common.Hash{}) {
+					preAcc.Storage[key] = val
+				}
+			}
+			diff.Pre[addr] = preAcc
+			continue
+		}
+
+		var (
+			modified bool
+			postAcc  = &diffAccount{Storage: make(map[common.Hash]common.Hash)}
+			balance  = post.GetBalance(addr)
+			nonce    = post.GetNonce(addr)
+			code     = post.GetCode(addr)
+		)
+		if preAcc.Balance == nil || preAcc.Balance.ToInt().Cmp(balance) != 0 {
+			modified = true
+			postAcc.Balance = (*hexutil.Big)(new(big.Int).Set(balance))
+		}
+		if nonce != preAcc.Nonce {
+			modified = true
+			postAcc.Nonce = nonce
+		}
+		if !bytes.Equal(code, preAcc.Code) {
+			modified = true
+			postAcc.Code = common.CopyBytes(code)
+		}
+		for key, val := range acc.Storage {
+			var prevVal common.Hash
+			if existed {
+				prevVal = val
+			}
+			newVal := post.GetState(addr, key)
+			if prevVal == newVal {
+				continue
+			}
+			modified = true
+			if prevVal != (common.Hash{}) {
+				preAcc.Storage[key] = prevVal
+			}
+			if newVal != (common.Hash{}) {
+				postAcc.Storage[key] = newVal
+			}
+		}
+		if !modified {
+			continue
+		}
+		if existed {
+			diff.Pre[addr] = preAcc
+		}
+		diff.Post[addr] = postAcc
+	}
+	return diff
+}
+
+// memorySlice returns a copy of the memory in the given range, or false if the
+// range is out of bounds.
+func memorySlice(mem *vm.Memory, offset, size uint64) ([]byte, bool) {
+	if size == 0 {
+		return []byte{}, true
+	}
+	end := offset + size
+	if end < offset || end > uint64(mem.Len()) {
+		return nil, false
+	}
+	return common.CopyBytes(mem.Data()[offset:end]), true
+}
@@ -0,0 +1,291 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/common/math"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readCallTracerTests reads all the callTracer test cases in the testdata.
+func readCallTracerTests(t *testing.T) map[string]*callTracerTest {
+	files, err := os.ReadDir("testdata")
+	require.NoError(t, err)
+
+	testsByName := make(map[string]*callTracerTest)
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name(), "call_tracer_") {
+			continue
+		}
+		blob, err := os.ReadFile(filepath.Join("testdata", file.Name()))
+		require.NoError(t, err)
+		test := new(callTracerTest)
+		require.NoError(t, json.Unmarshal(blob, test))
+		testsByName[camel(strings.TrimSuffix(strings.TrimPrefix(file.Name(), "call_tracer_"), ".json"))] = test
+	}
+	return testsByName
+}
+
+// runCallTracerTest executes the transaction of the test case with the given tracer.
+func runCallTracerTest(t *testing.T, test *callTracerTest, tracer vm.Tracer) {
+	signer := types.MakeSigner(test.Genesis.Config, new(big.Int).SetUint64(uint64(test.Context.Number)))
+	tx := new(types.Transaction)
+	if test.Input != "" {
+		require.NoError(t, rlp.DecodeBytes(common.FromHex(test.Input), tx))
+	} else {
+		value := new(big.Int)
+		gasPrice := new(big.Int)
+		require.NoError(t, value.UnmarshalJSON([]byte(test.Transaction["value"])))
+		require.NoError(t, gasPrice.UnmarshalJSON([]byte(test.Transaction["gasPrice"])))
+		nonce, ok := math.ParseUint64(test.Transaction["nonce"])
+		require.True(t, ok)
+		gas, ok := math.ParseUint64(test.Transaction["gas"])
+		require.True(t, ok)
+
+		to := common.HexToAddress(test.Transaction["to"])
+		tx = types.NewTransaction(nonce, to, value, gas, gasPrice, common.FromHex(test.Transaction["input"]))
+
+		testKey, err := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		require.NoError(t, err)
+		require.NoError(t, tx.Sign(signer, testKey))
+	}
+	origin, _ := signer.Sender(tx)
+
+	txContext := vm.TxContext{
+		Origin:   origin,
+		GasPrice: tx.GasPrice(),
+	}
+	blockContext := vm.BlockContext{
+		CanTransfer: blockchain.CanTransfer,
+		Transfer:    blockchain.Transfer,
+		Coinbase:    test.Context.Miner,
+		BlockNumber: new(big.Int).SetUint64(uint64(test.Context.Number)),
+		Time:        new(big.Int).SetUint64(uint64(test.Context.Time)),
+		BlockScore:  (*big.Int)(test.Context.BlockScore),
+		GasLimit:    uint64(test.Context.GasLimit),
+	}
+	statedb := tests.MakePreState(database.NewMemoryDBManager(), test.Genesis.Alloc)
+	evm := vm.NewEVM(blockContext, txContext, statedb, test.Genesis.Config, &vm.Config{Debug: true, Tracer: tracer})
+
+	fork.SetHardForkBlockNumberConfig(test.Genesis.Config)
+	msg, err := tx.AsMessageWithAccountKeyPicker(signer, statedb, blockContext.BlockNumber.Uint64())
+	require.NoError(t, err)
+	if p, ok := tracer.(txPreparer); ok {
+		p.prepare(statedb, msg)
+	}
+	_, err = blockchain.NewStateTransition(evm, msg).TransitionDb()
+	require.NoError(t, err)
+}
+
+// Iterates over all the input-output datasets in the tracer test harness and
+// checks that the native tracers produce the same output as the JavaScript ones.
+func TestNativeTracersMatchJS(t *testing.T) {
+	for name, test := range readCallTracerTests(t) {
+		test := test
+		for _, tracerName := range []string{"prestateTracer", "4byteTracer"} {
+			tracerName := tracerName
+			t.Run(tracerName+"/"+name, func(t *testing.T) {
+				jsTracer, err := New(tracerName, new(Context), false)
+				require.NoError(t, err)
+				runCallTracerTest(t, test, jsTracer)
+				expected, jsErr := jsTracer.GetResult()
+
+				native, ok, err := NewNative(tracerName, new(Context), nil)
+				require.True(t, ok)
+				require.NoError(t, err)
+				runCallTracerTest(t, test, native)
+				actual, err := native.GetResult()
+				require.NoError(t, err)
+
+				if jsErr != nil {
+					// prestate_tracer.js fails if no code is executed, where the native one
+					// reports the sender and the recipient.
+					require.Equal(t, "prestateTracer", tracerName, jsErr)
+					var prestate map[common.Address]prestateAccount
+					require.NoError(t, json.Unmarshal(actual, &prestate))
+					assert.Len(t, prestate, 2)
+					return
+				}
+				assert.JSONEq(t, string(expected), string(actual))
+			})
+		}
+	}
+}
+
+// flattenCallTrace flattens the call trace in the same order as the flatCallTracer.
+func flattenCallTrace(call callTrace, traceAddress []int) []callTrace {
+	calls := []callTrace{call}
+	for i, child := range call.Calls {
+		calls = append(calls, flattenCallTrace(child, append(append([]int{}, traceAddress...), i))...)
+	}
+	return calls
+}
+
+// Iterates over all the input-output datasets in the tracer test harness and
+// checks the flatCallTracer output against the expected call traces.
+func TestFlatCallTracer(t *testing.T) {
+	txInfo := &Context{BlockHash: common.HexToHash("0x01"), TxIndex: 2, TxHash: common.HexToHash("0x03")}
+	for name, test := range readCallTracerTests(t) {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			tracer, ok, err := NewNative("flatCallTracer", txInfo, json.RawMessage(`{"convertParityErrors":true}`))
+			require.True(t, ok)
+			require.NoError(t, err)
+			runCallTracerTest(t, test, tracer)
+			res, err := tracer.GetResult()
+			require.NoError(t, err)
+
+			var frames []flatCallFrame
+			require.NoError(t, json.Unmarshal(res, &frames))
+			expected := flattenCallTrace(*test.Result, nil)
+			require.Len(t, frames, len(expected))
+
+			for i, frame := range frames {
+				call := expected[i]
+				assert.Equal(t, txInfo.BlockHash, *frame.BlockHash)
+				assert.Equal(t, txInfo.TxHash, *frame.TransactionHash)
+				assert.Equal(t, uint64(txInfo.TxIndex), *frame.TransactionPosition)
+				assert.Equal(t, uint64(test.Context.Number), frame.BlockNumber)
+				assert.Equal(t, len(call.Calls), frame.Subtraces)
+				if i == 0 {
+					assert.Empty(t, frame.TraceAddress)
+				}
+				assert.Equal(t, call.Error != "", frame.Error != "")
+				if call.Error != "" || call.Type == "SELFDESTRUCT" {
+					assert.Nil(t, frame.Result)
+				} else {
+					require.NotNil(t, frame.Result)
+				}
+
+				switch call.Type {
+				case "CREATE", "CREATE2":
+					assert.Equal(t, "create", frame.Type)
+					assert.Equal(t, strings.ToLower(call.Type), frame.Action.CreationMethod)
+					assert.Equal(t, call.From, frame.Action.From)
+					assert.Equal(t, call.Input.String(), frame.Action.Init.String())
+					if frame.Result != nil {
+						assert.Equal(t, call.To, frame.Result.Address)
+						assert.Equal(t, call.Output.String(), frame.Result.Code.String())
+					}
+				case "SELFDESTRUCT":
+					assert.Equal(t, "suicide", frame.Type)
+					assert.Equal(t, call.From, frame.Action.SelfDestructed)
+					assert.Equal(t, call.To, frame.Action.RefundAddress)
+				default:
+					assert.Equal(t, "call", frame.Type)
+					assert.Equal(t, strings.ToLower(call.Type), frame.Action.CallType)
+					assert.Equal(t, call.From, frame.Action.From)
+					assert.Equal(t, call.To, frame.Action.To)
+					assert.Equal(t, call.Input.String(), frame.Action.Input.String())
+					if frame.Result != nil && i > 0 {
+						assert.Equal(t, call.GasUsed, frame.Result.GasUsed)
+						assert.Equal(t, call.Output.String(), frame.Result.Output.String())
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPrestateTracerDiffMode(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		origin   = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+		slot0    = common.Hash{}
+		slot1    = common.BigToHash(common.Big1)
+		balance  = big.NewInt(params.KLAY)
+		gasPrice = big.NewInt(1)
+	)
+	// The code clears slot 0 and stores 0x2a to slot 1
+	alloc := blockchain.GenesisAlloc{
+		contract: {
+			Nonce:   1,
+			Code:    hexutil.MustDecode("0x6000600055602a60015500"),
+			Storage: map[common.Hash]common.Hash{slot0: common.BigToHash(big.NewInt(7))},
+			Balance: new(big.Int),
+		},
+		origin: {Balance: balance},
+	}
+	tx, err := types.SignTx(types.NewTransaction(0, contract, big.NewInt(1), 100000, gasPrice, nil), types.LatestSignerForChainID(params.CypressChainConfig.ChainID), key)
+	require.NoError(t, err)
+
+	statedb := tests.MakePreState(database.NewMemoryDBManager(), alloc)
+	tracer, ok, err := NewNative("prestateTracer", new(Context), json.RawMessage(`{"diffMode":true}`))
+	require.True(t, ok)
+	require.NoError(t, err)
+
+	signer := types.LatestSignerForChainID(params.CypressChainConfig.ChainID)
+	blockContext := vm.BlockContext{
+		CanTransfer: blockchain.CanTransfer,
+		Transfer:    blockchain.Transfer,
+		BlockNumber: big.NewInt(1),
+		Time:        big.NewInt(5),
+		BlockScore:  big.NewInt(1),
+		GasLimit:    uint64(6000000),
+	}
+	evm := vm.NewEVM(blockContext, vm.TxContext{Origin: origin, GasPrice: gasPrice}, statedb, params.CypressChainConfig, &vm.Config{Debug: true, Tracer: tracer})
+	fork.SetHardForkBlockNumberConfig(&params.ChainConfig{})
+	msg, err := tx.AsMessageWithAccountKeyPicker(signer, statedb, 1)
+	require.NoError(t, err)
+	tracer.(txPreparer).prepare(statedb, msg)
+	res, err := blockchain.NewStateTransition(evm, msg).TransitionDb()
+	require.NoError(t, err)
+	require.False(t, res.Failed())
+
+	raw, err := tracer.GetResult()
+	require.NoError(t, err)
+	var diff prestateDiff
+	require.NoError(t, json.Unmarshal(raw, &diff))
+
+	// The sender paid the fee and the value, and its nonce increased
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(res.UsedGas), gasPrice)
+	require.Contains(t, diff.Pre, origin)
+	require.Contains(t, diff.Post, origin)
+	assert.Equal(t, (*hexutil.Big)(balance).String(), diff.Pre[origin].Balance.String())
+	assert.Equal(t, (*hexutil.Big)(new(big.Int).Sub(balance, new(big.Int).Add(fee, common.Big1))).String(), diff.Post[origin].Balance.String())
+	assert.Equal(t, uint64(0), diff.Pre[origin].Nonce)
+	assert.Equal(t, uint64(1), diff.Post[origin].Nonce)
+
+	// The contract received the value, the cleared slot is in the prestate only
+	require.Contains(t, diff.Pre, contract)
+	require.Contains(t, diff.Post, contract)
+	assert.Equal(t, "0x0", diff.Pre[contract].Balance.String())
+	assert.Equal(t, "0x1", diff.Post[contract].Balance.String())
+	assert.Empty(t, diff.Post[contract].Code)
+	assert.Equal(t, map[common.Hash]common.Hash{slot0: common.BigToHash(big.NewInt(7))}, diff.Pre[contract].Storage)
+	assert.Equal(t, map[common.Hash]common.Hash{slot1: common.BigToHash(big.NewInt(0x2a))}, diff.Post[contract].Storage)
+}
@@ -25,15 +25,15 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"reflect"
 	"sync/atomic"
-	"unsafe"
 
+	"github.com/dop251/goja"
 	"github.com/klaytn/klaytn/blockchain/vm"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/log"
-	"gopkg.in/olebedev/go-duktape.v3"
 )
 
 // bigIntegerJS is the minified version of https://github.com/peterolson/BigInteger.js.
@@ -41,54 +41,25 @@ const bigIntegerJS = `var bigInt=function(undefined){"use strict";var BASE=1e7,L
 
 var logger = log.NewModuleLogger(log.NodeCNTracers)
 
-// makeSlice convert an unsafe memory pointer with the given type into a Go byte
-// slice.
-//
-// Note, the returned slice uses the same memory area as the input arguments.
-// If those are duktape stack items, popping them off **will** make the slice
-// contents change.
-func makeSlice(ptr unsafe.Pointer, size uint) []byte {
-	sl := struct {
-		addr uintptr
-		len  int
-		cap  int
-	}{uintptr(ptr), int(size), int(size)}
-
-	return *(*[]byte)(unsafe.Pointer(&sl))
-}
-
-// popSlice pops a buffer off the JavaScript stack and returns it as a slice.
-func popSlice(ctx *duktape.Context) []byte {
-	blob := common.CopyBytes(makeSlice(ctx.GetBuffer(-1)))
-	ctx.Pop()
-	return blob
-}
+// jsonEncodeRecursionLimit is the maximum nesting depth of the tracer result.
+const jsonEncodeRecursionLimit = 1000
 
-// pushBigInt create a JavaScript BigInteger in the VM.
-func pushBigInt(n *big.Int, ctx *duktape.Context) {
-	ctx.GetGlobalString("bigInt")
-	ctx.PushString(n.String())
-	ctx.Call(1)
-}
+// bytesType is the type of the byte arrays, whose elements are not walked when
+// the nesting depth of the tracer result is checked.
+var bytesType = reflect.TypeOf([]byte(nil))
 
 // opWrapper provides a JavaScript wrapper around OpCode.
 type opWrapper struct {
 	op vm.OpCode
 }
 
-// pushObject assembles a JSVM object wrapping a swappable opcode and pushes it
-// onto the VM stack.
-func (ow *opWrapper) pushObject(vm *duktape.Context) {
-	obj := vm.PushObject()
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushInt(int(ow.op)); return 1 })
-	vm.PutPropString(obj, "toNumber")
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushString(ow.op.String()); return 1 })
-	vm.PutPropString(obj, "toString")
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushBoolean(ow.op.IsPush()); return 1 })
-	vm.PutPropString(obj, "isPush")
+// newObject assembles a JSVM object wrapping a swappable opcode.
+func (ow *opWrapper) newObject(jst *Tracer) *goja.Object {
+	obj := jst.vm.NewObject()
+	obj.Set("toNumber", func(goja.FunctionCall) goja.Value { return jst.vm.ToValue(int(ow.op)) })
+	obj.Set("toString", func(goja.FunctionCall) goja.Value { return jst.vm.ToValue(ow.op.String()) })
+	obj.Set("isPush", func(goja.FunctionCall) goja.Value { return jst.vm.ToValue(ow.op.IsPush()) })
+	return obj
 }
 
 // memoryWrapper provides a JavaScript wrapper around vm.Memory.
@@ -106,8 +77,6 @@ func (mw *memoryWrapper) slice(begin, end int64) []byte {
 		return nil
 	}
 	if mw.memory.Len() < int(end) {
-		// TODO(karalabe): We can't js-throw from Go inside duktape inside Go. The Go
-		// runtime goes belly up https://github.com/golang/go/issues/15639.
 		logger.Warn("Tracer accessed out of bound memory", "available", mw.memory.Len(), "offset", begin, "size", end-begin)
 		return nil
 	}
@@ -117,39 +86,25 @@ func (mw *memoryWrapper) slice(begin, end int64) []byte {
 // getUint returns the 32 bytes at the specified address interpreted as a uint.
 func (mw *memoryWrapper) getUint(addr int64) *big.Int {
 	if mw.memory.Len() < int(addr)+32 || addr < 0 {
-		// TODO(karalabe): We can't js-throw from Go inside duktape inside Go. The Go
-		// runtime goes belly up https://github.com/golang/go/issues/15639.
 		logger.Warn("Tracer accessed out of bound memory", "available", mw.memory.Len(), "offset", addr, "size", 32)
 		return new(big.Int)
 	}
 	return new(big.Int).SetBytes(mw.memory.GetPtr(addr, 32))
 }
 
-// pushObject assembles a JSVM object wrapping a swappable memory and pushes it
-// onto the VM stack.
-func (mw *memoryWrapper) pushObject(vm *duktape.Context) {
-	obj := vm.PushObject()
+// newObject assembles a JSVM object wrapping a swappable memory.
+func (mw *memoryWrapper) newObject(jst *Tracer) *goja.Object {
+	obj := jst.vm.NewObject()
 
 	// Generate the `slice` method which takes two ints and returns a buffer
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		blob := mw.slice(int64(ctx.GetInt(-2)), int64(ctx.GetInt(-1)))
-		ctx.Pop2()
-
-		ptr := ctx.PushFixedBuffer(len(blob))
-		copy(makeSlice(ptr, uint(len(blob))), blob[:])
-		return 1
+	obj.Set("slice", func(call goja.FunctionCall) goja.Value {
+		return jst.toBuf(mw.slice(call.Argument(0).ToInteger(), call.Argument(1).ToInteger()))
 	})
-	vm.PutPropString(obj, "slice")
-
 	// Generate the `getUint` method which takes an int and returns a bigint
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		offset := int64(ctx.GetInt(-1))
-		ctx.Pop()
-
-		pushBigInt(mw.getUint(offset), ctx)
-		return 1
+	obj.Set("getUint", func(call goja.FunctionCall) goja.Value {
+		return jst.toBig(mw.getUint(call.Argument(0).ToInteger()))
 	})
-	vm.PutPropString(obj, "getUint")
+	return obj
 }
 
 // stackWrapper provides a JavaScript wrapper around vm.Stack.
@@ -160,31 +115,22 @@ type stackWrapper struct {
 // peek returns the nth-from-the-top element of the stack.
 func (sw *stackWrapper) peek(idx int) *big.Int {
 	if len(sw.stack.Data()) <= idx || idx < 0 {
-		// TODO(karalabe): We can't js-throw from Go inside duktape inside Go. The Go
-		// runtime goes belly up https://github.com/golang/go/issues/15639.
 		logger.Warn("Tracer accessed out of bound stack", "size", len(sw.stack.Data()), "index", idx)
 		return new(big.Int)
 	}
 	return sw.stack.Data()[len(sw.stack.Data())-idx-1].ToBig()
 }
 
-// pushObject assembles a JSVM object wrapping a swappable stack and pushes it
-// onto the VM stack.
-func (sw *stackWrapper) pushObject(vm *duktape.Context) {
-	obj := vm.PushObject()
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushInt(len(sw.stack.Data())); return 1 })
-	vm.PutPropString(obj, "length")
+// newObject assembles a JSVM object wrapping a swappable stack.
+func (sw *stackWrapper) newObject(jst *Tracer) *goja.Object {
+	obj := jst.vm.NewObject()
+	obj.Set("length", func(goja.FunctionCall) goja.Value { return jst.vm.ToValue(len(sw.stack.Data())) })
 
 	// Generate the `peek` method which takes an int and returns a bigint
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		offset := ctx.GetInt(-1)
-		ctx.Pop()
-
-		pushBigInt(sw.peek(offset), ctx)
-		return 1
+	obj.Set("peek", func(call goja.FunctionCall) goja.Value {
+		return jst.toBig(sw.peek(int(call.Argument(0).ToInteger())))
 	})
-	vm.PutPropString(obj, "peek")
+	return obj
 }
 
 // dbWrapper provides a JavaScript wrapper around vm.Database.
@@ -192,54 +138,34 @@ type dbWrapper struct {
 	db vm.StateDB
 }
 
-// pushObject assembles a JSVM object wrapping a swappable database and pushes it
-// onto the VM stack.
-func (dw *dbWrapper) pushObject(vm *duktape.Context) {
-	obj := vm.PushObject()
+// newObject assembles a JSVM object wrapping a swappable database.
+func (dw *dbWrapper) newObject(jst *Tracer) *goja.Object {
+	obj := jst.vm.NewObject()
 
 	// Push the wrapper for statedb.GetBalance
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		pushBigInt(dw.db.GetBalance(common.BytesToAddress(popSlice(ctx))), ctx)
-		return 1
+	obj.Set("getBalance", func(call goja.FunctionCall) goja.Value {
+		return jst.toBig(dw.db.GetBalance(common.BytesToAddress(jst.fromBuf(call.Argument(0)))))
 	})
-	vm.PutPropString(obj, "getBalance")
-
 	// Push the wrapper for statedb.GetNonce
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		ctx.PushInt(int(dw.db.GetNonce(common.BytesToAddress(popSlice(ctx)))))
-		return 1
+	obj.Set("getNonce", func(call goja.FunctionCall) goja.Value {
+		return jst.vm.ToValue(dw.db.GetNonce(common.BytesToAddress(jst.fromBuf(call.Argument(0)))))
 	})
-	vm.PutPropString(obj, "getNonce")
-
 	// Push the wrapper for statedb.GetCode
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		code := dw.db.GetCode(common.BytesToAddress(popSlice(ctx)))
-
-		ptr := ctx.PushFixedBuffer(len(code))
-		copy(makeSlice(ptr, uint(len(code))), code[:])
-		return 1
+	obj.Set("getCode", func(call goja.FunctionCall) goja.Value {
+		return jst.toBuf(dw.db.GetCode(common.BytesToAddress(jst.fromBuf(call.Argument(0)))))
 	})
-	vm.PutPropString(obj, "getCode")
-
 	// Push the wrapper for statedb.GetState
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		hash := popSlice(ctx)
-		addr := popSlice(ctx)
-
-		state := dw.db.GetState(common.BytesToAddress(addr), common.BytesToHash(hash))
-
-		ptr := ctx.PushFixedBuffer(len(state))
-		copy(makeSlice(ptr, uint(len(state))), state[:])
-		return 1
+	obj.Set("getState", func(call goja.FunctionCall) goja.Value {
+		addr := common.BytesToAddress(jst.fromBuf(call.Argument(0)))
+		hash := common.BytesToHash(jst.fromBuf(call.Argument(1)))
+		state := dw.db.GetState(addr, hash)
+		return jst.toBuf(state[:])
 	})
-	vm.PutPropString(obj, "getState")
-
 	// Push the wrapper for statedb.Exists
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		ctx.PushBoolean(dw.db.Exist(common.BytesToAddress(popSlice(ctx))))
-		return 1
+	obj.Set("exists", func(call goja.FunctionCall) goja.Value {
+		return jst.vm.ToValue(dw.db.Exist(common.BytesToAddress(jst.fromBuf(call.Argument(0)))))
 	})
-	vm.PutPropString(obj, "exists")
+	return obj
 }
 
 // contractWrapper provides a JavaScript wrapper around vm.Contract
@@ -247,43 +173,14 @@ type contractWrapper struct {
 	contract *vm.Contract
 }
 
-// pushObject assembles a JSVM object wrapping a swappable contract and pushes it
-// onto the VM stack.
-func (cw *contractWrapper) pushObject(vm *duktape.Context) {
-	obj := vm.PushObject()
-
-	// Push the wrapper for contract.Caller
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		ptr := ctx.PushFixedBuffer(20)
-		copy(makeSlice(ptr, 20), cw.contract.Caller().Bytes())
-		return 1
-	})
-	vm.PutPropString(obj, "getCaller")
-
-	// Push the wrapper for contract.Address
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		ptr := ctx.PushFixedBuffer(20)
-		copy(makeSlice(ptr, 20), cw.contract.Address().Bytes())
-		return 1
-	})
-	vm.PutPropString(obj, "getAddress")
-
-	// Push the wrapper for contract.Value
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		pushBigInt(cw.contract.Value(), ctx)
-		return 1
-	})
-	vm.PutPropString(obj, "getValue")
-
-	// Push the wrapper for contract.Input
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		blob := cw.contract.Input
-
-		ptr := ctx.PushFixedBuffer(len(blob))
-		copy(makeSlice(ptr, uint(len(blob))), blob[:])
-		return 1
-	})
-	vm.PutPropString(obj, "getInput")
+// newObject assembles a JSVM object wrapping a swappable contract.
+func (cw *contractWrapper) newObject(jst *Tracer) *goja.Object {
+	obj := jst.vm.NewObject()
+	obj.Set("getCaller", func(goja.FunctionCall) goja.Value { return jst.toBuf(cw.contract.Caller().Bytes()) })
+	obj.Set("getAddress", func(goja.FunctionCall) goja.Value { return jst.toBuf(cw.contract.Address().Bytes()) })
+	obj.Set("getValue", func(goja.FunctionCall) goja.Value { return jst.toBig(cw.contract.Value()) })
+	obj.Set("getInput", func(goja.FunctionCall) goja.Value { return jst.toBuf(cw.contract.Input) })
+	return obj
 }
 
 type frame struct {
@@ -304,33 +201,20 @@ func newFrame() *frame {
 	}
 }
 
-func (f *frame) pushObject(vm *duktape.Context) {
-	obj := vm.PushObject()
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { pushValue(ctx, *f.typ); return 1 })
-	vm.PutPropString(obj, "getType")
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { pushValue(ctx, *f.from); return 1 })
-	vm.PutPropString(obj, "getFrom")
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { pushValue(ctx, *f.to); return 1 })
-	vm.PutPropString(obj, "getTo")
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { pushValue(ctx, f.input); return 1 })
-	vm.PutPropString(obj, "getInput")
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { pushValue(ctx, *f.gas); return 1 })
-	vm.PutPropString(obj, "getGas")
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
+func (f *frame) newObject(jst *Tracer) *goja.Object {
+	obj := jst.vm.NewObject()
+	obj.Set("getType", func(goja.FunctionCall) goja.Value { return jst.toValue(*f.typ) })
+	obj.Set("getFrom", func(goja.FunctionCall) goja.Value { return jst.toValue(*f.from) })
+	obj.Set("getTo", func(goja.FunctionCall) goja.Value { return jst.toValue(*f.to) })
+	obj.Set("getInput", func(goja.FunctionCall) goja.Value { return jst.toValue(f.input) })
+	obj.Set("getGas", func(goja.FunctionCall) goja.Value { return jst.toValue(*f.gas) })
+	obj.Set("getValue", func(goja.FunctionCall) goja.Value {
 		if f.value != nil {
-			pushValue(ctx, f.value)
-		} else {
-			ctx.PushUndefined()
+			return jst.toValue(f.value)
 		}
-		return 1
+		return goja.Undefined()
 	})
-	vm.PutPropString(obj, "getValue")
+	return obj
 }
 
 type frameResult struct {
@@ -345,24 +229,17 @@ func newFrameResult() *frameResult {
 	}
 }
 
-func (r *frameResult) pushObject(vm *duktape.Context) {
-	obj := vm.PushObject()
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { pushValue(ctx, *r.gasUsed); return 1 })
-	vm.PutPropString(obj, "getGasUsed")
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { pushValue(ctx, r.output); return 1 })
-	vm.PutPropString(obj, "getOutput")
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
+func (r *frameResult) newObject(jst *Tracer) *goja.Object {
+	obj := jst.vm.NewObject()
+	obj.Set("getGasUsed", func(goja.FunctionCall) goja.Value { return jst.toValue(*r.gasUsed) })
+	obj.Set("getOutput", func(goja.FunctionCall) goja.Value { return jst.toValue(r.output) })
+	obj.Set("getError", func(goja.FunctionCall) goja.Value {
 		if r.errorValue != nil {
-			pushValue(ctx, *r.errorValue)
-		} else {
-			ctx.PushUndefined()
+			return jst.toValue(*r.errorValue)
 		}
-		return 1
+		return goja.Undefined()
 	})
-	vm.PutPropString(obj, "getError")
+	return obj
 }
 
 // Tracer provides an implementation of Tracer that evaluates a Javascript
@@ -370,10 +247,18 @@ func (r *frameResult) pushObject(vm *duktape.Context) {
 type Tracer struct {
 	inited bool // Flag whether the context was already inited from the EVM
 
-	vm *duktape.Context // Javascript VM instance
+	vm *goja.Runtime // Javascript VM instance
+
+	tracerObject *goja.Object // The tracer JavaScript object
+	bigInt       goja.Callable
+	bufType      goja.Value // Constructor of the buffers passed to the tracer
 
-	tracerObject int // Stack index of the tracer JavaScript object
-	stateObject  int // Stack index of the global state to pull arguments from
+	step, fault, result, enter, exit goja.Callable // Functions of the tracer object
+
+	logValue         goja.Value
+	dbValue          goja.Value
+	frameValue       goja.Value
+	frameResultValue goja.Value
 
 	opWrapper       *opWrapper       // Wrapper around the VM opcode
 	stackWrapper    *stackWrapper    // Wrapper around the VM stack
@@ -424,7 +309,7 @@ func New(code string, ctx *Context, unsafeTrace bool) (*Tracer, error) {
 		return nil, fmt.Errorf("Only predefined tracers are supported")
 	}
 	tracer := &Tracer{
-		vm:              duktape.New(),
+		vm:              goja.New(),
 		ctx:             make(map[string]interface{}),
 		opWrapper:       new(opWrapper),
 		stackWrapper:    new(stackWrapper),
@@ -447,115 +332,38 @@ func New(code string, ctx *Context, unsafeTrace bool) (*Tracer, error) {
 			tracer.ctx["txHash"] = ctx.TxHash
 		}
 	}
+	// Inject the big int library to access large numbers
+	if _, err := tracer.vm.RunString(bigIntegerJS); err != nil {
+		return nil, err
+	}
+	bigInt, ok := goja.AssertFunction(tracer.vm.Get("bigInt"))
+	if !ok {
+		return nil, errors.New("failed to load the big integer library")
+	}
+	tracer.bigInt = bigInt
+	tracer.bufType = tracer.vm.Get("Uint8Array")
+
 	// Set up builtins for this environment
-	tracer.vm.PushGlobalGoFunction("toHex", func(ctx *duktape.Context) int {
-		ctx.PushString(hexutil.Encode(popSlice(ctx)))
-		return 1
-	})
-	tracer.vm.PushGlobalGoFunction("toWord", func(ctx *duktape.Context) int {
-		var word common.Hash
-		if ptr, size := ctx.GetBuffer(-1); ptr != nil {
-			word = common.BytesToHash(makeSlice(ptr, size))
-		} else {
-			word = common.HexToHash(ctx.GetString(-1))
-		}
-		ctx.Pop()
-		copy(makeSlice(ctx.PushFixedBuffer(32), 32), word[:])
-		return 1
-	})
-	tracer.vm.PushGlobalGoFunction("toAddress", func(ctx *duktape.Context) int {
-		var addr common.Address
-		if ptr, size := ctx.GetBuffer(-1); ptr != nil {
-			addr = common.BytesToAddress(makeSlice(ptr, size))
-		} else {
-			addr = common.HexToAddress(ctx.GetString(-1))
-		}
-		ctx.Pop()
-		copy(makeSlice(ctx.PushFixedBuffer(20), 20), addr[:])
-		return 1
-	})
-	tracer.vm.PushGlobalGoFunction("toContract", func(ctx *duktape.Context) int {
-		var from common.Address
-		if ptr, size := ctx.GetBuffer(-2); ptr != nil {
-			from = common.BytesToAddress(makeSlice(ptr, size))
-		} else {
-			from = common.HexToAddress(ctx.GetString(-2))
-		}
-		nonce := uint64(ctx.GetInt(-1))
-		ctx.Pop2()
+	tracer.setBuiltinFunctions()
 
-		contract := crypto.CreateAddress(from, nonce)
-		copy(makeSlice(ctx.PushFixedBuffer(20), 20), contract[:])
-		return 1
-	})
-	tracer.vm.PushGlobalGoFunction("toContract2", func(ctx *duktape.Context) int {
-		var from common.Address
-		if ptr, size := ctx.GetBuffer(-3); ptr != nil {
-			from = common.BytesToAddress(makeSlice(ptr, size))
-		} else {
-			from = common.HexToAddress(ctx.GetString(-3))
-		}
-		// Retrieve salt hex string from js stack
-		salt := common.HexToHash(ctx.GetString(-2))
-		// Retrieve code slice from js stack
-		var code []byte
-		if ptr, size := ctx.GetBuffer(-1); ptr != nil {
-			code = common.CopyBytes(makeSlice(ptr, size))
-		} else {
-			code = common.FromHex(ctx.GetString(-1))
-		}
-		codeHash := crypto.Keccak256(code)
-		ctx.Pop3()
-		contract := crypto.CreateAddress2(from, salt, codeHash)
-		copy(makeSlice(ctx.PushFixedBuffer(20), 20), contract[:])
-		return 1
-	})
-	tracer.vm.PushGlobalGoFunction("isPrecompiled", func(ctx *duktape.Context) int {
-		_, ok := vm.PrecompiledContractsByzantium[common.BytesToAddress(popSlice(ctx))]
-		ctx.PushBoolean(ok)
-		return 1
-	})
-	tracer.vm.PushGlobalGoFunction("slice", func(ctx *duktape.Context) int {
-		start, end := ctx.GetInt(-2), ctx.GetInt(-1)
-		ctx.Pop2()
-
-		blob := popSlice(ctx)
-		size := end - start
-
-		if start < 0 || start > end || end > len(blob) {
-			// TODO(karalabe): We can't js-throw from Go inside duktape inside Go. The Go
-			// runtime goes belly up https://github.com/golang/go/issues/15639.
-			logger.Warn("Tracer accessed out of bound memory", "available", len(blob), "offset", start, "size", size)
-			ctx.PushFixedBuffer(0)
-			return 1
-		}
-		copy(makeSlice(ctx.PushFixedBuffer(size), uint(size)), blob[start:end])
-		return 1
-	})
-	// Push the JavaScript tracer as object #0 onto the JSVM stack and validate it
-	if err := tracer.vm.PevalString("(" + code + ")"); err != nil {
+	// Evaluate the JavaScript tracer and validate it
+	value, err := tracer.vm.RunString("(" + code + ")")
+	if err != nil {
 		logger.Warn("Failed to compile tracer", "err", err)
 		return nil, err
 	}
-	tracer.tracerObject = 0 // yeah, nice, eval can't return the index itself
+	tracer.tracerObject = value.ToObject(tracer.vm)
 
-	hasStep := tracer.vm.GetPropString(tracer.tracerObject, "step")
-	tracer.vm.Pop()
-
-	if !tracer.vm.GetPropString(tracer.tracerObject, "fault") {
+	var hasStep, hasEnter, hasExit bool
+	tracer.step, hasStep = goja.AssertFunction(tracer.tracerObject.Get("step"))
+	if tracer.fault, ok = goja.AssertFunction(tracer.tracerObject.Get("fault")); !ok {
 		return nil, fmt.Errorf("Trace object must expose a function fault()")
 	}
-	tracer.vm.Pop()
-
-	if !tracer.vm.GetPropString(tracer.tracerObject, "result") {
+	if tracer.result, ok = goja.AssertFunction(tracer.tracerObject.Get("result")); !ok {
 		return nil, fmt.Errorf("Trace object must expose a function result()")
 	}
-	tracer.vm.Pop()
-
-	hasEnter := tracer.vm.GetPropString(tracer.tracerObject, "enter")
-	tracer.vm.Pop()
-	hasExit := tracer.vm.GetPropString(tracer.tracerObject, "exit")
-	tracer.vm.Pop()
+	tracer.enter, hasEnter = goja.AssertFunction(tracer.tracerObject.Get("enter"))
+	tracer.exit, hasExit = goja.AssertFunction(tracer.tracerObject.Get("exit"))
 
 	if hasEnter != hasExit {
 		return nil, fmt.Errorf("trace object must expose either both or none of enter() and exit()")
@@ -569,111 +377,174 @@ func New(code string, ctx *Context, unsafeTrace bool) (*Tracer, error) {
 	tracer.traceCallFrames = hasEnter
 	tracer.traceSteps = hasStep
 
-	// Tracer is valid, inject the big int library to access large numbers
-	tracer.vm.EvalString(bigIntegerJS)
-	tracer.vm.PutGlobalString("bigInt")
-
-	// Push the global environment state as object #1 into the JSVM stack
-	tracer.stateObject = tracer.vm.PushObject()
-
-	logObject := tracer.vm.PushObject()
-
-	tracer.opWrapper.pushObject(tracer.vm)
-	tracer.vm.PutPropString(logObject, "op")
-
-	tracer.stackWrapper.pushObject(tracer.vm)
-	tracer.vm.PutPropString(logObject, "stack")
-
-	tracer.memoryWrapper.pushObject(tracer.vm)
-	tracer.vm.PutPropString(logObject, "memory")
-
-	tracer.contractWrapper.pushObject(tracer.vm)
-	tracer.vm.PutPropString(logObject, "contract")
-
-	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushUint(*tracer.pcValue); return 1 })
-	tracer.vm.PutPropString(logObject, "getPC")
-
-	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushUint(*tracer.gasValue); return 1 })
-	tracer.vm.PutPropString(logObject, "getGas")
-
-	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushUint(*tracer.costValue); return 1 })
-	tracer.vm.PutPropString(logObject, "getCost")
-
-	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushUint(*tracer.depthValue); return 1 })
-	tracer.vm.PutPropString(logObject, "getDepth")
-
-	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushUint(*tracer.refundValue); return 1 })
-	tracer.vm.PutPropString(logObject, "getRefund")
-
-	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int {
+	// Assemble the objects passed to the tracer functions
+	logObject := tracer.vm.NewObject()
+	logObject.Set("op", tracer.opWrapper.newObject(tracer))
+	logObject.Set("stack", tracer.stackWrapper.newObject(tracer))
+	logObject.Set("memory", tracer.memoryWrapper.newObject(tracer))
+	logObject.Set("contract", tracer.contractWrapper.newObject(tracer))
+	logObject.Set("getPC", func(goja.FunctionCall) goja.Value { return tracer.vm.ToValue(*tracer.pcValue) })
+	logObject.Set("getGas", func(goja.FunctionCall) goja.Value { return tracer.vm.ToValue(*tracer.gasValue) })
+	logObject.Set("getCost", func(goja.FunctionCall) goja.Value { return tracer.vm.ToValue(*tracer.costValue) })
+	logObject.Set("getDepth", func(goja.FunctionCall) goja.Value { return tracer.vm.ToValue(*tracer.depthValue) })
+	logObject.Set("getRefund", func(goja.FunctionCall) goja.Value { return tracer.vm.ToValue(*tracer.refundValue) })
+	logObject.Set("getError", func(goja.FunctionCall) goja.Value {
 		if tracer.errorValue != nil {
-			ctx.PushString(*tracer.errorValue)
-		} else {
-			ctx.PushUndefined()
+			return tracer.vm.ToValue(*tracer.errorValue)
 		}
-		return 1
+		return goja.Undefined()
 	})
-	tracer.vm.PutPropString(logObject, "getError")
+	tracer.logValue = logObject
+	tracer.dbValue = tracer.dbWrapper.newObject(tracer)
+	tracer.frameValue = tracer.frame.newObject(tracer)
+	tracer.frameResultValue = tracer.frameResult.newObject(tracer)
+
+	return tracer, nil
+}
 
-	tracer.vm.PutPropString(tracer.stateObject, "log")
+// setBuiltinFunctions injects the global helper functions into the JSVM.
+func (jst *Tracer) setBuiltinFunctions() {
+	jsvm := jst.vm
+	jsvm.Set("toHex", func(call goja.FunctionCall) goja.Value {
+		return jsvm.ToValue(hexutil.Encode(jst.fromBuf(call.Argument(0))))
+	})
+	jsvm.Set("toWord", func(call goja.FunctionCall) goja.Value {
+		word := common.BytesToHash(jst.fromBuf(call.Argument(0)))
+		return jst.toBuf(word[:])
+	})
+	jsvm.Set("toAddress", func(call goja.FunctionCall) goja.Value {
+		addr := common.BytesToAddress(jst.fromBuf(call.Argument(0)))
+		return jst.toBuf(addr[:])
+	})
+	jsvm.Set("toContract", func(call goja.FunctionCall) goja.Value {
+		from := common.BytesToAddress(jst.fromBuf(call.Argument(0)))
+		nonce := uint64(call.Argument(1).ToInteger())
+		contract := crypto.CreateAddress(from, nonce)
+		return jst.toBuf(contract[:])
+	})
+	jsvm.Set("toContract2", func(call goja.FunctionCall) goja.Value {
+		from := common.BytesToAddress(jst.fromBuf(call.Argument(0)))
+		// Retrieve salt hex string from js stack
+		salt := common.HexToHash(call.Argument(1).String())
+		// Retrieve code slice from js stack
+		codeHash := crypto.Keccak256(jst.fromBuf(call.Argument(2)))
+		contract := crypto.CreateAddress2(from, salt, codeHash)
+		return jst.toBuf(contract[:])
+	})
+	jsvm.Set("isPrecompiled", func(call goja.FunctionCall) goja.Value {
+		_, ok := vm.PrecompiledContractsByzantium[common.BytesToAddress(jst.fromBuf(call.Argument(0)))]
+		return jsvm.ToValue(ok)
+	})
+	jsvm.Set("slice", func(call goja.FunctionCall) goja.Value {
+		blob := jst.fromBuf(call.Argument(0))
+		start, end := call.Argument(1).ToInteger(), call.Argument(2).ToInteger()
+		if start < 0 || start > end || end > int64(len(blob)) {
+			logger.Warn("Tracer accessed out of bound memory", "available", len(blob), "offset", start, "size", end-start)
+			return jst.toBuf(nil)
+		}
+		return jst.toBuf(blob[start:end])
+	})
+}
 
-	tracer.frame.pushObject(tracer.vm)
-	tracer.vm.PutPropString(tracer.stateObject, "frame")
+// toBuf converts a byte slice into a Uint8Array in the JSVM.
+func (jst *Tracer) toBuf(val []byte) goja.Value {
+	buf, err := jst.vm.New(jst.bufType, jst.vm.ToValue(jst.vm.NewArrayBuffer(common.CopyBytes(val))))
+	if err != nil {
+		panic(jst.vm.NewGoError(err))
+	}
+	return buf
+}
 
-	tracer.frameResult.pushObject(tracer.vm)
-	tracer.vm.PutPropString(tracer.stateObject, "frameResult")
+// fromBuf converts a buffer, an array of bytes or a hex string in the JSVM into
+// a byte slice. Any other value is converted into an empty slice.
+func (jst *Tracer) fromBuf(val goja.Value) []byte {
+	if val == nil || goja.IsUndefined(val) || goja.IsNull(val) {
+		return nil
+	}
+	switch v := val.Export().(type) {
+	case []byte:
+		return common.CopyBytes(v)
+	case string:
+		return common.FromHex(v)
+	case []interface{}:
+		var blob []byte
+		if err := jst.vm.ExportTo(val, &blob); err == nil {
+			return blob
+		}
+	}
+	return nil
+}
 
-	tracer.dbWrapper.pushObject(tracer.vm)
-	tracer.vm.PutPropString(tracer.stateObject, "db")
+// toBig creates a JavaScript BigInteger in the JSVM.
+func (jst *Tracer) toBig(n *big.Int) goja.Value {
+	value, err := jst.bigInt(goja.Undefined(), jst.vm.ToValue(n.String()))
+	if err != nil {
+		panic(jst.vm.NewGoError(err))
+	}
+	return value
+}
 
-	return tracer, nil
+// toValue converts a value of the transaction context into a JavaScript value.
+func (jst *Tracer) toValue(val interface{}) goja.Value {
+	switch val := val.(type) {
+	case uint64:
+		return jst.vm.ToValue(val)
+	case string:
+		return jst.vm.ToValue(val)
+	case []byte:
+		return jst.toBuf(val)
+	case common.Address:
+		return jst.toBuf(val[:])
+	case *big.Int:
+		return jst.toBig(val)
+	case int:
+		return jst.vm.ToValue(val)
+	case uint:
+		return jst.vm.ToValue(val)
+	case common.Hash:
+		return jst.toBuf(val[:])
+	default:
+		panic(fmt.Sprintf("unsupported type: %T", val))
+	}
 }
 
 // Stop terminates execution of the tracer at the first opportune moment.
 func (jst *Tracer) Stop(err error) {
 	jst.reason = err
 	atomic.StoreUint32(&jst.interrupt, 1)
+	jst.vm.Interrupt(err)
 }
 
 // call executes a method on a JS object, catching any errors, formatting and
 // returning them as error objects.
-func (jst *Tracer) call(noret bool, method string, args ...string) (json.RawMessage, error) {
-	// Execute the JavaScript call and return any error
-	jst.vm.PushString(method)
-	for _, arg := range args {
-		jst.vm.GetPropString(jst.stateObject, arg)
-	}
-	code := jst.vm.PcallProp(jst.tracerObject, len(args))
-	defer jst.vm.Pop()
-	if code != 0 {
-		err := jst.vm.SafeToString(-1)
-		return nil, errors.New(err)
-	}
-	// No error occurred, extract return value and return
-	if noret {
-		return nil, nil
+func (jst *Tracer) call(method string, fn goja.Callable, args ...goja.Value) (goja.Value, error) {
+	value, err := fn(jst.tracerObject, args...)
+	if err != nil {
+		return nil, wrapError(method, jst.unwrapError(err))
 	}
-	// Push a JSON marshaller onto the stack. We can't marshal from the out-
-	// side because duktape can crash on large nestings and we can't catch
-	// C++ exceptions ourselves from Go. TODO(karalabe): Yuck, why wrap?!
-	jst.vm.PushString("(JSON.stringify)")
-	jst.vm.Eval()
+	return value, nil
+}
 
-	jst.vm.Swap(-1, -2)
-	if code = jst.vm.Pcall(1); code != 0 {
-		err := jst.vm.SafeToString(-1)
-		return nil, errors.New(err)
+// unwrapError extracts the JavaScript error of the tracer from the JSVM error.
+func (jst *Tracer) unwrapError(err error) error {
+	var (
+		interrupted *goja.InterruptedError
+		exception   *goja.Exception
+	)
+	switch {
+	case errors.As(err, &interrupted):
+		if jst.reason != nil {
+			return jst.reason
+		}
+		return fmt.Errorf("%v", interrupted.Value())
+	case errors.As(err, &exception):
+		return errors.New(exception.Value().String())
 	}
-	return json.RawMessage(jst.vm.SafeToString(-1)), nil
+	return err
 }
 
 func wrapError(context string, err error) error {
-	var message string
-	switch err := err.(type) {
-	default:
-		message = err.Error()
-	}
-	return fmt.Errorf("%v    in server-side tracer function '%v'", message, context)
+	return fmt.Errorf("%v    in server-side tracer function '%v'", err.Error(), context)
 }
 
 // CaptureStart implements the Tracer interface to initialize the tracing operation.
@@ -707,7 +578,6 @@ func (jst *Tracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost,
 	// If tracing was interrupted, set the error and stop
 	if atomic.LoadUint32(&jst.interrupt) > 0 {
 		jst.err = jst.reason
-		// env.Cancel()
 		return
 	}
 	jst.opWrapper.op = op
@@ -727,9 +597,8 @@ func (jst *Tracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost,
 		jst.errorValue = new(string)
 		*jst.errorValue = err.Error()
 	}
-	_, err = jst.call(true, "step", "log", "db")
-	if err != nil {
-		jst.err = wrapError("step", err)
+	if _, err := jst.call("step", jst.step, jst.logValue, jst.dbValue); err != nil {
+		jst.err = err
 	}
 }
 
@@ -741,9 +610,8 @@ func (jst *Tracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost,
 		jst.errorValue = new(string)
 		*jst.errorValue = err.Error()
 
-		_, err := jst.call(true, "fault", "log", "db")
-		if err != nil {
-			jst.err = wrapError("fault", err)
+		if _, err := jst.call("fault", jst.fault, jst.logValue, jst.dbValue); err != nil {
+			jst.err = err
 		}
 	}
 }
@@ -782,8 +650,8 @@ func (jst *Tracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Ad
 		jst.frame.value = new(big.Int).SetBytes(value.Bytes())
 	}
 
-	if _, err := jst.call(true, "enter", "frame"); err != nil {
-		jst.err = wrapError("enter", err)
+	if _, err := jst.call("enter", jst.enter, jst.frameValue); err != nil {
+		jst.err = err
 	}
 }
 
@@ -810,8 +678,8 @@ func (jst *Tracer) CaptureExit(output []byte, gasUsed uint64, err error) {
 		*jst.frameResult.errorValue = err.Error()
 	}
 
-	if _, err := jst.call(true, "exit", "frameResult"); err != nil {
-		jst.err = wrapError("exit", err)
+	if _, err := jst.call("exit", jst.exit, jst.frameResultValue); err != nil {
+		jst.err = err
 	}
 }
 
@@ -825,54 +693,68 @@ func (jst *Tracer) CaptureTxEnd(restGas uint64) {
 
 // GetResult calls the Javascript 'result' function and returns its value, or any accumulated error
 func (jst *Tracer) GetResult() (json.RawMessage, error) {
-	// Transform the context into a JavaScript object and inject into the state
-	obj := jst.vm.PushObject()
-
+	if jst.err != nil {
+		return nil, jst.err
+	}
+	// The tracing was interrupted, don't run the tracer any further
+	if atomic.LoadUint32(&jst.interrupt) > 0 {
+		return nil, jst.reason
+	}
+	// Transform the context into a JavaScript object
+	ctx := jst.vm.NewObject()
 	for key, val := range jst.ctx {
-		jst.addToObj(obj, key, val)
+		ctx.Set(key, jst.toValue(val))
 	}
-	jst.vm.PutPropString(jst.stateObject, "ctx")
-
 	// Finalize the trace and return the results
-	result, err := jst.call(false, "result", "ctx", "db")
+	result, err := jst.call("result", jst.result, ctx, jst.dbValue)
 	if err != nil {
-		jst.err = wrapError("result", err)
+		return nil, err
 	}
-	// Clean up the JavaScript environment
-	jst.vm.DestroyHeap()
-	jst.vm.Destroy()
-
-	return result, jst.err
+	encoded, err := jst.stringify(result)
+	if err != nil {
+		return nil, wrapError("result", err)
+	}
+	return encoded, nil
 }
 
-// addToObj pushes a field to a JS object.
-func (jst *Tracer) addToObj(obj int, key string, val interface{}) {
-	pushValue(jst.vm, val)
-	jst.vm.PutPropString(obj, key)
+// stringify encodes the given JavaScript value with JSON.stringify, so that the
+// values are encoded the same as in the JavaScript code of the tracer.
+func (jst *Tracer) stringify(value goja.Value) (json.RawMessage, error) {
+	if exceedsDepth(value, 1) {
+		return nil, errors.New("RangeError: json encode recursion limit")
+	}
+	stringify, ok := goja.AssertFunction(jst.vm.Get("JSON").ToObject(jst.vm).Get("stringify"))
+	if !ok {
+		return nil, errors.New("JSON.stringify is not available")
+	}
+	encoded, err := stringify(goja.Undefined(), value)
+	if err != nil {
+		return nil, jst.unwrapError(err)
+	}
+	if goja.IsUndefined(encoded) {
+		return json.RawMessage("undefined"), nil
+	}
+	return json.RawMessage(encoded.String()), nil
 }
 
-func pushValue(ctx *duktape.Context, val interface{}) {
-	switch val := val.(type) {
-	case uint64:
-		ctx.PushUint(uint(val))
-	case string:
-		ctx.PushString(val)
-	case []byte:
-		ptr := ctx.PushFixedBuffer(len(val))
-		copy(makeSlice(ptr, uint(len(val))), val)
-	case common.Address:
-		ptr := ctx.PushFixedBuffer(20)
-		copy(makeSlice(ptr, 20), val[:])
-	case *big.Int:
-		pushBigInt(val, ctx)
-	case int:
-		ctx.PushInt(val)
-	case uint:
-		ctx.PushUint(val)
-	case common.Hash:
-		ptr := ctx.PushFixedBuffer(32)
-		copy(makeSlice(ptr, 32), val[:])
-	default:
-		panic(fmt.Sprintf("unsupported type: %T", val))
+// exceedsDepth reports whether the object nesting of the value is deeper than
+// the recursion limit of the JSON encoding. The object is walked in place, since
+// exporting it would copy the whole subtree at every level.
+func exceedsDepth(value goja.Value, depth int) bool {
+	obj, ok := value.(*goja.Object)
+	if !ok {
+		return false
+	}
+	if depth > jsonEncodeRecursionLimit {
+		return true
+	}
+	if obj.ExportType() == bytesType {
+		return false
+	}
+	for _, key := range obj.Keys() {
+		if exceedsDepth(obj.Get(key), depth+1) {
+			return true
+		}
 	}
+	return false
 }
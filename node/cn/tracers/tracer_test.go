@@ -178,10 +178,8 @@ func TestOpcodes(t *testing.T) {
 }
 
 func TestHalt(t *testing.T) {
-	t.Skip("duktape doesn't support abortion")
-
 	timeout := errors.New("stahp")
-	tracer, err := New("{step: function() { while(1); }, result: function() { return null; }}", new(Context), true)
+	tracer, err := New("{step: function() { while(1); }, fault: function() {}, result: function() { return null; }}", new(Context), true)
 	if err != nil {
 		t.Fatal(err)
 	}
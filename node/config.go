@@ -22,6 +22,7 @@ package node
 
 import (
 	"crypto/ecdsa"
+	"crypto/rand"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -31,6 +32,7 @@ import (
 	"github.com/klaytn/klaytn/accounts"
 	"github.com/klaytn/klaytn/accounts/keystore"
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/crypto/bls"
 	"github.com/klaytn/klaytn/log"
@@ -47,6 +49,8 @@ const (
 	datadirStaticNodes     = "static-nodes.json"  // Path within the datadir to the static node list
 	datadirTrustedNodes    = "trusted-nodes.json" // Path within the datadir to the trusted node list
 	datadirNodeDatabase    = "nodes"              // Path within the datadir to store the node infos
+
+	jwtSecretLength = 32 // Length of the secret authenticating the RPC callers
 )
 
 // Config represents a small collection of configuration values to fine tune the
@@ -143,6 +147,9 @@ type Config struct {
 	// interface.
 	HTTPTimeouts rpc.HTTPTimeouts
 
+	// GraphQLEnabled serves the GraphQL queries on the HTTP RPC interface.
+	GraphQLEnabled bool `toml:",omitempty"`
+
 	// WSHost is the host interface on which to start the websocket RPC server. If
 	// this field is empty, no websocket API endpoint will be started.
 	WSHost string `toml:",omitempty"`
@@ -169,6 +176,13 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// JWTSecret is the path to the file of the hex-encoded secret shared with the
+	// callers of the HTTP and websocket RPC interfaces. If set, the callers should
+	// be authenticated with the HS256 tokens signed with the secret, whose modules
+	// claim restricts the API modules they may call. The secret is generated if
+	// the file does not exist.
+	JWTSecret string `toml:",omitempty"`
+
 	// GRPCHost is the host interface on which to start the gRPC server. If
 	// this field is empty, no gRPC API endpoint will be started.
 	GRPCHost string `toml:",omitempty"`
@@ -386,6 +400,41 @@ func (c *Config) NodeKey() *ecdsa.PrivateKey {
 	return key
 }
 
+// LoadJWTSecret retrieves the secret authenticating the RPC callers from the
+// configured file. If the file does not exist, a new secret is generated and
+// stored in it. It returns nil if the authentication is disabled.
+func (c *Config) LoadJWTSecret() ([]byte, error) {
+	if c.JWTSecret == "" {
+		return nil, nil
+	}
+	path := c.ResolvePath(c.JWTSecret)
+	if data, err := os.ReadFile(path); err == nil {
+		secret, err := hexutil.Decode("0x" + strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT secret in %s: %w", path, err)
+		}
+		if len(secret) != jwtSecretLength {
+			return nil, fmt.Errorf("invalid JWT secret length in %s: have %d, want %d", path, len(secret), jwtSecretLength)
+		}
+		return secret, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	// No secret found, generate and store a new one.
+	secret := make([]byte, jwtSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(hexutil.Encode(secret)), 0o600); err != nil {
+		return nil, err
+	}
+	logger.Warn("Generated JWT secret", "path", path)
+	return secret, nil
+}
+
 // BlsNodeKey retrieves the currently configured BLS secret key key of the node,
 // check first any manually set key, falling back to the one found in the configured
 // data folder. If no key can be found, derive from the NodeKey.
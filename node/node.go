@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -79,6 +80,11 @@ type Node struct {
 	httpListener  net.Listener // HTTP RPC listener socket to server API requests
 	httpHandler   *rpc.Server  // HTTP RPC request handler to process the API requests
 
+	httpHandlers     map[string]http.Handler // Extra handlers served on the HTTP endpoint, keyed by path
+	httpHandlersLock sync.Mutex
+
+	jwtSecret []byte // Secret authenticating the callers of the HTTP and websocket endpoints (nil = disabled)
+
 	wsEndpoint string       // Websocket endpoint (interface + port) to listen at (empty = websocket disabled)
 	wsListener net.Listener // Websocket RPC listener socket to server API requests
 	wsHandler  *rpc.Server  // Websocket RPC request handler to process the API requests
@@ -346,12 +352,20 @@ func (n *Node) startRPC(services map[reflect.Type]Service) error {
 		return err
 	}
 
-	if err := n.startHTTP(n.httpEndpoint, apis, n.config.HTTPModules, n.config.HTTPCors, n.config.HTTPVirtualHosts, n.config.HTTPTimeouts); err != nil {
+	jwtSecret, err := n.config.LoadJWTSecret()
+	if err != nil {
+		n.stopIPC()
+		n.stopInProc()
+		return err
+	}
+	n.jwtSecret = jwtSecret
+
+	if err := n.startHTTP(n.httpEndpoint, apis, n.config.HTTPModules, n.config.HTTPCors, n.config.HTTPVirtualHosts, n.config.HTTPTimeouts, jwtSecret); err != nil {
 		n.stopIPC()
 		n.stopInProc()
 		return err
 	}
-	if err := n.startWS(n.wsEndpoint, apis, n.config.WSModules, n.config.WSOrigins, n.config.WSExposeAll); err != nil {
+	if err := n.startWS(n.wsEndpoint, apis, n.config.WSModules, n.config.WSOrigins, n.config.WSExposeAll, jwtSecret); err != nil {
 		n.stopHTTP()
 		n.stopIPC()
 		n.stopInProc()
@@ -449,16 +463,23 @@ func (n *Node) startgRPC(apis []rpc.API) error {
 }
 
 // startHTTP initializes and starts the HTTP RPC endpoint.
-func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors []string, vhosts []string, timeouts rpc.HTTPTimeouts) error {
+func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors []string, vhosts []string, timeouts rpc.HTTPTimeouts, jwtSecret []byte) error {
 	// Short circuit if the HTTP endpoint isn't being exposed
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts)
+	n.httpHandlersLock.Lock()
+	handlers := make(map[string]http.Handler, len(n.httpHandlers))
+	for path, h := range n.httpHandlers {
+		handlers[path] = h
+	}
+	n.httpHandlersLock.Unlock()
+
+	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts, jwtSecret, handlers)
 	if err != nil {
 		return err
 	}
-	n.logger.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","))
+	n.logger.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","), "auth", len(jwtSecret) > 0)
 	// All listeners booted successfully
 	n.httpEndpoint = endpoint
 	n.httpListener = listener
@@ -467,6 +488,19 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 	return nil
 }
 
+// RegisterHTTPHandler mounts the given handler on the given path of the HTTP RPC
+// endpoint. It is meant to be called by the services while they are starting,
+// since the handlers are collected when the endpoint is opened.
+func (n *Node) RegisterHTTPHandler(path string, handler http.Handler) {
+	n.httpHandlersLock.Lock()
+	defer n.httpHandlersLock.Unlock()
+
+	if n.httpHandlers == nil {
+		n.httpHandlers = make(map[string]http.Handler)
+	}
+	n.httpHandlers[path] = handler
+}
+
 // stopHTTP terminates the HTTP RPC endpoint.
 func (n *Node) stopHTTP() {
 	if n.httpListener != nil {
@@ -482,16 +516,16 @@ func (n *Node) stopHTTP() {
 }
 
 // startWS initializes and starts the websocket RPC endpoint.
-func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrigins []string, exposeAll bool) error {
+func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrigins []string, exposeAll bool, jwtSecret []byte) error {
 	// Short circuit if the WS endpoint isn't being exposed
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll)
+	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll, jwtSecret)
 	if err != nil {
 		return err
 	}
-	n.logger.Info("WebSocket endpoint opened", "url", fmt.Sprintf("ws://%s", listener.Addr()))
+	n.logger.Info("WebSocket endpoint opened", "url", fmt.Sprintf("ws://%s", listener.Addr()), "auth", len(jwtSecret) > 0)
 	// All listeners booted successfully
 	n.wsEndpoint = endpoint
 	n.wsListener = listener
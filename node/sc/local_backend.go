@@ -93,6 +93,10 @@ func (fb *filterLocalBackend) SubscribeNewTxsEvent(ch chan<- blockchain.NewTxsEv
 	return fb.subbridge.txPool.SubscribeNewTxsEvent(ch)
 }
 
+func (fb *filterLocalBackend) SubscribeDroppedTxsEvent(ch chan<- blockchain.DroppedTxsEvent) event.Subscription {
+	return fb.subbridge.txPool.SubscribeDroppedTxsEvent(ch)
+}
+
 func (fb *filterLocalBackend) SubscribeChainEvent(ch chan<- blockchain.ChainEvent) event.Subscription {
 	return fb.subbridge.blockchain.SubscribeChainEvent(ch)
 }
@@ -22,6 +22,7 @@ package snapshot
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math"
 	"runtime"
@@ -51,7 +52,57 @@ type (
 	leafCallbackFn func(accountHash, codeHash common.Hash, stat *generateStats) (common.Hash, error)
 )
 
-// TODO-Kaia-Snapshot port GenerateAccountTrieRoot/GenerateStorageTrieRoot/GenerateTrie
+// TODO-Kaia-Snapshot port GenerateAccountTrieRoot/GenerateStorageTrieRoot
+
+// TrieWriter is the destination of the trie nodes and the contract codes
+// regenerated by GenerateTrie.
+type TrieWriter interface {
+	statedb.TrieNodeWriter
+	WriteCode(hash common.Hash, code []byte)
+}
+
+// GenerateTrie takes the whole snapshot tree as the input, traverses all the
+// accounts as well as the corresponding storages and regenerate the whole state
+// (account trie + all storage tries). The regenerated trie nodes and the contract
+// codes read from src are written to dst.
+func GenerateTrie(snaptree *Tree, root common.Hash, src database.DBManager, dst TrieWriter) error {
+	// Traverse all state by snapshot, re-generate the whole state trie
+	acctIt, err := snaptree.AccountIterator(root, common.Hash{})
+	if err != nil {
+		return err // The required snapshot might not exist.
+	}
+	defer acctIt.Release()
+
+	got, err := generateTrieRoot(acctIt, common.Hash{}, stackTrieGenerate(dst), func(accountHash, codeHash common.Hash, stat *generateStats) (common.Hash, error) {
+		// Migrate the code first, commit the contract code into the tmp db.
+		if codeHash != emptyCode {
+			code := src.ReadCode(codeHash)
+			if len(code) == 0 {
+				return common.Hash{}, errors.New("failed to read contract code")
+			}
+			dst.WriteCode(codeHash, code)
+		}
+		// Then migrate all storage trie nodes into the tmp db.
+		storageIt, err := snaptree.StorageIterator(root, accountHash, common.Hash{})
+		if err != nil {
+			return common.Hash{}, err
+		}
+		defer storageIt.Release()
+
+		hash, err := generateTrieRoot(storageIt, accountHash, stackTrieGenerate(dst), nil, stat, false)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		return hash, nil
+	}, newGenerateStats(), true)
+	if err != nil {
+		return err
+	}
+	if got != root {
+		return fmt.Errorf("state root hash mismatch: got %x, want %x", got, root)
+	}
+	return nil
+}
 
 // generateStats is a collection of statistics gathered by the trie generator
 // for logging purposes.
@@ -315,6 +366,24 @@ func generateTrieRoot(it Iterator, accountHash common.Hash, generatorFn trieGene
 	return stop(nil)
 }
 
+// stackTrieGenerate returns a trie generator which commits the generated
+// trie nodes into the given database with a stack trie.
+func stackTrieGenerate(db statedb.TrieNodeWriter) trieGeneratorFn {
+	return func(in chan trieKV, out chan common.Hash) {
+		t := statedb.NewStackTrie(db)
+		for leaf := range in {
+			t.TryUpdate(leaf.key[:], leaf.value)
+		}
+		var root common.Hash
+		if db == nil {
+			root = t.Hash()
+		} else {
+			root, _ = t.Commit()
+		}
+		out <- root
+	}
+}
+
 func trieGenerate(in chan trieKV, out chan common.Hash) {
 	db := statedb.NewDatabase(database.NewMemoryDBManager())
 	t, _ := statedb.NewTrie(common.Hash{}, db, nil)
@@ -0,0 +1,269 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// errHistoryUnavailable is returned if the state history of a state root can't
+// be generated because the root isn't maintained as a diff layer.
+var errHistoryUnavailable = errors.New("state history unavailable")
+
+// HistoryEntry is the value of an account or a storage slot before a block is
+// applied, in the snapshot data format. An empty blob means the item didn't exist.
+type HistoryEntry struct {
+	Hash common.Hash
+	Blob []byte
+}
+
+// HistoryStorage is the values of the storage slots of an account before a block
+// is applied. If the account is destructed by the block, Slots contains all the
+// slots the account had and any slot not listed didn't exist before the block.
+type HistoryStorage struct {
+	Account    common.Hash
+	Destructed bool
+	Slots      []HistoryEntry
+}
+
+// StateHistory is the reverse diff of the state made by a block. It holds the
+// values of the accounts and the storage slots modified by the block, as they
+// were before the block is applied. Applying a state history to the state of
+// Root rolls it back to the state of Parent.
+type StateHistory struct {
+	Parent   common.Hash
+	Root     common.Hash
+	Accounts []HistoryEntry
+	Storages []HistoryStorage
+}
+
+// StateHistory generates the state history of the state transition from parent
+// to root. The state of root must be maintained as a diff layer on top of the
+// state of parent.
+func (t *Tree) StateHistory(root common.Hash, parent common.Hash) (*StateHistory, error) {
+	history := &StateHistory{Parent: parent, Root: root}
+	if root == parent {
+		// The block didn't modify the state, so there's nothing to revert.
+		return history, nil
+	}
+	diff, ok := t.Snapshot(root).(*diffLayer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %x is not a diff layer", errHistoryUnavailable, root)
+	}
+	diff.lock.RLock()
+	defer diff.lock.RUnlock()
+
+	if diff.parent.Root() != parent {
+		return nil, fmt.Errorf("%w: parent mismatch, want %x, have %x", errHistoryUnavailable, parent, diff.parent.Root())
+	}
+
+	// Collect the accounts modified or destructed by the block
+	accounts := make(map[common.Hash]struct{}, len(diff.accountData)+len(diff.destructSet))
+	for hash := range diff.destructSet {
+		accounts[hash] = struct{}{}
+	}
+	for hash := range diff.accountData {
+		accounts[hash] = struct{}{}
+	}
+	for _, hash := range sortedHashes(accounts) {
+		blob, err := diff.parent.AccountRLP(hash)
+		if err != nil {
+			return nil, err
+		}
+		history.Accounts = append(history.Accounts, HistoryEntry{Hash: hash, Blob: common.CopyBytes(blob)})
+	}
+
+	// Collect the storage slots modified by the block, including all the slots
+	// of the destructed accounts
+	storages := make(map[common.Hash]struct{}, len(diff.storageData)+len(diff.destructSet))
+	for hash := range diff.destructSet {
+		storages[hash] = struct{}{}
+	}
+	for hash := range diff.storageData {
+		storages[hash] = struct{}{}
+	}
+	for _, accountHash := range sortedHashes(storages) {
+		storage := HistoryStorage{Account: accountHash}
+		if _, destructed := diff.destructSet[accountHash]; destructed {
+			storage.Destructed = true
+
+			it, err := t.StorageIterator(parent, accountHash, common.Hash{})
+			if err != nil {
+				return nil, err
+			}
+			for it.Next() {
+				storage.Slots = append(storage.Slots, HistoryEntry{Hash: it.Hash(), Blob: common.CopyBytes(it.Slot())})
+			}
+			err = it.Error()
+			it.Release()
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			slots := make(map[common.Hash]struct{}, len(diff.storageData[accountHash]))
+			for hash := range diff.storageData[accountHash] {
+				slots[hash] = struct{}{}
+			}
+			for _, hash := range sortedHashes(slots) {
+				blob, err := diff.parent.Storage(accountHash, hash)
+				if err != nil {
+					return nil, err
+				}
+				storage.Slots = append(storage.Slots, HistoryEntry{Hash: hash, Blob: common.CopyBytes(blob)})
+			}
+		}
+		history.Storages = append(history.Storages, storage)
+	}
+	return history, nil
+}
+
+// sortedHashes returns the keys of the given set in ascending order.
+func sortedHashes(set map[common.Hash]struct{}) []common.Hash {
+	hashes := make([]common.Hash, 0, len(set))
+	for hash := range set {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return bytes.Compare(hashes[i][:], hashes[j][:]) < 0 })
+	return hashes
+}
+
+// EncodeStateHistory returns the RLP encoding of the given state history.
+func EncodeStateHistory(history *StateHistory) ([]byte, error) {
+	return rlp.EncodeToBytes(history)
+}
+
+// DecodeStateHistory decodes the RLP encoded state history.
+func DecodeStateHistory(blob []byte) (*StateHistory, error) {
+	history := new(StateHistory)
+	if err := rlp.DecodeBytes(blob, history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// historicalSnapshot is a read-only snapshot of a past state. It's built by
+// rolling a base snapshot back with the state histories of the blocks after
+// the past state, so the trie of the past state isn't required.
+type historicalSnapshot struct {
+	root common.Hash
+	base Snapshot
+
+	accounts  map[common.Hash][]byte                 // Account values reverted from the base
+	storages  map[common.Hash]map[common.Hash][]byte // Storage values reverted from the base
+	destructs map[common.Hash]struct{}               // Accounts whose unlisted storage slots didn't exist
+}
+
+// NewHistoricalSnapshot returns a snapshot of the state of root, which is built
+// by reverting the given state histories from the base snapshot. The histories
+// must be in ascending block order, starting from the child state of root and
+// ending at the state of the base snapshot.
+func NewHistoricalSnapshot(base Snapshot, root common.Hash, histories []*StateHistory) (Snapshot, error) {
+	snap := &historicalSnapshot{
+		root:      root,
+		base:      base,
+		accounts:  make(map[common.Hash][]byte),
+		storages:  make(map[common.Hash]map[common.Hash][]byte),
+		destructs: make(map[common.Hash]struct{}),
+	}
+	parent := root
+	for _, history := range histories {
+		if history.Parent != parent {
+			return nil, fmt.Errorf("state history gap, want parent %x, have %x", parent, history.Parent)
+		}
+		parent = history.Root
+
+		// The value of an item in the past state is the value before its first
+		// modification after the past state, so earlier histories take precedence.
+		for _, acc := range history.Accounts {
+			if _, ok := snap.accounts[acc.Hash]; !ok {
+				snap.accounts[acc.Hash] = acc.Blob
+			}
+		}
+		for _, storage := range history.Storages {
+			if _, destructed := snap.destructs[storage.Account]; destructed {
+				continue
+			}
+			slots := snap.storages[storage.Account]
+			if slots == nil {
+				slots = make(map[common.Hash][]byte, len(storage.Slots))
+				snap.storages[storage.Account] = slots
+			}
+			for _, slot := range storage.Slots {
+				if _, ok := slots[slot.Hash]; !ok {
+					slots[slot.Hash] = slot.Blob
+				}
+			}
+			if storage.Destructed {
+				snap.destructs[storage.Account] = struct{}{}
+			}
+		}
+	}
+	if parent != base.Root() {
+		return nil, fmt.Errorf("state history gap, want root %x, have %x", base.Root(), parent)
+	}
+	return snap, nil
+}
+
+// Root returns the root hash of the past state.
+func (snap *historicalSnapshot) Root() common.Hash {
+	return snap.root
+}
+
+// Account directly retrieves the account associated with a particular hash in
+// the snapshot slim data format.
+func (snap *historicalSnapshot) Account(hash common.Hash) (account.Account, error) {
+	data, err := snap.AccountRLP(hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 { // can be both nil and []byte{}
+		return nil, nil
+	}
+	serializer := account.NewAccountSerializer()
+	if err := rlp.DecodeBytes(data, serializer); err != nil {
+		return nil, err
+	}
+	return serializer.GetAccount(), nil
+}
+
+// AccountRLP directly retrieves the account RLP associated with a particular
+// hash in the snapshot slim data format.
+func (snap *historicalSnapshot) AccountRLP(hash common.Hash) ([]byte, error) {
+	if blob, ok := snap.accounts[hash]; ok {
+		return blob, nil
+	}
+	return snap.base.AccountRLP(hash)
+}
+
+// Storage directly retrieves the storage data associated with a particular hash,
+// within a particular account.
+func (snap *historicalSnapshot) Storage(accountHash, storageHash common.Hash) ([]byte, error) {
+	if blob, ok := snap.storages[accountHash][storageHash]; ok {
+		return blob, nil
+	}
+	if _, destructed := snap.destructs[accountHash]; destructed {
+		return nil, nil
+	}
+	return snap.base.Storage(accountHash, storageHash)
+}
@@ -0,0 +1,144 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/VictoriaMetrics/fastcache"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHistoryTestTree creates a snapshot tree of the following layers, and
+// returns the tree and the state histories of the diff layers.
+//   - 0x01: disk layer with the account 0xaa and its slots 0x01, 0x02.
+//   - 0x02: modifies 0xaa and its slot 0x01, deletes its slot 0x02, and creates
+//     the account 0xbb with the slot 0x01.
+//   - 0x03: destructs and recreates 0xaa with the slot 0x03, and deletes 0xbb.
+//   - 0x04: modifies 0xbb and its slot 0x02.
+func newHistoryTestTree(t *testing.T) (*Tree, []*StateHistory) {
+	db := database.NewMemoryDBManager()
+	db.WriteAccountSnapshot(common.HexToHash("0xaa"), randomAccount())
+	db.WriteStorageSnapshot(common.HexToHash("0xaa"), common.HexToHash("0x01"), randomHash().Bytes())
+	db.WriteStorageSnapshot(common.HexToHash("0xaa"), common.HexToHash("0x02"), randomHash().Bytes())
+
+	base := &diskLayer{
+		diskdb: db,
+		root:   common.HexToHash("0x01"),
+		cache:  fastcache.New(1024 * 500),
+	}
+	snaps := &Tree{layers: map[common.Hash]snapshot{base.root: base}}
+
+	require.NoError(t, snaps.Update(common.HexToHash("0x02"), common.HexToHash("0x01"), nil,
+		randomAccountSet("0xaa", "0xbb"),
+		randomStorageSet([]string{"0xaa", "0xbb"}, [][]string{{"0x01"}, {"0x01"}}, [][]string{{"0x02"}})))
+	require.NoError(t, snaps.Update(common.HexToHash("0x03"), common.HexToHash("0x02"),
+		map[common.Hash]struct{}{common.HexToHash("0xaa"): {}, common.HexToHash("0xbb"): {}},
+		randomAccountSet("0xaa"),
+		randomStorageSet([]string{"0xaa"}, [][]string{{"0x03"}}, nil)))
+	require.NoError(t, snaps.Update(common.HexToHash("0x04"), common.HexToHash("0x03"), nil,
+		randomAccountSet("0xbb"),
+		randomStorageSet([]string{"0xbb"}, [][]string{{"0x02"}}, nil)))
+
+	var histories []*StateHistory
+	for i := byte(2); i <= 4; i++ {
+		history, err := snaps.StateHistory(common.BytesToHash([]byte{i}), common.BytesToHash([]byte{i - 1}))
+		require.NoError(t, err)
+		histories = append(histories, history)
+	}
+	return snaps, histories
+}
+
+// checkSnapshotEqual checks the given snapshots have the same accounts and slots.
+func checkSnapshotEqual(t *testing.T, expected, actual Snapshot) {
+	for _, acc := range []string{"0xaa", "0xbb", "0xcc"} {
+		want, err := expected.AccountRLP(common.HexToHash(acc))
+		require.NoError(t, err)
+		have, err := actual.AccountRLP(common.HexToHash(acc))
+		require.NoError(t, err)
+		assert.Equal(t, len(want) == 0, len(have) == 0, "account %s", acc)
+		if len(want) != 0 {
+			assert.Equal(t, want, have, "account %s", acc)
+		}
+
+		for _, slot := range []string{"0x01", "0x02", "0x03", "0x04"} {
+			want, err := expected.Storage(common.HexToHash(acc), common.HexToHash(slot))
+			require.NoError(t, err)
+			have, err := actual.Storage(common.HexToHash(acc), common.HexToHash(slot))
+			require.NoError(t, err)
+			assert.Equal(t, len(want) == 0, len(have) == 0, "account %s slot %s", acc, slot)
+			if len(want) != 0 {
+				assert.Equal(t, want, have, "account %s slot %s", acc, slot)
+			}
+		}
+	}
+}
+
+func TestStateHistory(t *testing.T) {
+	snaps, histories := newHistoryTestTree(t)
+
+	// The histories hold the values of the parent layers.
+	assert.Len(t, histories[0].Accounts, 2)
+	assert.Empty(t, histories[0].Accounts[1].Blob, "0xbb didn't exist")
+	assert.True(t, histories[1].Storages[0].Destructed)
+	assert.Len(t, histories[1].Storages[0].Slots, 1, "all the slots of 0xaa are recorded")
+
+	// An empty state transition has an empty history.
+	history, err := snaps.StateHistory(common.HexToHash("0x04"), common.HexToHash("0x04"))
+	require.NoError(t, err)
+	assert.Empty(t, history.Accounts)
+	assert.Empty(t, history.Storages)
+
+	// The disk layer has no history, and the parent must match.
+	_, err = snaps.StateHistory(common.HexToHash("0x01"), common.Hash{})
+	assert.ErrorIs(t, err, errHistoryUnavailable)
+	_, err = snaps.StateHistory(common.HexToHash("0x04"), common.HexToHash("0x02"))
+	assert.ErrorIs(t, err, errHistoryUnavailable)
+
+	// The histories survive the encoding.
+	for _, history := range histories {
+		enc, err := EncodeStateHistory(history)
+		require.NoError(t, err)
+		dec, err := DecodeStateHistory(enc)
+		require.NoError(t, err)
+		assert.Equal(t, history.Root, dec.Root)
+		assert.Equal(t, len(history.Accounts), len(dec.Accounts))
+		assert.Equal(t, len(history.Storages), len(dec.Storages))
+	}
+}
+
+func TestHistoricalSnapshot(t *testing.T) {
+	snaps, histories := newHistoryTestTree(t)
+	head := snaps.Snapshot(common.HexToHash("0x04"))
+
+	for i := byte(1); i <= 4; i++ {
+		root := common.BytesToHash([]byte{i})
+		snap, err := NewHistoricalSnapshot(head, root, histories[i-1:])
+		require.NoError(t, err)
+		assert.Equal(t, root, snap.Root())
+		checkSnapshotEqual(t, snaps.Snapshot(root), snap)
+	}
+
+	// The histories must be contiguous up to the base.
+	_, err := NewHistoricalSnapshot(head, common.HexToHash("0x01"), []*StateHistory{histories[0], histories[2]})
+	assert.Error(t, err)
+	_, err = NewHistoricalSnapshot(head, common.HexToHash("0x01"), histories[:2])
+	assert.Error(t, err)
+}
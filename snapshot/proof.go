@@ -0,0 +1,162 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+// proofCollector is the database of the StackTrie regenerating a trie from the
+// snapshot. Out of all the trie nodes, it keeps only the nodes on the paths of
+// the keys to be proven, so the memory usage doesn't grow with the trie size.
+type proofCollector struct {
+	keys  [][]byte      // Hex-encoded keys to be proven
+	nodes [][]proofNode // Collected nodes of each key, from the leaf to the root
+}
+
+type proofNode struct {
+	hash common.ExtHash
+	blob []byte
+}
+
+func newProofCollector(keys []common.Hash) *proofCollector {
+	c := &proofCollector{
+		keys:  make([][]byte, len(keys)),
+		nodes: make([][]proofNode, len(keys)),
+	}
+	for i, key := range keys {
+		c.keys[i] = keybytesToHex(key.Bytes())
+	}
+	return c
+}
+
+// WriteTrieNodeWithPath keeps the node if it's on the path of any key. The
+// StackTrie writes the nodes in post-order, so the descendants come first.
+func (c *proofCollector) WriteTrieNodeWithPath(path []byte, hash common.ExtHash, node []byte) {
+	for i, key := range c.keys {
+		if bytes.HasPrefix(key, path) {
+			c.nodes[i] = append(c.nodes[i], proofNode{hash, common.CopyBytes(node)})
+		}
+	}
+}
+
+// WriteTrieNode is never called as the collector implements statedb.TrieNodePathWriter.
+func (c *proofCollector) WriteTrieNode(hash common.ExtHash, node []byte) {
+	panic("trie node without path")
+}
+
+// prove writes the collected proof of the i-th key into proofDb, from the root
+// to the leaf as statedb.Trie.Prove does.
+func (c *proofCollector) prove(i int, proofDb statedb.ProofDBWriter) {
+	nodes := c.nodes[i]
+	for j := len(nodes) - 1; j >= 0; j-- {
+		proofDb.WriteMerkleProof(database.TrieNodeKey(nodes[j].hash), nodes[j].blob)
+	}
+}
+
+// keybytesToHex converts the key into the nibbles used as the trie path.
+func keybytesToHex(key []byte) []byte {
+	nibbles := make([]byte, len(key)*2)
+	for i, b := range key {
+		nibbles[i*2] = b / 16
+		nibbles[i*2+1] = b % 16
+	}
+	return nibbles
+}
+
+// regenerateCheckInterval is the number of leaves fed into the StackTrie between
+// the checks of the context of the proof regeneration.
+const regenerateCheckInterval = 1024
+
+// regenerate feeds the leaves of the iterator into a StackTrie and checks the
+// resulting root. The leaf function returns the trie leaf value of the current
+// iterator position. It stops when the context is done.
+func regenerate(ctx context.Context, it Iterator, leaf func() []byte, root common.Hash, collector *proofCollector) error {
+	st := statedb.NewStackTrie(collector)
+	for n := 1; it.Next(); n++ {
+		if n%regenerateCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		st.TryUpdate(it.Hash().Bytes(), leaf())
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	got, err := st.Commit()
+	if err != nil {
+		return err
+	}
+	if got != root {
+		return fmt.Errorf("regenerated trie root mismatch: got %x, want %x", got, root)
+	}
+	return nil
+}
+
+// AccountProof writes the Merkle proof of the account in the state of root into
+// proofDb. The account trie is regenerated from the snapshot, so the proof can be
+// made even if the trie nodes of the state have been pruned, as long as the
+// snapshot layers still cover the state. It traverses all the accounts, hence
+// it's much slower than proving from the trie.
+func (t *Tree) AccountProof(ctx context.Context, root, accountHash common.Hash, proofDb statedb.ProofDBWriter) error {
+	it, err := t.AccountIterator(root, common.Hash{})
+	if err != nil {
+		return err
+	}
+	defer it.Release()
+
+	// The trie hashes the accounts with the unextended storage roots.
+	leaf := func() []byte { return account.UnextendSerializedAccount(it.Account()) }
+	collector := newProofCollector([]common.Hash{accountHash})
+	if err := regenerate(ctx, it, leaf, root, collector); err != nil {
+		return err
+	}
+	collector.prove(0, proofDb)
+	return nil
+}
+
+// StorageProof writes the Merkle proofs of the storage slots of the account in
+// the state of root into proofDbs, the i-th proof into the i-th writer. The
+// storage trie is regenerated from the snapshot and checked against storageRoot.
+func (t *Tree) StorageProof(ctx context.Context, root, accountHash, storageRoot common.Hash, slotHashes []common.Hash, proofDbs []statedb.ProofDBWriter) error {
+	if len(slotHashes) != len(proofDbs) {
+		return fmt.Errorf("proof writer count mismatch: have %d, want %d", len(proofDbs), len(slotHashes))
+	}
+	it, err := t.StorageIterator(root, accountHash, common.Hash{})
+	if err != nil {
+		return err
+	}
+	defer it.Release()
+
+	leaf := func() []byte { return common.CopyBytes(it.Slot()) }
+	collector := newProofCollector(slotHashes)
+	if err := regenerate(ctx, it, leaf, storageRoot, collector); err != nil {
+		return err
+	}
+	for i, proofDb := range proofDbs {
+		collector.prove(i, proofDb)
+	}
+	return nil
+}
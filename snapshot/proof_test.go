@@ -0,0 +1,120 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/VictoriaMetrics/fastcache"
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testProofList [][]byte
+
+func (l *testProofList) WriteMerkleProof(key, value []byte) {
+	*l = append(*l, value)
+}
+
+// Tests that the proofs made from the snapshot are the same as the ones made
+// from the trie, for both existent and nonexistent keys.
+func TestSnapshotProof(t *testing.T) {
+	var (
+		helper = newHelper()
+		keys   = []string{"key-1", "key-2", "key-3", "key-4"}
+		vals   = []string{"val-1", "val-2", "val-3", "val-4"}
+		stRoot = helper.makeStorageTrie(keys, vals)
+	)
+	for i := 0; i < 100; i++ {
+		var acc account.Account
+		if i%2 == 0 {
+			acc, _ = genExternallyOwnedAccount(uint64(i), big.NewInt(int64(i)))
+		} else {
+			acc, _ = genSmartContractAccount(uint64(i), big.NewInt(int64(i)), stRoot, emptyCode.Bytes())
+			helper.addSnapStorage(fmt.Sprintf("acc-%d", i), keys, vals)
+		}
+		helper.addAccount(fmt.Sprintf("acc-%d", i), acc)
+	}
+	root, _ := helper.accTrie.Commit(nil)
+	helper.triedb.Commit(root, false, 0)
+
+	base := &diskLayer{
+		diskdb: helper.diskdb,
+		triedb: helper.triedb,
+		root:   root,
+		cache:  fastcache.New(1024 * 500),
+	}
+	snaps := &Tree{layers: map[common.Hash]snapshot{root: base}}
+
+	accTrie, err := statedb.NewTrie(root, helper.triedb, nil)
+	require.NoError(t, err)
+	for _, key := range []string{"acc-0", "acc-1", "acc-99", "acc-100"} {
+		var want, have testProofList
+		require.NoError(t, accTrie.Prove(hashData([]byte(key)).Bytes(), 0, &want))
+		require.NoError(t, snaps.AccountProof(context.Background(), root, hashData([]byte(key)), &have))
+		assert.Equal(t, want, have, "account %s", key)
+	}
+
+	stTrie, err := statedb.NewTrie(stRoot, helper.triedb, nil)
+	require.NoError(t, err)
+	var (
+		slots = []common.Hash{hashData([]byte("key-1")), hashData([]byte("key-4")), hashData([]byte("key-5"))}
+		want  = make([]testProofList, len(slots))
+		have  = make([]testProofList, len(slots))
+		dbs   = make([]statedb.ProofDBWriter, len(slots))
+	)
+	for i, slot := range slots {
+		require.NoError(t, stTrie.Prove(slot.Bytes(), 0, &want[i]))
+		dbs[i] = &have[i]
+	}
+	require.NoError(t, snaps.StorageProof(context.Background(), root, hashData([]byte("acc-1")), stRoot, slots, dbs))
+	assert.Equal(t, want, have)
+
+	// The regenerated trie must match the expected root.
+	assert.Error(t, snaps.StorageProof(context.Background(), root, hashData([]byte("acc-0")), stRoot, slots, dbs))
+}
+
+// Tests that the proof regeneration stops when the context is done.
+func TestSnapshotProofCanceled(t *testing.T) {
+	helper := newHelper()
+	for i := 0; i < 2*regenerateCheckInterval; i++ {
+		acc, _ := genExternallyOwnedAccount(uint64(i), big.NewInt(int64(i)))
+		helper.addAccount(fmt.Sprintf("acc-%d", i), acc)
+	}
+	root, _ := helper.accTrie.Commit(nil)
+	helper.triedb.Commit(root, false, 0)
+
+	base := &diskLayer{
+		diskdb: helper.diskdb,
+		triedb: helper.triedb,
+		root:   root,
+		cache:  fastcache.New(1024 * 500),
+	}
+	snaps := &Tree{layers: map[common.Hash]snapshot{root: base}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var proof testProofList
+	assert.ErrorIs(t, snaps.AccountProof(ctx, root, hashData([]byte("acc-0")), &proof), context.Canceled)
+	assert.NoError(t, snaps.AccountProof(context.Background(), root, hashData([]byte("acc-0")), &proof))
+}
@@ -262,3 +262,47 @@ func (bg *badgerDB) Stat(property string) (string, error) {
 func (bg *badgerDB) Compact(start []byte, limit []byte) error {
 	return nil
 }
+
+// Checkpoint opens a read-only transaction, which keeps seeing the data at the
+// moment it's opened. The data is copied into a new database on Save.
+func (bg *badgerDB) Checkpoint(dir string) (Checkpoint, error) {
+	return &badgerCheckpoint{txn: bg.db.NewTransaction(false), dir: dir}, nil
+}
+
+type badgerCheckpoint struct {
+	txn *badger.Txn
+	dir string
+}
+
+func (cp *badgerCheckpoint) Save() error {
+	if _, err := os.Stat(cp.dir); err == nil {
+		return fmt.Errorf("checkpoint directory already exists: %v", cp.dir)
+	}
+	cpdb, err := badger.Open(getBadgerDBOptions(cp.dir))
+	if err != nil {
+		return err
+	}
+	defer cpdb.Close()
+
+	wb := cpdb.NewWriteBatch()
+	defer wb.Cancel()
+
+	it := cp.txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+
+	for it.Rewind(); it.Valid(); it.Next() {
+		item := it.Item()
+		value, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		if err := wb.Set(item.KeyCopy(nil), value); err != nil {
+			return err
+		}
+	}
+	return wb.Flush()
+}
+
+func (cp *badgerCheckpoint) Release() {
+	cp.txn.Discard()
+}
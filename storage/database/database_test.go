@@ -69,6 +69,22 @@ func newTestBadgerDB() (Database, func(), string) {
 	}, "badger"
 }
 
+func newTestPebbleDB() (Database, func(), string) {
+	dirName, err := os.MkdirTemp(os.TempDir(), "klay_pebbledb_test_")
+	if err != nil {
+		panic("failed to create test file: " + err.Error())
+	}
+	db, err := NewPebbleDB(&DBConfig{Dir: dirName, PebbleDBConfig: GetDefaultPebbleDBConfig()}, MiscDB)
+	if err != nil {
+		panic("failed to create test database: " + err.Error())
+	}
+
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dirName)
+	}, "pebble"
+}
+
 func newTestMemDB() (Database, func(), string) {
 	db := NewMemDB()
 	return db, func() {
@@ -117,7 +133,7 @@ func TestDatabaseTestSuite(t *testing.T) {
 	// var testDatabases = []func() (Database, func()){newTestLDB, newTestBadgerDB, newTestMemDB, newTestDynamoS3DB}
 
 	// TODO-Kaia-Database Need to add DynamoDB to the below list.
-	testDatabases = append(testDatabases, newTestLDB, newTestBadgerDB, newTestMemDB)
+	testDatabases = append(testDatabases, newTestLDB, newTestBadgerDB, newTestMemDB, newTestPebbleDB)
 	for _, newFn := range testDatabases {
 		suite.Run(t, &commonDatabaseTestSuite{newFn: newFn})
 	}
@@ -135,6 +135,16 @@ type DBManager interface {
 	WriteIstanbulSnapshot(hash common.Hash, blob []byte)
 	DeleteIstanbulSnapshot(hash common.Hash)
 
+	ReadIstanbulWAL() []byte
+	WriteIstanbulWAL(blob []byte)
+	IstanbulWALSynced() bool
+
+	ReadIstanbulMisbehaviours(from, to uint64) [][]byte
+	WriteIstanbulMisbehaviour(sequence uint64, hash common.Hash, blob []byte)
+
+	ReadIstanbulTrace(slot uint64) []byte
+	WriteIstanbulTrace(slot uint64, blob []byte)
+
 	WriteMerkleProof(key, value []byte)
 
 	// Bytecodes related operations
@@ -150,6 +160,7 @@ type DBManager interface {
 	HasTrieNode(hash common.ExtHash) (bool, error)
 	HasCodeWithPrefix(hash common.Hash) bool
 	ReadPreimage(hash common.Hash) []byte
+	NewPreimageIterator() Iterator
 
 	// Read StateTrie from new DB
 	ReadTrieNodeFromNew(hash common.ExtHash) ([]byte, error)
@@ -248,6 +259,12 @@ type DBManager interface {
 
 	NewSnapshotDBBatch() SnapshotDBBatch
 
+	ReadStateHistory(number uint64, hash common.Hash) []byte
+	WriteStateHistory(number uint64, hash common.Hash, history []byte)
+	DeleteStateHistories(number uint64)
+	ReadStateHistoryTail() *uint64
+	WriteStateHistoryTail(number uint64)
+
 	// below operations are used in parent chain side, not child chain side.
 	WriteChildChainTxHash(ccBlockHash common.Hash, ccTxHash common.Hash)
 	ConvertChildChainBlockHashToParentChainTxHash(scBlockHash common.Hash) common.Hash
@@ -301,6 +318,10 @@ type DBManager interface {
 	// DB migration related function
 	StartDBMigration(DBManager) error
 
+	// Ancient store related functions
+	Ancients() uint64
+	TruncateAncients(items uint64) error
+
 	// ChainDataFetcher checkpoint function
 	WriteChainDataFetcherCheckpoint(checkpoint uint64)
 	ReadChainDataFetcherCheckpoint() (uint64, error)
@@ -309,6 +330,21 @@ type DBManager interface {
 
 	Stat(string) (string, error)
 	Compact([]byte, []byte) error
+
+	// Backup related functions
+	Checkpoint(dir string) (Checkpoint, error)
+
+	// Receipts migration related functions
+	ReadReceiptsMigration() []byte
+	ScheduleReceiptsMigration()
+	MigrateReceipts(from []byte) ([]byte, int, int, error)
+
+	// Log index related functions
+	ReadLogIndexRange() (tail, next uint64, ok bool)
+	ReadLogIndexByAddress(address common.Address, from, to uint64) []uint64
+	ReadLogIndexByTopic(position int, topic common.Hash, from, to uint64) []uint64
+	BackfillLogIndex(quit <-chan struct{}) error
+	ExtendLogIndex(from, to uint64)
 }
 
 type DBEntryType uint8
@@ -417,6 +453,13 @@ func getDBEntryConfig(originalDBC *DBConfig, i DBEntryType, dbDir string) *DBCon
 		newDBC.RocksDBConfig = &newRocksDBConfig
 	}
 
+	if newDBC.PebbleDBConfig != nil {
+		newPebbleDBConfig := *originalDBC.PebbleDBConfig
+		newPebbleDBConfig.CacheSize = originalDBC.PebbleDBConfig.CacheSize * uint64(ratio) / 100
+		newPebbleDBConfig.MaxOpenFiles = originalDBC.PebbleDBConfig.MaxOpenFiles * ratio / 100
+		newDBC.PebbleDBConfig = &newPebbleDBConfig
+	}
+
 	return &newDBC
 }
 
@@ -430,6 +473,17 @@ type databaseManager struct {
 	lockInMigration      sync.RWMutex
 	inMigration          bool
 	migrationBlockNumber uint64
+
+	// ancient store keeping old blocks in flat files.
+	freezer     *freezer
+	lockFreezer sync.Mutex
+	quitFreezer chan struct{}
+	wgFreezer   sync.WaitGroup
+
+	// index of the block numbers by the addresses and topics of their logs.
+	lockLogIndex sync.RWMutex
+	logIndexTail uint64
+	logIndexNext uint64
 }
 
 func NewMemoryDBManager() DBManager {
@@ -464,15 +518,28 @@ type DBConfig struct {
 	// RocksDB related configurations
 	RocksDBConfig *RocksDBConfig
 
+	// PebbleDB related configurations
+	PebbleDBConfig *PebbleDBConfig
+
 	// DynamoDB related configurations
 	DynamoDBConfig *DynamoDBConfig
+
+	// Ancient store related configurations
+	EnableAncient    bool   // If true, old blocks are moved to the append-only ancient store
+	AncientThreshold uint64 // Number of recent blocks kept in the key-value stores
+
+	// Log index related configurations
+	EnableLogIndex bool // If true, the block numbers are indexed by the addresses and topics of their logs
+
+	// Receipts related configurations
+	CompactReceipts bool // If true, receipts are stored without the fields derivable from the block body
 }
 
 const dbMetricPrefix = "klay/db/chaindata/"
 
 // singleDatabaseDBManager returns DBManager which handles one single Database.
 // Each Database will share one common Database.
-func singleDatabaseDBManager(dbc *DBConfig) (DBManager, error) {
+func singleDatabaseDBManager(dbc *DBConfig) (*databaseManager, error) {
 	dbm := newDatabaseManager(dbc)
 	db, err := newDatabase(dbc, 0)
 	if err != nil {
@@ -552,6 +619,8 @@ func newDatabase(dbc *DBConfig, entryType DBEntryType) (Database, error) {
 		return NewRocksDB(dbc.Dir, dbc.RocksDBConfig)
 	case BadgerDB:
 		return NewBadgerDB(dbc.Dir)
+	case PebbleDB:
+		return NewPebbleDB(dbc, entryType)
 	case MemoryDB:
 		return NewMemDB(), nil
 	case DynamoDB:
@@ -580,6 +649,10 @@ func NewDBManager(dbc *DBConfig) DBManager {
 		if dbm, err := singleDatabaseDBManager(dbc); err != nil {
 			logger.Crit("Failed to create a single database", "DBType", dbc.DBType, "err", err)
 		} else {
+			if err := dbm.openAncientStore(); err != nil {
+				logger.Crit("Failed to open the ancient store", "err", err)
+			}
+			dbm.openLogIndex()
 			return dbm
 		}
 	} else {
@@ -598,6 +671,10 @@ func NewDBManager(dbc *DBConfig) DBManager {
 				dbm.migrationBlockNumber = migrationBlockNum
 			}
 		}
+		if err := dbm.openAncientStore(); err != nil {
+			logger.Crit("Failed to open the ancient store", "err", err)
+		}
+		dbm.openLogIndex()
 		return dbm
 	}
 	logger.Crit("Must not reach here!")
@@ -946,6 +1023,8 @@ func (dbm *databaseManager) getDatabase(dbEntryType DBEntryType) Database {
 }
 
 func (dbm *databaseManager) Close() {
+	dbm.closeAncientStore()
+
 	// If single DB, only close the first database.
 	if dbm.config.SingleDB {
 		dbm.dbs[0].Close()
@@ -971,7 +1050,11 @@ func (dbm *databaseManager) ReadCanonicalHash(number uint64) common.Hash {
 	db := dbm.getDatabase(headerDB)
 	data, _ := db.Get(headerHashKey(number))
 	if len(data) == 0 {
-		return common.Hash{}
+		hash := dbm.readAncientCanonicalHash(number)
+		if !common.EmptyHash(hash) {
+			dbm.cm.writeCanonicalHashCache(number, hash)
+		}
+		return hash
 	}
 
 	hash := common.BytesToHash(data)
@@ -981,11 +1064,18 @@ func (dbm *databaseManager) ReadCanonicalHash(number uint64) common.Hash {
 
 // WriteCanonicalHash stores the hash assigned to a canonical block number.
 func (dbm *databaseManager) WriteCanonicalHash(hash common.Hash, number uint64) {
+	var prev common.Hash
+	if dbm.config.EnableLogIndex {
+		prev = dbm.ReadCanonicalHash(number)
+	}
 	db := dbm.getDatabase(headerDB)
 	if err := db.Put(headerHashKey(number), hash.Bytes()); err != nil {
 		logger.Crit("Failed to store number to hash mapping", "err", err)
 	}
 	dbm.cm.writeCanonicalHashCache(number, hash)
+	if dbm.config.EnableLogIndex {
+		dbm.reindexLogs(prev, hash, number)
+	}
 }
 
 // DeleteCanonicalHash removes the number to hash canonical mapping.
@@ -1124,7 +1214,7 @@ func (dbm *databaseManager) HasHeader(hash common.Hash, number uint64) bool {
 
 	db := dbm.getDatabase(headerDB)
 	if has, err := db.Has(headerKey(number, hash)); !has || err != nil {
-		return false
+		return dbm.hasAncient(hash, number)
 	}
 	return true
 }
@@ -1154,6 +1244,9 @@ func (dbm *databaseManager) ReadHeader(hash common.Hash, number uint64) *types.H
 func (dbm *databaseManager) ReadHeaderRLP(hash common.Hash, number uint64) rlp.RawValue {
 	db := dbm.getDatabase(headerDB)
 	data, _ := db.Get(headerKey(number, hash))
+	if len(data) == 0 {
+		data = dbm.readAncient(freezerHeaderTable, hash, number)
+	}
 	return data
 }
 
@@ -1225,7 +1318,7 @@ func (dbm *databaseManager) ReadHeaderNumber(hash common.Hash) *uint64 {
 func (dbm *databaseManager) HasBody(hash common.Hash, number uint64) bool {
 	db := dbm.getDatabase(BodyDB)
 	if has, err := db.Has(blockBodyKey(number, hash)); !has || err != nil {
-		return false
+		return dbm.hasAncient(hash, number)
 	}
 	return true
 }
@@ -1275,6 +1368,9 @@ func (dbm *databaseManager) ReadBodyRLP(hash common.Hash, number uint64) rlp.Raw
 	// not found in cache, find body in database
 	db := dbm.getDatabase(BodyDB)
 	data, _ := db.Get(blockBodyKey(number, hash))
+	if len(data) == 0 {
+		data = dbm.readAncient(freezerBodiesTable, hash, number)
+	}
 
 	// Write to cache at the end of successful read.
 	dbm.cm.writeBodyRLPCache(hash, data)
@@ -1304,6 +1400,9 @@ func (dbm *databaseManager) ReadBodyRLPByHash(hash common.Hash) rlp.RawValue {
 
 	db := dbm.getDatabase(BodyDB)
 	data, _ := db.Get(blockBodyKey(*number, hash))
+	if len(data) == 0 {
+		data = dbm.readAncient(freezerBodiesTable, hash, *number)
+	}
 
 	// Write to cache at the end of successful read.
 	dbm.cm.writeBodyRLPCache(hash, data)
@@ -1364,6 +1463,9 @@ func (dbm *databaseManager) ReadTd(hash common.Hash, number uint64) *big.Int {
 
 	db := dbm.getDatabase(MiscDB)
 	data, _ := db.Get(headerTDKey(number, hash))
+	if len(data) == 0 {
+		data = dbm.readAncient(freezerDifficultyTable, hash, number)
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -1423,18 +1525,30 @@ func (dbm *databaseManager) ReadReceipts(blockHash common.Hash, number uint64) t
 	db := dbm.getDatabase(ReceiptsDB)
 	// Retrieve the flattened receipt slice
 	data, _ := db.Get(blockReceiptsKey(number, blockHash))
+	if len(data) == 0 {
+		data = dbm.readAncient(freezerReceiptTable, blockHash, number)
+	}
 	if len(data) == 0 {
 		return nil
 	}
 	// Convert the revceipts from their database form to their internal representation
-	storageReceipts := []*types.ReceiptForStorage{}
-	if err := rlp.DecodeBytes(data, &storageReceipts); err != nil {
+	receipts, compact, err := types.DecodeStoredReceipts(data)
+	if err != nil {
 		logger.Error("Invalid receipt array RLP", "blockHash", blockHash, "err", err)
 		return nil
 	}
-	receipts := make(types.Receipts, len(storageReceipts))
-	for i, receipt := range storageReceipts {
-		receipts[i] = (*types.Receipt)(receipt)
+	if !compact || len(receipts) == 0 {
+		return receipts
+	}
+	// The compact receipts are filled with the fields derivable from the block body
+	body := dbm.ReadBody(blockHash, number)
+	if body == nil {
+		logger.Error("Missing body of the compact receipts", "number", number, "blockHash", blockHash)
+		return nil
+	}
+	if err := receipts.DeriveFields(blockHash, number, body.Transactions); err != nil {
+		logger.Error("Failed to derive the fields of the receipts", "number", number, "blockHash", blockHash, "err", err)
+		return nil
 	}
 	return receipts
 }
@@ -1458,24 +1572,42 @@ func (dbm *databaseManager) WriteReceipts(hash common.Hash, number uint64, recei
 	db := dbm.getDatabase(ReceiptsDB)
 	// When putReceiptsToPutter is called from WriteReceipts, txReceipt is cached.
 	dbm.putReceiptsToPutter(db, hash, number, receipts, true)
+	// The log index entries are already written, so the range is extended at once.
+	if dbm.config.EnableLogIndex && dbm.putLogIndex(db, hash, number, receipts) {
+		dbm.ExtendLogIndex(number, number)
+	}
 }
 
 func (dbm *databaseManager) PutReceiptsToBatch(batch Batch, hash common.Hash, number uint64, receipts types.Receipts) {
 	// When putReceiptsToPutter is called from PutReceiptsToBatch, txReceipt is not cached.
 	dbm.putReceiptsToPutter(batch, hash, number, receipts, false)
+	// The log index range is extended by ExtendLogIndex after the batch is written.
+	if dbm.config.EnableLogIndex {
+		dbm.putLogIndex(batch, hash, number, receipts)
+	}
 }
 
 func (dbm *databaseManager) putReceiptsToPutter(putter KeyValueWriter, hash common.Hash, number uint64, receipts types.Receipts, addToCache bool) {
-	// Convert the receipts into their database form and serialize them
-	storageReceipts := make([]*types.ReceiptForStorage, len(receipts))
-	for i, receipt := range receipts {
-		storageReceipts[i] = (*types.ReceiptForStorage)(receipt)
-
-		if addToCache {
+	if addToCache {
+		for _, receipt := range receipts {
 			dbm.cm.writeTxReceiptCache(receipt.TxHash, receipt)
 		}
 	}
-	bytes, err := rlp.EncodeToBytes(storageReceipts)
+	var (
+		bytes []byte
+		err   error
+	)
+	if dbm.config.CompactReceipts {
+		// Convert the receipts into their compact database form and serialize them
+		bytes, err = encodeCompactReceipts(receipts)
+	} else {
+		// Convert the receipts into their database form and serialize them
+		storageReceipts := make([]*types.ReceiptForStorage, len(receipts))
+		for i, receipt := range receipts {
+			storageReceipts[i] = (*types.ReceiptForStorage)(receipt)
+		}
+		bytes, err = rlp.EncodeToBytes(storageReceipts)
+	}
 	if err != nil {
 		logger.Crit("Failed to encode block receipts", "err", err)
 	}
@@ -1493,6 +1625,9 @@ func (dbm *databaseManager) DeleteReceipts(hash common.Hash, number uint64) {
 	if err := db.Delete(blockReceiptsKey(number, hash)); err != nil {
 		logger.Crit("Failed to delete block receipts", "err", err)
 	}
+	if dbm.config.EnableLogIndex {
+		dbm.deleteLogIndex(hash, number, receipts)
+	}
 
 	// Delete blockReceiptsCache and txReceiptCache.
 	dbm.cm.deleteBlockReceiptsCache(hash)
@@ -1742,6 +1877,79 @@ func (dbm *databaseManager) DeleteIstanbulSnapshot(hash common.Hash) {
 	}
 }
 
+// ReadIstanbulWAL retrieves the write-ahead log of the istanbul consensus core.
+func (dbm *databaseManager) ReadIstanbulWAL() []byte {
+	db := dbm.getDatabase(MiscDB)
+	data, _ := db.Get(istanbulWALKey)
+	return data
+}
+
+// WriteIstanbulWAL stores the write-ahead log of the istanbul consensus core.
+// The write is synced to the disk if the database supports it, since the log
+// must survive an OS crash to keep the node from signing conflicting messages.
+func (dbm *databaseManager) WriteIstanbulWAL(blob []byte) {
+	db := dbm.getDatabase(MiscDB)
+	put := db.Put
+	if syncer, ok := db.(SyncWriter); ok {
+		put = syncer.PutSync
+	}
+	if err := put(istanbulWALKey, blob); err != nil {
+		logger.Crit("Failed to write istanbul write-ahead log", "err", err)
+	}
+}
+
+// IstanbulWALSynced returns whether the writes of the istanbul write-ahead log
+// are synced to the disk. It is false if the database does not support synced
+// writes, in which case the log may be lost on an OS crash.
+func (dbm *databaseManager) IstanbulWALSynced() bool {
+	_, ok := dbm.getDatabase(MiscDB).(SyncWriter)
+	return ok
+}
+
+// ReadIstanbulMisbehaviours retrieves the evidences of the misbehaving validators
+// detected in the sequences [from, to], in ascending order of the sequences.
+func (dbm *databaseManager) ReadIstanbulMisbehaviours(from, to uint64) [][]byte {
+	it := dbm.getDatabase(MiscDB).NewIterator(istanbulMisbehaviourPrefix, common.Int64ToByteBigEndian(from))
+	defer it.Release()
+
+	var blobs [][]byte
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(istanbulMisbehaviourPrefix)+8+common.HashLength {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(istanbulMisbehaviourPrefix):]) > to {
+			break
+		}
+		blobs = append(blobs, common.CopyBytes(it.Value()))
+	}
+	return blobs
+}
+
+// WriteIstanbulMisbehaviour stores the evidence of a misbehaving validator
+// detected in the given sequence.
+func (dbm *databaseManager) WriteIstanbulMisbehaviour(sequence uint64, hash common.Hash, blob []byte) {
+	db := dbm.getDatabase(MiscDB)
+	if err := db.Put(istanbulMisbehaviourKey(sequence, hash), blob); err != nil {
+		logger.Crit("Failed to write istanbul misbehaviour", "err", err)
+	}
+}
+
+// ReadIstanbulTrace retrieves the consensus events stored in the given slot.
+func (dbm *databaseManager) ReadIstanbulTrace(slot uint64) []byte {
+	db := dbm.getDatabase(MiscDB)
+	data, _ := db.Get(istanbulTraceKey(slot))
+	return data
+}
+
+// WriteIstanbulTrace stores the consensus events in the given slot.
+func (dbm *databaseManager) WriteIstanbulTrace(slot uint64, blob []byte) {
+	db := dbm.getDatabase(MiscDB)
+	if err := db.Put(istanbulTraceKey(slot), blob); err != nil {
+		logger.Crit("Failed to write istanbul trace", "err", err)
+	}
+}
+
 // Merkle Proof operation.
 func (dbm *databaseManager) WriteMerkleProof(key, value []byte) {
 	db := dbm.getDatabase(MiscDB)
@@ -1869,6 +2077,12 @@ func (dbm *databaseManager) ReadPreimage(hash common.Hash) []byte {
 	return dbm.ReadPreimageFromOld(hash)
 }
 
+// NewPreimageIterator returns an iterator over the preimages stored in the state trie database.
+// The keys of the iterator have the preimage prefix.
+func (dbm *databaseManager) NewPreimageIterator() Iterator {
+	return dbm.getDatabase(StateTrieDB).NewIterator(preimagePrefix, nil)
+}
+
 func (dbm *databaseManager) ReadTrieNodeFromNew(hash common.ExtHash) ([]byte, error) {
 	return dbm.GetStateTrieMigrationDB().Get(TrieNodeKey(hash))
 }
@@ -2526,6 +2740,55 @@ func (dbm *databaseManager) NewSnapshotDBIterator(prefix []byte, start []byte) I
 	return db.NewIterator(prefix, start)
 }
 
+// ReadStateHistory retrieves the state history of the block with the given number and hash.
+func (dbm *databaseManager) ReadStateHistory(number uint64, hash common.Hash) []byte {
+	db := dbm.getDatabase(SnapshotDB)
+	data, _ := db.Get(stateHistoryKey(number, hash))
+	return data
+}
+
+// WriteStateHistory stores the state history of the block with the given number and hash.
+func (dbm *databaseManager) WriteStateHistory(number uint64, hash common.Hash, history []byte) {
+	db := dbm.getDatabase(SnapshotDB)
+	if err := db.Put(stateHistoryKey(number, hash), history); err != nil {
+		logger.Crit("Failed to store state history", "number", number, "hash", hash, "err", err)
+	}
+}
+
+// DeleteStateHistories removes the state histories of all the blocks with the given number.
+func (dbm *databaseManager) DeleteStateHistories(number uint64) {
+	db := dbm.getDatabase(SnapshotDB)
+	it := db.NewIterator(stateHistoryKeyPrefix(number), nil)
+	defer it.Release()
+
+	for it.Next() {
+		if err := db.Delete(it.Key()); err != nil {
+			logger.Crit("Failed to delete state history", "number", number, "err", err)
+		}
+	}
+}
+
+// ReadStateHistoryTail retrieves the number of the oldest block whose state
+// history may be stored.
+func (dbm *databaseManager) ReadStateHistoryTail() *uint64 {
+	db := dbm.getDatabase(SnapshotDB)
+	data, _ := db.Get(stateHistoryTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteStateHistoryTail stores the number of the oldest block whose state
+// history may be stored.
+func (dbm *databaseManager) WriteStateHistoryTail(number uint64) {
+	db := dbm.getDatabase(SnapshotDB)
+	if err := db.Put(stateHistoryTailKey, common.Int64ToByteBigEndian(number)); err != nil {
+		logger.Crit("Failed to store state history tail", "err", err)
+	}
+}
+
 // WriteChildChainTxHash writes stores a transaction hash of a transaction which contains
 // AnchoringData, with the key made with given child chain block hash.
 func (dbm *databaseManager) WriteChildChainTxHash(ccBlockHash common.Hash, ccTxHash common.Hash) {
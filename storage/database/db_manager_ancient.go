@@ -0,0 +1,337 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/rcrowley/go-metrics"
+)
+
+const (
+	// DefaultAncientThreshold is the default number of recent blocks which are
+	// kept in the key-value stores when the ancient store is enabled.
+	DefaultAncientThreshold = 172800 // 2 days with 1 second block interval
+
+	// ancientDirName is the directory of the ancient store under the chaindata directory.
+	ancientDirName = "ancient"
+
+	// freezerRecheckInterval is the frequency to check the key-value stores for
+	// blocks to be moved to the ancient store.
+	freezerRecheckInterval = time.Minute
+
+	// freezerBatchLimit is the maximum number of blocks to freeze in one batch
+	// before doing an fsync and deleting them from the key-value stores.
+	freezerBatchLimit = 30000
+)
+
+var ancientItemsGauge = metrics.NewRegisteredGauge("klay/db/ancient/items", nil)
+
+// openAncientStore opens the ancient store of the database manager. If the
+// ancient store is enabled, it also starts the background migrator moving old
+// blocks from the key-value stores. If not enabled, the blocks already moved
+// are still readable but no more blocks are moved.
+func (dbm *databaseManager) openAncientStore() error {
+	if dbm.config.DBType == MemoryDB {
+		return nil
+	}
+	dir := filepath.Join(dbm.config.Dir, ancientDirName)
+	if !dbm.config.EnableAncient {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			return nil
+		}
+	}
+	f, err := newFreezer(dir)
+	if err != nil {
+		return err
+	}
+	dbm.freezer = f
+	dbm.quitFreezer = make(chan struct{})
+	ancientItemsGauge.Update(int64(f.Ancients()))
+
+	if !dbm.config.EnableAncient {
+		logger.Warn("Ancient store exists but is disabled, no more blocks will be moved", "dir", dir, "frozen", f.Ancients())
+		return nil
+	}
+	if dbm.config.AncientThreshold == 0 {
+		dbm.config.AncientThreshold = DefaultAncientThreshold
+	}
+	logger.Info("Ancient store is used for old blocks", "dir", dir, "frozen", f.Ancients(),
+		"threshold", dbm.config.AncientThreshold)
+
+	dbm.wgFreezer.Add(1)
+	go dbm.freezeLoop()
+	return nil
+}
+
+// freezeLoop periodically moves the canonical blocks older than
+// AncientThreshold from the key-value stores to the ancient store.
+func (dbm *databaseManager) freezeLoop() {
+	defer dbm.wgFreezer.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-dbm.quitFreezer:
+			return
+		case <-timer.C:
+		}
+
+		frozen, err := dbm.freeze()
+		if err != nil {
+			logger.Error("Failed to move blocks to the ancient store", "err", err)
+		}
+		// Continue immediately if the batch was full, there may be more blocks to freeze.
+		if err == nil && frozen == freezerBatchLimit {
+			timer.Reset(0)
+		} else {
+			timer.Reset(freezerRecheckInterval)
+		}
+	}
+}
+
+// freeze moves at most freezerBatchLimit blocks to the ancient store and
+// returns the number of blocks moved.
+func (dbm *databaseManager) freeze() (uint64, error) {
+	dbm.lockFreezer.Lock()
+	defer dbm.lockFreezer.Unlock()
+
+	headHash := dbm.ReadHeadBlockHash()
+	if headHash == (common.Hash{}) {
+		return 0, nil
+	}
+	headNumber := dbm.ReadHeaderNumber(headHash)
+	if headNumber == nil || *headNumber < dbm.config.AncientThreshold {
+		return 0, nil
+	}
+
+	// Keep the most recent AncientThreshold blocks in the key-value stores.
+	var (
+		first = dbm.freezer.Ancients()
+		limit = *headNumber + 1 - dbm.config.AncientThreshold // exclusive
+	)
+	if limit <= first {
+		return 0, nil
+	}
+	if limit-first > freezerBatchLimit {
+		limit = first + freezerBatchLimit
+	}
+
+	start := time.Now()
+	hashes := make([]common.Hash, 0, limit-first)
+	for number := first; number < limit; number++ {
+		hash, err := dbm.freezeBlock(number)
+		if err != nil {
+			// Blocks frozen so far are still valid. Clean them up from the key-value stores below.
+			logger.Warn("Stopped moving blocks to the ancient store", "number", number, "err", err)
+			break
+		}
+		hashes = append(hashes, hash)
+	}
+	if len(hashes) == 0 {
+		return 0, nil
+	}
+	if err := dbm.freezer.Sync(); err != nil {
+		return 0, err
+	}
+	ancientItemsGauge.Update(int64(dbm.freezer.Ancients()))
+
+	// Data is safely stored in the ancient store. Wipe it out from the key-value stores.
+	if err := dbm.deleteFrozenBlocks(first, hashes); err != nil {
+		return uint64(len(hashes)), err
+	}
+
+	logger.Info("Moved blocks to the ancient store", "from", first, "to", first+uint64(len(hashes))-1,
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return uint64(len(hashes)), nil
+}
+
+// freezeBlock appends the canonical block of the given number to the ancient store.
+func (dbm *databaseManager) freezeBlock(number uint64) (common.Hash, error) {
+	hash := dbm.ReadCanonicalHash(number)
+	if hash == (common.Hash{}) {
+		return common.Hash{}, fmt.Errorf("canonical hash missing")
+	}
+	header, _ := dbm.getDatabase(headerDB).Get(headerKey(number, hash))
+	if len(header) == 0 {
+		return common.Hash{}, fmt.Errorf("block header missing")
+	}
+	body, _ := dbm.getDatabase(BodyDB).Get(blockBodyKey(number, hash))
+	if len(body) == 0 {
+		return common.Hash{}, fmt.Errorf("block body missing")
+	}
+	receipts, _ := dbm.getDatabase(ReceiptsDB).Get(blockReceiptsKey(number, hash))
+	if len(receipts) == 0 {
+		return common.Hash{}, fmt.Errorf("block receipts missing")
+	}
+	td, _ := dbm.getDatabase(MiscDB).Get(headerTDKey(number, hash))
+	if len(td) == 0 {
+		return common.Hash{}, fmt.Errorf("total blockscore missing")
+	}
+	if err := dbm.freezer.AppendAncient(number, hash.Bytes(), header, body, receipts, td); err != nil {
+		return common.Hash{}, err
+	}
+	return hash, nil
+}
+
+// deleteFrozenBlocks removes the frozen canonical blocks and the side chain
+// blocks of the same numbers from the key-value stores. The genesis block is
+// kept in the key-value stores.
+func (dbm *databaseManager) deleteFrozenBlocks(first uint64, hashes []common.Hash) error {
+	var (
+		headerBatch   = dbm.NewBatch(headerDB)
+		bodyBatch     = dbm.NewBatch(BodyDB)
+		receiptsBatch = dbm.NewBatch(ReceiptsDB)
+		miscBatch     = dbm.NewBatch(MiscDB)
+	)
+	defer func() {
+		headerBatch.Release()
+		bodyBatch.Release()
+		receiptsBatch.Release()
+		miscBatch.Release()
+	}()
+
+	for i, hash := range hashes {
+		number := first + uint64(i)
+		if number == 0 {
+			continue
+		}
+		headerBatch.Delete(headerHashKey(number))
+		headerBatch.Delete(headerKey(number, hash))
+		bodyBatch.Delete(blockBodyKey(number, hash))
+		receiptsBatch.Delete(blockReceiptsKey(number, hash))
+		miscBatch.Delete(headerTDKey(number, hash))
+
+		// Side chain blocks of the same number are not reachable anymore.
+		for _, sideHash := range dbm.readHeaderHashes(number) {
+			if sideHash == hash {
+				continue
+			}
+			headerBatch.Delete(headerKey(number, sideHash))
+			headerBatch.Delete(headerNumberKey(sideHash))
+			bodyBatch.Delete(blockBodyKey(number, sideHash))
+			receiptsBatch.Delete(blockReceiptsKey(number, sideHash))
+			miscBatch.Delete(headerTDKey(number, sideHash))
+		}
+
+		if _, err := WriteBatchesOverThreshold(headerBatch, bodyBatch, receiptsBatch, miscBatch); err != nil {
+			return err
+		}
+	}
+	_, err := WriteBatches(headerBatch, bodyBatch, receiptsBatch, miscBatch)
+	return err
+}
+
+// readHeaderHashes returns the hashes of all headers of the given number
+// stored in the key-value store.
+func (dbm *databaseManager) readHeaderHashes(number uint64) []common.Hash {
+	prefix := headerKey(number, common.Hash{})[:len(headerPrefix)+8]
+
+	it := dbm.getDatabase(headerDB).NewIterator(prefix, nil)
+	defer it.Release()
+
+	var hashes []common.Hash
+	for it.Next() {
+		if key := it.Key(); len(key) == len(prefix)+common.HashLength {
+			hashes = append(hashes, common.BytesToHash(key[len(prefix):]))
+		}
+	}
+	return hashes
+}
+
+// readAncient retrieves the item of the given kind from the ancient store
+// if the block of the given hash and number has been frozen.
+func (dbm *databaseManager) readAncient(kind string, hash common.Hash, number uint64) []byte {
+	if dbm.freezer == nil || !dbm.freezer.HasAncient(number) {
+		return nil
+	}
+	frozenHash, err := dbm.freezer.Ancient(freezerHashTable, number)
+	if err != nil || common.BytesToHash(frozenHash) != hash {
+		return nil
+	}
+	data, err := dbm.freezer.Ancient(kind, number)
+	if err != nil {
+		logger.Error("Failed to read the ancient store", "kind", kind, "number", number, "err", err)
+		return nil
+	}
+	return data
+}
+
+// hasAncient returns true if the block of the given hash and number has been frozen.
+func (dbm *databaseManager) hasAncient(hash common.Hash, number uint64) bool {
+	return dbm.readAncientCanonicalHash(number) == hash
+}
+
+// readAncientCanonicalHash retrieves the canonical hash of the given number
+// from the ancient store.
+func (dbm *databaseManager) readAncientCanonicalHash(number uint64) common.Hash {
+	if dbm.freezer == nil || !dbm.freezer.HasAncient(number) {
+		return common.Hash{}
+	}
+	data, err := dbm.freezer.Ancient(freezerHashTable, number)
+	if err != nil {
+		return common.Hash{}
+	}
+	return common.BytesToHash(data)
+}
+
+// Ancients returns the number of blocks moved to the ancient store.
+// It returns 0 if the ancient store is disabled.
+func (dbm *databaseManager) Ancients() uint64 {
+	if dbm.freezer == nil {
+		return 0
+	}
+	return dbm.freezer.Ancients()
+}
+
+// TruncateAncients discards the blocks in the ancient store after the given
+// number of blocks. It is used to rewind the chain below the frozen blocks.
+func (dbm *databaseManager) TruncateAncients(items uint64) error {
+	if dbm.freezer == nil {
+		return nil
+	}
+	dbm.lockFreezer.Lock()
+	defer dbm.lockFreezer.Unlock()
+
+	if items >= dbm.freezer.Ancients() {
+		return nil
+	}
+	logger.Warn("Truncating the ancient store", "from", dbm.freezer.Ancients(), "to", items)
+	if err := dbm.freezer.TruncateAncients(items); err != nil {
+		return err
+	}
+	ancientItemsGauge.Update(int64(items))
+	return dbm.freezer.Sync()
+}
+
+// closeAncientStore stops the background migrator and closes the ancient store.
+func (dbm *databaseManager) closeAncientStore() {
+	if dbm.freezer == nil {
+		return
+	}
+	close(dbm.quitFreezer)
+	dbm.wgFreezer.Wait()
+	if err := dbm.freezer.Close(); err != nil {
+		logger.Error("Failed to close the ancient store", "err", err)
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+// BackupManifestFile is the name of the manifest file in a backup directory.
+const BackupManifestFile = "BACKUP.json"
+
+var (
+	errCheckpointInMigration = errors.New("checkpoint is not allowed during state trie migration")
+	errAncientTruncated      = errors.New("ancient store truncated while saving the checkpoint")
+)
+
+// BackupManifest describes a backup of the chain data. The databases in the
+// backup must be opened with the same database configuration as the node the
+// backup was taken from.
+type BackupManifest struct {
+	DBType             DBType      `json:"dbType"`
+	SingleDB           bool        `json:"singleDB"`
+	NumStateTrieShards uint        `json:"numStateTrieShards"`
+	HeadNumber         uint64      `json:"headNumber"`
+	HeadHash           common.Hash `json:"headHash"`
+	Time               time.Time   `json:"time"`
+}
+
+// WriteBackupManifest writes the manifest into the backup directory.
+func WriteBackupManifest(dir string, manifest *BackupManifest) error {
+	enc, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, BackupManifestFile), enc, 0o644)
+}
+
+// ReadBackupManifest reads the manifest of the backup directory.
+func ReadBackupManifest(dir string) (*BackupManifest, error) {
+	enc, err := os.ReadFile(filepath.Join(dir, BackupManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	manifest := new(BackupManifest)
+	if err := json.Unmarshal(enc, manifest); err != nil {
+		return nil, fmt.Errorf("invalid backup manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// savedCheckpoint is the checkpoint already written to the disk when taken.
+type savedCheckpoint struct{}
+
+func (savedCheckpoint) Save() error { return nil }
+func (savedCheckpoint) Release()    {}
+
+// multiCheckpoint saves and releases a group of checkpoints in order.
+type multiCheckpoint []Checkpoint
+
+func (cps multiCheckpoint) Save() error {
+	for _, cp := range cps {
+		if err := cp.Save(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cps multiCheckpoint) Release() {
+	for _, cp := range cps {
+		cp.Release()
+	}
+}
+
+// checkpointDatabase takes the checkpoint of the database if supported.
+func checkpointDatabase(db Database, dir string) (Checkpoint, error) {
+	cpr, ok := db.(Checkpointer)
+	if !ok {
+		return nil, fmt.Errorf("%v does not support checkpoint", db.Type())
+	}
+	return cpr.Checkpoint(dir)
+}
+
+// Checkpoint takes the checkpoints of all databases and the ancient store in
+// the given directory, in the same layout as the chain data directory. The
+// databases must not be written until it returns for the checkpoints to be
+// consistent with each other, but the returned checkpoint can be saved while
+// they are written.
+func (dbm *databaseManager) Checkpoint(dir string) (Checkpoint, error) {
+	dbm.lockInMigration.RLock()
+	defer dbm.lockInMigration.RUnlock()
+
+	if dbm.inMigration {
+		return nil, errCheckpointInMigration
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		return nil, fmt.Errorf("backup directory already exists: %v", dir)
+	}
+
+	// Block the ancient store from moving blocks out of the key-value stores.
+	dbm.lockFreezer.Lock()
+	defer dbm.lockFreezer.Unlock()
+
+	var cps multiCheckpoint
+	add := func(db Database, dir string) error {
+		cp, err := checkpointDatabase(db, dir)
+		if err != nil {
+			return err
+		}
+		cps = append(cps, cp)
+		return nil
+	}
+
+	if dbm.config.SingleDB {
+		if err := add(dbm.dbs[0], dir); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+		for et, db := range dbm.dbs {
+			if db == nil {
+				continue
+			}
+			if err := add(db, filepath.Join(dir, dbm.getDBDir(DBEntryType(et)))); err != nil {
+				cps.Release()
+				return nil, fmt.Errorf("%v: %w", DBEntryType(et), err)
+			}
+		}
+	}
+	if dbm.freezer != nil {
+		cps = append(cps, dbm.freezer.checkpoint(filepath.Join(dir, ancientDirName)))
+	}
+	return cps, nil
+}
+
+// freezerCheckpoint copies the items of the freezer tables frozen at the time
+// the checkpoint is taken. The tables are append-only, so the items are kept
+// unless the freezer is truncated.
+type freezerCheckpoint struct {
+	f     *freezer
+	dir   string
+	items uint64
+	sizes map[string]uint64
+}
+
+func (f *freezer) checkpoint(dir string) *freezerCheckpoint {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	cp := &freezerCheckpoint{f: f, dir: dir, items: f.Ancients(), sizes: make(map[string]uint64, len(f.tables))}
+	for name, table := range f.tables {
+		table.lock.RLock()
+		cp.sizes[name] = table.size
+		table.lock.RUnlock()
+	}
+	return cp
+}
+
+func (cp *freezerCheckpoint) Save() error {
+	cp.f.lock.Lock()
+	defer cp.f.lock.Unlock()
+
+	if cp.f.Ancients() < cp.items {
+		return errAncientTruncated
+	}
+	if err := os.MkdirAll(cp.dir, 0o755); err != nil {
+		return err
+	}
+	for name, size := range cp.sizes {
+		if err := copyFilePrefix(filepath.Join(cp.f.dir, name+".idx"), filepath.Join(cp.dir, name+".idx"), cp.items*freezerIndexEntrySize); err != nil {
+			return err
+		}
+		if err := copyFilePrefix(filepath.Join(cp.f.dir, name+".dat"), filepath.Join(cp.dir, name+".dat"), size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cp *freezerCheckpoint) Release() {}
+
+// copyFilePrefix copies the first n bytes of the src file to a new dst file.
+func copyFilePrefix(src, dst string, n uint64) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.CopyN(out, in, int64(n)); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// RestoreBackup copies the backup in backupDir into the chain data directory,
+// which should be empty or not exist. The manifest of the backup is returned.
+func RestoreBackup(backupDir, chainDataDir string) (*BackupManifest, error) {
+	manifest, err := ReadBackupManifest(backupDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the backup manifest: %w", err)
+	}
+	if entries, err := os.ReadDir(chainDataDir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("chain data directory is not empty: %v", chainDataDir)
+	} else if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	err = filepath.WalkDir(backupDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(backupDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == BackupManifestFile {
+			return nil
+		}
+		target := filepath.Join(chainDataDir, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		// The files are copied rather than linked, as some of them, like the
+		// manifest of LevelDB, are appended in place once the database is opened.
+		return copyFilePrefix(path, target, uint64(info.Size()))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
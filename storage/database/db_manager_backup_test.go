@@ -0,0 +1,141 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests that the backup contains the data written before the checkpoint is
+// taken, but not the data written after it, and that it can be restored.
+func TestDBManager_BackupRestore(t *testing.T) {
+	configs := []*DBConfig{
+		{DBType: LevelDB, SingleDB: false, NumStateTrieShards: 4, EnableAncient: true},
+		{DBType: LevelDB, SingleDB: true, NumStateTrieShards: 1, EnableAncient: true},
+		{DBType: PebbleDB, SingleDB: false, NumStateTrieShards: 1},
+		{DBType: PebbleDB, SingleDB: true, NumStateTrieShards: 4},
+		{DBType: BadgerDB, SingleDB: false, NumStateTrieShards: 2},
+	}
+	for _, config := range configs {
+		name := fmt.Sprintf("%v-single=%v-shards=%v", config.DBType, config.SingleDB, config.NumStateTrieShards)
+		t.Run(name, func(t *testing.T) {
+			testBackupRestore(t, *config)
+		})
+	}
+}
+
+func testBackupRestore(t *testing.T, config DBConfig) {
+	var (
+		backupDir  = filepath.Join(t.TempDir(), "backup")
+		restoreDir = filepath.Join(t.TempDir(), "chaindata")
+		nodeBefore = common.BytesToHash([]byte("before")).ExtendZero()
+		nodeAfter  = common.BytesToHash([]byte("after")).ExtendZero()
+	)
+	config.Dir = t.TempDir()
+	dbm := NewDBManager(&config).(*databaseManager)
+
+	dbm.WriteCanonicalHash(hash1, num1)
+	dbm.WriteTrieNode(nodeBefore, []byte("before"))
+	if dbm.freezer != nil {
+		appendTestAncients(t, dbm.freezer, 0, 10)
+	}
+
+	cp, err := dbm.Checkpoint(backupDir)
+	require.NoError(t, err)
+
+	// The data written after the checkpoint is taken is not in the backup.
+	dbm.WriteCanonicalHash(hash2, num2)
+	dbm.WriteTrieNode(nodeAfter, []byte("after"))
+	if dbm.freezer != nil {
+		appendTestAncients(t, dbm.freezer, 10, 15)
+	}
+
+	require.NoError(t, cp.Save())
+	cp.Release()
+	dbm.Close()
+
+	manifest := &BackupManifest{DBType: config.DBType, SingleDB: config.SingleDB, NumStateTrieShards: config.NumStateTrieShards, HeadNumber: num1, HeadHash: hash1}
+	require.NoError(t, WriteBackupManifest(backupDir, manifest))
+
+	restored, err := RestoreBackup(backupDir, restoreDir)
+	require.NoError(t, err)
+	assert.Equal(t, manifest.HeadHash, restored.HeadHash)
+
+	// The restored directory is not a backup anymore.
+	_, err = os.Stat(filepath.Join(restoreDir, BackupManifestFile))
+	assert.True(t, os.IsNotExist(err))
+
+	config.Dir = restoreDir
+	dbm = NewDBManager(&config).(*databaseManager)
+	defer dbm.Close()
+
+	assert.Equal(t, hash1, dbm.ReadCanonicalHash(num1))
+	assert.Equal(t, common.Hash{}, dbm.ReadCanonicalHash(num2))
+
+	node, err := dbm.ReadTrieNode(nodeBefore)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("before"), node)
+	_, err = dbm.ReadTrieNode(nodeAfter)
+	assert.Error(t, err)
+
+	if dbm.freezer != nil {
+		assert.Equal(t, uint64(10), dbm.Ancients())
+		item, err := dbm.freezer.Ancient(freezerBodiesTable, 9)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("item-9"), item)
+	}
+}
+
+func TestDBManager_BackupRestoreErrors(t *testing.T) {
+	// The memory database cannot be backed up.
+	_, err := NewMemoryDBManager().Checkpoint(filepath.Join(t.TempDir(), "backup"))
+	assert.Error(t, err)
+
+	dbm := NewDBManager(&DBConfig{Dir: t.TempDir(), DBType: LevelDB, NumStateTrieShards: 1})
+	defer dbm.Close()
+
+	// The backup directory should not exist.
+	_, err = dbm.Checkpoint(t.TempDir())
+	assert.Error(t, err)
+
+	backupDir := filepath.Join(t.TempDir(), "backup")
+	cp, err := dbm.Checkpoint(backupDir)
+	require.NoError(t, err)
+	require.NoError(t, cp.Save())
+	cp.Release()
+
+	// A backup should have the manifest.
+	_, err = RestoreBackup(backupDir, t.TempDir())
+	assert.Error(t, err)
+
+	// The chain data directory should be empty.
+	require.NoError(t, WriteBackupManifest(backupDir, &BackupManifest{DBType: LevelDB}))
+	notEmpty := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(notEmpty, "file"), nil, 0o644))
+	_, err = RestoreBackup(backupDir, notEmpty)
+	assert.Error(t, err)
+
+	_, err = RestoreBackup(backupDir, t.TempDir())
+	assert.NoError(t, err)
+}
@@ -0,0 +1,265 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+const (
+	// singleDBName is the name of the database reported when the single DB layout is used.
+	singleDBName = "single"
+
+	// ancientDBName is the name of the ancient store reported by InspectDatabase.
+	ancientDBName = "ancient"
+
+	// inspectLogInterval is the interval of the progress logs of InspectDatabase.
+	inspectLogInterval = 8 * time.Second
+)
+
+// InspectStat is the number and the total size of the entries of a category.
+type InspectStat struct {
+	Count uint64             `json:"count"`
+	Size  common.StorageSize `json:"size"`
+}
+
+func (s *InspectStat) add(size int) {
+	s.Count++
+	s.Size += common.StorageSize(size)
+}
+
+// InspectEntry is the stat of a category of keys stored in a database.
+type InspectEntry struct {
+	Database string `json:"database"`
+	Category string `json:"category"`
+	InspectStat
+}
+
+// InspectResult is the storage usage of the databases managed by a DBManager.
+type InspectResult struct {
+	// Entries holds the stats per database and category, in the order of
+	// DBEntryType and then the order of the categories in inspectCategories.
+	Entries []InspectEntry `json:"entries"`
+
+	// Databases holds the total stats of each database.
+	Databases map[string]InspectStat `json:"databases"`
+
+	// Total is the sum of all the stats.
+	Total InspectStat `json:"total"`
+}
+
+// inspectCategory classifies the keys of the schema by their prefixes and lengths.
+type inspectCategory struct {
+	name  string
+	match func(key []byte) bool
+}
+
+// hasPrefixLen returns a matcher of the keys of the given prefix and length.
+// If length is 0, the length of the key is not checked.
+func hasPrefixLen(prefix []byte, length int) func([]byte) bool {
+	return func(key []byte) bool {
+		return bytes.HasPrefix(key, prefix) && (length == 0 || len(key) == length)
+	}
+}
+
+// metadataKeys are the single keys storing metadata of the databases.
+var metadataKeys = [][]byte{
+	databaseVerisionKey, headHeaderKey, headBlockKey, headBlockBackupKey, headFastBlockKey, headFastBlockBackupKey,
+	fastTrieProgressKey, validSectionKey, snapshotJournalKey, SnapshotGeneratorKey, snapshotDisabledKey,
+	snapshotRecoveryKey, snapshotSyncStatusKey, snapshotRootKey, stateHistoryTailKey, badBlockKey, pruningEnabledKey,
+	lastPrunedBlockNumberKey, lastServiceChainTxReceiptKey, lastIndexedBlockKey, governanceHistoryKey,
+	governanceStateKey, migrationStatusKey, chaindatafetcherCheckpointKey,
+}
+
+// inspectCategories is the list of the categories of keys. A key falls into
+// the first matching category. Keys matching no category are reported as unaccounted.
+var inspectCategories = []inspectCategory{
+	{"Headers", hasPrefixLen(headerPrefix, len(headerPrefix)+8+common.HashLength)},
+	{"Total blockscores", func(key []byte) bool {
+		return hasPrefixLen(headerPrefix, len(headerPrefix)+8+common.HashLength+len(headerTDSuffix))(key) &&
+			bytes.HasSuffix(key, headerTDSuffix)
+	}},
+	{"Canonical hashes", func(key []byte) bool {
+		return hasPrefixLen(headerPrefix, len(headerPrefix)+8+len(headerHashSuffix))(key) &&
+			bytes.HasSuffix(key, headerHashSuffix)
+	}},
+	{"Header numbers", hasPrefixLen(headerNumberPrefix, len(headerNumberPrefix)+common.HashLength)},
+	{"Bodies", hasPrefixLen(blockBodyPrefix, len(blockBodyPrefix)+8+common.HashLength)},
+	{"Receipts", hasPrefixLen(blockReceiptsPrefix, len(blockReceiptsPrefix)+8+common.HashLength)},
+	{"Tx lookups", hasPrefixLen(txLookupPrefix, len(txLookupPrefix)+common.HashLength)},
+	{"Sender tx hashes", hasPrefixLen(senderTxHashToTxHashPrefix, 0)},
+	{"Bloombits", hasPrefixLen(bloomBitsPrefix, len(bloomBitsPrefix)+2+8+common.HashLength)},
+	{"Bloombits indexes", hasPrefixLen(BloomBitsIndexPrefix, 0)},
+	{"Log index", hasPrefixLen(logIndexPrefix, 0)},
+	{"Section heads", hasPrefixLen(sectionHeadKeyPrefix, 0)},
+	{"Snapshot accounts", hasPrefixLen(SnapshotAccountPrefix, len(SnapshotAccountPrefix)+common.HashLength)},
+	{"Snapshot storages", hasPrefixLen(SnapshotStoragePrefix, len(SnapshotStoragePrefix)+2*common.HashLength)},
+	{"Snapshot metadata", hasPrefixLen(snapshotKeyPrefix, 0)},
+	{"State histories", hasPrefixLen(stateHistoryPrefix, len(stateHistoryPrefix)+8+common.HashLength)},
+	{"Trie nodes", func(key []byte) bool {
+		return len(key) == common.HashLength || len(key) == common.ExtHashLength
+	}},
+	{"Contract codes", hasPrefixLen(codePrefix, len(codePrefix)+common.HashLength)},
+	{"Trie preimages", hasPrefixLen(preimagePrefix, len(preimagePrefix)+common.HashLength)},
+	{"Pruning marks", hasPrefixLen(pruningMarkPrefix, pruningMarkKeyLen)},
+	{"Chain configs", hasPrefixLen(configPrefix, 0)},
+	{"Governance", hasPrefixLen(governancePrefix, 0)},
+	{"Staking info", hasPrefixLen(stakingInfoPrefix, 0)},
+	{"Database directories", hasPrefixLen(databaseDirPrefix, 0)},
+	{"Service chain", func(key []byte) bool {
+		return bytes.HasPrefix(key, childChainTxHashPrefix) ||
+			bytes.HasPrefix(key, receiptFromParentChainKeyPrefix) ||
+			bytes.HasPrefix(key, parentOperatorFeePayerPrefix) ||
+			bytes.HasPrefix(key, childOperatorFeePayerPrefix) ||
+			bytes.HasPrefix(key, valueTransferTxHashPrefix)
+	}},
+	{"Metadata", func(key []byte) bool {
+		for _, meta := range metadataKeys {
+			if bytes.Equal(key, meta) {
+				return true
+			}
+		}
+		return false
+	}},
+}
+
+// unaccountedCategory is the category of the keys matching no category.
+const unaccountedCategory = "Unaccounted"
+
+// classifyKey returns the index of the category of the given key in
+// inspectCategories, or -1 if the key matches no category.
+func classifyKey(key []byte) int {
+	for i, category := range inspectCategories {
+		if category.match(key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// InspectDatabase iterates all the databases managed by the given DBManager
+// and reports the number and the size of the entries per database and per
+// category of keys. The size of an entry is the sum of the key and the value.
+// If the single DB layout is used, all entries are reported as a database named "single".
+func InspectDatabase(dbm DBManager) (*InspectResult, error) {
+	result := &InspectResult{Entries: []InspectEntry{}, Databases: make(map[string]InspectStat)}
+
+	visited := make(map[Database]bool)
+	for et := MiscDB; et < databaseEntryTypeSize; et++ {
+		db := dbm.getDatabase(et)
+		if db == nil || visited[db] {
+			continue
+		}
+		visited[db] = true
+
+		name := et.String()
+		if dbm.IsSingle() || dbm.GetDBConfig().DBType == MemoryDB {
+			name = singleDBName
+		}
+		if err := inspectDB(db, name, result); err != nil {
+			return nil, err
+		}
+	}
+
+	if dbManager, ok := dbm.(*databaseManager); ok && dbManager.freezer != nil {
+		if err := inspectAncient(dbManager.freezer, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// inspectDB iterates the given database and adds the stats to the result.
+func inspectDB(db Database, name string, result *InspectResult) error {
+	var (
+		stats       = make([]InspectStat, len(inspectCategories))
+		unaccounted InspectStat
+		total       InspectStat
+
+		start  = time.Now()
+		logged = time.Now()
+	)
+
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+
+	for it.Next() {
+		key, size := it.Key(), len(it.Key())+len(it.Value())
+		if idx := classifyKey(key); idx >= 0 {
+			stats[idx].add(size)
+		} else {
+			unaccounted.add(size)
+		}
+		total.add(size)
+
+		if time.Since(logged) > inspectLogInterval {
+			logger.Info("Inspecting database", "database", name, "count", total.Count, "size", total.Size,
+				"elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	for i, stat := range stats {
+		if stat.Count > 0 {
+			result.addEntry(name, inspectCategories[i].name, stat)
+		}
+	}
+	if unaccounted.Count > 0 {
+		result.addEntry(name, unaccountedCategory, unaccounted)
+	}
+	logger.Info("Inspected database", "database", name, "count", total.Count, "size", total.Size,
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// inspectAncient adds the stats of the tables of the ancient store to the result.
+// The size of a table is the size of its index and data files.
+func inspectAncient(f *freezer, result *InspectResult) error {
+	frozen := f.Ancients()
+	for _, name := range freezerTables {
+		var size int64
+		for _, ext := range []string{".idx", ".dat"} {
+			stat, err := os.Stat(filepath.Join(f.dir, name+ext))
+			if err != nil {
+				return err
+			}
+			size += stat.Size()
+		}
+		result.addEntry(ancientDBName, "Ancient "+name, InspectStat{Count: frozen, Size: common.StorageSize(size)})
+	}
+	return nil
+}
+
+func (r *InspectResult) addEntry(database, category string, stat InspectStat) {
+	r.Entries = append(r.Entries, InspectEntry{Database: database, Category: category, InspectStat: stat})
+
+	dbStat := r.Databases[database]
+	dbStat.Count += stat.Count
+	dbStat.Size += stat.Size
+	r.Databases[database] = dbStat
+
+	r.Total.Count += stat.Count
+	r.Total.Size += stat.Size
+}
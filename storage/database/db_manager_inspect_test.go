@@ -0,0 +1,116 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findInspectEntry(result *InspectResult, database, category string) *InspectEntry {
+	for i, entry := range result.Entries {
+		if entry.Database == database && entry.Category == category {
+			return &result.Entries[i]
+		}
+	}
+	return nil
+}
+
+func TestInspectDatabase(t *testing.T) {
+	testcases := []struct {
+		name   string
+		config *DBConfig
+	}{
+		{"single", &DBConfig{DBType: LevelDB, SingleDB: true, NumStateTrieShards: 1}},
+		{"multi", &DBConfig{DBType: LevelDB, SingleDB: false, NumStateTrieShards: 1}},
+		{"sharded", &DBConfig{DBType: LevelDB, SingleDB: false, NumStateTrieShards: 4}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.config.Dir = t.TempDir()
+			dbm := NewDBManager(tc.config)
+			defer dbm.Close()
+
+			const numBlocks = 3
+			for i := 0; i < numBlocks; i++ {
+				header := &types.Header{Number: big.NewInt(int64(i)), Time: big.NewInt(int64(i))}
+				block := types.NewBlockWithHeader(header)
+				dbm.WriteBlock(block)
+				dbm.WriteCanonicalHash(block.Hash(), block.NumberU64())
+				dbm.WriteReceipts(block.Hash(), block.NumberU64(), types.Receipts{genReceipt(i)})
+			}
+			dbm.WriteHeadBlockHash(common.Hash{1})
+			dbm.WriteCode(common.Hash{2}, []byte{0x60, 0x00})
+			dbm.WriteAccountSnapshot(common.Hash{3}, []byte{0x01})
+
+			// An unknown key is reported as unaccounted.
+			require.NoError(t, dbm.GetMiscDB().Put([]byte("unknown-key"), []byte("value")))
+
+			result, err := InspectDatabase(dbm)
+			require.NoError(t, err)
+
+			dbName := func(et DBEntryType) string {
+				if tc.config.SingleDB {
+					return singleDBName
+				}
+				return et.String()
+			}
+			for _, expected := range []struct {
+				et       DBEntryType
+				category string
+				count    uint64
+			}{
+				{headerDB, "Headers", numBlocks},
+				{headerDB, "Canonical hashes", numBlocks},
+				{headerDB, "Header numbers", numBlocks},
+				{BodyDB, "Bodies", numBlocks},
+				{ReceiptsDB, "Receipts", numBlocks},
+				{StateTrieDB, "Contract codes", 1},
+				{SnapshotDB, "Snapshot accounts", 1},
+				{MiscDB, unaccountedCategory, 1},
+			} {
+				entry := findInspectEntry(result, dbName(expected.et), expected.category)
+				if assert.NotNil(t, entry, expected.category) {
+					assert.Equal(t, expected.count, entry.Count, expected.category)
+					assert.NotZero(t, entry.Size, expected.category)
+				}
+			}
+			hasMetadata := false
+			for _, entry := range result.Entries {
+				hasMetadata = hasMetadata || entry.Category == "Metadata"
+			}
+			assert.True(t, hasMetadata)
+
+			// The totals are the sum of the entries.
+			var total InspectStat
+			for _, entry := range result.Entries {
+				total.Count += entry.Count
+				total.Size += entry.Size
+			}
+			assert.Equal(t, total, result.Total)
+			if tc.config.SingleDB {
+				assert.Len(t, result.Databases, 1)
+			}
+		})
+	}
+}
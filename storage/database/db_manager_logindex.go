@@ -0,0 +1,352 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+)
+
+const (
+	// logIndexAddressKind is the kind of the log index entries keyed by the
+	// address of the logs. The entries keyed by a topic use the position of
+	// the topic as their kind.
+	logIndexAddressKind = byte(0xff)
+
+	// logIndexBackfillLogInterval is the interval of the progress logs of the
+	// log index backfill.
+	logIndexBackfillLogInterval = 8 * time.Second
+)
+
+var (
+	errLogIndexDisabled  = errors.New("log index is disabled")
+	errLogIndexInterrupt = errors.New("log index backfill is interrupted")
+)
+
+// openLogIndex loads the range of the indexed blocks. The index only covers
+// consecutive blocks up to the head block, so it is restarted from the head
+// block if blocks were written while the log index was disabled.
+func (dbm *databaseManager) openLogIndex() {
+	if !dbm.config.EnableLogIndex {
+		return
+	}
+	head := uint64(0)
+	if hash := dbm.ReadHeadBlockHash(); hash != (common.Hash{}) {
+		if number := dbm.ReadHeaderNumber(hash); number != nil {
+			head = *number + 1
+		}
+	}
+
+	db := dbm.getDatabase(ReceiptsDB)
+	tailData, _ := db.Get(logIndexTailKey)
+	nextData, _ := db.Get(logIndexNextKey)
+
+	tail, next := head, head
+	if len(tailData) == 8 && len(nextData) == 8 {
+		tail, next = binary.BigEndian.Uint64(tailData), binary.BigEndian.Uint64(nextData)
+	}
+	if next < head || tail > next {
+		if next != tail {
+			logger.Warn("Log index is behind the head block, restarting it", "tail", tail, "next", next, "head", head)
+		}
+		tail, next = head, head
+	}
+	dbm.writeLogIndexRange(db, tail, next)
+	dbm.logIndexTail, dbm.logIndexNext = tail, next
+	logger.Info("Log index is used for the log filters", "tail", tail, "next", next)
+}
+
+func (dbm *databaseManager) writeLogIndexRange(putter KeyValueWriter, tail, next uint64) {
+	if err := putter.Put(logIndexTailKey, common.Int64ToByteBigEndian(tail)); err != nil {
+		logger.Crit("Failed to store the log index tail", "err", err)
+	}
+	if err := putter.Put(logIndexNextKey, common.Int64ToByteBigEndian(next)); err != nil {
+		logger.Crit("Failed to store the log index next", "err", err)
+	}
+}
+
+// logIndexKeys returns the deduplicated log index keys of the given receipts.
+func logIndexKeys(number uint64, receipts types.Receipts) [][]byte {
+	var (
+		keys [][]byte
+		seen = make(map[string]struct{})
+	)
+	add := func(key []byte) {
+		if _, ok := seen[string(key)]; !ok {
+			seen[string(key)] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	for _, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			add(logIndexKey(logIndexAddressKind, log.Address.Bytes(), number))
+			for i, topic := range log.Topics {
+				if i >= int(logIndexAddressKind) {
+					break
+				}
+				add(logIndexKey(byte(i), topic.Bytes(), number))
+			}
+		}
+	}
+	return keys
+}
+
+// putLogIndex indexes the block number by the addresses and topics of the
+// logs in the given receipts. Only the canonical blocks are indexed, so the
+// receipts of the side chains are indexed once their block becomes canonical.
+// It returns false if the block is not indexed. The indexed range is left to
+// the caller to extend after the entries are written.
+func (dbm *databaseManager) putLogIndex(putter KeyValueWriter, hash common.Hash, number uint64, receipts types.Receipts) bool {
+	if dbm.ReadCanonicalHash(number) != hash {
+		return false
+	}
+	for _, key := range logIndexKeys(number, receipts) {
+		if err := putter.Put(key, logIndexValue); err != nil {
+			logger.Crit("Failed to store the log index", "err", err)
+		}
+	}
+	return true
+}
+
+// reindexLogs replaces the index entries of the previous canonical block at
+// the number with the ones of the new canonical block. The entries shared by
+// both blocks are kept.
+func (dbm *databaseManager) reindexLogs(prev, hash common.Hash, number uint64) {
+	if prev == hash {
+		return
+	}
+	db := dbm.getDatabase(ReceiptsDB)
+	// The receipts are not written yet if the block is not processed, and
+	// they are indexed when they are written in that case.
+	indexed, _ := db.Has(blockReceiptsKey(number, hash))
+	if !indexed && prev == (common.Hash{}) {
+		return
+	}
+	batch := db.NewBatch()
+	defer batch.Release()
+
+	keys := make(map[string]struct{})
+	if indexed {
+		for _, key := range logIndexKeys(number, dbm.ReadReceipts(hash, number)) {
+			keys[string(key)] = struct{}{}
+			if err := batch.Put(key, logIndexValue); err != nil {
+				logger.Crit("Failed to store the log index", "err", err)
+			}
+		}
+	}
+	if prev != (common.Hash{}) {
+		for _, key := range logIndexKeys(number, dbm.ReadReceipts(prev, number)) {
+			if _, ok := keys[string(key)]; ok {
+				continue
+			}
+			if err := batch.Delete(key); err != nil {
+				logger.Crit("Failed to delete the log index", "err", err)
+			}
+		}
+	}
+	if err := batch.Write(); err != nil {
+		logger.Crit("Failed to store the log index", "err", err)
+	}
+	if indexed {
+		dbm.ExtendLogIndex(number, number)
+	}
+}
+
+// ExtendLogIndex extends the indexed range over the blocks in [from, to] if
+// the range reaches them. It must be called after the index entries of the
+// blocks are written, e.g. after the batch given to PutReceiptsToBatch is
+// written, so that the range never covers the entries which are not stored.
+func (dbm *databaseManager) ExtendLogIndex(from, to uint64) {
+	if !dbm.config.EnableLogIndex {
+		return
+	}
+	dbm.lockLogIndex.Lock()
+	defer dbm.lockLogIndex.Unlock()
+
+	if from <= dbm.logIndexNext && dbm.logIndexNext <= to {
+		dbm.logIndexNext = to + 1
+		db := dbm.getDatabase(ReceiptsDB)
+		if err := db.Put(logIndexNextKey, common.Int64ToByteBigEndian(dbm.logIndexNext)); err != nil {
+			logger.Crit("Failed to store the log index next", "err", err)
+		}
+	}
+}
+
+// deleteLogIndex removes the index entries of the given receipts, and shrinks
+// the indexed range if the block is in the range. The entries are kept if
+// another block is canonical at the number, since they may be shared.
+func (dbm *databaseManager) deleteLogIndex(hash common.Hash, number uint64, receipts types.Receipts) {
+	if canonical := dbm.ReadCanonicalHash(number); canonical != (common.Hash{}) && canonical != hash {
+		return
+	}
+	batch := dbm.getDatabase(ReceiptsDB).NewBatch()
+	defer batch.Release()
+
+	for _, key := range logIndexKeys(number, receipts) {
+		if err := batch.Delete(key); err != nil {
+			logger.Crit("Failed to delete the log index", "err", err)
+		}
+	}
+
+	dbm.lockLogIndex.Lock()
+	defer dbm.lockLogIndex.Unlock()
+
+	if number < dbm.logIndexNext {
+		dbm.logIndexNext = number
+		if dbm.logIndexTail > number {
+			dbm.logIndexTail = number
+		}
+		dbm.writeLogIndexRange(batch, dbm.logIndexTail, dbm.logIndexNext)
+	}
+	if err := batch.Write(); err != nil {
+		logger.Crit("Failed to delete the log index", "err", err)
+	}
+}
+
+// ReadLogIndexRange returns the range [tail, next) of the blocks whose logs
+// are indexed. It returns false if the log index is disabled.
+func (dbm *databaseManager) ReadLogIndexRange() (uint64, uint64, bool) {
+	if !dbm.config.EnableLogIndex {
+		return 0, 0, false
+	}
+	dbm.lockLogIndex.RLock()
+	defer dbm.lockLogIndex.RUnlock()
+
+	return dbm.logIndexTail, dbm.logIndexNext, true
+}
+
+// ReadLogIndexByAddress returns the numbers of the blocks in [from, to] which
+// have logs of the given address, in ascending order.
+func (dbm *databaseManager) ReadLogIndexByAddress(address common.Address, from, to uint64) []uint64 {
+	return dbm.readLogIndex(logIndexAddressKind, address.Bytes(), from, to)
+}
+
+// ReadLogIndexByTopic returns the numbers of the blocks in [from, to] which
+// have logs with the given topic at the given position, in ascending order.
+func (dbm *databaseManager) ReadLogIndexByTopic(position int, topic common.Hash, from, to uint64) []uint64 {
+	if position < 0 || position >= int(logIndexAddressKind) {
+		return nil
+	}
+	return dbm.readLogIndex(byte(position), topic.Bytes(), from, to)
+}
+
+func (dbm *databaseManager) readLogIndex(kind byte, term []byte, from, to uint64) []uint64 {
+	prefix := logIndexTermKey(kind, term)
+	it := dbm.getDatabase(ReceiptsDB).NewIterator(prefix, common.Int64ToByteBigEndian(from))
+	defer it.Release()
+
+	var numbers []uint64
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8 {
+			continue
+		}
+		number := binary.BigEndian.Uint64(key[len(prefix):])
+		if number > to {
+			break
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers
+}
+
+// BackfillLogIndex indexes the logs of the canonical blocks older than the
+// indexed range, from the newest one down to the genesis block. It can be
+// interrupted by closing quit and continues from where it stopped next time.
+func (dbm *databaseManager) BackfillLogIndex(quit <-chan struct{}) error {
+	tail, next, ok := dbm.ReadLogIndexRange()
+	if !ok {
+		return errLogIndexDisabled
+	}
+	if tail == 0 {
+		logger.Info("Log index is already complete", "next", next)
+		return nil
+	}
+	var (
+		db      = dbm.getDatabase(ReceiptsDB)
+		batch   = db.NewBatch()
+		start   = time.Now()
+		logged  = time.Now()
+		from    = tail
+		indexed = 0
+	)
+	defer batch.Release()
+
+	flush := func() error {
+		dbm.lockLogIndex.Lock()
+		defer dbm.lockLogIndex.Unlock()
+
+		// The range is restarted if the chain was rewound below the backfill.
+		if dbm.logIndexTail != from {
+			return fmt.Errorf("log index range is changed during the backfill: tail %d, expected %d", dbm.logIndexTail, from)
+		}
+		if err := batch.Put(logIndexTailKey, common.Int64ToByteBigEndian(tail)); err != nil {
+			return err
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+		dbm.logIndexTail, from = tail, tail
+		return nil
+	}
+
+	logger.Info("Backfilling the log index", "tail", tail, "next", next)
+	for tail > 0 {
+		select {
+		case <-quit:
+			if err := flush(); err != nil {
+				return err
+			}
+			logger.Warn("Log index backfill is interrupted", "tail", tail)
+			return errLogIndexInterrupt
+		default:
+		}
+		number := tail - 1
+		hash := dbm.ReadCanonicalHash(number)
+		if hash == (common.Hash{}) {
+			return fmt.Errorf("canonical hash of block %d is missing", number)
+		}
+		for _, key := range logIndexKeys(number, dbm.ReadReceipts(hash, number)) {
+			if err := batch.Put(key, logIndexValue); err != nil {
+				return err
+			}
+		}
+		tail = number
+		indexed++
+
+		if batch.ValueSize() >= IdealBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if time.Since(logged) > logIndexBackfillLogInterval {
+			logger.Info("Backfilling the log index", "tail", tail, "indexed", indexed, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	logger.Info("Backfilled the log index", "indexed", indexed, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
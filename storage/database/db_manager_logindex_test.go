@@ -0,0 +1,175 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeLogIndexTestBlock writes a canonical head block whose receipt has a log
+// of the given address and topic.
+func writeLogIndexTestBlock(t *testing.T, dbm DBManager, number uint64, address common.Address, topic common.Hash) common.Hash {
+	header := &types.Header{Number: new(big.Int).SetUint64(number)}
+	hash := header.Hash()
+	body, receipts := genBodyWithReceipts(t, hash, number, int(number)+1)
+	receipts[0].Logs = []*types.Log{{Address: address, Topics: []common.Hash{topic}}}
+
+	dbm.WriteHeader(header)
+	dbm.WriteBody(hash, number, body)
+	dbm.WriteCanonicalHash(hash, number)
+	dbm.WriteHeadBlockHash(hash)
+	dbm.WriteReceipts(hash, number, receipts)
+	return hash
+}
+
+// Tests that the log index covers the blocks written while it is enabled, the
+// blocks backfilled, and shrinks when the blocks are deleted.
+func TestDBManager_LogIndex(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		addr1  = common.HexToAddress("0x1111")
+		addr2  = common.HexToAddress("0x2222")
+		topic1 = common.HexToHash("0xaaaa")
+		topic2 = common.HexToHash("0xbbbb")
+	)
+	// Blocks are written while the log index is disabled.
+	dbm := NewDBManager(&DBConfig{Dir: dir, DBType: LevelDB, NumStateTrieShards: 1})
+	for i := uint64(0); i < 4; i++ {
+		addr, topic := addr1, topic1
+		if i%2 == 1 {
+			addr, topic = addr2, topic2
+		}
+		writeLogIndexTestBlock(t, dbm, i, addr, topic)
+	}
+	_, _, ok := dbm.ReadLogIndexRange()
+	assert.False(t, ok)
+	assert.Equal(t, errLogIndexDisabled, dbm.BackfillLogIndex(nil))
+	dbm.Close()
+
+	// The log index starts from the next block of the head block.
+	dbm = NewDBManager(&DBConfig{Dir: dir, DBType: LevelDB, NumStateTrieShards: 1, EnableLogIndex: true})
+	defer dbm.Close()
+
+	tail, next, ok := dbm.ReadLogIndexRange()
+	require.True(t, ok)
+	assert.Equal(t, uint64(4), tail)
+	assert.Equal(t, uint64(4), next)
+
+	hash4 := writeLogIndexTestBlock(t, dbm, 4, addr1, topic2)
+	tail, next, _ = dbm.ReadLogIndexRange()
+	assert.Equal(t, uint64(4), tail)
+	assert.Equal(t, uint64(5), next)
+	assert.Equal(t, []uint64{4}, dbm.ReadLogIndexByAddress(addr1, 0, 10))
+	assert.Equal(t, []uint64{4}, dbm.ReadLogIndexByTopic(0, topic2, 0, 10))
+	assert.Empty(t, dbm.ReadLogIndexByTopic(1, topic2, 0, 10))
+
+	// The older blocks are indexed by the backfill.
+	require.NoError(t, dbm.BackfillLogIndex(nil))
+	tail, next, _ = dbm.ReadLogIndexRange()
+	assert.Equal(t, uint64(0), tail)
+	assert.Equal(t, uint64(5), next)
+	assert.Equal(t, []uint64{0, 2, 4}, dbm.ReadLogIndexByAddress(addr1, 0, 10))
+	assert.Equal(t, []uint64{2, 4}, dbm.ReadLogIndexByAddress(addr1, 1, 10))
+	assert.Equal(t, []uint64{0, 2}, dbm.ReadLogIndexByAddress(addr1, 0, 3))
+	assert.Equal(t, []uint64{1, 3, 4}, dbm.ReadLogIndexByTopic(0, topic2, 0, 10))
+
+	// The log index is pruned with the deleted blocks.
+	dbm.DeleteReceipts(hash4, 4)
+	tail, next, _ = dbm.ReadLogIndexRange()
+	assert.Equal(t, uint64(0), tail)
+	assert.Equal(t, uint64(4), next)
+	assert.Equal(t, []uint64{0, 2}, dbm.ReadLogIndexByAddress(addr1, 0, 10))
+	assert.Equal(t, []uint64{1, 3}, dbm.ReadLogIndexByTopic(0, topic2, 0, 10))
+}
+
+// Tests that the indexed range is extended only after the batch having the
+// index entries is written.
+func TestDBManager_LogIndexBatch(t *testing.T) {
+	var (
+		dbm  = NewDBManager(&DBConfig{DBType: MemoryDB, SingleDB: true, EnableLogIndex: true})
+		addr = common.HexToAddress("0x1111")
+	)
+	defer dbm.Close()
+
+	header := &types.Header{Number: big.NewInt(0)}
+	hash := header.Hash()
+	_, receipts := genBodyWithReceipts(t, hash, 0, 1)
+	receipts[0].Logs = []*types.Log{{Address: addr}}
+	dbm.WriteHeader(header)
+	dbm.WriteCanonicalHash(hash, 0)
+
+	batch := dbm.NewBatch(ReceiptsDB)
+	defer batch.Release()
+	dbm.PutReceiptsToBatch(batch, hash, 0, receipts)
+
+	_, next, _ := dbm.ReadLogIndexRange()
+	assert.Equal(t, uint64(0), next)
+	assert.Empty(t, dbm.ReadLogIndexByAddress(addr, 0, 10))
+
+	require.NoError(t, batch.Write())
+	dbm.ExtendLogIndex(0, 0)
+	_, next, _ = dbm.ReadLogIndexRange()
+	assert.Equal(t, uint64(1), next)
+	assert.Equal(t, []uint64{0}, dbm.ReadLogIndexByAddress(addr, 0, 10))
+
+	// The range is not extended over a gap.
+	dbm.ExtendLogIndex(2, 3)
+	_, next, _ = dbm.ReadLogIndexRange()
+	assert.Equal(t, uint64(1), next)
+}
+
+// Tests that the receipts of the side chains are indexed only when their block
+// becomes canonical, replacing the entries of the previous canonical block.
+func TestDBManager_LogIndexSideChain(t *testing.T) {
+	var (
+		dbm   = NewDBManager(&DBConfig{DBType: MemoryDB, SingleDB: true, EnableLogIndex: true})
+		addr1 = common.HexToAddress("0x1111")
+		addr2 = common.HexToAddress("0x2222")
+		topic = common.HexToHash("0xaaaa")
+	)
+	defer dbm.Close()
+
+	canonical := writeLogIndexTestBlock(t, dbm, 0, addr1, topic)
+
+	side := &types.Header{Number: big.NewInt(0), Extra: []byte("side")}
+	sideHash := side.Hash()
+	body, receipts := genBodyWithReceipts(t, sideHash, 0, 1)
+	receipts[0].Logs = []*types.Log{{Address: addr2, Topics: []common.Hash{topic}}}
+	dbm.WriteHeader(side)
+	dbm.WriteBody(sideHash, 0, body)
+	dbm.WriteReceipts(sideHash, 0, receipts)
+	assert.Empty(t, dbm.ReadLogIndexByAddress(addr2, 0, 10))
+
+	dbm.WriteCanonicalHash(sideHash, 0)
+	assert.Empty(t, dbm.ReadLogIndexByAddress(addr1, 0, 10))
+	assert.Equal(t, []uint64{0}, dbm.ReadLogIndexByAddress(addr2, 0, 10))
+	assert.Equal(t, []uint64{0}, dbm.ReadLogIndexByTopic(0, topic, 0, 10))
+
+	dbm.WriteCanonicalHash(canonical, 0)
+	assert.Equal(t, []uint64{0}, dbm.ReadLogIndexByAddress(addr1, 0, 10))
+	assert.Empty(t, dbm.ReadLogIndexByAddress(addr2, 0, 10))
+	assert.Equal(t, []uint64{0}, dbm.ReadLogIndexByTopic(0, topic, 0, 10))
+
+	_, next, _ := dbm.ReadLogIndexRange()
+	assert.Equal(t, uint64(1), next)
+}
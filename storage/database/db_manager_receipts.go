@@ -0,0 +1,164 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// receiptsMigrationBatchLimit is the maximum number of blocks whose receipts are
+// re-encoded in one batch of the receipts migration.
+const receiptsMigrationBatchLimit = 1000
+
+// blockReceiptsKeyLength is the length of the keys of the block receipts,
+// used to tell them from the other keys starting with blockReceiptsPrefix.
+var blockReceiptsKeyLength = len(blockReceiptsKey(0, common.Hash{}))
+
+// ReadReceiptsMigration retrieves the key of the block receipts from which the
+// receipts migration continues. It returns nil if no migration is scheduled.
+func (dbm *databaseManager) ReadReceiptsMigration() []byte {
+	data, _ := dbm.getDatabase(MiscDB).Get(receiptsMigrationKey)
+	return data
+}
+
+func (dbm *databaseManager) writeReceiptsMigration(next []byte) {
+	if err := dbm.getDatabase(MiscDB).Put(receiptsMigrationKey, next); err != nil {
+		logger.Crit("Failed to store the receipts migration progress", "err", err)
+	}
+}
+
+func (dbm *databaseManager) deleteReceiptsMigration() {
+	if err := dbm.getDatabase(MiscDB).Delete(receiptsMigrationKey); err != nil {
+		logger.Crit("Failed to delete the receipts migration progress", "err", err)
+	}
+}
+
+// ScheduleReceiptsMigration schedules the migration re-encoding the receipts
+// stored in the legacy encoding with all the fields into the compact one. It
+// does nothing if the migration is already scheduled. The receipts moved to the
+// ancient store are not converted, as the ancient store is append-only, and they
+// are kept in the legacy encoding.
+func (dbm *databaseManager) ScheduleReceiptsMigration() {
+	if dbm.ReadReceiptsMigration() != nil {
+		return
+	}
+	dbm.writeReceiptsMigration(blockReceiptsPrefix)
+}
+
+// ErrReceiptsMigrationUnsupported is returned by MigrateReceipts if the database
+// type doesn't support iterating the receipts. The receipts stored before the
+// compact encoding are then kept in the legacy encoding.
+var ErrReceiptsMigrationUnsupported = errors.New("receipts migration is not supported on the database type")
+
+// MigrateReceipts re-encodes a batch of the block receipts stored in the legacy
+// encoding, starting from the given key, and stores the progress. It returns
+// the key to continue from, which is nil once the migration is completed, and
+// the numbers of the processed and re-encoded block receipts.
+//
+// The caller must keep the receipts from being deleted while a batch runs, e.g.
+// by holding the chain lock against reorgs and SetHead. Otherwise the receipts
+// deleted in the meantime are written back.
+func (dbm *databaseManager) MigrateReceipts(from []byte) ([]byte, int, int, error) {
+	switch dbm.config.DBType {
+	case LevelDB, RocksDB, PebbleDB, MemoryDB:
+	default:
+		return nil, 0, 0, ErrReceiptsMigrationUnsupported
+	}
+	keys, last := dbm.readReceiptsKeys(from, receiptsMigrationBatchLimit)
+	migrated, err := dbm.compactReceipts(keys)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if last {
+		dbm.deleteReceiptsMigration()
+		return nil, len(keys), migrated, nil
+	}
+	next := append(common.CopyBytes(keys[len(keys)-1]), 0)
+	dbm.writeReceiptsMigration(next)
+	return next, len(keys), migrated, nil
+}
+
+// readReceiptsKeys returns at most limit keys of the block receipts from the
+// given key, and whether there are no more keys after them.
+func (dbm *databaseManager) readReceiptsKeys(from []byte, limit int) ([][]byte, bool) {
+	it := dbm.getDatabase(ReceiptsDB).NewIterator(blockReceiptsPrefix, bytes.TrimPrefix(from, blockReceiptsPrefix))
+	defer it.Release()
+
+	var keys [][]byte
+	for it.Next() {
+		if len(it.Key()) != blockReceiptsKeyLength {
+			continue
+		}
+		keys = append(keys, common.CopyBytes(it.Key()))
+		if len(keys) == limit {
+			return keys, false
+		}
+	}
+	return keys, true
+}
+
+// compactReceipts re-encodes the block receipts of the given keys stored in the
+// legacy encoding into the compact one, and returns the number of them.
+func (dbm *databaseManager) compactReceipts(keys [][]byte) (int, error) {
+	// Block the ancient store from deleting the receipts being re-encoded.
+	dbm.lockFreezer.Lock()
+	defer dbm.lockFreezer.Unlock()
+
+	db := dbm.getDatabase(ReceiptsDB)
+	batch := db.NewBatch()
+	defer batch.Release()
+
+	migrated := 0
+	for _, key := range keys {
+		data, _ := db.Get(key)
+		if len(data) == 0 {
+			continue
+		}
+		receipts, compact, err := types.DecodeStoredReceipts(data)
+		if err != nil {
+			logger.Warn("Skipping invalid receipts", "key", key, "err", err)
+			continue
+		}
+		if compact {
+			continue
+		}
+		enc, err := encodeCompactReceipts(receipts)
+		if err != nil {
+			return migrated, err
+		}
+		if err := batch.Put(key, enc); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, batch.Write()
+}
+
+// encodeCompactReceipts encodes the receipts of a block in the compact storage
+// encoding, leaving out the fields derivable from the block.
+func encodeCompactReceipts(receipts types.Receipts) ([]byte, error) {
+	storageReceipts := make([]*types.ReceiptForCompactStorage, len(receipts))
+	for i, receipt := range receipts {
+		storageReceipts[i] = (*types.ReceiptForCompactStorage)(receipt)
+	}
+	return rlp.EncodeToBytes(storageReceipts)
+}
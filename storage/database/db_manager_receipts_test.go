@@ -0,0 +1,135 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// genBodyWithReceipts returns a block body with a transaction and its receipt
+// filled with the fields derived from the block.
+func genBodyWithReceipts(t *testing.T, hash common.Hash, number uint64, gasUsed int) (*types.Body, types.Receipts) {
+	tx, err := genTransaction(uint64(gasUsed))
+	require.NoError(t, err)
+	body := &types.Body{Transactions: types.Transactions{tx}}
+	receipts := types.Receipts{genReceipt(gasUsed)}
+	require.NoError(t, receipts.DeriveFields(hash, number, body.Transactions))
+	return body, receipts
+}
+
+// Tests that the receipts are stored in the compact encoding only if enabled,
+// and that the compact receipts are filled from the block body on read.
+func TestDBManager_CompactReceipts(t *testing.T) {
+	header := &types.Header{Number: big.NewInt(int64(num1))}
+	hash := header.Hash()
+	body, receipts := genBodyWithReceipts(t, hash, num1, 111)
+
+	for _, compact := range []bool{false, true} {
+		dbm := NewDBManager(&DBConfig{DBType: MemoryDB, CompactReceipts: compact}).(*databaseManager)
+		dbm.WriteReceipts(hash, num1, receipts)
+
+		enc, _ := dbm.getDatabase(ReceiptsDB).Get(blockReceiptsKey(num1, hash))
+		isCompact, err := types.IsCompactStoredReceipts(enc)
+		require.NoError(t, err)
+		assert.Equal(t, compact, isCompact)
+
+		if compact {
+			// The compact receipts cannot be read without the block body.
+			assert.Nil(t, dbm.ReadReceipts(hash, num1))
+			dbm.WriteBody(hash, num1, body)
+		}
+		assert.Equal(t, receipts, dbm.ReadReceipts(hash, num1))
+		dbm.Close()
+	}
+}
+
+// Tests that the receipts stored in the legacy encoding are readable, and are
+// re-encoded in the compact encoding by the receipts migration.
+func TestDBManager_ReceiptsMigration(t *testing.T) {
+	dbm := NewDBManager(&DBConfig{Dir: t.TempDir(), DBType: LevelDB, NumStateTrieShards: 1, CompactReceipts: true}).(*databaseManager)
+	defer dbm.Close()
+
+	const numBlocks = receiptsMigrationBatchLimit + 5
+	var (
+		hashes   []common.Hash
+		receipts []types.Receipts
+	)
+	for i := 0; i < numBlocks; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i))}
+		hash, number := header.Hash(), uint64(i)
+		body, blockReceipts := genBodyWithReceipts(t, hash, number, i+1)
+		dbm.WriteBody(hash, number, body)
+
+		// Store the receipts in the legacy encoding as done before the compact one.
+		storageReceipts := make([]*types.ReceiptForStorage, len(blockReceipts))
+		for j, receipt := range blockReceipts {
+			storageReceipts[j] = (*types.ReceiptForStorage)(receipt)
+		}
+		enc, err := rlp.EncodeToBytes(storageReceipts)
+		require.NoError(t, err)
+		require.NoError(t, dbm.getDatabase(ReceiptsDB).Put(blockReceiptsKey(number, hash), enc))
+
+		hashes, receipts = append(hashes, hash), append(receipts, blockReceipts)
+	}
+	// A key sharing the prefix of the block receipts is not migrated.
+	require.NoError(t, dbm.getDatabase(ReceiptsDB).Put([]byte("r-unknown"), []byte("value")))
+
+	// The receipts in the legacy encoding are readable before the migration.
+	for i, hash := range hashes {
+		assert.Equal(t, receipts[i], dbm.ReadReceipts(hash, uint64(i)))
+	}
+
+	dbm.ScheduleReceiptsMigration()
+	assert.Equal(t, blockReceiptsPrefix, dbm.ReadReceiptsMigration())
+
+	// The progress is stored after each batch.
+	next, processed, migrated, err := dbm.MigrateReceipts(dbm.ReadReceiptsMigration())
+	require.NoError(t, err)
+	assert.Equal(t, receiptsMigrationBatchLimit, processed)
+	assert.Equal(t, receiptsMigrationBatchLimit, migrated)
+	assert.Equal(t, next, dbm.ReadReceiptsMigration())
+
+	next, processed, migrated, err = dbm.MigrateReceipts(next)
+	require.NoError(t, err)
+	assert.Nil(t, next)
+	assert.Equal(t, numBlocks-receiptsMigrationBatchLimit, processed)
+	assert.Equal(t, numBlocks-receiptsMigrationBatchLimit, migrated)
+
+	assert.Nil(t, dbm.ReadReceiptsMigration())
+	for i, hash := range hashes {
+		enc, _ := dbm.getDatabase(ReceiptsDB).Get(blockReceiptsKey(uint64(i), hash))
+		compact, err := types.IsCompactStoredReceipts(enc)
+		require.NoError(t, err)
+		assert.True(t, compact, "block %d", i)
+		assert.Equal(t, receipts[i], dbm.ReadReceipts(hash, uint64(i)))
+	}
+	value, _ := dbm.getDatabase(ReceiptsDB).Get([]byte("r-unknown"))
+	assert.Equal(t, []byte("value"), value)
+
+	// The database types which can't iterate the receipts are not migrated.
+	dbm.config.DBType = DynamoDB
+	_, _, _, err = dbm.MigrateReceipts(blockReceiptsPrefix)
+	assert.ErrorIs(t, err, ErrReceiptsMigrationUnsupported)
+	dbm.config.DBType = LevelDB
+}
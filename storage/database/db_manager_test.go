@@ -20,6 +20,7 @@ import (
 	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
+	"math"
 	"math/big"
 	"math/rand"
 	"os"
@@ -89,10 +90,13 @@ func init() {
 		memoryConfig.DBType = MemoryDB
 		rockdbConfig := *bc
 		rockdbConfig.DBType = RocksDB
+		pebbleConfig := *bc
+		pebbleConfig.DBType = PebbleDB
 
 		dbConfigs = append(dbConfigs, bc)
 		dbConfigs = append(dbConfigs, &badgerConfig)
 		dbConfigs = append(dbConfigs, &memoryConfig)
+		dbConfigs = append(dbConfigs, &pebbleConfig)
 		if addRocksDB {
 			dbConfigs = append(dbConfigs, &rockdbConfig)
 		}
@@ -456,6 +460,80 @@ func TestDBManager_IstanbulSnapshot(t *testing.T) {
 	}
 }
 
+// TestDBManager_IstanbulWAL tests read and write operations of the istanbul write-ahead log.
+func TestDBManager_IstanbulWAL(t *testing.T) {
+	log.EnableLogForTest(log.LvlCrit, log.LvlTrace)
+	for _, dbm := range dbManagers {
+		dbm.WriteIstanbulWAL(hash2[:])
+		assert.Equal(t, hash2[:], dbm.ReadIstanbulWAL())
+
+		dbm.WriteIstanbulWAL(hash1[:])
+		assert.Equal(t, hash1[:], dbm.ReadIstanbulWAL())
+	}
+}
+
+// syncRecorder is a database recording the keys written by PutSync.
+type syncRecorder struct {
+	Database
+	synced [][]byte
+}
+
+func (db *syncRecorder) PutSync(key []byte, value []byte) error {
+	db.synced = append(db.synced, common.CopyBytes(key))
+	return db.Database.Put(key, value)
+}
+
+// TestDBManager_IstanbulWALSync tests that the istanbul write-ahead log is written
+// with a synced write if the database supports it.
+func TestDBManager_IstanbulWALSync(t *testing.T) {
+	dbm := NewMemoryDBManager().(*databaseManager)
+	assert.False(t, dbm.IstanbulWALSynced())
+
+	recorder := &syncRecorder{Database: dbm.dbs[0]}
+	dbm.dbs[0] = recorder
+	assert.True(t, dbm.IstanbulWALSynced())
+
+	dbm.WriteIstanbulWAL(hash1[:])
+	assert.Equal(t, [][]byte{istanbulWALKey}, recorder.synced)
+	assert.Equal(t, hash1[:], dbm.ReadIstanbulWAL())
+
+	// Other writes to the misc database are not synced.
+	dbm.WriteIstanbulTrace(0, hash2[:])
+	assert.Equal(t, [][]byte{istanbulWALKey}, recorder.synced)
+}
+
+// TestDBManager_IstanbulMisbehaviour tests read and write operations of the istanbul misbehaviours.
+func TestDBManager_IstanbulMisbehaviour(t *testing.T) {
+	log.EnableLogForTest(log.LvlCrit, log.LvlTrace)
+	for _, dbm := range dbManagers {
+		if dbm.GetMiscDB().Type() == BadgerDB {
+			continue // badgerDB doesn't support NewIterator, so cannot test ReadIstanbulMisbehaviours.
+		}
+		dbm.WriteIstanbulMisbehaviour(3, hash1, hash1[:])
+		dbm.WriteIstanbulMisbehaviour(1, hash2, hash2[:])
+		dbm.WriteIstanbulMisbehaviour(3, hash3, hash3[:])
+
+		assert.Equal(t, [][]byte{hash2[:]}, dbm.ReadIstanbulMisbehaviours(0, 2))
+		assert.ElementsMatch(t, [][]byte{hash1[:], hash3[:]}, dbm.ReadIstanbulMisbehaviours(2, 3))
+		assert.Len(t, dbm.ReadIstanbulMisbehaviours(0, math.MaxUint64), 3)
+		assert.Empty(t, dbm.ReadIstanbulMisbehaviours(4, math.MaxUint64))
+	}
+}
+
+// TestDBManager_IstanbulTrace tests read and write operations of the istanbul traces.
+func TestDBManager_IstanbulTrace(t *testing.T) {
+	log.EnableLogForTest(log.LvlCrit, log.LvlTrace)
+	for _, dbm := range dbManagers {
+		assert.Nil(t, dbm.ReadIstanbulTrace(1))
+
+		dbm.WriteIstanbulTrace(1, hash1[:])
+		assert.Equal(t, hash1[:], dbm.ReadIstanbulTrace(1))
+
+		dbm.WriteIstanbulTrace(1, hash2[:])
+		assert.Equal(t, hash2[:], dbm.ReadIstanbulTrace(1))
+	}
+}
+
 // TestDBManager_TrieNode tests read and write operations of state trie nodes.
 func TestDBManager_TrieNode(t *testing.T) {
 	log.EnableLogForTest(log.LvlCrit, log.LvlTrace)
@@ -0,0 +1,397 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// freezerHashTable indicates the name of the freezer canonical hash table.
+	freezerHashTable = "hashes"
+
+	// freezerHeaderTable indicates the name of the freezer header table.
+	freezerHeaderTable = "headers"
+
+	// freezerBodiesTable indicates the name of the freezer block body table.
+	freezerBodiesTable = "bodies"
+
+	// freezerReceiptTable indicates the name of the freezer receipts table.
+	freezerReceiptTable = "receipts"
+
+	// freezerDifficultyTable indicates the name of the freezer total blockscore table.
+	freezerDifficultyTable = "diffs"
+
+	// freezerIndexEntrySize is the size of an index entry, the end offset of an item.
+	freezerIndexEntrySize = 8
+)
+
+// freezerTables is the list of tables stored in the freezer.
+// Every table holds exactly the same number of items.
+var freezerTables = []string{
+	freezerHashTable,
+	freezerHeaderTable,
+	freezerBodiesTable,
+	freezerReceiptTable,
+	freezerDifficultyTable,
+}
+
+var (
+	errUnknownAncientTable = errors.New("unknown ancient table")
+	errOutOfBounds         = errors.New("out of bounds")
+	errOutOrderInsertion   = errors.New("the append operation is out-order")
+	errFreezerClosed       = errors.New("freezer is closed")
+)
+
+// freezerTable is an append-only flat file storing items of a single kind.
+// The n-th item is stored in the data file between the (n-1)-th and the n-th
+// end offsets written in the index file.
+type freezerTable struct {
+	name  string
+	index *os.File
+	data  *os.File
+
+	items uint64 // number of items stored in the table
+	size  uint64 // size of the data file, which is the end offset of the last item
+
+	lock sync.RWMutex
+}
+
+// newFreezerTable opens the table of the given name in the given directory.
+// It repairs the table if the data file and the index file do not match each
+// other, which may happen if the node crashed in the middle of an append.
+func newFreezerTable(dir, name string) (*freezerTable, error) {
+	index, err := os.OpenFile(filepath.Join(dir, name+".idx"), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.OpenFile(filepath.Join(dir, name+".dat"), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		index.Close()
+		return nil, err
+	}
+	t := &freezerTable{name: name, index: index, data: data}
+	if err := t.repair(); err != nil {
+		t.close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// repair drops the trailing index entries which point beyond the data file,
+// and then truncates the data file to the end offset of the last item.
+func (t *freezerTable) repair() error {
+	indexStat, err := t.index.Stat()
+	if err != nil {
+		return err
+	}
+	dataStat, err := t.data.Stat()
+	if err != nil {
+		return err
+	}
+	items := uint64(indexStat.Size()) / freezerIndexEntrySize
+	dataSize := uint64(dataStat.Size())
+
+	for ; items > 0; items-- {
+		end, err := t.readOffset(items)
+		if err != nil {
+			return err
+		}
+		if end <= dataSize {
+			dataSize = end
+			break
+		}
+	}
+	if items == 0 {
+		dataSize = 0
+	}
+	if err := t.index.Truncate(int64(items * freezerIndexEntrySize)); err != nil {
+		return err
+	}
+	if err := t.data.Truncate(int64(dataSize)); err != nil {
+		return err
+	}
+	t.items, t.size = items, dataSize
+	return nil
+}
+
+// readOffset returns the end offset of the n-th item (1-based). Offset of the
+// 0-th item is always 0, the beginning of the data file.
+func (t *freezerTable) readOffset(n uint64) (uint64, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	buf := make([]byte, freezerIndexEntrySize)
+	if _, err := t.index.ReadAt(buf, int64((n-1)*freezerIndexEntrySize)); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf), nil
+}
+
+// append writes the given item at the end of the table. The number of the
+// item should be the same as the number of items stored in the table.
+func (t *freezerTable) append(number uint64, item []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errFreezerClosed
+	}
+	if number != t.items {
+		return errOutOrderInsertion
+	}
+	if _, err := t.data.WriteAt(item, int64(t.size)); err != nil {
+		return err
+	}
+	end := t.size + uint64(len(item))
+	buf := make([]byte, freezerIndexEntrySize)
+	binary.BigEndian.PutUint64(buf, end)
+	if _, err := t.index.WriteAt(buf, int64(t.items*freezerIndexEntrySize)); err != nil {
+		return err
+	}
+	t.items, t.size = t.items+1, end
+	return nil
+}
+
+// retrieve returns the item of the given number.
+func (t *freezerTable) retrieve(number uint64) ([]byte, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil {
+		return nil, errFreezerClosed
+	}
+	if number >= t.items {
+		return nil, errOutOfBounds
+	}
+	start, err := t.readOffset(number)
+	if err != nil {
+		return nil, err
+	}
+	end, err := t.readOffset(number + 1)
+	if err != nil {
+		return nil, err
+	}
+	if start > end {
+		return nil, fmt.Errorf("corrupted index of ancient table %s at %d", t.name, number)
+	}
+	item := make([]byte, end-start)
+	if _, err := t.data.ReadAt(item, int64(start)); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// truncate discards the items after the given number of items.
+func (t *freezerTable) truncate(items uint64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errFreezerClosed
+	}
+	if items >= t.items {
+		return nil
+	}
+	size, err := t.readOffset(items)
+	if err != nil {
+		return err
+	}
+	if err := t.index.Truncate(int64(items * freezerIndexEntrySize)); err != nil {
+		return err
+	}
+	if err := t.data.Truncate(int64(size)); err != nil {
+		return err
+	}
+	t.items, t.size = items, size
+	return nil
+}
+
+// sync flushes the data file and the index file to the disk.
+func (t *freezerTable) sync() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errFreezerClosed
+	}
+	if err := t.data.Sync(); err != nil {
+		return err
+	}
+	return t.index.Sync()
+}
+
+func (t *freezerTable) close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var errs []error
+	if t.index != nil {
+		if err := t.index.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if t.data != nil {
+		if err := t.data.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	t.index, t.data = nil, nil
+	return errors.Join(errs...)
+}
+
+// freezer is an append-only store of canonical blocks which are old enough
+// not to be reorganized anymore. It stores the canonical hash, header, body,
+// receipts and total blockscore of a block in separate flat file tables.
+type freezer struct {
+	dir    string
+	tables map[string]*freezerTable
+	frozen uint64 // number of blocks frozen, accessed atomically
+
+	lock sync.Mutex // serializes append and truncate operations
+}
+
+// newFreezer opens the freezer in the given directory. If the tables have
+// different number of items, they are truncated to the shortest one.
+func newFreezer(dir string) (*freezer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	f := &freezer{dir: dir, tables: make(map[string]*freezerTable, len(freezerTables))}
+	for _, name := range freezerTables {
+		table, err := newFreezerTable(dir, name)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		f.tables[name] = table
+	}
+
+	minItems := ^uint64(0)
+	for _, table := range f.tables {
+		if table.items < minItems {
+			minItems = table.items
+		}
+	}
+	for _, table := range f.tables {
+		if err := table.truncate(minItems); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	atomic.StoreUint64(&f.frozen, minItems)
+	return f, nil
+}
+
+// Ancients returns the number of blocks frozen.
+func (f *freezer) Ancients() uint64 {
+	return atomic.LoadUint64(&f.frozen)
+}
+
+// HasAncient returns true if the block of the given number is frozen.
+func (f *freezer) HasAncient(number uint64) bool {
+	return number < f.Ancients()
+}
+
+// Ancient retrieves the item of the given kind and number.
+func (f *freezer) Ancient(kind string, number uint64) ([]byte, error) {
+	table, ok := f.tables[kind]
+	if !ok {
+		return nil, errUnknownAncientTable
+	}
+	if !f.HasAncient(number) {
+		return nil, errOutOfBounds
+	}
+	return table.retrieve(number)
+}
+
+// AppendAncient appends a block to the freezer. The number of the block should
+// be the same as the number of blocks frozen.
+func (f *freezer) AppendAncient(number uint64, hash, header, body, receipts, td []byte) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if number != f.Ancients() {
+		return errOutOrderInsertion
+	}
+	items := map[string][]byte{
+		freezerHashTable:       hash,
+		freezerHeaderTable:     header,
+		freezerBodiesTable:     body,
+		freezerReceiptTable:    receipts,
+		freezerDifficultyTable: td,
+	}
+	for _, name := range freezerTables {
+		if err := f.tables[name].append(number, items[name]); err != nil {
+			// Roll back the tables already appended to keep them aligned.
+			for _, table := range f.tables {
+				table.truncate(number)
+			}
+			return fmt.Errorf("failed to append to ancient table %s: %w", name, err)
+		}
+	}
+	atomic.StoreUint64(&f.frozen, number+1)
+	return nil
+}
+
+// TruncateAncients discards the frozen blocks after the given number of blocks.
+func (f *freezer) TruncateAncients(items uint64) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if items >= f.Ancients() {
+		return nil
+	}
+	for _, table := range f.tables {
+		if err := table.truncate(items); err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.frozen, items)
+	return nil
+}
+
+// Sync flushes all tables to the disk.
+func (f *freezer) Sync() error {
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes all tables of the freezer.
+func (f *freezer) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,179 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func appendTestAncients(t *testing.T, f *freezer, from, to uint64) {
+	for i := from; i < to; i++ {
+		item := []byte(fmt.Sprintf("item-%d", i))
+		require.NoError(t, f.AppendAncient(i, common.BytesToHash(item).Bytes(), item, item, item, item))
+	}
+}
+
+func TestFreezer_AppendAndTruncate(t *testing.T) {
+	dir := t.TempDir()
+
+	f, err := newFreezer(dir)
+	require.NoError(t, err)
+	appendTestAncients(t, f, 0, 10)
+	assert.Equal(t, uint64(10), f.Ancients())
+
+	// Out-of-order append is not allowed.
+	assert.ErrorIs(t, f.AppendAncient(11, nil, nil, nil, nil, nil), errOutOrderInsertion)
+
+	for i := uint64(0); i < 10; i++ {
+		data, err := f.Ancient(freezerBodiesTable, i)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(fmt.Sprintf("item-%d", i)), data)
+	}
+	_, err = f.Ancient(freezerBodiesTable, 10)
+	assert.ErrorIs(t, err, errOutOfBounds)
+	_, err = f.Ancient("unknown", 0)
+	assert.ErrorIs(t, err, errUnknownAncientTable)
+
+	require.NoError(t, f.TruncateAncients(5))
+	assert.Equal(t, uint64(5), f.Ancients())
+	_, err = f.Ancient(freezerHeaderTable, 5)
+	assert.ErrorIs(t, err, errOutOfBounds)
+
+	// Items can be appended again after truncation.
+	appendTestAncients(t, f, 5, 8)
+	data, err := f.Ancient(freezerHeaderTable, 7)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("item-7"), data)
+	require.NoError(t, f.Close())
+
+	// Reopen and check the items are persisted.
+	f, err = newFreezer(dir)
+	require.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, uint64(8), f.Ancients())
+	data, err = f.Ancient(freezerReceiptTable, 6)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("item-6"), data)
+}
+
+func TestFreezer_Repair(t *testing.T) {
+	dir := t.TempDir()
+
+	f, err := newFreezer(dir)
+	require.NoError(t, err)
+	appendTestAncients(t, f, 0, 10)
+	require.NoError(t, f.Close())
+
+	// Simulate a crash in the middle of an append: the data file of the bodies
+	// table lost its last item and the receipts table has an index entry only.
+	bodies := filepath.Join(dir, freezerBodiesTable+".dat")
+	stat, err := os.Stat(bodies)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(bodies, stat.Size()-1))
+
+	f, err = newFreezer(dir)
+	require.NoError(t, err)
+	defer f.Close()
+
+	// All tables are aligned to the shortest one.
+	assert.Equal(t, uint64(9), f.Ancients())
+	for _, name := range freezerTables {
+		assert.Equal(t, uint64(9), f.tables[name].items, name)
+	}
+	data, err := f.Ancient(freezerBodiesTable, 8)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("item-8"), data)
+}
+
+func TestDBManager_Ancient(t *testing.T) {
+	const (
+		numBlocks = 20
+		threshold = 5
+	)
+	dbm := NewDBManager(&DBConfig{
+		Dir: t.TempDir(), DBType: LevelDB, SingleDB: false, NumStateTrieShards: 1,
+		EnableAncient: true, AncientThreshold: threshold,
+	}).(*databaseManager)
+	defer dbm.Close()
+
+	var (
+		blocks   []*types.Block
+		receipts []types.Receipts
+	)
+	for i := 0; i < numBlocks; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i)), Time: big.NewInt(int64(i))}
+		if i > 0 {
+			header.ParentHash = blocks[i-1].Hash()
+		}
+		block := types.NewBlockWithHeader(header)
+		blockReceipts := types.Receipts{genReceipt(i)}
+
+		dbm.WriteBlock(block)
+		dbm.WriteTd(block.Hash(), block.NumberU64(), big.NewInt(int64(i+1)))
+		dbm.WriteReceipts(block.Hash(), block.NumberU64(), blockReceipts)
+		dbm.WriteCanonicalHash(block.Hash(), block.NumberU64())
+
+		blocks, receipts = append(blocks, block), append(receipts, blockReceipts)
+	}
+	// A side chain block which should be removed together with the canonical one.
+	sideHeader := &types.Header{Number: big.NewInt(3), Time: big.NewInt(100), ParentHash: blocks[2].Hash()}
+	dbm.WriteHeader(sideHeader)
+	dbm.WriteHeadBlockHash(blocks[numBlocks-1].Hash())
+
+	_, err := dbm.freeze()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(numBlocks-threshold), dbm.Ancients())
+
+	// Clear caches to make sure the data is read from the ancient store.
+	dbm.ClearHeaderChainCache()
+	dbm.ClearBlockChainCache()
+
+	for i, block := range blocks {
+		hash, number := block.Hash(), block.NumberU64()
+
+		kvHeader, _ := dbm.getDatabase(headerDB).Get(headerKey(number, hash))
+		assert.Equal(t, i > 0 && i < numBlocks-threshold, len(kvHeader) == 0, "block %d", i)
+
+		assert.Equal(t, hash, dbm.ReadCanonicalHash(number))
+		assert.True(t, dbm.HasHeader(hash, number))
+		assert.True(t, dbm.HasBody(hash, number))
+		assert.Equal(t, hash, dbm.ReadBlock(hash, number).Hash())
+		assert.Equal(t, hash, dbm.ReadBlockByNumber(number).Hash())
+		assert.Equal(t, big.NewInt(int64(i+1)), dbm.ReadTd(hash, number))
+		assert.Equal(t, receipts[i], dbm.ReadReceipts(hash, number))
+	}
+	assert.Nil(t, dbm.ReadHeader(sideHeader.Hash(), 3))
+	assert.Nil(t, dbm.ReadHeaderNumber(sideHeader.Hash()))
+
+	// Truncating the ancient store discards the blocks after the given number.
+	require.NoError(t, dbm.TruncateAncients(10))
+	dbm.ClearHeaderChainCache()
+	dbm.ClearBlockChainCache()
+	assert.Equal(t, uint64(10), dbm.Ancients())
+	assert.NotNil(t, dbm.ReadBlock(blocks[9].Hash(), 9))
+	assert.Nil(t, dbm.ReadBlock(blocks[10].Hash(), 10))
+	assert.Equal(t, common.Hash{}, dbm.ReadCanonicalHash(10))
+}
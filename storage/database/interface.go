@@ -34,12 +34,13 @@ const (
 	MemoryDB         = "MemoryDB"
 	DynamoDB         = "DynamoDBS3"
 	ShardedDB        = "ShardedDB"
+	PebbleDB         = "PebbleDB"
 )
 
 // ToValid converts DBType to a valid one.
 // If it is unable to convert, "" is returned.
 func (db DBType) ToValid() DBType {
-	validDBType := []DBType{LevelDB, RocksDB, BadgerDB, MemoryDB, DynamoDB}
+	validDBType := []DBType{LevelDB, RocksDB, BadgerDB, MemoryDB, DynamoDB, PebbleDB}
 
 	for _, vdb := range validDBType {
 		if strings.ToLower(string(vdb)) == strings.ToLower(string(db)) {
@@ -68,6 +69,14 @@ type KeyValueWriter interface {
 	Delete(key []byte) error
 }
 
+// SyncWriter wraps the PutSync method of a backing data store which can flush
+// a write to the disk before returning.
+type SyncWriter interface {
+	// PutSync inserts the given value into the key-value data store and syncs
+	// the write to the disk, so that it survives an OS crash or a power failure.
+	PutSync(key []byte, value []byte) error
+}
+
 // KeyValueStater wraps the Stat method of a backing data store.
 type KeyValueStater interface {
 	// Stat returns a particular internal stat of the database.
@@ -86,6 +95,26 @@ type Compacter interface {
 	Compact(start []byte, limit []byte) error
 }
 
+// Checkpointer wraps the Checkpoint method of a backing data store.
+type Checkpointer interface {
+	// Checkpoint captures a consistent point-in-time view of the data store,
+	// which is written to the given directory by Checkpoint.Save. The directory
+	// must not exist.
+	Checkpoint(dir string) (Checkpoint, error)
+}
+
+// Checkpoint is a consistent view of a data store taken by Checkpointer. The
+// view is fixed when the checkpoint is taken, so it can be saved while the
+// data store keeps being written.
+type Checkpoint interface {
+	// Save writes the captured data to the directory of the checkpoint.
+	Save() error
+
+	// Release releases the resources held by the checkpoint. It must be called
+	// even if the checkpoint is not saved.
+	Release()
+}
+
 // Database wraps all database operations. All methods are safe for concurrent use.
 type Database interface {
 	KeyValueWriter
@@ -280,6 +280,11 @@ func (db *levelDB) put(key []byte, value []byte) error {
 	return db.db.Put(key, value, nil)
 }
 
+// PutSync puts the given key / value and syncs the write to the disk.
+func (db *levelDB) PutSync(key []byte, value []byte) error {
+	return db.db.Put(key, value, &opt.WriteOptions{Sync: true})
+}
+
 func (db *levelDB) Has(key []byte) (bool, error) {
 	return db.db.Has(key, nil)
 }
@@ -354,6 +359,55 @@ func (db *levelDB) Compact(start []byte, limit []byte) error {
 	return db.db.CompactRange(util.Range{Start: start, Limit: limit})
 }
 
+// Checkpoint takes a snapshot of the database. LevelDB cannot make a checkpoint
+// on disk by itself, so the snapshot is copied into a new database on Save.
+func (db *levelDB) Checkpoint(dir string) (Checkpoint, error) {
+	snap, err := db.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &ldbCheckpoint{snap: snap, dir: dir}, nil
+}
+
+type ldbCheckpoint struct {
+	snap *leveldb.Snapshot
+	dir  string
+}
+
+func (cp *ldbCheckpoint) Save() error {
+	cpdb, err := leveldb.OpenFile(cp.dir, &opt.Options{ErrorIfExist: true})
+	if err != nil {
+		return err
+	}
+	defer cpdb.Close()
+
+	it := cp.snap.NewIterator(nil, nil)
+	defer it.Release()
+
+	var (
+		batch = new(leveldb.Batch)
+		size  int
+	)
+	for it.Next() {
+		batch.Put(it.Key(), it.Value())
+		if size += len(it.Key()) + len(it.Value()); size >= IdealBatchSize {
+			if err := cpdb.Write(batch, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+			size = 0
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return cpdb.Write(batch, nil)
+}
+
+func (cp *ldbCheckpoint) Release() {
+	cp.snap.Release()
+}
+
 // Meter configures the database metrics collectors and
 func (db *levelDB) Meter(prefix string) {
 	db.prefix = prefix
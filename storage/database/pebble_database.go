@@ -0,0 +1,623 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/bloom"
+	"github.com/klaytn/klaytn/log"
+	klaytnmetrics "github.com/klaytn/klaytn/metrics"
+	metricutils "github.com/klaytn/klaytn/metrics/utils"
+	"github.com/rcrowley/go-metrics"
+)
+
+const (
+	defaultPebbleDBCacheSize    = 16 // 16MB
+	minCacheSizeForPebbleDB     = 16
+	defaultOpenFilesForPebbleDB = 1024
+	minOpenFilesForPebbleDB     = 16
+)
+
+type PebbleDBConfig struct {
+	CacheSize      uint64 // Size of the block cache and memtables (MiB)
+	MaxOpenFiles   int
+	DisableMetrics bool
+}
+
+func GetDefaultPebbleDBConfig() *PebbleDBConfig {
+	return &PebbleDBConfig{
+		CacheSize:      defaultPebbleDBCacheSize,
+		MaxOpenFiles:   defaultOpenFilesForPebbleDB,
+		DisableMetrics: false,
+	}
+}
+
+type pebbleDB struct {
+	fn     string     // filename for reporting
+	db     *pebble.DB // PebbleDB instance
+	config *PebbleDBConfig
+
+	writeDelayCountMeter    metrics.Meter // Meter for measuring the cumulative number of write delays
+	writeDelayDurationMeter metrics.Meter // Meter for measuring the cumulative duration of write delays
+
+	compTimer       klaytnmetrics.HybridTimer // Meter for measuring the total time spent in database compaction
+	compReadMeter   metrics.Meter             // Meter for measuring the data read during compaction
+	compWriteMeter  metrics.Meter             // Meter for measuring the data written during compaction
+	diskWriteMeter  metrics.Meter             // Meter for measuring the effective amount of data written
+	blockCacheGauge metrics.Gauge             // Gauge for measuring the current size of block cache
+	memCompGauge    metrics.Gauge             // Gauge for tracking the number of memory compaction
+	level0CompGauge metrics.Gauge             // Gauge for tracking the number of table compaction in level0
+	compCountGauge  metrics.Gauge             // Gauge for tracking the number of table compaction in all levels
+	compDebtGauge   metrics.Gauge             // Gauge for tracking the estimated number of bytes to be compacted
+
+	levelSizesGauge  []metrics.Gauge
+	levelTablesGauge []metrics.Gauge
+	levelReadGauge   []metrics.Gauge
+	levelWriteGauge  []metrics.Gauge
+
+	perfCheck       bool
+	getTimer        klaytnmetrics.HybridTimer
+	putTimer        klaytnmetrics.HybridTimer
+	batchWriteTimer klaytnmetrics.HybridTimer
+
+	writeDelayStartTime time.Time    // The start time of the latest write stall
+	writeDelayCount     atomic.Int64 // Total number of write stall counts
+	writeDelayTime      atomic.Int64 // Total time spent in write stalls
+	level0Comp          atomic.Int64 // Total number of compactions from level0
+	closed              atomic.Bool  // Whether the database is closed
+
+	quitLock sync.Mutex      // Mutex protecting the quit channel access
+	quitChan chan chan error // Quit channel to stop the metrics collection before closing the database
+
+	prefix string     // prefix used for metrics
+	logger log.Logger // Contextual logger tracking the database path
+}
+
+func (db *pebbleDB) onCompactionBegin(info pebble.CompactionInfo) {
+	if len(info.Input) > 0 && info.Input[0].Level == 0 {
+		db.level0Comp.Add(1)
+	}
+}
+
+func (db *pebbleDB) onWriteStallBegin(b pebble.WriteStallBeginInfo) {
+	db.writeDelayStartTime = time.Now()
+}
+
+func (db *pebbleDB) onWriteStallEnd() {
+	db.writeDelayCount.Add(1)
+	db.writeDelayTime.Add(int64(time.Since(db.writeDelayStartTime)))
+}
+
+func NewPebbleDB(dbc *DBConfig, entryType DBEntryType) (*pebbleDB, error) {
+	localLogger := logger.NewWith("path", dbc.Dir)
+
+	config := GetDefaultPebbleDBConfig()
+	if dbc.PebbleDBConfig != nil {
+		copied := *dbc.PebbleDBConfig
+		config = &copied
+	}
+	// Ensure we have some minimal caching and file guarantees
+	if config.CacheSize < minCacheSizeForPebbleDB {
+		config.CacheSize = minCacheSizeForPebbleDB
+	}
+	if config.MaxOpenFiles < minOpenFilesForPebbleDB {
+		config.MaxOpenFiles = minOpenFilesForPebbleDB
+	}
+
+	// The max memtable size is limited by the uint32 offsets stored in
+	// internal/arenaskl.node, DeferredBatchOp, and flushableBatchEntry.
+	// Taken from https://github.com/cockroachdb/pebble/blob/master/open.go#L38
+	const maxMemTableSize = 4<<30 - 1
+
+	// Two memory tables is configured which is identical to LevelDB,
+	// including a frozen memory table and another live one.
+	memTableLimit := 2
+	cacheSize := int64(config.CacheSize * 1024 * 1024)
+	memTableSize := cacheSize * 1 / 2 / int64(memTableLimit)
+	if memTableSize >= maxMemTableSize {
+		memTableSize = maxMemTableSize - 1
+	}
+
+	db := &pebbleDB{
+		fn:        dbc.Dir,
+		config:    config,
+		logger:    localLogger,
+		perfCheck: dbc.EnableDBPerfMetrics,
+	}
+	opt := &pebble.Options{
+		// Pebble has a single combined cache area and the write
+		// buffers are taken from this too. Assign all available
+		// memory allowance for cache.
+		Cache:        pebble.NewCache(cacheSize / 2),
+		MaxOpenFiles: config.MaxOpenFiles,
+
+		// The size of memory table(as well as the write buffer).
+		// Note, there may have more than two memory tables in the system.
+		MemTableSize: uint64(memTableSize),
+
+		// MemTableStopWritesThreshold places a hard limit on the size
+		// of the existent MemTables(including the frozen one).
+		MemTableStopWritesThreshold: memTableLimit,
+
+		// The default compaction concurrency(1 thread),
+		// Here use all available CPUs for faster compaction.
+		MaxConcurrentCompactions: func() int { return runtime.NumCPU() },
+
+		// Per-level options. Options for at least one level must be specified. The
+		// options for the last level are used for all subsequent levels.
+		Levels: []pebble.LevelOptions{
+			{TargetFileSize: 2 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 4 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 8 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 16 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 32 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 64 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 128 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+		},
+		EventListener: &pebble.EventListener{
+			CompactionBegin: db.onCompactionBegin,
+			WriteStallBegin: db.onWriteStallBegin,
+			WriteStallEnd:   db.onWriteStallEnd,
+		},
+	}
+	// Disable seek compaction explicitly. Check https://github.com/ethereum/go-ethereum/pull/20130
+	// for more details.
+	opt.Experimental.ReadSamplingMultiplier = -1
+
+	localLogger.Info("PebbleDB configurations", "entryType", entryType, "cacheSize(MB)", config.CacheSize,
+		"maxOpenFiles", config.MaxOpenFiles, "memTableSize(MB)", memTableSize/1024/1024, "usePerfCheck", dbc.EnableDBPerfMetrics)
+
+	// Open the db and recover any potential corruptions
+	innerDB, err := pebble.Open(dbc.Dir, opt)
+	// The cache is referenced by the database, release the reference of the constructor.
+	opt.Cache.Unref()
+	if err != nil {
+		return nil, err
+	}
+	db.db = innerDB
+	return db, nil
+}
+
+func (db *pebbleDB) Type() DBType {
+	return PebbleDB
+}
+
+// Path returns the path to the database directory.
+func (db *pebbleDB) Path() string {
+	return db.fn
+}
+
+// Put puts the given key / value to the queue
+func (db *pebbleDB) Put(key []byte, value []byte) error {
+	if db.perfCheck {
+		start := time.Now()
+		err := db.put(key, value)
+		db.putTimer.Update(time.Since(start))
+		return err
+	}
+	return db.put(key, value)
+}
+
+func (db *pebbleDB) put(key []byte, value []byte) error {
+	return db.db.Set(key, value, pebble.NoSync)
+}
+
+// PutSync puts the given key / value and syncs the write to the disk.
+func (db *pebbleDB) PutSync(key []byte, value []byte) error {
+	return db.db.Set(key, value, pebble.Sync)
+}
+
+func (db *pebbleDB) Has(key []byte) (bool, error) {
+	_, closer, err := db.db.Get(key)
+	if err == pebble.ErrNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	closer.Close()
+	return true, nil
+}
+
+// Get returns the given key if it's present.
+func (db *pebbleDB) Get(key []byte) ([]byte, error) {
+	if db.perfCheck {
+		start := time.Now()
+		val, err := db.get(key)
+		db.getTimer.Update(time.Since(start))
+		return val, err
+	}
+	return db.get(key)
+}
+
+func (db *pebbleDB) get(key []byte) ([]byte, error) {
+	dat, closer, err := db.db.Get(key)
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return nil, dataNotFoundErr
+		}
+		return nil, err
+	}
+	ret := make([]byte, len(dat))
+	copy(ret, dat)
+	closer.Close()
+	return ret, nil
+}
+
+// Delete deletes the key from the queue and database
+func (db *pebbleDB) Delete(key []byte) error {
+	return db.db.Delete(key, pebble.NoSync)
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset
+// of database content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (db *pebbleDB) NewIterator(prefix []byte, start []byte) Iterator {
+	iter, err := db.db.NewIter(&pebble.IterOptions{
+		LowerBound: append(append([]byte{}, prefix...), start...),
+		UpperBound: upperBound(prefix),
+	})
+	if err != nil {
+		return &pdbIter{err: err}
+	}
+	iter.First()
+	return &pdbIter{iter: iter, moved: true}
+}
+
+func (db *pebbleDB) Close() {
+	// Stop the metrics collection to avoid internal database races
+	db.quitLock.Lock()
+	defer db.quitLock.Unlock()
+
+	if db.closed.Swap(true) {
+		return
+	}
+	if db.quitChan != nil {
+		errc := make(chan error)
+		db.quitChan <- errc
+		if err := <-errc; err != nil {
+			db.logger.Error("Metrics collection failed", "err", err)
+		}
+		db.quitChan = nil
+	}
+	err := db.db.Close()
+	if err == nil {
+		db.logger.Info("Database closed")
+	} else {
+		db.logger.Error("Failed to close database", "err", err)
+	}
+}
+
+// Stat returns the internal metrics of PebbleDB. The property is ignored.
+func (db *pebbleDB) Stat(property string) (string, error) {
+	return db.db.Metrics().String(), nil
+}
+
+// Compact flattens the underlying data store for the given key range. In essence,
+// deleted and overwritten versions are discarded, and the data is rearranged to
+// reduce the cost of operations needed to access them.
+//
+// A nil start is treated as a key before all keys in the data store; a nil limit
+// is treated as a key after all keys in the data store. If both is nil then it
+// will compact entire data store.
+func (db *pebbleDB) Compact(start []byte, limit []byte) error {
+	// There is no special flag to represent the end of key range
+	// in pebble(nil in leveldb). Use an ugly hack to construct a
+	// large key to represent it.
+	// Note any prefixed database entry will be smaller than this
+	// flag, as for trie nodes we need the 32 byte 0xff because
+	// there might be a shared prefix starting with a number of
+	// 0xff-s, so 32 ensures than only a hash collision could touch it.
+	// https://github.com/cockroachdb/pebble/issues/2359#issuecomment-1443995833
+	if limit == nil {
+		limit = bytes.Repeat([]byte{0xff}, 32)
+	}
+	return db.db.Compact(start, limit, true) // Parallelization is preferred
+}
+
+// Checkpoint creates a checkpoint of the database in the given directory right
+// away. The checkpoint hard-links the immutable sstables, so it's cheap as long
+// as the directory is on the same filesystem as the database.
+func (db *pebbleDB) Checkpoint(dir string) (Checkpoint, error) {
+	if err := db.db.Checkpoint(dir, pebble.WithFlushedWAL()); err != nil {
+		return nil, err
+	}
+	return savedCheckpoint{}, nil
+}
+
+// Meter configures the database metrics collectors and
+func (db *pebbleDB) Meter(prefix string) {
+	db.prefix = prefix
+
+	// Initialize all the metrics collector at the requested prefix
+	db.writeDelayCountMeter = metrics.NewRegisteredMeter(prefix+"writedelay/count", nil)
+	db.writeDelayDurationMeter = metrics.NewRegisteredMeter(prefix+"writedelay/duration", nil)
+	db.compTimer = klaytnmetrics.NewRegisteredHybridTimer(prefix+"compaction/time", nil)
+	db.compReadMeter = metrics.NewRegisteredMeter(prefix+"compaction/read", nil)
+	db.compWriteMeter = metrics.NewRegisteredMeter(prefix+"compaction/write", nil)
+	db.diskWriteMeter = metrics.NewRegisteredMeter(prefix+"disk/write", nil)
+	db.blockCacheGauge = metrics.NewRegisteredGauge(prefix+"blockcache", nil)
+
+	db.getTimer = klaytnmetrics.NewRegisteredHybridTimer(prefix+"get/time", nil)
+	db.putTimer = klaytnmetrics.NewRegisteredHybridTimer(prefix+"put/time", nil)
+	db.batchWriteTimer = klaytnmetrics.NewRegisteredHybridTimer(prefix+"batchwrite/time", nil)
+
+	db.memCompGauge = metrics.NewRegisteredGauge(prefix+"compact/memory", nil)
+	db.level0CompGauge = metrics.NewRegisteredGauge(prefix+"compact/level0", nil)
+	db.compCountGauge = metrics.NewRegisteredGauge(prefix+"compact/count", nil)
+	db.compDebtGauge = metrics.NewRegisteredGauge(prefix+"compact/debt", nil)
+
+	// Short circuit metering if the metrics system is disabled
+	// Above meters are initialized by NilMeter if metricutils.Enabled == false
+	if !metricutils.Enabled || db.config.DisableMetrics {
+		return
+	}
+
+	// Create a quit channel for the periodic collector and run it
+	db.quitLock.Lock()
+	db.quitChan = make(chan chan error)
+	db.quitLock.Unlock()
+
+	go db.meter(3 * time.Second)
+}
+
+// meter periodically retrieves internal pebble counters and reports them to
+// the metrics subsystem.
+func (db *pebbleDB) meter(refresh time.Duration) {
+	// Write delay related stats
+	var prevWriteDelayCount, prevWriteDelayDuration int64
+
+	// Compaction related stats
+	var prevCompRead, prevCompWrite int64
+	var prevCompTime time.Duration
+
+	// IO related stats
+	var prevWrite uint64
+
+	var errc chan error
+
+	for errc == nil {
+		m := db.db.Metrics()
+
+		// Write delay related stats
+		writeDelayCount, writeDelayDuration := db.writeDelayCount.Load(), db.writeDelayTime.Load()
+		db.writeDelayCountMeter.Mark(writeDelayCount - prevWriteDelayCount)
+		db.writeDelayDurationMeter.Mark(writeDelayDuration - prevWriteDelayDuration)
+		prevWriteDelayCount, prevWriteDelayDuration = writeDelayCount, writeDelayDuration
+
+		// Compaction related stats
+		var currCompRead, currCompWrite int64
+		for i := 0; i < len(m.Levels); i++ {
+			currCompRead += int64(m.Levels[i].BytesRead)
+			currCompWrite += int64(m.Levels[i].BytesCompacted)
+
+			db.updateLevelStats(m, i)
+		}
+		db.compTimer.Update(m.Compact.Duration - prevCompTime)
+		db.compReadMeter.Mark(currCompRead - prevCompRead)
+		db.compWriteMeter.Mark(currCompWrite - prevCompWrite)
+		prevCompTime, prevCompRead, prevCompWrite = m.Compact.Duration, currCompRead, currCompWrite
+
+		// IO related stats
+		currWrite := m.WAL.BytesWritten
+		for i := 0; i < len(m.Levels); i++ {
+			currWrite += m.Levels[i].BytesCompacted + m.Levels[i].BytesFlushed
+		}
+		db.diskWriteMeter.Mark(int64(currWrite - prevWrite))
+		prevWrite = currWrite
+
+		// BlockCache related stats
+		db.blockCacheGauge.Update(m.BlockCache.Size)
+
+		// Compaction related stats
+		db.memCompGauge.Update(m.Flush.Count)
+		db.level0CompGauge.Update(db.level0Comp.Load())
+		db.compCountGauge.Update(m.Compact.Count)
+		db.compDebtGauge.Update(int64(m.Compact.EstimatedDebt))
+
+		// Sleep a bit, then repeat the stats collection
+		select {
+		case errc = <-db.quitChan:
+			// Quit requesting, stop hammering the database
+		case <-time.After(refresh):
+			// Timeout, gather a new set of stats
+		}
+	}
+	errc <- nil
+}
+
+// updateLevelStats collects level-wise stats.
+func (db *pebbleDB) updateLevelStats(m *pebble.Metrics, lv int) {
+	// dynamically creates a new metrics for a new level
+	if len(db.levelSizesGauge) <= lv {
+		prefix := db.prefix + fmt.Sprintf("level%v/", lv)
+		db.levelSizesGauge = append(db.levelSizesGauge, metrics.NewRegisteredGauge(prefix+"size", nil))
+		db.levelTablesGauge = append(db.levelTablesGauge, metrics.NewRegisteredGauge(prefix+"tables", nil))
+		db.levelReadGauge = append(db.levelReadGauge, metrics.NewRegisteredGauge(prefix+"read", nil))
+		db.levelWriteGauge = append(db.levelWriteGauge, metrics.NewRegisteredGauge(prefix+"write", nil))
+	}
+
+	db.levelSizesGauge[lv].Update(m.Levels[lv].Size)
+	db.levelTablesGauge[lv].Update(m.Levels[lv].NumFiles)
+	db.levelReadGauge[lv].Update(int64(m.Levels[lv].BytesRead))
+	db.levelWriteGauge[lv].Update(int64(m.Levels[lv].BytesCompacted))
+}
+
+func (db *pebbleDB) TryCatchUpWithPrimary() error {
+	return nil
+}
+
+func (db *pebbleDB) NewBatch() Batch {
+	return &pdbBatch{b: db.db.NewBatch(), db: db}
+}
+
+// pdbBatch is a write-only pebble batch that commits changes to its host database
+// when Write is called. A batch cannot be used concurrently.
+type pdbBatch struct {
+	b    *pebble.Batch
+	db   *pebbleDB
+	size int
+}
+
+// Put inserts the given value into the batch for later committing.
+func (b *pdbBatch) Put(key, value []byte) error {
+	b.b.Set(key, value, nil)
+	b.size += len(value)
+	return nil
+}
+
+// Delete inserts the a key removal into the batch for later committing.
+func (b *pdbBatch) Delete(key []byte) error {
+	b.b.Delete(key, nil)
+	b.size += len(key)
+	return nil
+}
+
+// Write flushes any accumulated data to disk.
+func (b *pdbBatch) Write() error {
+	if b.db.perfCheck {
+		start := time.Now()
+		err := b.write()
+		b.db.batchWriteTimer.Update(time.Since(start))
+		return err
+	}
+	return b.write()
+}
+
+func (b *pdbBatch) write() error {
+	return b.b.Commit(pebble.NoSync)
+}
+
+// ValueSize retrieves the amount of data queued up for writing.
+func (b *pdbBatch) ValueSize() int {
+	return b.size
+}
+
+// Reset resets the batch for reuse.
+func (b *pdbBatch) Reset() {
+	b.b.Reset()
+	b.size = 0
+}
+
+// Release returns the batch to the pool of pebble.
+func (b *pdbBatch) Release() {
+	b.b.Close()
+}
+
+// Replay replays the batch contents.
+func (b *pdbBatch) Replay(w KeyValueWriter) error {
+	reader := b.b.Reader()
+	for {
+		kind, k, v, ok, err := reader.Next()
+		if !ok || err != nil {
+			return err
+		}
+		// The (k,v) slices might be overwritten if the batch is reset/reused,
+		// and the receiver should copy them if they are to be retained long-term.
+		if kind == pebble.InternalKeyKindSet {
+			if err := w.Put(k, v); err != nil {
+				return err
+			}
+		} else if kind == pebble.InternalKeyKindDelete {
+			if err := w.Delete(k); err != nil {
+				return err
+			}
+		} else {
+			return fmt.Errorf("unhandled operation, keytype: %v", kind)
+		}
+	}
+}
+
+// upperBound returns the upper bound for the given prefix
+func upperBound(prefix []byte) (limit []byte) {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		c := prefix[i]
+		if c == 0xff {
+			continue
+		}
+		limit = make([]byte, i+1)
+		copy(limit, prefix)
+		limit[i] = c + 1
+		break
+	}
+	return limit
+}
+
+// pdbIter is a wrapper of pebble.Iterator to satisfy the Iterator interface.
+type pdbIter struct {
+	iter     *pebble.Iterator
+	moved    bool
+	released bool
+	err      error
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (iter *pdbIter) Next() bool {
+	if iter.iter == nil {
+		return false
+	}
+	if iter.moved {
+		iter.moved = false
+		return iter.iter.Valid()
+	}
+	return iter.iter.Next()
+}
+
+// Error returns any accumulated error. Exhausting all the key/value pairs
+// is not considered to be an error.
+func (iter *pdbIter) Error() error {
+	if iter.iter == nil {
+		return iter.err
+	}
+	return iter.iter.Error()
+}
+
+// Key returns the key of the current key/value pair, or nil if done. The caller
+// should not modify the contents of the returned slice, and its contents may
+// change on the next call to Next.
+func (iter *pdbIter) Key() []byte {
+	if iter.iter == nil || iter.moved || !iter.iter.Valid() {
+		return nil
+	}
+	return iter.iter.Key()
+}
+
+// Value returns the value of the current key/value pair, or nil if done. The
+// caller should not modify the contents of the returned slice, and its contents
+// may change on the next call to Next.
+func (iter *pdbIter) Value() []byte {
+	if iter.iter == nil || iter.moved || !iter.iter.Valid() {
+		return nil
+	}
+	return iter.iter.Value()
+}
+
+// Release releases associated resources. Release should always succeed and can
+// be called multiple times without causing error.
+func (iter *pdbIter) Release() {
+	if iter.iter != nil && !iter.released {
+		iter.iter.Close()
+		iter.released = true
+	}
+}
@@ -180,6 +180,17 @@ func (db *rocksDB) Put(key []byte, value []byte) error {
 	return db.db.Put(db.wo, key, value)
 }
 
+// PutSync puts the given key / value and syncs the write to the disk.
+func (db *rocksDB) PutSync(key []byte, value []byte) error {
+	if db.config.Secondary {
+		return nil
+	}
+	wo := grocksdb.NewDefaultWriteOptions()
+	defer wo.Destroy()
+	wo.SetSync(true)
+	return db.db.Put(wo, key, value)
+}
+
 func (db *rocksDB) Has(key []byte) (bool, error) {
 	dat, err := db.db.GetBytes(db.ro, key)
 	if dat == nil || err != nil {
@@ -296,6 +307,22 @@ func (db *rocksDB) Close() {
 	db.logger.Info("RocksDB is closed")
 }
 
+// Checkpoint creates a checkpoint of the database in the given directory right
+// away. The SST files are hard-linked if the directory is on the same filesystem.
+func (db *rocksDB) Checkpoint(dir string) (Checkpoint, error) {
+	cp, err := db.db.NewCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+	defer cp.Destroy()
+
+	// Flush the memtables always so that the WAL files are not copied.
+	if err := cp.CreateCheckpoint(dir, 0); err != nil {
+		return nil, err
+	}
+	return savedCheckpoint{}, nil
+}
+
 func (db *rocksDB) updateMeter(name string, meter metrics.Meter) {
 	v, s := db.db.GetIntProperty(name)
 	if s {
@@ -73,6 +73,19 @@ var (
 	// snapshotRootKey tracks the hash of the last snapshot.
 	snapshotRootKey = []byte("SnapshotRoot")
 
+	// stateHistoryTailKey tracks the number of the oldest block whose state history may be stored.
+	stateHistoryTailKey = []byte("StateHistoryTail")
+
+	// receiptsMigrationKey tracks the key of the block receipts from which the receipts migration continues.
+	receiptsMigrationKey = []byte("ReceiptsMigration")
+
+	// logIndexTailKey and logIndexNextKey track the range of the blocks whose logs are indexed.
+	logIndexTailKey = []byte("LogIndexTail")
+	logIndexNextKey = []byte("LogIndexNext")
+
+	// istanbulWALKey tracks the write-ahead log of the consensus messages signed by the node.
+	istanbulWALKey = []byte("IstanbulWAL")
+
 	// badBlockKey tracks the list of bad blocks seen by local
 	badBlockKey = []byte("InvalidBlock")
 
@@ -90,6 +103,8 @@ var (
 	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
 	codePrefix            = []byte("c") // codePrefix + code hash -> contract code
 
+	stateHistoryPrefix = []byte("StateHistory-") // stateHistoryPrefix + num (uint64 big endian) + hash -> state history
+
 	preimagePrefix = []byte("secure-key-")  // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("klay-config-") // config prefix for the db
 
@@ -101,6 +116,8 @@ var (
 
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
 	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
+	logIndexPrefix       = []byte("iL") // logIndexPrefix + kind + address or topic + num (uint64 big endian) -> logIndexValue
+	logIndexValue        = []byte{0x01} // A nonempty value to store a log index entry
 
 	preimageCounter    = metrics.NewRegisteredCounter("db/preimage/total", nil)
 	preimageHitCounter = metrics.NewRegisteredCounter("db/preimage/hits", nil)
@@ -129,6 +146,12 @@ var (
 
 	stakingInfoPrefix = []byte("stakingInfo")
 
+	// istanbulMisbehaviourPrefix + sequence (uint64 big endian) + hash -> evidence of a misbehaving validator
+	istanbulMisbehaviourPrefix = []byte("istanbulMisbehaviour")
+
+	// istanbulTracePrefix + slot (uint64 big endian) -> consensus events of a block, in the slot of its number
+	istanbulTracePrefix = []byte("istanbulTrace")
+
 	chaindatafetcherCheckpointKey = []byte("chaindatafetcherCheckpoint")
 )
 
@@ -194,6 +217,16 @@ func SenderTxHashToTxHashKey(senderTxHash common.Hash) []byte {
 	return append(senderTxHashToTxHashPrefix, senderTxHash.Bytes()...)
 }
 
+// stateHistoryKeyPrefix = stateHistoryPrefix + num (uint64 big endian)
+func stateHistoryKeyPrefix(number uint64) []byte {
+	return append(stateHistoryPrefix, common.Int64ToByteBigEndian(number)...)
+}
+
+// stateHistoryKey = stateHistoryPrefix + num (uint64 big endian) + hash
+func stateHistoryKey(number uint64, hash common.Hash) []byte {
+	return append(stateHistoryKeyPrefix(number), hash.Bytes()...)
+}
+
 // preimageKey = preimagePrefix + hash
 func preimageKey(hash common.Hash) []byte {
 	return append(preimagePrefix, hash.Bytes()...)
@@ -248,11 +281,34 @@ func BloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	return key
 }
 
+// logIndexTermKey = logIndexPrefix + kind + term
+func logIndexTermKey(kind byte, term []byte) []byte {
+	key := make([]byte, 0, len(logIndexPrefix)+1+len(term)+8)
+	key = append(key, logIndexPrefix...)
+	key = append(key, kind)
+	return append(key, term...)
+}
+
+// logIndexKey = logIndexPrefix + kind + term + num (uint64 big endian)
+func logIndexKey(kind byte, term []byte, number uint64) []byte {
+	return append(logIndexTermKey(kind, term), common.Int64ToByteBigEndian(number)...)
+}
+
 func makeKey(prefix []byte, num uint64) []byte {
 	byteKey := common.Int64ToByteLittleEndian(num)
 	return append(prefix, byteKey...)
 }
 
+// istanbulMisbehaviourKey = istanbulMisbehaviourPrefix + sequence (uint64 big endian) + hash
+func istanbulMisbehaviourKey(sequence uint64, hash common.Hash) []byte {
+	return append(append(istanbulMisbehaviourPrefix, common.Int64ToByteBigEndian(sequence)...), hash.Bytes()...)
+}
+
+// istanbulTraceKey = istanbulTracePrefix + slot (uint64 big endian)
+func istanbulTraceKey(slot uint64) []byte {
+	return append(istanbulTracePrefix, common.Int64ToByteBigEndian(slot)...)
+}
+
 func databaseDirKey(dbEntryType uint64) []byte {
 	return append(databaseDirPrefix, common.Int64ToByteBigEndian(dbEntryType)...)
 }
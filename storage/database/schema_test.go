@@ -0,0 +1,97 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestSchemaPrefixCollision tests that the prefixes of the key ranges which are
+// iterated or deleted by their prefixes don't collide with the other keys of the
+// schema, which are stored in the same database in the single DB layout.
+func TestSchemaPrefixCollision(t *testing.T) {
+	schema := map[string][]byte{
+		"databaseVerisionKey":             databaseVerisionKey,
+		"headHeaderKey":                   headHeaderKey,
+		"headBlockKey":                    headBlockKey,
+		"headBlockBackupKey":              headBlockBackupKey,
+		"headFastBlockKey":                headFastBlockKey,
+		"headFastBlockBackupKey":          headFastBlockBackupKey,
+		"fastTrieProgressKey":             fastTrieProgressKey,
+		"validSectionKey":                 validSectionKey,
+		"sectionHeadKeyPrefix":            sectionHeadKeyPrefix,
+		"snapshotKeyPrefix":               snapshotKeyPrefix,
+		"snapshotJournalKey":              snapshotJournalKey,
+		"SnapshotGeneratorKey":            SnapshotGeneratorKey,
+		"snapshotDisabledKey":             snapshotDisabledKey,
+		"snapshotRecoveryKey":             snapshotRecoveryKey,
+		"snapshotSyncStatusKey":           snapshotSyncStatusKey,
+		"snapshotRootKey":                 snapshotRootKey,
+		"stateHistoryTailKey":             stateHistoryTailKey,
+		"receiptsMigrationKey":            receiptsMigrationKey,
+		"logIndexTailKey":                 logIndexTailKey,
+		"logIndexNextKey":                 logIndexNextKey,
+		"istanbulWALKey":                  istanbulWALKey,
+		"badBlockKey":                     badBlockKey,
+		"headerPrefix":                    headerPrefix,
+		"headerNumberPrefix":              headerNumberPrefix,
+		"blockBodyPrefix":                 blockBodyPrefix,
+		"blockReceiptsPrefix":             blockReceiptsPrefix,
+		"txLookupPrefix":                  txLookupPrefix,
+		"SnapshotAccountPrefix":           SnapshotAccountPrefix,
+		"SnapshotStoragePrefix":           SnapshotStoragePrefix,
+		"codePrefix":                      codePrefix,
+		"stateHistoryPrefix":              stateHistoryPrefix,
+		"preimagePrefix":                  preimagePrefix,
+		"configPrefix":                    configPrefix,
+		"pruningEnabledKey":               pruningEnabledKey,
+		"pruningMarkPrefix":               pruningMarkPrefix,
+		"lastPrunedBlockNumberKey":        lastPrunedBlockNumberKey,
+		"BloomBitsIndexPrefix":            BloomBitsIndexPrefix,
+		"logIndexPrefix":                  logIndexPrefix,
+		"childChainTxHashPrefix":          childChainTxHashPrefix,
+		"lastServiceChainTxReceiptKey":    lastServiceChainTxReceiptKey,
+		"lastIndexedBlockKey":             lastIndexedBlockKey,
+		"receiptFromParentChainKeyPrefix": receiptFromParentChainKeyPrefix,
+		"parentOperatorFeePayerPrefix":    parentOperatorFeePayerPrefix,
+		"childOperatorFeePayerPrefix":     childOperatorFeePayerPrefix,
+		"valueTransferTxHashPrefix":       valueTransferTxHashPrefix,
+		"bloomBitsPrefix":                 bloomBitsPrefix,
+		"senderTxHashToTxHashPrefix":      senderTxHashToTxHashPrefix,
+		"governancePrefix":                governancePrefix,
+		"governanceHistoryKey":            governanceHistoryKey,
+		"governanceStateKey":              governanceStateKey,
+		"databaseDirPrefix":               databaseDirPrefix,
+		"migrationStatusKey":              migrationStatusKey,
+		"stakingInfoPrefix":               stakingInfoPrefix,
+		"istanbulMisbehaviourPrefix":      istanbulMisbehaviourPrefix,
+		"istanbulTracePrefix":             istanbulTracePrefix,
+		"chaindatafetcherCheckpointKey":   chaindatafetcherCheckpointKey,
+	}
+	for _, name := range []string{"stateHistoryPrefix", "logIndexPrefix", "istanbulMisbehaviourPrefix", "istanbulTracePrefix"} {
+		prefix := schema[name]
+		for other, key := range schema {
+			if other == name {
+				continue
+			}
+			if bytes.HasPrefix(key, prefix) || bytes.HasPrefix(prefix, key) {
+				t.Errorf("%s (%q) collides with %s (%q)", name, prefix, other, key)
+			}
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"container/heap"
 	"context"
 	"fmt"
+	"os"
 	"path"
 	"strconv"
 	"sync"
@@ -77,6 +78,12 @@ func newShardedDB(dbc *DBConfig, et DBEntryType, numShards uint) (*shardedDB, er
 		sdbRocksDBCacheSize = dbc.RocksDBConfig.CacheSize / uint64(numShards)
 		sdbRocksDBMaxOpenFiles = dbc.RocksDBConfig.MaxOpenFiles / int(numShards)
 	}
+	sdbPebbleDBCacheSize := GetDefaultPebbleDBConfig().CacheSize / uint64(numShards)
+	sdbPebbleDBMaxOpenFiles := GetDefaultPebbleDBConfig().MaxOpenFiles / int(numShards)
+	if dbc.PebbleDBConfig != nil {
+		sdbPebbleDBCacheSize = dbc.PebbleDBConfig.CacheSize / uint64(numShards)
+		sdbPebbleDBMaxOpenFiles = dbc.PebbleDBConfig.MaxOpenFiles / int(numShards)
+	}
 	for i := 0; i < int(numShards); i++ {
 		copiedDBC := *dbc
 		copiedDBC.Dir = path.Join(copiedDBC.Dir, strconv.Itoa(i))
@@ -86,6 +93,12 @@ func newShardedDB(dbc *DBConfig, et DBEntryType, numShards uint) (*shardedDB, er
 			copiedDBC.RocksDBConfig.CacheSize = sdbRocksDBCacheSize
 			copiedDBC.RocksDBConfig.MaxOpenFiles = sdbRocksDBMaxOpenFiles
 		}
+		if copiedDBC.PebbleDBConfig != nil {
+			pebbleDBConfig := *copiedDBC.PebbleDBConfig
+			pebbleDBConfig.CacheSize = sdbPebbleDBCacheSize
+			pebbleDBConfig.MaxOpenFiles = sdbPebbleDBMaxOpenFiles
+			copiedDBC.PebbleDBConfig = &pebbleDBConfig
+		}
 
 		db, err := newDatabase(&copiedDBC, et)
 		if err != nil {
@@ -558,3 +571,21 @@ func (db *shardedDB) Compact(start []byte, limit []byte) error {
 		return errors.New(errs)
 	}
 }
+
+// Checkpoint takes the checkpoints of the shards in the numbered subdirectories
+// of the given directory, the same layout as the sharded database.
+func (db *shardedDB) Checkpoint(dir string) (Checkpoint, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	cps := make(multiCheckpoint, 0, len(db.shards))
+	for idx, shard := range db.shards {
+		cp, err := checkpointDatabase(shard, path.Join(dir, strconv.Itoa(idx)))
+		if err != nil {
+			cps.Release()
+			return nil, fmt.Errorf("shard[%d]: %w", idx, err)
+		}
+		cps = append(cps, cp)
+	}
+	return cps, nil
+}
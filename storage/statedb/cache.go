@@ -36,6 +36,9 @@ type TrieNodeCacheConfig struct {
 	FastCacheSavePeriod       time.Duration // Period of saving in memory trie cache to file if fastcache is used
 	RedisEndpoints            []string      // Endpoints of redis cache
 	RedisClusterEnable        bool          // Enable cluster-enabled mode of redis cache
+	RedisShardingEnable       bool          // Enable sharding of redis cache over the endpoints with consistent hashing
+	RedisTTL                  time.Duration // Expiration of the items in redis cache, 0 means no expiration
+	RedisEvictionPolicy       string        // Eviction policy (maxmemory-policy) applied to the redis servers, empty means the server setting
 	RedisPublishBlockEnable   bool          // Enable publishing every inserted block to the redis server
 	RedisSubscribeBlockEnable bool          // Enable subscribing blocks from the redis server
 }
@@ -17,6 +17,9 @@
 package statedb
 
 import (
+	"fmt"
+	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/VictoriaMetrics/fastcache"
@@ -40,7 +43,8 @@ var (
 )
 
 type FastCache struct {
-	fast *fastcache.Cache
+	fast     atomic.Pointer[fastcache.Cache] // Replaced when the cache is loaded from a file
+	maxBytes int
 }
 
 // newFastCache creates a FastCache with given cache size.
@@ -59,7 +63,8 @@ func newFastCache(config *TrieNodeCacheConfig) TrieNodeCache {
 		"MaxMiB", config.LocalCacheSizeMiB, "FilePath", config.FastCacheFileDir)
 
 	start := time.Now()
-	fc := &FastCache{fast: fastcache.LoadFromFileOrNew(config.FastCacheFileDir, config.LocalCacheSizeMiB*int(units.MiB))}
+	fc := &FastCache{maxBytes: config.LocalCacheSizeMiB * int(units.MiB)}
+	fc.fast.Store(fastcache.LoadFromFileOrNew(config.FastCacheFileDir, fc.maxBytes))
 	stats := fc.UpdateStats().(fastcache.Stats)
 
 	logger.Info("Initialized local trie node cache (fastCache)",
@@ -69,20 +74,20 @@ func newFastCache(config *TrieNodeCacheConfig) TrieNodeCache {
 }
 
 func (cache *FastCache) Get(k []byte) []byte {
-	return cache.fast.Get(nil, k)
+	return cache.fast.Load().Get(nil, k)
 }
 
 func (cache *FastCache) Set(k, v []byte) {
-	cache.fast.Set(k, v)
+	cache.fast.Load().Set(k, v)
 }
 
 func (cache *FastCache) Has(k []byte) ([]byte, bool) {
-	return cache.fast.HasGet(nil, k)
+	return cache.fast.Load().HasGet(nil, k)
 }
 
 func (cache *FastCache) UpdateStats() interface{} {
 	var stats fastcache.Stats
-	cache.fast.UpdateStats(&stats)
+	cache.fast.Load().UpdateStats(&stats)
 
 	memcacheFastMisses.Update(int64(stats.Misses))
 	memcacheFastCollisions.Update(int64(stats.Collisions))
@@ -100,7 +105,25 @@ func (cache *FastCache) UpdateStats() interface{} {
 }
 
 func (cache *FastCache) SaveToFile(filePath string, concurrency int) error {
-	return cache.fast.SaveToFileConcurrent(filePath, concurrency)
+	return cache.fast.Load().SaveToFileConcurrent(filePath, concurrency)
+}
+
+// LoadFromFile replaces the cached items with the ones saved in the file by
+// SaveToFile. The file must have been saved from a cache of the same size.
+func (cache *FastCache) LoadFromFile(filePath string) error {
+	if _, err := os.Stat(filePath); err != nil {
+		return err
+	}
+	// LoadFromFileOrNew returns an empty cache if the file can't be loaded in the size
+	loaded := fastcache.LoadFromFileOrNew(filePath, cache.maxBytes)
+	var stats fastcache.Stats
+	loaded.UpdateStats(&stats)
+	if stats.EntriesCount == 0 {
+		loaded.Reset()
+		return fmt.Errorf("no trie node is loaded from %s, the file may be saved from a cache of a different size", filePath)
+	}
+	cache.fast.Swap(loaded).Reset()
+	return nil
 }
 
 func (cache *FastCache) Close() error {
@@ -18,6 +18,7 @@ package statedb
 
 import (
 	"errors"
+	"fmt"
 	"runtime"
 	"time"
 
@@ -37,13 +38,21 @@ var (
 	redisCacheDialTimeout = time.Duration(900 * time.Millisecond)
 	redisCacheTimeout     = time.Duration(900 * time.Millisecond)
 
-	errRedisNoEndpoint = errors.New("redis endpoint not specified")
+	errRedisNoEndpoint          = errors.New("redis endpoint not specified")
+	errRedisClusterWithSharding = errors.New("redis cluster and sharding can't be enabled together")
+
+	// redisEvictionPolicies are the available maxmemory-policy values of redis.
+	redisEvictionPolicies = []string{
+		"noeviction", "allkeys-lru", "allkeys-lfu", "allkeys-random",
+		"volatile-lru", "volatile-lfu", "volatile-random", "volatile-ttl",
+	}
 )
 
 type RedisCache struct {
 	client    redis.UniversalClient
 	setItemCh chan setItem
 	pubSub    *redis.PubSub
+	ttl       time.Duration // Expiration of the items, 0 means no expiration
 }
 
 type setItem struct {
@@ -51,10 +60,13 @@ type setItem struct {
 	value []byte
 }
 
-func newRedisClient(endpoints []string, isCluster bool) (redis.UniversalClient, error) {
+func newRedisClient(endpoints []string, isCluster, isSharding bool) (redis.UniversalClient, error) {
 	if endpoints == nil {
 		return nil, errRedisNoEndpoint
 	}
+	if isCluster && isSharding {
+		return nil, errRedisClusterWithSharding
+	}
 
 	// cluster-enabled redis can have more than one shard
 	if isCluster {
@@ -68,6 +80,23 @@ func newRedisClient(endpoints []string, isCluster bool) (redis.UniversalClient,
 		}), nil
 	}
 
+	// independent redis servers can be sharded by the client with consistent hashing,
+	// so that only a small portion of the items is remapped when a shard is added or removed
+	if isSharding {
+		addrs := make(map[string]string, len(endpoints))
+		for _, endpoint := range endpoints {
+			addrs[endpoint] = endpoint
+		}
+		return redis.NewRing(&redis.RingOptions{
+			// it takes Timeout * (MaxRetries+1) to raise an error
+			Addrs:        addrs,
+			DialTimeout:  redisCacheDialTimeout,
+			ReadTimeout:  redisCacheTimeout,
+			WriteTimeout: redisCacheTimeout,
+			MaxRetries:   2,
+		}), nil
+	}
+
 	return redis.NewClient(&redis.Options{
 		// it takes Timeout * (MaxRetries+1) to raise an error
 		Addr:         endpoints[0],
@@ -81,17 +110,25 @@ func newRedisClient(endpoints []string, isCluster bool) (redis.UniversalClient,
 // newRedisCache creates a redis cache containing redis client, setItemCh and pubSub.
 // It generates worker goroutines to process Set commands asynchronously.
 func newRedisCache(config *TrieNodeCacheConfig) (*RedisCache, error) {
-	cli, err := newRedisClient(config.RedisEndpoints, config.RedisClusterEnable)
+	cli, err := newRedisClient(config.RedisEndpoints, config.RedisClusterEnable, config.RedisShardingEnable)
 	if err != nil {
 		logger.Error("failed to create a redis client", "err", err, "endpoint", config.RedisEndpoints,
-			"isCluster", config.RedisClusterEnable)
+			"isCluster", config.RedisClusterEnable, "isSharding", config.RedisShardingEnable)
 		return nil, err
 	}
+	if config.RedisEvictionPolicy != "" {
+		if err := setRedisEvictionPolicy(cli, config.RedisEvictionPolicy); err != nil {
+			logger.Error("failed to set the eviction policy of redis", "err", err, "policy", config.RedisEvictionPolicy)
+			cli.Close()
+			return nil, err
+		}
+	}
 
 	cache := &RedisCache{
 		client:    cli,
 		setItemCh: make(chan setItem, redisSetItemChannelSize),
 		pubSub:    cli.Subscribe(),
+		ttl:       config.RedisTTL,
 	}
 
 	workerNum := runtime.NumCPU()/2 + 1
@@ -104,10 +141,40 @@ func newRedisCache(config *TrieNodeCacheConfig) (*RedisCache, error) {
 	}
 
 	logger.Info("Initialized trie node cache with redis", "endpoint", config.RedisEndpoints,
-		"isCluster", config.RedisClusterEnable)
+		"isCluster", config.RedisClusterEnable, "isSharding", config.RedisShardingEnable,
+		"ttl", config.RedisTTL, "evictionPolicy", config.RedisEvictionPolicy)
 	return cache, nil
 }
 
+// setRedisEvictionPolicy sets the maxmemory-policy of all the redis servers
+// which the client is connected to.
+func setRedisEvictionPolicy(cli redis.UniversalClient, policy string) error {
+	valid := false
+	for _, p := range redisEvictionPolicies {
+		if p == policy {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return fmt.Errorf("invalid redis eviction policy %q, available policies: %v", policy, redisEvictionPolicies)
+	}
+
+	set := func(client *redis.Client) error {
+		return client.ConfigSet("maxmemory-policy", policy).Err()
+	}
+	switch c := cli.(type) {
+	case *redis.ClusterClient:
+		return c.ForEachMaster(set)
+	case *redis.Ring:
+		return c.ForEachShard(set)
+	case *redis.Client:
+		return set(c)
+	default:
+		return fmt.Errorf("unsupported redis client type %T", cli)
+	}
+}
+
 func (cache *RedisCache) Get(k []byte) []byte {
 	val, err := cache.client.Get(hexutil.Encode(k)).Bytes()
 	if err != nil {
@@ -120,7 +187,7 @@ func (cache *RedisCache) Get(k []byte) []byte {
 // Set writes data synchronously.
 // To write data asynchronously, use SetAsync instead.
 func (cache *RedisCache) Set(k, v []byte) {
-	if err := cache.client.Set(hexutil.Encode(k), v, 0).Err(); err != nil {
+	if err := cache.client.Set(hexutil.Encode(k), v, cache.ttl).Err(); err != nil {
 		logger.Error("failed to set an item on redis cache", "err", err, "key", hexutil.Encode(k))
 	}
 }
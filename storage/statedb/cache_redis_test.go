@@ -239,13 +239,13 @@ func TestRedisCache_Timeout(t *testing.T) {
 		}
 	}()
 
-	var cache TrieNodeCache = &RedisCache{redis.NewClient(&redis.Options{
+	var cache TrieNodeCache = &RedisCache{client: redis.NewClient(&redis.Options{
 		Addr:         "localhost:11234",
 		DialTimeout:  redisCacheDialTimeout,
 		ReadTimeout:  redisCacheTimeout,
 		WriteTimeout: redisCacheTimeout,
 		MaxRetries:   0,
-	}), nil, nil}
+	})}
 
 	key, value := randBytes(32), randBytes(500)
 
@@ -262,3 +262,14 @@ func TestRedisCache_Timeout(t *testing.T) {
 	_, _ = cache.Has(key)
 	assert.Equal(t, redisCacheTimeout, time.Since(start).Round(redisCacheTimeout/2))
 }
+
+// TestRedisCache_InvalidConfig tests the redis configs rejected before connecting.
+func TestRedisCache_InvalidConfig(t *testing.T) {
+	cli, err := newRedisClient([]string{"localhost:6379"}, false, false)
+	assert.NoError(t, err)
+	defer cli.Close()
+	assert.Error(t, setRedisEvictionPolicy(cli, "lru"))
+
+	_, err = newRedisClient([]string{"localhost:6379"}, true, true)
+	assert.ErrorIs(t, err, errRedisClusterWithSharding)
+}
@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/storage/database"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -81,3 +82,57 @@ func TestFastCache_SaveAndLoad(t *testing.T) {
 		assert.Equal(t, fastCacheFromFile.Get(key), vals[idx])
 	}
 }
+
+// TestFastCache_LoadFromFile tests replacing the items of a running fastcache
+// with the ones saved by another fastcache.
+func TestFastCache_LoadFromFile(t *testing.T) {
+	dirName := t.TempDir()
+
+	config := getTestFastCacheConfig()
+	config.FastCacheFileDir = ""
+	src := newFastCache(config).(*FastCache)
+	dst := newFastCache(config).(*FastCache)
+
+	key, val := common.MakeRandomBytes(128), common.MakeRandomBytes(128)
+	src.Set(key, val)
+	assert.NoError(t, src.SaveToFile(dirName, runtime.NumCPU()))
+
+	dstKey, dstVal := common.MakeRandomBytes(128), common.MakeRandomBytes(128)
+	dst.Set(dstKey, dstVal)
+	assert.NoError(t, dst.LoadFromFile(dirName))
+	assert.Equal(t, val, dst.Get(key))
+	assert.Nil(t, dst.Get(dstKey), "the previous items are replaced")
+
+	// The file must exist and be saved from a cache of the same size.
+	assert.Error(t, dst.LoadFromFile(dirName+"/missing"))
+	config.LocalCacheSizeMiB = 200
+	other := newFastCache(config).(*FastCache)
+	assert.Error(t, other.LoadFromFile(dirName))
+}
+
+// TestDatabase_ExportImportTrieNodeCache tests exporting and importing the local
+// trie node cache through the Database.
+func TestDatabase_ExportImportTrieNodeCache(t *testing.T) {
+	dirName := t.TempDir()
+
+	config := getTestFastCacheConfig()
+	config.FastCacheFileDir = ""
+	src := NewDatabaseWithNewCache(database.NewMemoryDBManager(), config)
+	dst := NewDatabaseWithNewCache(database.NewMemoryDBManager(), config)
+
+	key, val := common.MakeRandomBytes(32), common.MakeRandomBytes(128)
+	src.trieNodeCache.Set(key, val)
+	assert.NoError(t, src.ExportTrieNodeCache(dirName, runtime.NumCPU()))
+	assert.NoError(t, dst.ImportTrieNodeCache(dirName))
+	assert.Equal(t, val, dst.trieNodeCache.Get(key))
+
+	// Only one saving can run at a time.
+	src.savingTrieNodeCacheTriggered.Store(true)
+	assert.ErrorIs(t, src.ExportTrieNodeCache(dirName, 1), errSavingTrieNodeCacheInProgress)
+	src.savingTrieNodeCacheTriggered.Store(false)
+
+	// The trie node cache must be enabled.
+	noCache := NewDatabase(database.NewMemoryDBManager())
+	assert.ErrorIs(t, noCache.ExportTrieNodeCache(dirName, 1), errNoLocalTrieNodeCache)
+	assert.ErrorIs(t, noCache.ImportTrieNodeCache(dirName), errNoLocalTrieNodeCache)
+}
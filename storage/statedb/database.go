@@ -26,8 +26,10 @@ import (
 	"io"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/VictoriaMetrics/fastcache"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/rlp"
@@ -110,7 +112,7 @@ type Database struct {
 
 	trieNodeCache                TrieNodeCache        // GC friendly memory cache of trie node RLPs
 	trieNodeCacheConfig          *TrieNodeCacheConfig // Configuration of trieNodeCache
-	savingTrieNodeCacheTriggered bool                 // Whether saving trie node cache has been triggered or not
+	savingTrieNodeCacheTriggered atomic.Bool          // Whether saving trie node cache has been triggered or not
 }
 
 // rawNode is a simple binary blob used to differentiate between collapsed trie
@@ -1104,7 +1106,7 @@ func (db *Database) CanSaveTrieNodeCacheToFile() error {
 	if db.trieNodeCache == nil {
 		return errDisabledTrieNodeCache
 	}
-	if db.savingTrieNodeCacheTriggered {
+	if db.savingTrieNodeCacheTriggered.Load() {
 		return errSavingTrieNodeCacheInProgress
 	}
 	return nil
@@ -1112,7 +1114,12 @@ func (db *Database) CanSaveTrieNodeCacheToFile() error {
 
 // SaveTrieNodeCacheToFile saves the current cached trie nodes to file to reuse when the node restarts
 func (db *Database) SaveTrieNodeCacheToFile(filePath string, concurrency int) {
-	db.savingTrieNodeCacheTriggered = true
+	if !db.savingTrieNodeCacheTriggered.CompareAndSwap(false, true) {
+		logger.Warn("failed to save cache to file", "filePath", filePath, "err", errSavingTrieNodeCacheInProgress)
+		return
+	}
+	defer db.savingTrieNodeCacheTriggered.Store(false)
+
 	start := time.Now()
 	logger.Info("start saving cache to file",
 		"filePath", filePath, "concurrency", concurrency)
@@ -1123,7 +1130,60 @@ func (db *Database) SaveTrieNodeCacheToFile(filePath string, concurrency int) {
 		logger.Info("successfully saved cache to file",
 			"filePath", filePath, "elapsed", time.Since(start))
 	}
-	db.savingTrieNodeCacheTriggered = false
+}
+
+var errNoLocalTrieNodeCache = errors.New("local trie node cache is disabled, nothing to export or import")
+
+// localTrieNodeCache returns the local trie node cache, which is the fastcache
+// itself or the local part of the hybrid cache.
+func (db *Database) localTrieNodeCache() (*FastCache, error) {
+	switch cache := db.trieNodeCache.(type) {
+	case *FastCache:
+		return cache, nil
+	case *HybridCache:
+		if local, ok := cache.Local().(*FastCache); ok && local != nil {
+			return local, nil
+		}
+	}
+	return nil, errNoLocalTrieNodeCache
+}
+
+// ExportTrieNodeCache saves the local trie node cache to the given path. Unlike
+// SaveTrieNodeCacheToFile, it returns after the cache is saved, so that the file
+// can be copied to other nodes to warm up their caches.
+func (db *Database) ExportTrieNodeCache(filePath string, concurrency int) error {
+	cache, err := db.localTrieNodeCache()
+	if err != nil {
+		return err
+	}
+	if !db.savingTrieNodeCacheTriggered.CompareAndSwap(false, true) {
+		return errSavingTrieNodeCacheInProgress
+	}
+	defer db.savingTrieNodeCacheTriggered.Store(false)
+
+	start := time.Now()
+	if err := cache.SaveToFile(filePath, concurrency); err != nil {
+		return err
+	}
+	logger.Info("Exported trie node cache", "filePath", filePath, "elapsed", time.Since(start))
+	return nil
+}
+
+// ImportTrieNodeCache replaces the local trie node cache with the one exported
+// to the given path by ExportTrieNodeCache.
+func (db *Database) ImportTrieNodeCache(filePath string) error {
+	cache, err := db.localTrieNodeCache()
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	if err := cache.LoadFromFile(filePath); err != nil {
+		return err
+	}
+	stats := cache.UpdateStats().(fastcache.Stats)
+	logger.Info("Imported trie node cache", "filePath", filePath,
+		"entries", stats.EntriesCount, "elapsed", time.Since(start))
+	return nil
 }
 
 // DumpPeriodically atomically saves fast cache data to the given dir with the specified interval.
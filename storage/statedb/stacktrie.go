@@ -31,18 +31,29 @@ import (
 
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/rlp"
-	"github.com/klaytn/klaytn/storage/database"
 )
 
 var ErrCommitDisabled = errors.New("no database for committing")
 
+// TrieNodeWriter is the destination of the trie nodes committed by a StackTrie.
+type TrieNodeWriter interface {
+	WriteTrieNode(hash common.ExtHash, node []byte)
+}
+
+// TrieNodePathWriter is an optional interface of the database given to the
+// StackTrie. If the database implements it, the committed trie nodes are written
+// along with their paths, the hex-encoded key prefixes from the root to the nodes.
+type TrieNodePathWriter interface {
+	WriteTrieNodeWithPath(path []byte, hash common.ExtHash, node []byte)
+}
+
 var stPool = sync.Pool{
 	New: func() interface{} {
 		return NewStackTrie(nil)
 	},
 }
 
-func stackTrieFromPool(db database.DBManager) *StackTrie {
+func stackTrieFromPool(db TrieNodeWriter) *StackTrie {
 	st := stPool.Get().(*StackTrie)
 	st.db = db
 	return st
@@ -57,16 +68,16 @@ func returnToPool(st *StackTrie) {
 // in order. Once it determines that a subtree will no longer be inserted
 // into, it will hash it and free up the memory it uses.
 type StackTrie struct {
-	nodeType  uint8              // node type (as in branch, ext, leaf)
-	val       []byte             // value contained by this node if it's a leaf
-	key       []byte             // key chunk covered by this (full|ext) node
-	keyOffset int                // offset of the key chunk inside a full key
-	children  [16]*StackTrie     // list of children (for fullnodes and exts)
-	db        database.DBManager // Pointer to the commit db, can be nil
+	nodeType  uint8          // node type (as in branch, ext, leaf)
+	val       []byte         // value contained by this node if it's a leaf
+	key       []byte         // key chunk covered by this (full|ext) node
+	keyOffset int            // offset of the key chunk inside a full key
+	children  [16]*StackTrie // list of children (for fullnodes and exts)
+	db        TrieNodeWriter // Pointer to the commit db, can be nil
 }
 
 // NewStackTrie allocates and initializes an empty trie.
-func NewStackTrie(db database.DBManager) *StackTrie {
+func NewStackTrie(db TrieNodeWriter) *StackTrie {
 	return &StackTrie{
 		nodeType: emptyNode,
 		db:       db,
@@ -74,7 +85,7 @@ func NewStackTrie(db database.DBManager) *StackTrie {
 }
 
 // NewFromBinary initialises a serialized stacktrie with the given db.
-func NewFromBinary(data []byte, db database.DBManager) (*StackTrie, error) {
+func NewFromBinary(data []byte, db TrieNodeWriter) (*StackTrie, error) {
 	var st StackTrie
 	if err := st.UnmarshalBinary(data); err != nil {
 		return nil, err
@@ -154,7 +165,7 @@ func (st *StackTrie) unmarshalBinary(r io.Reader) error {
 	return nil
 }
 
-func (st *StackTrie) setDb(db database.DBManager) {
+func (st *StackTrie) setDb(db TrieNodeWriter) {
 	st.db = db
 	for _, child := range st.children {
 		if child != nil {
@@ -163,7 +174,7 @@ func (st *StackTrie) setDb(db database.DBManager) {
 	}
 }
 
-func newLeaf(ko int, key, val []byte, db database.DBManager) *StackTrie {
+func newLeaf(ko int, key, val []byte, db TrieNodeWriter) *StackTrie {
 	st := stackTrieFromPool(db)
 	st.nodeType = leafNode
 	st.keyOffset = ko
@@ -172,7 +183,7 @@ func newLeaf(ko int, key, val []byte, db database.DBManager) *StackTrie {
 	return st
 }
 
-func newExt(ko int, key []byte, child *StackTrie, db database.DBManager) *StackTrie {
+func newExt(ko int, key []byte, child *StackTrie, db TrieNodeWriter) *StackTrie {
 	st := stackTrieFromPool(db)
 	st.nodeType = extNode
 	st.keyOffset = ko
@@ -237,7 +248,7 @@ func (st *StackTrie) insert(key, value []byte) {
 		for i := idx - 1; i >= 0; i-- {
 			if st.children[i] != nil {
 				if st.children[i].nodeType != hashedNode {
-					st.children[i].hash()
+					st.children[i].hash(append(key[:st.keyOffset:st.keyOffset], byte(i)))
 				}
 				break
 			}
@@ -276,7 +287,7 @@ func (st *StackTrie) insert(key, value []byte) {
 			n = st.children[0]
 		}
 		// Convert to hash
-		n.hash()
+		n.hash(append(key[:st.keyOffset:st.keyOffset], st.key[:diffidx+1]...))
 		var p *StackTrie
 		if diffidx == 0 {
 			// the break is on the first byte, so
@@ -343,7 +354,7 @@ func (st *StackTrie) insert(key, value []byte) {
 		// free up some memory.
 		origIdx := st.key[diffidx]
 		p.children[origIdx] = newLeaf(diffidx+1, st.key, st.val, st.db)
-		p.children[origIdx].hash()
+		p.children[origIdx].hash(append(key[:st.keyOffset:st.keyOffset], st.key[:diffidx+1]...))
 
 		newIdx := key[diffidx+st.keyOffset]
 		p.children[newIdx] = newLeaf(p.keyOffset+1, key, value, st.db)
@@ -376,7 +387,10 @@ func (st *StackTrie) insert(key, value []byte) {
 // This method will also:
 // set 'st.type' to hashedNode
 // clear 'st.key'
-func (st *StackTrie) hash() {
+//
+// The path is the hex-encoded key prefix from the root to the node, which is
+// handed to the database if it implements TrieNodePathWriter.
+func (st *StackTrie) hash(path []byte) {
 	/* Shortcut if node is already hashed */
 	if st.nodeType == hashedNode {
 		return
@@ -394,7 +408,7 @@ func (st *StackTrie) hash() {
 				nodes[i] = nilValueNode
 				continue
 			}
-			child.hash()
+			child.hash(append(path[:len(path):len(path)], byte(i)))
 			if len(child.val) < 32 {
 				nodes[i] = rawNode(child.val)
 			} else {
@@ -411,7 +425,7 @@ func (st *StackTrie) hash() {
 			panic(err)
 		}
 	case extNode:
-		st.children[0].hash()
+		st.children[0].hash(append(path[:len(path):len(path)], st.key...))
 		h = newHasher(nil)
 		defer returnHasherToPool(h)
 		h.tmp.Reset()
@@ -464,13 +478,23 @@ func (st *StackTrie) hash() {
 	h.sha.Write(h.tmp)
 	h.sha.Read(st.val)
 	if st.db != nil {
-		st.db.WriteTrieNode(common.BytesToExtHash(st.val), h.tmp)
+		st.writeNode(path, common.BytesToExtHash(st.val), h.tmp)
+	}
+}
+
+// writeNode writes the trie node into the database, along with its path if the
+// database wants it.
+func (st *StackTrie) writeNode(path []byte, hash common.ExtHash, node []byte) {
+	if w, ok := st.db.(TrieNodePathWriter); ok {
+		w.WriteTrieNodeWithPath(path, hash, node)
+		return
 	}
+	st.db.WriteTrieNode(hash, node)
 }
 
 // Hash returns the hash of the current node
 func (st *StackTrie) Hash() (h common.Hash) {
-	st.hash()
+	st.hash(nil)
 	if len(st.val) != 32 {
 		// If the node's RLP isn't 32 bytes long, the node will not
 		// be hashed, and instead contain the  rlp-encoding of the
@@ -497,7 +521,7 @@ func (st *StackTrie) Commit() (common.Hash, error) {
 	if st.db == nil {
 		return common.Hash{}, ErrCommitDisabled
 	}
-	st.hash()
+	st.hash(nil)
 	if len(st.val) != 32 {
 		// If the node's RLP isn't 32 bytes long, the node will not
 		// be hashed (and committed), and instead contain the  rlp-encoding of the
@@ -508,7 +532,7 @@ func (st *StackTrie) Commit() (common.Hash, error) {
 		h.sha.Reset()
 		h.sha.Write(st.val)
 		h.sha.Read(ret)
-		st.db.WriteTrieNode(common.BytesToExtHash(ret), st.val)
+		st.writeNode(nil, common.BytesToExtHash(ret), st.val)
 		return common.BytesToHash(ret), nil
 	}
 	return common.BytesToHash(st.val), nil
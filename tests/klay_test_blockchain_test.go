@@ -141,6 +141,8 @@ func NewBCData(maxAccounts, numValidators int) (*BCData, error) {
 }
 
 func (bcdata *BCData) Shutdown() {
+	// Stop the consensus core before closing the database it writes to.
+	bcdata.engine.Stop()
 	bcdata.bc.Stop()
 
 	bcdata.db.Close()
@@ -20,6 +20,7 @@ import (
 	params "github.com/klaytn/klaytn/params"
 	rlp "github.com/klaytn/klaytn/rlp"
 	snapshot "github.com/klaytn/klaytn/snapshot"
+	database "github.com/klaytn/klaytn/storage/database"
 )
 
 // MockBlockChain is a mock of BlockChain interface.
@@ -61,6 +62,21 @@ func (mr *MockBlockChainMockRecorder) ApplyTransaction(arg0, arg1, arg2, arg3, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyTransaction", reflect.TypeOf((*MockBlockChain)(nil).ApplyTransaction), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
 }
 
+// Backup mocks base method.
+func (m *MockBlockChain) Backup(arg0 string) (*database.BackupManifest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Backup", arg0)
+	ret0, _ := ret[0].(*database.BackupManifest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Backup indicates an expected call of Backup.
+func (mr *MockBlockChainMockRecorder) Backup(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Backup", reflect.TypeOf((*MockBlockChain)(nil).Backup), arg0)
+}
+
 // BadBlocks mocks base method.
 func (m *MockBlockChain) BadBlocks() ([]blockchain.BadBlockArgs, error) {
 	m.ctrl.T.Helper()
@@ -240,6 +256,20 @@ func (mr *MockBlockChainMockRecorder) ExportN(arg0, arg1, arg2 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportN", reflect.TypeOf((*MockBlockChain)(nil).ExportN), arg0, arg1, arg2)
 }
 
+// ExportTrieNodeCache mocks base method.
+func (m *MockBlockChain) ExportTrieNodeCache(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportTrieNodeCache", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportTrieNodeCache indicates an expected call of ExportTrieNodeCache.
+func (mr *MockBlockChainMockRecorder) ExportTrieNodeCache(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportTrieNodeCache", reflect.TypeOf((*MockBlockChain)(nil).ExportTrieNodeCache), arg0)
+}
+
 // FastSyncCommitHead mocks base method.
 func (m *MockBlockChain) FastSyncCommitHead(arg0 common.Hash) error {
 	m.ctrl.T.Helper()
@@ -577,6 +607,35 @@ func (mr *MockBlockChainMockRecorder) HasHeader(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasHeader", reflect.TypeOf((*MockBlockChain)(nil).HasHeader), arg0, arg1)
 }
 
+// HistoricalStateAt mocks base method.
+func (m *MockBlockChain) HistoricalStateAt(arg0 *types.Header) (*state.StateDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HistoricalStateAt", arg0)
+	ret0, _ := ret[0].(*state.StateDB)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HistoricalStateAt indicates an expected call of HistoricalStateAt.
+func (mr *MockBlockChainMockRecorder) HistoricalStateAt(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HistoricalStateAt", reflect.TypeOf((*MockBlockChain)(nil).HistoricalStateAt), arg0)
+}
+
+// ImportTrieNodeCache mocks base method.
+func (m *MockBlockChain) ImportTrieNodeCache(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportTrieNodeCache", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportTrieNodeCache indicates an expected call of ImportTrieNodeCache.
+func (mr *MockBlockChainMockRecorder) ImportTrieNodeCache(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportTrieNodeCache", reflect.TypeOf((*MockBlockChain)(nil).ImportTrieNodeCache), arg0)
+}
+
 // InsertChain mocks base method.
 func (m *MockBlockChain) InsertChain(arg0 types.Blocks) (int, error) {
 	m.ctrl.T.Helper()
@@ -215,6 +215,20 @@ func (mr *MockTxPoolMockRecorder) StopSpamThrottler() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopSpamThrottler", reflect.TypeOf((*MockTxPool)(nil).StopSpamThrottler))
 }
 
+// SubscribeDroppedTxsEvent mocks base method.
+func (m *MockTxPool) SubscribeDroppedTxsEvent(arg0 chan<- blockchain.DroppedTxsEvent) event.Subscription {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeDroppedTxsEvent", arg0)
+	ret0, _ := ret[0].(event.Subscription)
+	return ret0
+}
+
+// SubscribeDroppedTxsEvent indicates an expected call of SubscribeDroppedTxsEvent.
+func (mr *MockTxPoolMockRecorder) SubscribeDroppedTxsEvent(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeDroppedTxsEvent", reflect.TypeOf((*MockTxPool)(nil).SubscribeDroppedTxsEvent), arg0)
+}
+
 // SubscribeNewTxsEvent mocks base method.
 func (m *MockTxPool) SubscribeNewTxsEvent(arg0 chan<- blockchain.NewTxsEvent) event.Subscription {
 	m.ctrl.T.Helper()
@@ -0,0 +1,89 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package work
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+)
+
+// TxOrderingPolicy is the policy ordering the transactions of the blocks built by the node.
+type TxOrderingPolicy string
+
+const (
+	// TxOrderingTime orders the transactions by the time they were first seen.
+	TxOrderingTime TxOrderingPolicy = "time"
+
+	// TxOrderingTip orders the transactions by the effective gas tip, and then by
+	// the time they were first seen.
+	TxOrderingTip TxOrderingPolicy = "tip"
+
+	// TxOrderingHybrid orders the transactions like TxOrderingTip, but an account
+	// yields to the other accounts once SenderCap transactions of it are included.
+	TxOrderingHybrid TxOrderingPolicy = "hybrid"
+
+	// DefaultTxOrderingSenderCap is the default number of the transactions of an
+	// account included in a row with TxOrderingHybrid.
+	DefaultTxOrderingSenderCap = 16
+)
+
+// TxOrderingConfig is the configuration of the transaction ordering of the block building.
+type TxOrderingConfig struct {
+	Policy    TxOrderingPolicy
+	SenderCap int // Only for TxOrderingHybrid
+}
+
+// DefaultTxOrderingConfig keeps the transactions in the order they were first seen.
+var DefaultTxOrderingConfig = TxOrderingConfig{
+	Policy:    TxOrderingTime,
+	SenderCap: DefaultTxOrderingSenderCap,
+}
+
+// ParseTxOrderingPolicy returns the policy of the given name.
+func ParseTxOrderingPolicy(name string) (TxOrderingPolicy, error) {
+	switch policy := TxOrderingPolicy(name); policy {
+	case TxOrderingTime, TxOrderingTip, TxOrderingHybrid:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown transaction ordering policy %q, must be one of %q, %q and %q",
+			name, TxOrderingTime, TxOrderingTip, TxOrderingHybrid)
+	}
+}
+
+// NewTransactionSet returns the set of the given pending transactions ordered by
+// the policy. baseFee is the base fee of the block to be built, nil before the
+// Magma hardfork.
+//
+// Note, the input map is reowned so the caller should not interact any more with
+// if after providing it.
+func (c *TxOrderingConfig) NewTransactionSet(signer types.Signer, pending map[common.Address]types.Transactions, baseFee *big.Int) types.TransactionSet {
+	switch c.Policy {
+	case TxOrderingTip:
+		return types.NewTransactionsByTipAndNonce(signer, pending, baseFee)
+	case TxOrderingHybrid:
+		senderCap := c.SenderCap
+		if senderCap <= 0 {
+			senderCap = DefaultTxOrderingSenderCap
+		}
+		return types.NewTransactionsByTipAndNonceWithSenderCap(signer, pending, baseFee, senderCap)
+	default:
+		return types.NewTransactionsByTimeAndNonce(signer, pending)
+	}
+}
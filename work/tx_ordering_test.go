@@ -0,0 +1,48 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package work
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTxOrderingPolicy(t *testing.T) {
+	for _, name := range []string{"time", "tip", "hybrid"} {
+		policy, err := ParseTxOrderingPolicy(name)
+		assert.NoError(t, err)
+		assert.Equal(t, TxOrderingPolicy(name), policy)
+	}
+	_, err := ParseTxOrderingPolicy("price")
+	assert.Error(t, err)
+}
+
+func TestTxOrderingConfig_NewTransactionSet(t *testing.T) {
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	newSet := func(c TxOrderingConfig) types.TransactionSet {
+		return c.NewTransactionSet(signer, make(map[common.Address]types.Transactions), big.NewInt(25))
+	}
+
+	assert.IsType(t, &types.TransactionsByTimeAndNonce{}, newSet(DefaultTxOrderingConfig))
+	assert.IsType(t, &types.TransactionsByTimeAndNonce{}, newSet(TxOrderingConfig{}))
+	assert.IsType(t, &types.TransactionsByTipAndNonce{}, newSet(TxOrderingConfig{Policy: TxOrderingTip}))
+	assert.IsType(t, &types.TransactionsByTipAndNonce{}, newSet(TxOrderingConfig{Policy: TxOrderingHybrid}))
+}
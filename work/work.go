@@ -61,6 +61,10 @@ type TxPool interface {
 	// NewTxsEvent and send events to the given channel.
 	SubscribeNewTxsEvent(chan<- blockchain.NewTxsEvent) event.Subscription
 
+	// SubscribeDroppedTxsEvent should return an event subscription of
+	// DroppedTxsEvent and send events to the given channel.
+	SubscribeDroppedTxsEvent(chan<- blockchain.DroppedTxsEvent) event.Subscription
+
 	GetPendingNonce(addr common.Address) uint64
 	AddLocal(tx *types.Transaction) error
 	GasPrice() *big.Int
@@ -97,12 +101,12 @@ type Miner struct {
 	shouldStart int32 // should start indicates whether we should start after sync
 }
 
-func New(backend Backend, config *params.ChainConfig, mux *event.TypeMux, engine consensus.Engine, nodetype common.ConnType, rewardbase common.Address, TxResendUseLegacy bool) *Miner {
+func New(backend Backend, config *params.ChainConfig, mux *event.TypeMux, engine consensus.Engine, nodetype common.ConnType, rewardbase common.Address, TxResendUseLegacy bool, txOrdering TxOrderingConfig) *Miner {
 	miner := &Miner{
 		backend:  backend,
 		mux:      mux,
 		engine:   engine,
-		worker:   newWorker(config, engine, rewardbase, backend, mux, nodetype, TxResendUseLegacy),
+		worker:   newWorker(config, engine, rewardbase, backend, mux, nodetype, TxResendUseLegacy, txOrdering),
 		canStart: 1,
 	}
 	// TODO-Kaia drop or missing tx
@@ -276,6 +280,7 @@ type BlockChain interface {
 	PrunableStateAt(root common.Hash, num uint64) (*state.StateDB, error)
 	StateAtWithPersistent(root common.Hash) (*state.StateDB, error)
 	StateAtWithGCLock(root common.Hash) (*state.StateDB, error)
+	HistoricalStateAt(header *types.Header) (*state.StateDB, error)
 	Export(w io.Writer) error
 	ExportN(w io.Writer, first, last uint64) error
 	Engine() consensus.Engine
@@ -309,6 +314,11 @@ type BlockChain interface {
 
 	// Save trie node cache to this
 	SaveTrieNodeCacheToDisk() error
+	ExportTrieNodeCache(filePath string) error
+	ImportTrieNodeCache(filePath string) error
+
+	// Backup
+	Backup(dir string) (*database.BackupManifest, error)
 
 	// KES
 	BlockSubscriptionLoop(pool *blockchain.TxPool)